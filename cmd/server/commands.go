@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/audit"
+	"github.com/novintriantonius/cosmos-validator-service/internal/config"
+	"github.com/novintriantonius/cosmos-validator-service/internal/database"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tasks"
+)
+
+// runCommand executes a CLI subcommand and returns the process exit code
+func runCommand(cfg *config.Config, args []string) int {
+	switch {
+	case len(args) == 2 && args[0] == "config" && args[1] == "print":
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to print configuration: %v\n", err)
+			return 1
+		}
+		return 0
+	case len(args) >= 2 && args[0] == "validators" && args[1] == "import":
+		return importValidators(cfg, args[2:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", strings.Join(args, " "))
+		fmt.Fprintln(os.Stderr, "Available commands:")
+		fmt.Fprintln(os.Stderr, "  config print         Print the effective configuration with secrets redacted")
+		fmt.Fprintln(os.Stderr, "  validators import    Import the validator set of the chain")
+		return 2
+	}
+}
+
+// importValidators imports the validator set of the chain into the database
+func importValidators(cfg *config.Config, args []string) int {
+	var filter tasks.ImportFilter
+	flags := flag.NewFlagSet("validators import", flag.ContinueOnError)
+	flags.StringVar(&filter.Status, "status", "", "bond status of the validators to import (default BOND_STATUS_BONDED)")
+	flags.Int64Var(&filter.MinVotingPower, "min-voting-power", 0, "voting power a validator needs to be tracked")
+	flags.IntVar(&filter.TopN, "top", 0, "only track the N validators with the most voting power (0 tracks all)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if validationErrors := filter.Validate(); len(validationErrors) > 0 {
+		fmt.Fprintf(os.Stderr, "Invalid filter: %s\n", strings.Join(validationErrors, "; "))
+		return 2
+	}
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	if err := database.RunMigrations(db); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to run database migrations: %v\n", err)
+		return 1
+	}
+
+	ctx := audit.WithActor(context.Background(), audit.Actor{Name: audit.SystemActor})
+	importer := tasks.NewValidatorImportTask(store.NewValidatorStore(db), newCosmosService(cfg))
+	summary, err := importer.Import(ctx, filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to import validators: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Listed %d validators, tracking %d: %d created, %d updated, %d unchanged, %d archived and skipped\n",
+		summary.Listed, summary.Tracked, summary.Created, summary.Updated, summary.Unchanged, summary.Archived)
+	return 0
+}
@@ -2,82 +2,225 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"database/sql"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
-	"time"
 
+	"github.com/novintriantonius/cosmos-validator-service/internal/address"
+	"github.com/novintriantonius/cosmos-validator-service/internal/alerts"
+	"github.com/novintriantonius/cosmos-validator-service/internal/auth"
+	"github.com/novintriantonius/cosmos-validator-service/internal/bus"
+	"github.com/novintriantonius/cosmos-validator-service/internal/config"
 	"github.com/novintriantonius/cosmos-validator-service/internal/database"
+	"github.com/novintriantonius/cosmos-validator-service/internal/email"
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/health"
+	"github.com/novintriantonius/cosmos-validator-service/internal/logging"
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
+	"github.com/novintriantonius/cosmos-validator-service/internal/notify"
+	"github.com/novintriantonius/cosmos-validator-service/internal/outbox"
+	"github.com/novintriantonius/cosmos-validator-service/internal/reports"
 	"github.com/novintriantonius/cosmos-validator-service/internal/routes"
 	"github.com/novintriantonius/cosmos-validator-service/internal/scheduler"
 	"github.com/novintriantonius/cosmos-validator-service/internal/services"
 	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/novintriantonius/cosmos-validator-service/internal/stream"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tasks"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tracing"
+	"github.com/novintriantonius/cosmos-validator-service/internal/webhooks"
 )
 
-// Config holds application configuration
-type Config struct {
-	ServerPort int
-}
+func main() {
+	configPath := flag.String("config", os.Getenv(config.ConfigFileEnv), "path to a YAML or TOML configuration file")
+	flag.Parse()
 
-// NewConfig creates a new config with values from environment or defaults
-func NewConfig() *Config {
-	port := 8080
-	if portStr := os.Getenv("SERVER_PORT"); portStr != "" {
-		if p, err := strconv.Atoi(portStr); err == nil {
-			port = p
-		}
+	// Load and validate configuration
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
-	return &Config{
-		ServerPort: port,
+
+	// Run a subcommand instead of the server when one is given
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(cfg, flag.Args()))
 	}
-}
 
-func main() {
+	// Initialize structured logging
+	if _, err := logging.Setup(cfg.Logging); err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Std())
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
 	// Initialize database connection
-	dbConfig := database.NewConfig()
-	db, err := database.Connect(dbConfig)
+	db, err := openDatabase(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to connect to database", err)
 	}
 	defer db.Close()
 
 	// Run database migrations
 	if err := database.RunMigrations(db); err != nil {
-		log.Fatalf("Failed to run database migrations: %v", err)
+		fatal("Failed to run database migrations", err)
+	}
+
+	// Export connection pool statistics
+	if err := metrics.RegisterDBStats(db, cfg.Database.Name); err != nil {
+		fatal("Failed to register database metrics", err)
 	}
 
 	// Initialize stores with PostgreSQL
 	validatorStore := store.NewValidatorStore(db)
 	delegationStore := store.NewDelegationStore(db)
+	apiKeyStore := store.NewAPIKeyStore(db)
+	auditStore := store.NewAuditStore(db)
+	alertStore := store.NewAlertStore(db)
+	webhookStore := store.NewWebhookStore(db)
+	notificationStore := store.NewNotificationChannelStore(db)
+	emailDigestStore := store.NewEmailDigestStore(db)
+	busOutboxStore := store.NewBusOutboxStore(db)
+	eventOutboxStore := store.NewEventOutboxStore(db)
 	
 	// Initialize cosmos service
-	cosmosService := services.NewCosmosService()
+	cosmosService := newCosmosService(cfg)
+	
+	// Initialize webhook delivery
+	webhookWorker := newWebhookWorker(cfg, webhookStore)
+	
+	// Initialize chat notifications
+	notifier := notify.NewDispatcher(notificationStore, notify.DispatcherConfig{})
+	
+	// Initialize the live delegation change feed
+	delegationStream := stream.NewBroker(cfg.Stream.BufferSize)
+	
+	// Initialize message bus publishing
+	var busRelay *bus.Relay
+	var busDispatcher events.Publisher
+	if cfg.Bus.Driver != "none" {
+		busPublisher, err := newBusPublisher(cfg)
+		if err != nil {
+			fatal("Failed to set up message bus publishing", err)
+		}
+		defer busPublisher.Close()
+		busRelay = newBusRelay(cfg, busOutboxStore, busPublisher)
+		busDispatcher = bus.NewDispatcher(busOutboxStore, cfg.Bus.Topic, busRelay)
+	} else {
+		slog.Info("Message bus publishing is disabled")
+	}
+	
+	// Initialize the event outbox relay. The consumers that queue events
+	// durably and deduplicate them by ID come first, so that the live streams
+	// and chat notifications, which cannot take events back, only get events
+	// once those accepted them. Chat notifications are not retried.
+	consumers := events.Sequence{alerts.NewEvaluator(alertStore, validatorStore), webhooks.NewDispatcher(webhookStore)}
+	if busDispatcher != nil {
+		consumers = append(consumers, busDispatcher)
+	}
+	consumers = append(consumers, delegationStream, events.BestEffort{Name: "notifications", Publisher: notifier})
+	eventRelay := newEventRelay(cfg, eventOutboxStore, consumers)
+	
+	// Initialize email digests. Digests can be managed but are not sent while
+	// email is disabled.
+	var digestTask *tasks.EmailDigestTask
+	var digestSender routes.EmailDigestSender
+	if cfg.Email.Enabled {
+		digestTask = newEmailDigestTask(cfg, emailDigestStore, validatorStore, delegationStore)
+		digestSender = digestTask
+	} else {
+		slog.Info("Email digests are disabled")
+	}
+	
+	// Initialize health checks
+	healthChecker := health.NewChecker(db, cosmosService, validatorStore, cfg.Health)
+	
+	// Initialize API key authentication
+	var authenticator *auth.Authenticator
+	if cfg.Auth.Enabled {
+		authenticator = auth.NewAuthenticator(apiKeyStore, cfg.Auth.BootstrapKey)
+		if cfg.Auth.BootstrapKey != "" {
+			slog.Warn("Bootstrap API key is configured; unset AUTH_BOOTSTRAP_KEY once real API keys exist")
+		}
+	} else {
+		slog.Warn("API key authentication is disabled; every endpoint is open")
+	}
 	
 	// Set up router with all dependencies
-	router := routes.SetupRouter(validatorStore, delegationStore, cosmosService)
+	router := routes.SetupRouter(routes.Dependencies{
+		ValidatorStore:     validatorStore,
+		DelegationStore:    delegationStore,
+		CosmosService:      cosmosService,
+		HealthChecker:      healthChecker,
+		APIKeyStore:        apiKeyStore,
+		AuditStore:         auditStore,
+		AlertStore:         alertStore,
+		WebhookStore:       webhookStore,
+		WebhookTester:      webhookWorker,
+		NotificationStore:  notificationStore,
+		NotificationTester: notifier,
+		EmailDigestStore:   emailDigestStore,
+		EmailDigestSender:  digestSender,
+		DelegationStream:   delegationStream,
+		StreamHeartbeat:    cfg.Stream.HeartbeatInterval.Std(),
+		Authenticator:      authenticator,
+		Addresses:          address.NewValidator(cfg.Cosmos.AccountPrefix, cfg.Cosmos.ValidatorPrefix),
+		ValidatorImporter:  tasks.NewValidatorImportTask(validatorStore, cosmosService),
+	})
 	
 	// Initialize and setup scheduler with all tasks
-	sched := scheduler.SetupScheduler(validatorStore, delegationStore, cosmosService)
+	sched := scheduler.SetupScheduler(cfg.Scheduler, validatorStore, delegationStore, cosmosService,
+		eventRelay, digestTask)
 	
 	// Start the scheduler
 	sched.Start()
 	defer sched.Stop()
 	
+	// Start delivering webhooks until shutdown. Events are still queued while
+	// the worker is disabled.
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	defer stopWorker()
+	if cfg.Webhooks.Enabled {
+		go webhookWorker.Run(workerCtx)
+	} else {
+		slog.Warn("Webhook delivery worker is disabled; webhook deliveries stay queued")
+	}
+	go eventRelay.Run(workerCtx)
+	if busRelay != nil {
+		go busRelay.Run(workerCtx)
+	}
+	
 	// Create HTTP server
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%d", NewConfig().ServerPort),
-		Handler: router,
+		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout.Std(),
+		WriteTimeout: cfg.Server.WriteTimeout.Std(),
 	}
+	// End the open delegation streams, which would otherwise hold up shutdown
+	srv.RegisterOnShutdown(delegationStream.Close)
 	
 	// Start the server in a goroutine
 	go func() {
-		log.Printf("Starting server on %s", srv.Addr)
+		slog.Info("Starting server", "addr", srv.Addr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server error: %v", err)
+			fatal("Server error", err)
 		}
 	}()
 	
@@ -86,16 +229,128 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
+	stopWorker()
 	
 	// Create a deadline to wait for
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Std())
 	defer cancel()
 	
 	// Doesn't block if no connections, but will otherwise wait until the timeout
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal("Server forced to shutdown", err)
 	}
 	
-	log.Println("Server exited gracefully")
+	slog.Info("Server exited gracefully")
+}
+
+// openDatabase connects to the configured database
+func openDatabase(cfg *config.Config) (*sql.DB, error) {
+	return database.Connect(&database.Config{
+		Host:            cfg.Database.Host,
+		Port:            cfg.Database.Port,
+		User:            cfg.Database.User,
+		Password:        cfg.Database.Password,
+		DBName:          cfg.Database.Name,
+		SSLMode:         cfg.Database.SSLMode,
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime.Std(),
+	})
+}
+
+// newCosmosService creates the Cosmos API client from the configuration
+func newCosmosService(cfg *config.Config) *services.CosmosService {
+	return services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{
+		BaseURL:    cfg.Cosmos.BaseURL,
+		MaxRetries: cfg.Cosmos.MaxRetries,
+		RetryDelay: cfg.Cosmos.RetryDelay.Std(),
+		Timeout:    cfg.Cosmos.Timeout.Std(),
+	})
+}
+
+// newWebhookWorker creates the webhook delivery worker from the configuration
+func newWebhookWorker(cfg *config.Config, webhookStore store.WebhookStore) *webhooks.Worker {
+	return webhooks.NewWorker(webhookStore, webhooks.WorkerConfig{
+		PollInterval:   cfg.Webhooks.PollInterval.Std(),
+		BatchSize:      cfg.Webhooks.BatchSize,
+		Timeout:        cfg.Webhooks.Timeout.Std(),
+		MaxAttempts:    cfg.Webhooks.MaxAttempts,
+		InitialBackoff: cfg.Webhooks.InitialBackoff.Std(),
+		MaxBackoff:     cfg.Webhooks.MaxBackoff.Std(),
+	})
+}
+
+// newBusPublisher creates the message bus publisher of the configured driver
+func newBusPublisher(cfg *config.Config) (bus.Publisher, error) {
+	switch cfg.Bus.Driver {
+	case "nats":
+		return bus.NewNATSPublisher(bus.NATSConfig{
+			URL:      cfg.Bus.NATSURL,
+			Token:    cfg.Bus.NATSToken,
+			Username: cfg.Bus.NATSUsername,
+			Password: cfg.Bus.NATSPassword,
+			Timeout:  cfg.Bus.Timeout.Std(),
+		})
+	case "kafka":
+		kafkaConfig := bus.KafkaConfig{
+			Brokers:       cfg.Bus.KafkaBrokerList(),
+			Username:      cfg.Bus.KafkaUsername,
+			Password:      cfg.Bus.KafkaPassword,
+			SASLMechanism: cfg.Bus.KafkaSASLMechanism,
+			Timeout:       cfg.Bus.Timeout.Std(),
+		}
+		if cfg.Bus.KafkaTLS {
+			kafkaConfig.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		return bus.NewKafkaPublisher(kafkaConfig)
+	default:
+		return nil, fmt.Errorf("unknown message bus driver %q", cfg.Bus.Driver)
+	}
+}
+
+// newBusRelay creates the message bus outbox relay from the configuration
+func newBusRelay(cfg *config.Config, outboxStore store.BusOutboxStore, publisher bus.Publisher) *bus.Relay {
+	return bus.NewRelay(outboxStore, publisher, bus.RelayConfig{
+		PollInterval:   cfg.Bus.PollInterval.Std(),
+		BatchSize:      cfg.Bus.BatchSize,
+		Timeout:        cfg.Bus.Timeout.Std(),
+		InitialBackoff: cfg.Bus.InitialBackoff.Std(),
+		MaxBackoff:     cfg.Bus.MaxBackoff.Std(),
+		Retention:      cfg.Bus.Retention.Std(),
+	})
+}
+
+// newEventRelay creates the event outbox relay from the configuration
+func newEventRelay(cfg *config.Config, outboxStore store.EventOutboxStore, consumers events.Publisher) *outbox.Relay {
+	return outbox.NewRelay(outboxStore, consumers, outbox.RelayConfig{
+		PollInterval:   cfg.Outbox.PollInterval.Std(),
+		BatchSize:      cfg.Outbox.BatchSize,
+		Timeout:        cfg.Outbox.Timeout.Std(),
+		InitialBackoff: cfg.Outbox.InitialBackoff.Std(),
+		MaxBackoff:     cfg.Outbox.MaxBackoff.Std(),
+		Retention:      cfg.Outbox.Retention.Std(),
+	})
+}
+
+// newEmailDigestTask creates the email digest task from the configuration
+func newEmailDigestTask(cfg *config.Config, digestStore store.EmailDigestStore, validatorStore store.ValidatorStore,
+	delegationStore store.DelegationStore) *tasks.EmailDigestTask {
+	sender := email.NewSMTPSender(email.SMTPConfig{
+		Host:     cfg.Email.SMTPHost,
+		Port:     cfg.Email.SMTPPort,
+		Username: cfg.Email.SMTPUsername,
+		Password: cfg.Email.SMTPPassword,
+		From:     cfg.Email.From,
+		TLS:      cfg.Email.SMTPTLS,
+		Timeout:  cfg.Email.Timeout.Std(),
+	})
+	generator := reports.NewGenerator(validatorStore, delegationStore, cfg.Email.TopMovers)
+	return tasks.NewEmailDigestTask(digestStore, generator, sender)
+}
+
+// fatal logs the error and exits the process
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 } 
\ No newline at end of file
@@ -0,0 +1,70 @@
+package address
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultAccountPrefix is the bech32 prefix of Cosmos Hub account addresses
+	DefaultAccountPrefix = "cosmos"
+
+	// DefaultValidatorPrefix is the bech32 prefix of Cosmos Hub validator operator addresses
+	DefaultValidatorPrefix = "cosmosvaloper"
+
+	// maxAddressBytes is the longest address payload accepted by the Cosmos SDK
+	maxAddressBytes = 255
+)
+
+// Validator checks account and validator operator addresses of one chain
+type Validator struct {
+	accountPrefix   string
+	validatorPrefix string
+}
+
+// NewValidator creates a validator for the given bech32 prefixes
+func NewValidator(accountPrefix, validatorPrefix string) *Validator {
+	return &Validator{accountPrefix: accountPrefix, validatorPrefix: validatorPrefix}
+}
+
+// AccountPrefix returns the bech32 prefix of account addresses
+func (v *Validator) AccountPrefix() string {
+	return v.accountPrefix
+}
+
+// ValidatorPrefix returns the bech32 prefix of validator operator addresses
+func (v *Validator) ValidatorPrefix() string {
+	return v.validatorPrefix
+}
+
+// ValidateValidatorAddress checks that addr is a valid validator operator address
+func (v *Validator) ValidateValidatorAddress(addr string) error {
+	return validate(addr, v.validatorPrefix, "validator address")
+}
+
+// ValidateAccountAddress checks that addr is a valid account (delegator) address
+func (v *Validator) ValidateAccountAddress(addr string) error {
+	return validate(addr, v.accountPrefix, "delegator address")
+}
+
+// validate decodes addr and checks its prefix and payload length
+func validate(addr, prefix, kind string) error {
+	if addr == "" {
+		return fmt.Errorf("%s is required", kind)
+	}
+
+	hrp, data, err := Decode(addr)
+	if err != nil {
+		return fmt.Errorf("%s %q is not valid bech32: %v", kind, addr, err)
+	}
+	if hrp != prefix {
+		return fmt.Errorf("%s %q has prefix %q, expected %q", kind, addr, hrp, prefix)
+	}
+	if addr != strings.ToLower(addr) {
+		return fmt.Errorf("%s %q must be lower case", kind, addr)
+	}
+	if len(data) == 0 || len(data) > maxAddressBytes {
+		return fmt.Errorf("%s %q has an invalid length of %d bytes", kind, addr, len(data))
+	}
+	return nil
+}
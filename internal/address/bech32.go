@@ -0,0 +1,70 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+)
+
+// maxLength is the longest address accepted, matching the Cosmos SDK
+const maxLength = 1023
+
+// ErrInvalidChecksum is returned when the checksum of an address does not match its content
+var ErrInvalidChecksum = errors.New("invalid checksum")
+
+// Decode decodes a bech32 string into its human-readable part and data bytes.
+// Cosmos addresses are longer than the 90 characters BIP-173 allows for
+// Bitcoin, so strings up to maxLength are accepted. Checksums of the bech32m
+// variant are rejected.
+func Decode(s string) (string, []byte, error) {
+	if len(s) > maxLength {
+		return "", nil, fmt.Errorf("too long: %d characters, at most %d allowed", len(s), maxLength)
+	}
+
+	hrp, values, version, err := bech32.DecodeNoLimitWithVersion(s)
+	if err != nil {
+		return "", nil, decodeError(err)
+	}
+	if version != bech32.Version0 {
+		return "", nil, ErrInvalidChecksum
+	}
+
+	data, err := bech32.ConvertBits(values, 5, 8, false)
+	if err != nil {
+		return "", nil, errors.New("invalid data padding")
+	}
+	return hrp, data, nil
+}
+
+// decodeError describes a decoding error of the bech32 package in the terms
+// used by the API responses
+func decodeError(err error) error {
+	switch e := err.(type) {
+	case bech32.ErrInvalidChecksum:
+		return ErrInvalidChecksum
+	case bech32.ErrMixedCase:
+		return errors.New("mixes upper and lower case")
+	case bech32.ErrInvalidLength:
+		return errors.New("too short: missing data or checksum")
+	case bech32.ErrInvalidSeparatorIndex:
+		if e < 1 {
+			return errors.New("missing human-readable prefix or separator '1'")
+		}
+		return errors.New("too short: missing data or checksum")
+	case bech32.ErrInvalidCharacter:
+		return fmt.Errorf("invalid character %q", rune(e))
+	case bech32.ErrNonCharsetChar:
+		return fmt.Errorf("invalid character %q", rune(e))
+	}
+	return err
+}
+
+// Encode encodes data bytes as a bech32 string with the given human-readable part
+func Encode(hrp string, data []byte) (string, error) {
+	values, err := bech32.ConvertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return bech32.Encode(hrp, values)
+}
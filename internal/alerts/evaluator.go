@@ -0,0 +1,181 @@
+// Package alerts evaluates whale movement alert rules against delegation events
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+// stakePercentDecimals is the number of decimals of the stake percentage of an alert
+const stakePercentDecimals = 4
+
+// Evaluator matches delegation events against the alert rules and records the
+// alerts that fire
+type Evaluator struct {
+	alertStore     store.AlertStore
+	validatorStore store.ValidatorStore
+}
+
+// NewEvaluator creates a new alert rule evaluator
+func NewEvaluator(alertStore store.AlertStore, validatorStore store.ValidatorStore) *Evaluator {
+	return &Evaluator{
+		alertStore:     alertStore,
+		validatorStore: validatorStore,
+	}
+}
+
+// Publish implements events.Publisher by evaluating the alert rules against
+// the delegation changes, so that the event outbox relay evaluates every
+// stored change at least once. Evaluating a change again fires no alert
+// twice. Other events are ignored.
+func (e *Evaluator) Publish(ctx context.Context, evts []models.Event) error {
+	var validators []string
+	changes := make(map[string][]models.DelegationEvent)
+	for _, event := range evts {
+		if event.Type != models.EventDelegationChanged {
+			continue
+		}
+		var change models.DelegationEvent
+		if err := json.Unmarshal(event.Data, &change); err != nil {
+			return fmt.Errorf("error decoding delegation change %s: %v", event.ID, err)
+		}
+		if _, ok := changes[change.ValidatorAddress]; !ok {
+			validators = append(validators, change.ValidatorAddress)
+		}
+		changes[change.ValidatorAddress] = append(changes[change.ValidatorAddress], change)
+	}
+
+	for _, validatorAddress := range validators {
+		if _, err := e.Evaluate(ctx, validatorAddress, changes[validatorAddress]); err != nil {
+			return fmt.Errorf("error evaluating alert rules for validator %s: %v", validatorAddress, err)
+		}
+	}
+	return nil
+}
+
+// Evaluate matches the delegation events of a validator against the enabled
+// rules that apply to it and records the alerts that fire. It returns the new
+// alerts; alerts that already fired for an event are not returned again.
+//
+// Delta thresholds compare shares, which equal tokens in the base denomination
+// unless the validator has been slashed. Stake percentages are based on the
+// validator's tokens from its stored chain metadata; percentage thresholds
+// never fire while that metadata is unknown.
+func (e *Evaluator) Evaluate(ctx context.Context, validatorAddress string, events []models.DelegationEvent) ([]models.Alert, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	rules, err := e.alertStore.GetActiveRules(ctx, validatorAddress)
+	if err != nil {
+		return nil, fmt.Errorf("error getting alert rules: %v", err)
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	stake := e.validatorStake(ctx, validatorAddress)
+
+	var fired []models.Alert
+	for _, event := range events {
+		delta, ok := new(big.Rat).SetString(event.Delta)
+		if !ok {
+			slog.WarnContext(ctx, "Skipping delegation event with invalid delta", "event", event.ID, "delta", event.Delta)
+			continue
+		}
+		delta.Abs(delta)
+
+		var stakePercent *big.Rat
+		if stake != nil {
+			stakePercent = new(big.Rat).Mul(new(big.Rat).Quo(delta, stake), big.NewRat(100, 1))
+		}
+
+		for _, rule := range rules {
+			if !Matches(rule, delta, stakePercent) {
+				continue
+			}
+			ruleID := rule.ID
+			alert := models.Alert{
+				RuleID:            &ruleID,
+				RuleName:          rule.Name,
+				DelegationEventID: event.ID,
+				ValidatorAddress:  validatorAddress,
+				DelegatorAddress:  event.DelegatorAddress,
+				EventType:         event.Type,
+				Delta:             event.Delta,
+			}
+			if stakePercent != nil {
+				alert.StakePercent = stakePercent.FloatString(stakePercentDecimals)
+			}
+			fired = append(fired, alert)
+		}
+	}
+	if len(fired) == 0 {
+		return nil, nil
+	}
+
+	recorded, err := e.alertStore.RecordAlerts(ctx, fired)
+	if err != nil {
+		return nil, fmt.Errorf("error recording alerts: %v", err)
+	}
+
+	for _, alert := range recorded {
+		slog.WarnContext(ctx, "Whale movement alert",
+			"rule", alert.RuleName,
+			"validator", alert.ValidatorAddress,
+			"delegator", alert.DelegatorAddress,
+			"type", alert.EventType,
+			"delta", alert.Delta,
+			"stake_percent", alert.StakePercent,
+		)
+	}
+	metrics.AlertsFiredTotal.WithLabelValues(validatorAddress).Add(float64(len(recorded)))
+	return recorded, nil
+}
+
+// Matches reports whether an absolute delta meets every threshold of a rule.
+// stakePercent is nil when the validator's stake is unknown, in which case a
+// rule with a percentage threshold does not match.
+func Matches(rule models.AlertRule, delta, stakePercent *big.Rat) bool {
+	matched := false
+	if rule.MinDelta != "" {
+		minDelta, ok := new(big.Rat).SetString(rule.MinDelta)
+		if !ok || delta.Cmp(minDelta) < 0 {
+			return false
+		}
+		matched = true
+	}
+	if rule.MinStakePercent != "" {
+		minPercent, ok := new(big.Rat).SetString(rule.MinStakePercent)
+		if !ok || stakePercent == nil || stakePercent.Cmp(minPercent) < 0 {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+// validatorStake returns the tokens of a validator from its stored chain
+// metadata, or nil when they are unknown or zero
+func (e *Evaluator) validatorStake(ctx context.Context, validatorAddress string) *big.Rat {
+	validator, err := e.validatorStore.GetByAddress(ctx, validatorAddress)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get validator stake for alert rules", "validator", validatorAddress, "error", err)
+		return nil
+	}
+	if validator.Metadata == nil {
+		return nil
+	}
+	tokens, ok := new(big.Rat).SetString(validator.Metadata.Tokens)
+	if !ok || tokens.Sign() <= 0 {
+		return nil
+	}
+	return tokens
+}
@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"context"
+)
+
+const (
+	// AnonymousActor is recorded when a change is made without authentication
+	AnonymousActor = "anonymous"
+
+	// SystemActor is recorded for changes made by the service itself
+	SystemActor = "system"
+)
+
+// Actor identifies who made a change
+type Actor struct {
+	// Name is the API key name, or AnonymousActor / SystemActor
+	Name string
+	// KeyID is the ID of the API key used, zero when no stored key was used
+	KeyID int
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying the actor making changes
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored in ctx, or the anonymous actor
+func ActorFromContext(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok && actor.Name != "" {
+		return actor
+	}
+	return Actor{Name: AnonymousActor}
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+const (
+	// KeyPrefix starts every generated API key so that keys are easy to recognise
+	KeyPrefix = "cvs_"
+
+	// keyBytes is the amount of randomness in a generated API key
+	keyBytes = 32
+
+	// displayPrefixLength is how much of a key is stored in clear text to identify it
+	displayPrefixLength = 12
+)
+
+var (
+	// ErrMissingKey is returned when a request carries no API key
+	ErrMissingKey = errors.New("api key is required")
+
+	// ErrInvalidKey is returned when an API key is unknown or revoked
+	ErrInvalidKey = errors.New("api key is invalid or revoked")
+)
+
+// Principal identifies the caller of an authenticated request
+type Principal struct {
+	KeyID     int         `json:"keyId,omitempty"`
+	Name      string      `json:"name"`
+	Role      models.Role `json:"role"`
+	Bootstrap bool        `json:"bootstrap,omitempty"`
+}
+
+// KeyStore is the subset of the API key store used for authentication
+type KeyStore interface {
+	GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	TouchLastUsed(ctx context.Context, id int) error
+}
+
+// Authenticator resolves API keys to principals
+type Authenticator struct {
+	store         KeyStore
+	bootstrapHash []byte
+}
+
+// NewAuthenticator creates an authenticator backed by the key store. A
+// non-empty bootstrapKey is accepted as an admin key without a database
+// lookup, so the first real keys can be created through the API.
+func NewAuthenticator(keys KeyStore, bootstrapKey string) *Authenticator {
+	a := &Authenticator{store: keys}
+	if bootstrapKey != "" {
+		sum := sha256.Sum256([]byte(bootstrapKey))
+		a.bootstrapHash = sum[:]
+	}
+	return a
+}
+
+// Authenticate returns the principal owning the given API key
+func (a *Authenticator) Authenticate(ctx context.Context, key string) (*Principal, error) {
+	if key == "" {
+		return nil, ErrMissingKey
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	if a.bootstrapHash != nil && subtle.ConstantTimeCompare(sum[:], a.bootstrapHash) == 1 {
+		return &Principal{Name: "bootstrap", Role: models.RoleAdmin, Bootstrap: true}, nil
+	}
+
+	if a.store == nil {
+		return nil, ErrInvalidKey
+	}
+	apiKey, err := a.store.GetByHash(ctx, hex.EncodeToString(sum[:]))
+	if errors.Is(err, store.ErrAPIKeyNotFound) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error looking up api key: %v", err)
+	}
+
+	if err := a.store.TouchLastUsed(ctx, apiKey.ID); err != nil {
+		slog.WarnContext(ctx, "Failed to record API key usage", "key_id", apiKey.ID, "error", err)
+	}
+
+	return &Principal{KeyID: apiKey.ID, Name: apiKey.Name, Role: apiKey.Role}, nil
+}
+
+// GenerateKey creates a new random API key and returns the secret, the
+// clear-text prefix used to identify it and the hash to store
+func GenerateKey() (secret, prefix, hash string, err error) {
+	buf := make([]byte, keyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("error generating api key: %v", err)
+	}
+	secret = KeyPrefix + hex.EncodeToString(buf)
+	return secret, secret[:displayPrefixLength], HashKey(secret), nil
+}
+
+// HashKey returns the hex encoded SHA-256 hash of an API key
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated principal stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
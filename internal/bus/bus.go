@@ -0,0 +1,129 @@
+// Package bus publishes delegation changes to a message bus such as NATS or
+// Kafka. Changes are queued in an outbox table first and relayed from there,
+// so they are delivered at least once even while the bus is down.
+//
+// The bus outbox is filled from the event outbox rather than the bus reading
+// the event outbox itself: the event outbox relay stops at the first consumer
+// that fails, so publishing from there would hold back the live streams and
+// chat notifications of a validator for as long as the bus is down. Queuing
+// only fails with the database, and the bus retries on its own schedule.
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+// Publisher publishes outbox messages to a message bus
+type Publisher interface {
+	// Publish publishes messages in order. It returns nil only once the bus
+	// has accepted every message; the whole batch is retried otherwise, so
+	// messages may be published more than once.
+	Publish(ctx context.Context, messages []models.BusMessage) error
+
+	// Close releases the connections of the publisher
+	Close() error
+}
+
+// Dispatcher queues the delegation changes relayed from the event outbox
+// in the bus outbox
+type Dispatcher struct {
+	store store.BusOutboxStore
+	topic string
+	relay *Relay
+}
+
+// NewDispatcher creates a new dispatcher queuing changes for topic. When relay
+// is not nil, it is woken up to publish the changes right away.
+func NewDispatcher(outboxStore store.BusOutboxStore, topic string, relay *Relay) *Dispatcher {
+	return &Dispatcher{
+		store: outboxStore,
+		topic: topic,
+		relay: relay,
+	}
+}
+
+// Publish implements events.Publisher. Events other than delegation changes
+// are ignored.
+func (d *Dispatcher) Publish(ctx context.Context, events []models.Event) error {
+	var messages []models.BusMessage
+	for _, event := range events {
+		if event.Type != models.EventDelegationChanged {
+			continue
+		}
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("error encoding event %s: %v", event.ID, err)
+		}
+		messages = append(messages, models.BusMessage{
+			EventID: event.ID,
+			Topic:   d.topic,
+			Key:     event.ValidatorAddress,
+			Payload: payload,
+		})
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	queued, err := d.store.Enqueue(ctx, messages)
+	if err != nil {
+		return fmt.Errorf("error queuing bus messages: %v", err)
+	}
+	if queued > 0 {
+		slog.DebugContext(ctx, "Queued bus messages", "messages", queued)
+		if d.relay != nil {
+			d.relay.Wake()
+		}
+	}
+	return nil
+}
+
+// Memory is a Publisher keeping the published messages in memory, for tests
+// and local development
+type Memory struct {
+	mu       sync.Mutex
+	messages []models.BusMessage
+	err      error
+}
+
+// NewMemory creates a new in-memory publisher
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// Publish implements Publisher
+func (m *Memory) Publish(ctx context.Context, messages []models.BusMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, messages...)
+	return nil
+}
+
+// Close implements Publisher
+func (m *Memory) Close() error {
+	return nil
+}
+
+// Messages returns the messages published so far
+func (m *Memory) Messages() []models.BusMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.BusMessage(nil), m.messages...)
+}
+
+// SetError makes Publish fail with err, or succeed again when err is nil
+func (m *Memory) SetError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
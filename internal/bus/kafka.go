@@ -0,0 +1,157 @@
+package bus
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// KafkaEventIDHeader is the record header holding the event ID of a message,
+// which consumers deduplicate on
+const KafkaEventIDHeader = "event-id"
+
+// SASL mechanisms of the Kafka publisher
+const (
+	KafkaSASLPlain       = "PLAIN"
+	KafkaSASLSCRAMSHA256 = "SCRAM-SHA-256"
+	KafkaSASLSCRAMSHA512 = "SCRAM-SHA-512"
+)
+
+// KafkaConfig holds configuration for the Kafka publisher
+type KafkaConfig struct {
+	// Brokers are the host:port addresses the client discovers the cluster
+	// from
+	Brokers []string
+	// Username and Password authenticate with SASL when Username is set
+	Username string
+	Password string
+	// SASLMechanism is one of KafkaSASLPlain, the default, KafkaSASLSCRAMSHA256
+	// or KafkaSASLSCRAMSHA512
+	SASLMechanism string
+	// Timeout bounds dialing a broker
+	Timeout time.Duration
+	// TLSConfig is used for TLS connections when set
+	TLSConfig *tls.Config
+}
+
+// KafkaPublisher produces messages to Kafka with an idempotent producer that
+// waits for all in-sync replicas, so that records retried by the client are
+// written once and in order. Messages are keyed by validator address, so that
+// the changes of a validator land on one partition in order.
+type KafkaPublisher struct {
+	client *kgo.Client
+}
+
+// NewKafkaPublisher creates a new Kafka publisher. The client connects on the
+// first publish, so that the service starts while the brokers are down.
+func NewKafkaPublisher(config KafkaConfig) (*KafkaPublisher, error) {
+	if len(config.Brokers) == 0 {
+		return nil, fmt.Errorf("Kafka publisher needs at least one broker")
+	}
+	for _, broker := range config.Brokers {
+		if host, port, err := net.SplitHostPort(broker); err != nil || host == "" || port == "" {
+			return nil, fmt.Errorf("Kafka broker %q must be a host:port address", broker)
+		}
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	options := []kgo.Opt{
+		kgo.SeedBrokers(config.Brokers...),
+		kgo.ClientID("cosmos-validator-service"),
+		kgo.DialTimeout(config.Timeout),
+		// Idempotent writes are the default of the client and need the
+		// acknowledgement of all in-sync replicas
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		// The client retries failures, such as rejected credentials, until the
+		// publish context ends, so its warnings carry the cause
+		kgo.WithLogger(kafkaLogger{}),
+	}
+	if config.Username != "" {
+		mechanism, err := kafkaSASL(config)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, kgo.SASL(mechanism))
+	}
+	if config.TLSConfig != nil {
+		options = append(options, kgo.DialTLSConfig(config.TLSConfig))
+	}
+
+	client, err := kgo.NewClient(options...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Kafka client: %v", err)
+	}
+	return &KafkaPublisher{client: client}, nil
+}
+
+// Publish implements Publisher. It returns once every record is acknowledged,
+// or with the error of the first record that failed.
+func (p *KafkaPublisher) Publish(ctx context.Context, messages []models.BusMessage) error {
+	records := make([]*kgo.Record, len(messages))
+	for i, message := range messages {
+		records[i] = &kgo.Record{
+			Topic:   message.Topic,
+			Value:   message.Payload,
+			Headers: []kgo.RecordHeader{{Key: KafkaEventIDHeader, Value: []byte(message.EventID)}},
+		}
+		if message.Key != "" {
+			records[i].Key = []byte(message.Key)
+		}
+	}
+
+	for i, result := range p.client.ProduceSync(ctx, records...) {
+		if result.Err != nil {
+			return fmt.Errorf("error producing message %d to Kafka topic %s: %v", messages[i].ID, messages[i].Topic, result.Err)
+		}
+	}
+	return nil
+}
+
+// Close implements Publisher
+func (p *KafkaPublisher) Close() error {
+	p.client.Close()
+	return nil
+}
+
+// kafkaSASL returns the SASL mechanism of the configuration
+func kafkaSASL(config KafkaConfig) (sasl.Mechanism, error) {
+	switch config.SASLMechanism {
+	case "", KafkaSASLPlain:
+		return plain.Auth{User: config.Username, Pass: config.Password}.AsMechanism(), nil
+	case KafkaSASLSCRAMSHA256:
+		return scram.Auth{User: config.Username, Pass: config.Password}.AsSha256Mechanism(), nil
+	case KafkaSASLSCRAMSHA512:
+		return scram.Auth{User: config.Username, Pass: config.Password}.AsSha512Mechanism(), nil
+	default:
+		return nil, fmt.Errorf("Kafka SASL mechanism %q must be one of %s, %s, %s",
+			config.SASLMechanism, KafkaSASLPlain, KafkaSASLSCRAMSHA256, KafkaSASLSCRAMSHA512)
+	}
+}
+
+// kafkaLogger logs the warnings and errors of the Kafka client
+type kafkaLogger struct{}
+
+// Level implements kgo.Logger
+func (kafkaLogger) Level() kgo.LogLevel {
+	return kgo.LogLevelWarn
+}
+
+// Log implements kgo.Logger
+func (kafkaLogger) Log(level kgo.LogLevel, msg string, keyvals ...any) {
+	if level == kgo.LogLevelError {
+		slog.Error("Kafka client: "+msg, keyvals...)
+		return
+	}
+	slog.Warn("Kafka client: "+msg, keyvals...)
+}
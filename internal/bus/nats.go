@@ -0,0 +1,144 @@
+package bus
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+// NATSMessageIDHeader holds the event ID of a message. JetStream discards
+// messages with an ID the stream stored within its duplicate window.
+const NATSMessageIDHeader = jetstream.MsgIDHeader
+
+// NATSConfig holds configuration for the NATS publisher
+type NATSConfig struct {
+	// URL is the nats:// or tls:// URL of the server, optionally with a user
+	// and password
+	URL string
+	// Token authenticates with a server token instead of a user and password
+	Token string
+	// Username and Password take precedence over the URL credentials
+	Username string
+	Password string
+	// Timeout bounds connecting to the server
+	Timeout time.Duration
+	// TLSConfig is used for TLS connections
+	TLSConfig *tls.Config
+}
+
+// NATSPublisher publishes messages to a JetStream stream. Each message goes
+// to the subject "<topic>.<validator address>", which a stream must capture,
+// with its event ID in the Nats-Msg-Id header. A message is accepted once the
+// stream acknowledged storing it.
+type NATSPublisher struct {
+	config NATSConfig
+
+	mu   sync.Mutex
+	conn *nats.Conn
+	js   jetstream.JetStream
+}
+
+// NewNATSPublisher creates a new NATS publisher. It connects on the first
+// publish, so that the service starts while the server is down.
+func NewNATSPublisher(config NATSConfig) (*NATSPublisher, error) {
+	server, err := url.Parse(config.URL)
+	if err != nil || (server.Scheme != "nats" && server.Scheme != "tls") || server.Hostname() == "" {
+		return nil, fmt.Errorf("NATS URL %q must be a nats:// or tls:// URL", config.URL)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	return &NATSPublisher{config: config}, nil
+}
+
+// Publish implements Publisher
+func (p *NATSPublisher) Publish(ctx context.Context, messages []models.BusMessage) error {
+	js, err := p.jetStream()
+	if err != nil {
+		return fmt.Errorf("error connecting to NATS: %v", err)
+	}
+
+	for _, message := range messages {
+		msg := nats.NewMsg(messageSubject(message))
+		msg.Data = message.Payload
+		if _, err := js.PublishMsg(ctx, msg, jetstream.WithMsgID(message.EventID)); err != nil {
+			if errors.Is(err, jetstream.ErrNoStreamResponse) {
+				return fmt.Errorf("error publishing message %d to NATS: no JetStream stream captures subject %s", message.ID, msg.Subject)
+			}
+			return fmt.Errorf("error publishing message %d to NATS: %v", message.ID, err)
+		}
+	}
+	return nil
+}
+
+// Close implements Publisher
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Drain()
+	p.conn, p.js = nil, nil
+	return err
+}
+
+// jetStream returns the JetStream context, connecting first if needed. Once
+// connected, the client reconnects by itself.
+func (p *NATSPublisher) jetStream() (jetstream.JetStream, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.js != nil {
+		return p.js, nil
+	}
+
+	options := []nats.Option{
+		nats.Name("cosmos-validator-service"),
+		nats.Timeout(p.config.Timeout),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				slog.Warn("Disconnected from NATS", "error", err)
+			}
+		}),
+	}
+	if p.config.Token != "" {
+		options = append(options, nats.Token(p.config.Token))
+	}
+	if p.config.Username != "" {
+		options = append(options, nats.UserInfo(p.config.Username, p.config.Password))
+	}
+	if p.config.TLSConfig != nil {
+		options = append(options, nats.Secure(p.config.TLSConfig))
+	}
+
+	conn, err := nats.Connect(p.config.URL, options...)
+	if err != nil {
+		return nil, err
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	p.conn, p.js = conn, js
+	return js, nil
+}
+
+// messageSubject returns the NATS subject of a message: its topic followed by
+// its key when it has one
+func messageSubject(message models.BusMessage) string {
+	if message.Key == "" {
+		return message.Topic
+	}
+	return message.Topic + "." + message.Key
+}
@@ -0,0 +1,157 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
+	"github.com/novintriantonius/cosmos-validator-service/internal/poller"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Default relay settings, used for zero values in RelayConfig
+const (
+	DefaultPollInterval   = 5 * time.Second
+	DefaultBatchSize      = 100
+	DefaultTimeout        = 10 * time.Second
+	DefaultInitialBackoff = 5 * time.Second
+	DefaultMaxBackoff     = 5 * time.Minute
+)
+
+// RelayConfig holds configuration for the outbox relay
+type RelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Timeout bounds the publishing of one batch
+	Timeout time.Duration
+	// InitialBackoff is the delay before the first retry of a batch. It
+	// doubles with every further retry up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Retention is how long published messages are kept in the outbox. They
+	// are kept forever when it is zero.
+	Retention time.Duration
+}
+
+// Relay publishes the messages queued in the outbox in order, retrying
+// failed batches with exponential backoff until the bus accepts them
+type Relay struct {
+	store     store.BusOutboxStore
+	publisher Publisher
+	config    RelayConfig
+	loop      *poller.Loop
+}
+
+// NewRelay creates a new outbox relay, applying defaults for empty
+// configuration values
+func NewRelay(outboxStore store.BusOutboxStore, publisher Publisher, config RelayConfig) *Relay {
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultInitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+
+	r := &Relay{
+		store:     outboxStore,
+		publisher: publisher,
+		config:    config,
+	}
+	// Keep going without waiting while full batches are published
+	r.loop = poller.New(poller.Config{
+		Name:         "Message bus relay",
+		PollInterval: config.PollInterval,
+		Retention:    config.Retention,
+	}, func(ctx context.Context) (bool, error) {
+		claimed, err := r.RelayNext(ctx)
+		return claimed >= config.BatchSize, err
+	}, outboxStore.DeletePublished)
+	return r
+}
+
+// Wake makes a running relay publish without waiting for the next poll
+func (r *Relay) Wake() {
+	r.loop.Wake()
+}
+
+// Run publishes queued messages every poll interval, and when woken up, until
+// ctx is done
+func (r *Relay) Run(ctx context.Context) {
+	r.loop.Run(ctx)
+}
+
+// RelayNext publishes the next batch of queued messages and returns the
+// number of messages claimed. A batch the bus does not accept is retried
+// after a backoff, and later messages wait for it.
+func (r *Relay) RelayNext(ctx context.Context) (claimed int, err error) {
+	// Claimed messages stay locked for a little longer than the attempt, so
+	// that no other relay publishes them meanwhile
+	messages, err := r.store.ClaimNext(ctx, r.config.BatchSize, 2*r.config.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("error claiming bus messages: %v", err)
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	ctx, span := tracing.StartSpan(ctx, "bus.publish",
+		attribute.Int("bus.messages", len(messages)),
+		attribute.Int64("bus.first_message_id", messages[0].ID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ids := make([]int64, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	publishErr := r.publisher.Publish(publishCtx, messages)
+	cancel()
+
+	if ctx.Err() != nil {
+		// Shutting down: the messages are claimed again when their lock
+		// expires
+		return len(messages), ctx.Err()
+	}
+
+	if publishErr != nil {
+		attempt := messages[0].Attempts + 1
+		retryAt := time.Now().Add(r.Backoff(attempt))
+		metrics.BusMessagesTotal.WithLabelValues("retry").Add(float64(len(messages)))
+		slog.WarnContext(ctx, "Failed to publish bus messages, retrying",
+			"messages", len(messages), "first_message", messages[0].ID, "attempt", attempt,
+			"retry_at", retryAt, "error", publishErr)
+		if err := r.store.MarkFailed(ctx, ids, publishErr.Error(), retryAt); err != nil {
+			return len(messages), err
+		}
+		return len(messages), nil
+	}
+
+	metrics.BusMessagesTotal.WithLabelValues("published").Add(float64(len(messages)))
+	// Messages not marked published are published again once their lock
+	// expires
+	if err := r.store.MarkPublished(ctx, ids); err != nil {
+		return len(messages), err
+	}
+	return len(messages), nil
+}
+
+// Backoff returns the delay after a failed attempt (counted from 1) before the
+// next one
+func (r *Relay) Backoff(attempt int) time.Duration {
+	return poller.Backoff(r.config.InitialBackoff, r.config.MaxBackoff, attempt)
+}
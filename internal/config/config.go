@@ -0,0 +1,651 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// redactedValue replaces secrets when the configuration is printed
+const redactedValue = "********"
+
+// Config holds the complete application configuration
+type Config struct {
+	Server    ServerConfig    `yaml:"server" toml:"server"`
+	Database  DatabaseConfig  `yaml:"database" toml:"database"`
+	Cosmos    CosmosConfig    `yaml:"cosmos" toml:"cosmos"`
+	Scheduler SchedulerConfig `yaml:"scheduler" toml:"scheduler"`
+	Logging   LoggingConfig   `yaml:"logging" toml:"logging"`
+	Tracing   TracingConfig   `yaml:"tracing" toml:"tracing"`
+	Health    HealthConfig    `yaml:"health" toml:"health"`
+	Auth      AuthConfig      `yaml:"auth" toml:"auth"`
+	Webhooks  WebhooksConfig  `yaml:"webhooks" toml:"webhooks"`
+	Email     EmailConfig     `yaml:"email" toml:"email"`
+	Stream    StreamConfig    `yaml:"stream" toml:"stream"`
+	Bus       BusConfig       `yaml:"bus" toml:"bus"`
+	Outbox    OutboxConfig    `yaml:"outbox" toml:"outbox"`
+}
+
+// ServerConfig holds HTTP server configuration
+type ServerConfig struct {
+	Port            int      `yaml:"port" toml:"port"`
+	ReadTimeout     Duration `yaml:"readTimeout" toml:"readTimeout"`
+	WriteTimeout    Duration `yaml:"writeTimeout" toml:"writeTimeout"`
+	ShutdownTimeout Duration `yaml:"shutdownTimeout" toml:"shutdownTimeout"`
+}
+
+// DatabaseConfig holds PostgreSQL connection and pool configuration
+type DatabaseConfig struct {
+	Host            string   `yaml:"host" toml:"host"`
+	Port            int      `yaml:"port" toml:"port"`
+	User            string   `yaml:"user" toml:"user"`
+	Password        string   `yaml:"password" toml:"password"`
+	Name            string   `yaml:"name" toml:"name"`
+	SSLMode         string   `yaml:"sslMode" toml:"sslMode"`
+	MaxOpenConns    int      `yaml:"maxOpenConns" toml:"maxOpenConns"`
+	MaxIdleConns    int      `yaml:"maxIdleConns" toml:"maxIdleConns"`
+	ConnMaxLifetime Duration `yaml:"connMaxLifetime" toml:"connMaxLifetime"`
+}
+
+// CosmosConfig holds configuration for the Cosmos LCD API client
+type CosmosConfig struct {
+	BaseURL    string   `yaml:"baseURL" toml:"baseURL"`
+	MaxRetries int      `yaml:"maxRetries" toml:"maxRetries"`
+	RetryDelay Duration `yaml:"retryDelay" toml:"retryDelay"`
+	Timeout    Duration `yaml:"timeout" toml:"timeout"`
+	// AccountPrefix and ValidatorPrefix are the bech32 prefixes of the chain's
+	// account (delegator) and validator operator addresses
+	AccountPrefix   string `yaml:"accountPrefix" toml:"accountPrefix"`
+	ValidatorPrefix string `yaml:"validatorPrefix" toml:"validatorPrefix"`
+}
+
+// SchedulerConfig holds configuration for scheduled tasks
+type SchedulerConfig struct {
+	// DelegationSyncSchedule is a cron expression with a leading seconds field
+	DelegationSyncSchedule string   `yaml:"delegationSyncSchedule" toml:"delegationSyncSchedule"`
+	TaskTimeout            Duration `yaml:"taskTimeout" toml:"taskTimeout"`
+	InitialSyncEnabled     bool     `yaml:"initialSyncEnabled" toml:"initialSyncEnabled"`
+	InitialSyncDelay       Duration `yaml:"initialSyncDelay" toml:"initialSyncDelay"`
+	InitialSyncTimeout     Duration `yaml:"initialSyncTimeout" toml:"initialSyncTimeout"`
+	// DailyDigestSchedule and WeeklyDigestSchedule are the cron expressions,
+	// with a leading seconds field, of the daily and weekly email digests
+	DailyDigestSchedule  string `yaml:"dailyDigestSchedule" toml:"dailyDigestSchedule"`
+	WeeklyDigestSchedule string `yaml:"weeklyDigestSchedule" toml:"weeklyDigestSchedule"`
+}
+
+// LoggingConfig holds logging configuration
+type LoggingConfig struct {
+	Level  string `yaml:"level" toml:"level"`
+	Format string `yaml:"format" toml:"format"`
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// Exporter is either "otlp" (OTLP over HTTP) or "stdout"
+	Exporter    string  `yaml:"exporter" toml:"exporter"`
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint"`
+	Insecure    bool    `yaml:"insecure" toml:"insecure"`
+	ServiceName string  `yaml:"serviceName" toml:"serviceName"`
+	SampleRatio float64 `yaml:"sampleRatio" toml:"sampleRatio"`
+}
+
+// HealthConfig holds thresholds for the health and readiness reports
+type HealthConfig struct {
+	// MaxSyncAge is how old the last successful sync of a tracked validator may
+	// be before the service reports itself as degraded
+	MaxSyncAge   Duration `yaml:"maxSyncAge" toml:"maxSyncAge"`
+	CheckTimeout Duration `yaml:"checkTimeout" toml:"checkTimeout"`
+}
+
+// minBootstrapKeyLength is the shortest accepted bootstrap API key
+const minBootstrapKeyLength = 32
+
+// AuthConfig holds API key authentication configuration
+type AuthConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// BootstrapKey is an admin API key accepted without a database entry. Use
+	// it to create the first keys, then unset it.
+	BootstrapKey string `yaml:"bootstrapKey" toml:"bootstrapKey"`
+}
+
+// WebhooksConfig holds configuration for the webhook delivery worker
+type WebhooksConfig struct {
+	// Enabled runs the delivery worker. Events are still queued while it is off.
+	Enabled      bool     `yaml:"enabled" toml:"enabled"`
+	PollInterval Duration `yaml:"pollInterval" toml:"pollInterval"`
+	BatchSize    int      `yaml:"batchSize" toml:"batchSize"`
+	// Timeout bounds a single delivery request
+	Timeout     Duration `yaml:"timeout" toml:"timeout"`
+	MaxAttempts int      `yaml:"maxAttempts" toml:"maxAttempts"`
+	// InitialBackoff is the delay before the first retry. It doubles with
+	// every further retry up to MaxBackoff.
+	InitialBackoff Duration `yaml:"initialBackoff" toml:"initialBackoff"`
+	MaxBackoff     Duration `yaml:"maxBackoff" toml:"maxBackoff"`
+}
+
+// EmailConfig holds the SMTP server used to send email digests
+type EmailConfig struct {
+	// Enabled schedules the email digests
+	Enabled      bool   `yaml:"enabled" toml:"enabled"`
+	SMTPHost     string `yaml:"smtpHost" toml:"smtpHost"`
+	SMTPPort     int    `yaml:"smtpPort" toml:"smtpPort"`
+	SMTPUsername string `yaml:"smtpUsername" toml:"smtpUsername"`
+	SMTPPassword string `yaml:"smtpPassword" toml:"smtpPassword"`
+	// SMTPTLS is one of "starttls", "tls" (implicit TLS) or "none"
+	SMTPTLS string `yaml:"smtpTLS" toml:"smtpTLS"`
+	// From is the sender address, optionally with a display name
+	From    string   `yaml:"from" toml:"from"`
+	Timeout Duration `yaml:"timeout" toml:"timeout"`
+	// TopMovers is the number of delegators listed per validator
+	TopMovers int `yaml:"topMovers" toml:"topMovers"`
+}
+
+// StreamConfig holds configuration for the live delegation change streams
+type StreamConfig struct {
+	// HeartbeatInterval is how often an idle stream sends a heartbeat and a
+	// WebSocket connection a ping, which keeps proxies from closing them
+	HeartbeatInterval Duration `yaml:"heartbeatInterval" toml:"heartbeatInterval"`
+	// BufferSize is the number of changes buffered per client. Clients that
+	// fall further behind are disconnected and resume from the stored events.
+	BufferSize int `yaml:"bufferSize" toml:"bufferSize"`
+}
+
+// BusConfig holds configuration for publishing delegation changes to a
+// message bus
+type BusConfig struct {
+	// Driver is one of "none", "nats" or "kafka". Changes are not queued for
+	// the bus when it is "none".
+	Driver string `yaml:"driver" toml:"driver"`
+	// Topic is the Kafka topic, or the NATS subject prefix, of the changes
+	Topic        string   `yaml:"topic" toml:"topic"`
+	PollInterval Duration `yaml:"pollInterval" toml:"pollInterval"`
+	BatchSize    int      `yaml:"batchSize" toml:"batchSize"`
+	// Timeout bounds the publishing of one batch
+	Timeout Duration `yaml:"timeout" toml:"timeout"`
+	// InitialBackoff is the delay before the first retry of a batch. It
+	// doubles with every further retry up to MaxBackoff.
+	InitialBackoff Duration `yaml:"initialBackoff" toml:"initialBackoff"`
+	MaxBackoff     Duration `yaml:"maxBackoff" toml:"maxBackoff"`
+	// Retention is how long published changes are kept in the outbox, or
+	// forever when it is zero
+	Retention Duration `yaml:"retention" toml:"retention"`
+	// NATSURL is the nats:// or tls:// URL of the NATS server
+	NATSURL      string `yaml:"natsURL" toml:"natsURL"`
+	NATSToken    string `yaml:"natsToken" toml:"natsToken"`
+	NATSUsername string `yaml:"natsUsername" toml:"natsUsername"`
+	NATSPassword string `yaml:"natsPassword" toml:"natsPassword"`
+	// KafkaBrokers is a comma-separated list of the host:port addresses of
+	// Kafka brokers
+	KafkaBrokers string `yaml:"kafkaBrokers" toml:"kafkaBrokers"`
+	// KafkaUsername and KafkaPassword authenticate with SASL when
+	// KafkaUsername is set
+	KafkaUsername string `yaml:"kafkaUsername" toml:"kafkaUsername"`
+	KafkaPassword string `yaml:"kafkaPassword" toml:"kafkaPassword"`
+	// KafkaSASLMechanism is one of "PLAIN", "SCRAM-SHA-256" or
+	// "SCRAM-SHA-512"
+	KafkaSASLMechanism string `yaml:"kafkaSASLMechanism" toml:"kafkaSASLMechanism"`
+	// KafkaTLS connects to the brokers with TLS
+	KafkaTLS bool `yaml:"kafkaTLS" toml:"kafkaTLS"`
+}
+
+// KafkaBrokerList returns the addresses of KafkaBrokers
+func (c BusConfig) KafkaBrokerList() []string {
+	var brokers []string
+	for _, broker := range strings.Split(c.KafkaBrokers, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+	return brokers
+}
+
+// OutboxConfig holds configuration for relaying the events in the event
+// outbox to the event consumers: webhooks, the message bus, live streams,
+// chat notifications and alert rules
+type OutboxConfig struct {
+	PollInterval Duration `yaml:"pollInterval" toml:"pollInterval"`
+	BatchSize    int      `yaml:"batchSize" toml:"batchSize"`
+	// Timeout bounds the relaying of one batch
+	Timeout Duration `yaml:"timeout" toml:"timeout"`
+	// InitialBackoff is the delay before the first retry of the events of a
+	// validator. It doubles with every further retry up to MaxBackoff.
+	InitialBackoff Duration `yaml:"initialBackoff" toml:"initialBackoff"`
+	MaxBackoff     Duration `yaml:"maxBackoff" toml:"maxBackoff"`
+	// Retention is how long relayed events are kept in the outbox, or
+	// forever when it is zero
+	Retention Duration `yaml:"retention" toml:"retention"`
+}
+
+// Default returns a configuration populated with default values
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            8080,
+			ReadTimeout:     Duration(15 * time.Second),
+			WriteTimeout:    Duration(30 * time.Second),
+			ShutdownTimeout: Duration(10 * time.Second),
+		},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "cosmos",
+			Name:            "cosmos_validator",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: Duration(30 * time.Minute),
+		},
+		Cosmos: CosmosConfig{
+			BaseURL:         "https://cosmos-api.polkachu.com",
+			MaxRetries:      3,
+			RetryDelay:      Duration(500 * time.Millisecond),
+			Timeout:         Duration(10 * time.Second),
+			AccountPrefix:   "cosmos",
+			ValidatorPrefix: "cosmosvaloper",
+		},
+		Scheduler: SchedulerConfig{
+			DelegationSyncSchedule: "0 0 * * * *",
+			TaskTimeout:            Duration(55 * time.Second),
+			InitialSyncEnabled:     true,
+			InitialSyncDelay:       Duration(5 * time.Second),
+			InitialSyncTimeout:     Duration(2 * time.Minute),
+			DailyDigestSchedule:    "0 0 7 * * *",
+			WeeklyDigestSchedule:   "0 0 7 * * 1",
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Tracing: TracingConfig{
+			Enabled:     false,
+			Exporter:    "otlp",
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			ServiceName: "cosmos-validator-service",
+			SampleRatio: 1,
+		},
+		Health: HealthConfig{
+			MaxSyncAge:   Duration(2 * time.Hour),
+			CheckTimeout: Duration(5 * time.Second),
+		},
+		Auth: AuthConfig{
+			Enabled: true,
+		},
+		Webhooks: WebhooksConfig{
+			Enabled:        true,
+			PollInterval:   Duration(5 * time.Second),
+			BatchSize:      50,
+			Timeout:        Duration(10 * time.Second),
+			MaxAttempts:    8,
+			InitialBackoff: Duration(30 * time.Second),
+			MaxBackoff:     Duration(1 * time.Hour),
+		},
+		Email: EmailConfig{
+			Enabled:   false,
+			SMTPHost:  "localhost",
+			SMTPPort:  587,
+			SMTPTLS:   "starttls",
+			From:      "Cosmos Validator Service <digests@localhost>",
+			Timeout:   Duration(30 * time.Second),
+			TopMovers: 5,
+		},
+		Stream: StreamConfig{
+			HeartbeatInterval: Duration(15 * time.Second),
+			BufferSize:        256,
+		},
+		Bus: BusConfig{
+			Driver:             "none",
+			Topic:              "cosmos-validator.delegation-changes",
+			PollInterval:       Duration(5 * time.Second),
+			BatchSize:          100,
+			Timeout:            Duration(10 * time.Second),
+			InitialBackoff:     Duration(5 * time.Second),
+			MaxBackoff:         Duration(5 * time.Minute),
+			Retention:          Duration(7 * 24 * time.Hour),
+			NATSURL:            "nats://localhost:4222",
+			KafkaBrokers:       "localhost:9092",
+			KafkaSASLMechanism: "PLAIN",
+		},
+		Outbox: OutboxConfig{
+			PollInterval:   Duration(5 * time.Second),
+			BatchSize:      100,
+			Timeout:        Duration(30 * time.Second),
+			InitialBackoff: Duration(5 * time.Second),
+			MaxBackoff:     Duration(5 * time.Minute),
+			Retention:      Duration(7 * 24 * time.Hour),
+		},
+	}
+}
+
+// Validate checks the configuration and returns all problems found
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port must be between 1 and 65535, got %d", c.Server.Port))
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("server.shutdownTimeout must be positive"))
+	}
+
+	if c.Database.Host == "" {
+		errs = append(errs, errors.New("database.host is required"))
+	}
+	if c.Database.Port < 1 || c.Database.Port > 65535 {
+		errs = append(errs, fmt.Errorf("database.port must be between 1 and 65535, got %d", c.Database.Port))
+	}
+	if c.Database.User == "" {
+		errs = append(errs, errors.New("database.user is required"))
+	}
+	if c.Database.Name == "" {
+		errs = append(errs, errors.New("database.name is required"))
+	}
+	switch c.Database.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, fmt.Errorf("database.sslMode %q is not a valid PostgreSQL sslmode", c.Database.SSLMode))
+	}
+	if c.Database.MaxOpenConns < 0 {
+		errs = append(errs, errors.New("database.maxOpenConns must not be negative"))
+	}
+	if c.Database.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database.maxIdleConns must not be negative"))
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("database.maxIdleConns (%d) must not exceed database.maxOpenConns (%d)",
+			c.Database.MaxIdleConns, c.Database.MaxOpenConns))
+	}
+
+	if u, err := url.Parse(c.Cosmos.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("cosmos.baseURL %q must be an absolute http(s) URL", c.Cosmos.BaseURL))
+	}
+	if c.Cosmos.MaxRetries < 1 {
+		errs = append(errs, errors.New("cosmos.maxRetries must be at least 1"))
+	}
+	if c.Cosmos.RetryDelay <= 0 {
+		errs = append(errs, errors.New("cosmos.retryDelay must be positive"))
+	}
+	if c.Cosmos.Timeout <= 0 {
+		errs = append(errs, errors.New("cosmos.timeout must be positive"))
+	}
+	if !validBech32Prefix(c.Cosmos.AccountPrefix) {
+		errs = append(errs, fmt.Errorf("cosmos.accountPrefix %q must be a non-empty lower case bech32 prefix", c.Cosmos.AccountPrefix))
+	}
+	if !validBech32Prefix(c.Cosmos.ValidatorPrefix) {
+		errs = append(errs, fmt.Errorf("cosmos.validatorPrefix %q must be a non-empty lower case bech32 prefix", c.Cosmos.ValidatorPrefix))
+	}
+
+	if _, err := ParseSchedule(c.Scheduler.DelegationSyncSchedule); err != nil {
+		errs = append(errs, fmt.Errorf("scheduler.delegationSyncSchedule: %v", err))
+	}
+	if c.Scheduler.TaskTimeout <= 0 {
+		errs = append(errs, errors.New("scheduler.taskTimeout must be positive"))
+	}
+	if c.Scheduler.InitialSyncEnabled && c.Scheduler.InitialSyncTimeout <= 0 {
+		errs = append(errs, errors.New("scheduler.initialSyncTimeout must be positive"))
+	}
+	if _, err := ParseSchedule(c.Scheduler.DailyDigestSchedule); err != nil {
+		errs = append(errs, fmt.Errorf("scheduler.dailyDigestSchedule: %v", err))
+	}
+	if _, err := ParseSchedule(c.Scheduler.WeeklyDigestSchedule); err != nil {
+		errs = append(errs, fmt.Errorf("scheduler.weeklyDigestSchedule: %v", err))
+	}
+
+	switch strings.ToLower(c.Logging.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("logging.level %q must be one of debug, info, warn, error", c.Logging.Level))
+	}
+	switch strings.ToLower(c.Logging.Format) {
+	case "text", "json":
+	default:
+		errs = append(errs, fmt.Errorf("logging.format %q must be one of text, json", c.Logging.Format))
+	}
+
+	if c.Tracing.Enabled {
+		switch c.Tracing.Exporter {
+		case "otlp":
+			if c.Tracing.Endpoint == "" {
+				errs = append(errs, errors.New("tracing.endpoint is required for the otlp exporter"))
+			}
+		case "stdout":
+		default:
+			errs = append(errs, fmt.Errorf("tracing.exporter %q must be one of otlp, stdout", c.Tracing.Exporter))
+		}
+		if c.Tracing.ServiceName == "" {
+			errs = append(errs, errors.New("tracing.serviceName is required"))
+		}
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sampleRatio must be between 0 and 1, got %v", c.Tracing.SampleRatio))
+	}
+
+	if c.Health.MaxSyncAge <= 0 {
+		errs = append(errs, errors.New("health.maxSyncAge must be positive"))
+	}
+	if c.Health.CheckTimeout <= 0 {
+		errs = append(errs, errors.New("health.checkTimeout must be positive"))
+	}
+
+	if c.Auth.BootstrapKey != "" && len(c.Auth.BootstrapKey) < minBootstrapKeyLength {
+		errs = append(errs, fmt.Errorf("auth.bootstrapKey must be at least %d characters", minBootstrapKeyLength))
+	}
+
+	if c.Webhooks.PollInterval <= 0 {
+		errs = append(errs, errors.New("webhooks.pollInterval must be positive"))
+	}
+	if c.Webhooks.BatchSize < 1 {
+		errs = append(errs, errors.New("webhooks.batchSize must be at least 1"))
+	}
+	if c.Webhooks.Timeout <= 0 {
+		errs = append(errs, errors.New("webhooks.timeout must be positive"))
+	}
+	if c.Webhooks.MaxAttempts < 1 {
+		errs = append(errs, errors.New("webhooks.maxAttempts must be at least 1"))
+	}
+	if c.Webhooks.InitialBackoff <= 0 {
+		errs = append(errs, errors.New("webhooks.initialBackoff must be positive"))
+	}
+	if c.Webhooks.MaxBackoff < c.Webhooks.InitialBackoff {
+		errs = append(errs, errors.New("webhooks.maxBackoff must not be less than webhooks.initialBackoff"))
+	}
+
+	if c.Email.Enabled {
+		if c.Email.SMTPHost == "" {
+			errs = append(errs, errors.New("email.smtpHost is required"))
+		}
+		if _, err := mail.ParseAddress(c.Email.From); err != nil {
+			errs = append(errs, fmt.Errorf("email.from %q must be an email address: %v", c.Email.From, err))
+		}
+	}
+	if c.Email.SMTPPort < 1 || c.Email.SMTPPort > 65535 {
+		errs = append(errs, fmt.Errorf("email.smtpPort must be between 1 and 65535, got %d", c.Email.SMTPPort))
+	}
+	switch c.Email.SMTPTLS {
+	case "starttls", "tls", "none":
+	default:
+		errs = append(errs, fmt.Errorf("email.smtpTLS %q must be one of starttls, tls, none", c.Email.SMTPTLS))
+	}
+	if c.Email.Timeout <= 0 {
+		errs = append(errs, errors.New("email.timeout must be positive"))
+	}
+	if c.Email.TopMovers < 1 {
+		errs = append(errs, errors.New("email.topMovers must be at least 1"))
+	}
+
+	if c.Stream.HeartbeatInterval <= 0 {
+		errs = append(errs, errors.New("stream.heartbeatInterval must be positive"))
+	}
+	if c.Stream.BufferSize < 1 {
+		errs = append(errs, errors.New("stream.bufferSize must be at least 1"))
+	}
+
+	switch c.Bus.Driver {
+	case "none":
+	case "nats":
+		if u, err := url.Parse(c.Bus.NATSURL); err != nil || (u.Scheme != "nats" && u.Scheme != "tls") || u.Hostname() == "" {
+			errs = append(errs, fmt.Errorf("bus.natsURL %q must be a nats:// or tls:// URL", c.Bus.NATSURL))
+		}
+	case "kafka":
+		brokers := c.Bus.KafkaBrokerList()
+		if len(brokers) == 0 {
+			errs = append(errs, errors.New("bus.kafkaBrokers must list at least one broker"))
+		}
+		for _, broker := range brokers {
+			if host, port, err := net.SplitHostPort(broker); err != nil || host == "" || port == "" {
+				errs = append(errs, fmt.Errorf("bus.kafkaBrokers %q must be host:port addresses", broker))
+			}
+		}
+		switch c.Bus.KafkaSASLMechanism {
+		case "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512":
+		default:
+			errs = append(errs, fmt.Errorf("bus.kafkaSASLMechanism %q must be one of PLAIN, SCRAM-SHA-256, SCRAM-SHA-512", c.Bus.KafkaSASLMechanism))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("bus.driver %q must be one of none, nats, kafka", c.Bus.Driver))
+	}
+	if !validBusTopic(c.Bus.Topic) {
+		errs = append(errs, fmt.Errorf("bus.topic %q must be 1 to 249 letters, digits, '.', '_' or '-'", c.Bus.Topic))
+	}
+	if c.Bus.PollInterval <= 0 {
+		errs = append(errs, errors.New("bus.pollInterval must be positive"))
+	}
+	if c.Bus.BatchSize < 1 {
+		errs = append(errs, errors.New("bus.batchSize must be at least 1"))
+	}
+	if c.Bus.Timeout <= 0 {
+		errs = append(errs, errors.New("bus.timeout must be positive"))
+	}
+	if c.Bus.InitialBackoff <= 0 {
+		errs = append(errs, errors.New("bus.initialBackoff must be positive"))
+	}
+	if c.Bus.MaxBackoff < c.Bus.InitialBackoff {
+		errs = append(errs, errors.New("bus.maxBackoff must not be less than bus.initialBackoff"))
+	}
+	if c.Bus.Retention < 0 {
+		errs = append(errs, errors.New("bus.retention must not be negative"))
+	}
+
+	if c.Outbox.PollInterval <= 0 {
+		errs = append(errs, errors.New("outbox.pollInterval must be positive"))
+	}
+	if c.Outbox.BatchSize < 1 {
+		errs = append(errs, errors.New("outbox.batchSize must be at least 1"))
+	}
+	if c.Outbox.Timeout <= 0 {
+		errs = append(errs, errors.New("outbox.timeout must be positive"))
+	}
+	if c.Outbox.InitialBackoff <= 0 {
+		errs = append(errs, errors.New("outbox.initialBackoff must be positive"))
+	}
+	if c.Outbox.MaxBackoff < c.Outbox.InitialBackoff {
+		errs = append(errs, errors.New("outbox.maxBackoff must not be less than outbox.initialBackoff"))
+	}
+	if c.Outbox.Retention < 0 {
+		errs = append(errs, errors.New("outbox.retention must not be negative"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Redacted returns a copy of the configuration with secrets masked
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	if redacted.Database.Password != "" {
+		redacted.Database.Password = redactedValue
+	}
+	if redacted.Auth.BootstrapKey != "" {
+		redacted.Auth.BootstrapKey = redactedValue
+	}
+	if redacted.Email.SMTPPassword != "" {
+		redacted.Email.SMTPPassword = redactedValue
+	}
+	if redacted.Bus.NATSToken != "" {
+		redacted.Bus.NATSToken = redactedValue
+	}
+	if redacted.Bus.NATSPassword != "" {
+		redacted.Bus.NATSPassword = redactedValue
+	}
+	if u, err := url.Parse(redacted.Bus.NATSURL); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redactedValue)
+			redacted.Bus.NATSURL = u.String()
+		}
+	}
+	if redacted.Bus.KafkaPassword != "" {
+		redacted.Bus.KafkaPassword = redactedValue
+	}
+	return &redacted
+}
+
+// validBech32Prefix reports whether prefix can be the human-readable part of
+// a lower case bech32 address
+func validBech32Prefix(prefix string) bool {
+	if prefix == "" || len(prefix) > 83 {
+		return false
+	}
+	for _, c := range prefix {
+		if c < 33 || c > 126 || (c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// validBusTopic reports whether topic is a valid Kafka topic name, which is
+// also a valid NATS subject prefix
+func validBusTopic(topic string) bool {
+	if topic == "" || len(topic) > 249 || topic == "." || topic == ".." {
+		return false
+	}
+	for _, c := range topic {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSchedule parses a cron expression with a leading seconds field
+func ParseSchedule(expr string) (cron.Schedule, error) {
+	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	return parser.Parse(expr)
+}
+
+// Duration is a time.Duration that reads and writes as a string such as "30s"
+type Duration time.Duration
+
+// Std returns the value as a time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// String returns the duration formatted like time.Duration
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %v", string(text), err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
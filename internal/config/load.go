@@ -0,0 +1,222 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv is the environment variable naming the configuration file
+const ConfigFileEnv = "CONFIG_FILE"
+
+// Load builds the configuration from defaults, the optional YAML or TOML file
+// at path, and environment variable overrides, then validates the result
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadFile decodes the configuration file into cfg based on its extension
+func loadFile(path string, cfg *Config) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file %s: %v", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("error parsing YAML config file %s: %v", path, err)
+		}
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(cfg); err != nil {
+			return fmt.Errorf("error parsing TOML config file %s: %v", path, err)
+		}
+	default:
+		return fmt.Errorf("unsupported config file extension %q: use .yaml, .yml or .toml", filepath.Ext(path))
+	}
+
+	return nil
+}
+
+// applyEnv overrides configuration values with environment variables
+func applyEnv(cfg *Config) error {
+	var errs []string
+
+	setString := func(key string, dst *string) {
+		if value, ok := os.LookupEnv(key); ok && value != "" {
+			*dst = value
+		}
+	}
+	setInt := func(key string, dst *int) {
+		if value, ok := os.LookupEnv(key); ok && value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s must be an integer, got %q", key, value))
+				return
+			}
+			*dst = parsed
+		}
+	}
+	setBool := func(key string, dst *bool) {
+		if value, ok := os.LookupEnv(key); ok && value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s must be a boolean, got %q", key, value))
+				return
+			}
+			*dst = parsed
+		}
+	}
+	setFloat := func(key string, dst *float64) {
+		if value, ok := os.LookupEnv(key); ok && value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s must be a number, got %q", key, value))
+				return
+			}
+			*dst = parsed
+		}
+	}
+	setDuration := func(key string, dst *Duration) {
+		if value, ok := os.LookupEnv(key); ok && value != "" {
+			if err := dst.UnmarshalText([]byte(value)); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", key, err))
+			}
+		}
+	}
+
+	setInt("SERVER_PORT", &cfg.Server.Port)
+	setDuration("SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	setDuration("SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	setDuration("SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+
+	setString("DB_HOST", &cfg.Database.Host)
+	setInt("DB_PORT", &cfg.Database.Port)
+	setString("DB_USER", &cfg.Database.User)
+	setString("DB_PASSWORD", &cfg.Database.Password)
+	setString("DB_NAME", &cfg.Database.Name)
+	setString("DB_SSL_MODE", &cfg.Database.SSLMode)
+	setInt("DB_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns)
+	setInt("DB_MAX_IDLE_CONNS", &cfg.Database.MaxIdleConns)
+	setDuration("DB_CONN_MAX_LIFETIME", &cfg.Database.ConnMaxLifetime)
+
+	setString("COSMOS_BASE_URL", &cfg.Cosmos.BaseURL)
+	setInt("COSMOS_MAX_RETRIES", &cfg.Cosmos.MaxRetries)
+	setDuration("COSMOS_RETRY_DELAY", &cfg.Cosmos.RetryDelay)
+	setDuration("COSMOS_TIMEOUT", &cfg.Cosmos.Timeout)
+	setString("COSMOS_ACCOUNT_PREFIX", &cfg.Cosmos.AccountPrefix)
+	setString("COSMOS_VALIDATOR_PREFIX", &cfg.Cosmos.ValidatorPrefix)
+
+	setString("SCHEDULER_DELEGATION_SYNC_SCHEDULE", &cfg.Scheduler.DelegationSyncSchedule)
+	setDuration("SCHEDULER_TASK_TIMEOUT", &cfg.Scheduler.TaskTimeout)
+	setBool("SCHEDULER_INITIAL_SYNC_ENABLED", &cfg.Scheduler.InitialSyncEnabled)
+	setDuration("SCHEDULER_INITIAL_SYNC_DELAY", &cfg.Scheduler.InitialSyncDelay)
+	setDuration("SCHEDULER_INITIAL_SYNC_TIMEOUT", &cfg.Scheduler.InitialSyncTimeout)
+	setString("SCHEDULER_DAILY_DIGEST_SCHEDULE", &cfg.Scheduler.DailyDigestSchedule)
+	setString("SCHEDULER_WEEKLY_DIGEST_SCHEDULE", &cfg.Scheduler.WeeklyDigestSchedule)
+
+	setString("LOG_LEVEL", &cfg.Logging.Level)
+	setString("LOG_FORMAT", &cfg.Logging.Format)
+
+	setBool("TRACING_ENABLED", &cfg.Tracing.Enabled)
+	setString("TRACING_EXPORTER", &cfg.Tracing.Exporter)
+	setString("OTEL_EXPORTER_OTLP_ENDPOINT", &cfg.Tracing.Endpoint)
+	setBool("TRACING_INSECURE", &cfg.Tracing.Insecure)
+	setString("OTEL_SERVICE_NAME", &cfg.Tracing.ServiceName)
+	setFloat("TRACING_SAMPLE_RATIO", &cfg.Tracing.SampleRatio)
+
+	setDuration("HEALTH_MAX_SYNC_AGE", &cfg.Health.MaxSyncAge)
+	setDuration("HEALTH_CHECK_TIMEOUT", &cfg.Health.CheckTimeout)
+
+	setBool("AUTH_ENABLED", &cfg.Auth.Enabled)
+	setString("AUTH_BOOTSTRAP_KEY", &cfg.Auth.BootstrapKey)
+
+	setBool("WEBHOOKS_ENABLED", &cfg.Webhooks.Enabled)
+	setDuration("WEBHOOKS_POLL_INTERVAL", &cfg.Webhooks.PollInterval)
+	setInt("WEBHOOKS_BATCH_SIZE", &cfg.Webhooks.BatchSize)
+	setDuration("WEBHOOKS_TIMEOUT", &cfg.Webhooks.Timeout)
+	setInt("WEBHOOKS_MAX_ATTEMPTS", &cfg.Webhooks.MaxAttempts)
+	setDuration("WEBHOOKS_INITIAL_BACKOFF", &cfg.Webhooks.InitialBackoff)
+	setDuration("WEBHOOKS_MAX_BACKOFF", &cfg.Webhooks.MaxBackoff)
+
+	setBool("EMAIL_ENABLED", &cfg.Email.Enabled)
+	setString("EMAIL_SMTP_HOST", &cfg.Email.SMTPHost)
+	setInt("EMAIL_SMTP_PORT", &cfg.Email.SMTPPort)
+	setString("EMAIL_SMTP_USERNAME", &cfg.Email.SMTPUsername)
+	setString("EMAIL_SMTP_PASSWORD", &cfg.Email.SMTPPassword)
+	setString("EMAIL_SMTP_TLS", &cfg.Email.SMTPTLS)
+	setString("EMAIL_FROM", &cfg.Email.From)
+	setDuration("EMAIL_TIMEOUT", &cfg.Email.Timeout)
+	setInt("EMAIL_TOP_MOVERS", &cfg.Email.TopMovers)
+
+	setDuration("STREAM_HEARTBEAT_INTERVAL", &cfg.Stream.HeartbeatInterval)
+	setInt("STREAM_BUFFER_SIZE", &cfg.Stream.BufferSize)
+
+	setString("BUS_DRIVER", &cfg.Bus.Driver)
+	setString("BUS_TOPIC", &cfg.Bus.Topic)
+	setDuration("BUS_POLL_INTERVAL", &cfg.Bus.PollInterval)
+	setInt("BUS_BATCH_SIZE", &cfg.Bus.BatchSize)
+	setDuration("BUS_TIMEOUT", &cfg.Bus.Timeout)
+	setDuration("BUS_INITIAL_BACKOFF", &cfg.Bus.InitialBackoff)
+	setDuration("BUS_MAX_BACKOFF", &cfg.Bus.MaxBackoff)
+	setDuration("BUS_RETENTION", &cfg.Bus.Retention)
+	setString("BUS_NATS_URL", &cfg.Bus.NATSURL)
+	setString("BUS_NATS_TOKEN", &cfg.Bus.NATSToken)
+	setString("BUS_NATS_USERNAME", &cfg.Bus.NATSUsername)
+	setString("BUS_NATS_PASSWORD", &cfg.Bus.NATSPassword)
+	setString("BUS_KAFKA_BROKERS", &cfg.Bus.KafkaBrokers)
+	setString("BUS_KAFKA_USERNAME", &cfg.Bus.KafkaUsername)
+	setString("BUS_KAFKA_PASSWORD", &cfg.Bus.KafkaPassword)
+	setString("BUS_KAFKA_SASL_MECHANISM", &cfg.Bus.KafkaSASLMechanism)
+	setBool("BUS_KAFKA_TLS", &cfg.Bus.KafkaTLS)
+
+	setDuration("OUTBOX_POLL_INTERVAL", &cfg.Outbox.PollInterval)
+	setInt("OUTBOX_BATCH_SIZE", &cfg.Outbox.BatchSize)
+	setDuration("OUTBOX_TIMEOUT", &cfg.Outbox.Timeout)
+	setDuration("OUTBOX_INITIAL_BACKOFF", &cfg.Outbox.InitialBackoff)
+	setDuration("OUTBOX_MAX_BACKOFF", &cfg.Outbox.MaxBackoff)
+	setDuration("OUTBOX_RETENTION", &cfg.Outbox.Retention)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid environment configuration: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Print writes the configuration to w as YAML with secrets redacted
+func Print(w io.Writer, cfg *Config) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg.Redacted()); err != nil {
+		return fmt.Errorf("error encoding config: %v", err)
+	}
+	return encoder.Close()
+}
@@ -1,10 +1,12 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
 	"io/fs"
+	"log/slog"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -13,30 +15,48 @@ import (
 //go:embed migrations/*.up.sql
 var migrationsFS embed.FS
 
+// createMigrationsTable records which migrations have been applied
+const createMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version VARCHAR(255) PRIMARY KEY,
+		applied_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	)
+`
+
 // RunMigrations executes all SQL migration files in the migrations directory
+// that have not been applied yet. Each migration runs in its own transaction.
 func RunMigrations(db *sql.DB) error {
-	// Read all files from the embedded filesystem
-	files, err := fs.ReadDir(migrationsFS, "migrations")
+	if _, err := db.Exec(createMigrationsTable); err != nil {
+		return fmt.Errorf("error creating schema_migrations table: %v", err)
+	}
+
+	migrationFiles, err := listMigrations()
 	if err != nil {
-		return fmt.Errorf("error reading migrations directory: %v", err)
+		return err
 	}
-	
-	// Filter and sort migration files
-	var migrationFiles []string
-	for _, file := range files {
-		if strings.HasSuffix(file.Name(), ".up.sql") {
-			migrationFiles = append(migrationFiles, file.Name())
-		}
+
+	applied, err := appliedMigrations(context.Background(), db)
+	if err != nil {
+		return err
 	}
-	sort.Strings(migrationFiles)
 	
-	// Execute each migration in order
+	// Execute each pending migration in order
 	for _, filename := range migrationFiles {
+		version := migrationVersion(filename)
+		if applied[version] {
+			continue
+		}
+
 		// Read the migration file
 		content, err := fs.ReadFile(migrationsFS, filepath.Join("migrations", filename))
 		if err != nil {
 			return fmt.Errorf("error reading migration file %s: %v", filename, err)
 		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("error starting migration %s: %v", filename, err)
+		}
 		
 		// Split the content into individual statements
 		statements := strings.Split(string(content), ";")
@@ -49,14 +69,104 @@ func RunMigrations(db *sql.DB) error {
 			}
 			
 			// Execute the statement
-			_, err := db.Exec(stmt)
-			if err != nil {
+			if _, err := tx.Exec(stmt); err != nil {
+				tx.Rollback()
 				return fmt.Errorf("error executing migration %s: %v", filename, err)
 			}
 		}
+
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error recording migration %s: %v", filename, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error committing migration %s: %v", filename, err)
+		}
 		
-		fmt.Printf("Successfully applied migration: %s\n", filename)
+		slog.Info("Applied migration", "migration", filename)
 	}
 	
 	return nil
-} 
\ No newline at end of file
+}
+
+// PendingMigrations returns the versions of embedded migrations that have not been applied
+func PendingMigrations(ctx context.Context, db *sql.DB) ([]string, error) {
+	migrationFiles, err := listMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, filename := range migrationFiles {
+		if version := migrationVersion(filename); !applied[version] {
+			pending = append(pending, version)
+		}
+	}
+	return pending, nil
+}
+
+// MigrationVersions returns the versions of all embedded migrations in order
+func MigrationVersions() ([]string, error) {
+	migrationFiles, err := listMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, len(migrationFiles))
+	for i, filename := range migrationFiles {
+		versions[i] = migrationVersion(filename)
+	}
+	return versions, nil
+}
+
+// listMigrations returns the embedded up migration file names in order
+func listMigrations() ([]string, error) {
+	// Read all files from the embedded filesystem
+	files, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error reading migrations directory: %v", err)
+	}
+	
+	// Filter and sort migration files
+	var migrationFiles []string
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".up.sql") {
+			migrationFiles = append(migrationFiles, file.Name())
+		}
+	}
+	sort.Strings(migrationFiles)
+	return migrationFiles, nil
+}
+
+// appliedMigrations returns the set of migration versions recorded as applied
+func appliedMigrations(ctx context.Context, db *sql.DB) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying applied migrations: %v", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("error scanning migration version: %v", err)
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating migration versions: %v", err)
+	}
+	return applied, nil
+}
+
+// migrationVersion strips the .up.sql suffix from a migration file name
+func migrationVersion(filename string) string {
+	return strings.TrimSuffix(filename, ".up.sql")
+}
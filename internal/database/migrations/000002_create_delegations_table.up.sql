@@ -1,5 +1,3 @@
-DROP TABLE IF EXISTS delegations;
-
 CREATE TABLE IF NOT EXISTS delegations (
     id SERIAL PRIMARY KEY,
     validator_address VARCHAR(255) NOT NULL REFERENCES validators(address) ON DELETE CASCADE,
@@ -7,4 +5,4 @@ CREATE TABLE IF NOT EXISTS delegations (
     delegation_shares VARCHAR(255) NOT NULL,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-); 
\ No newline at end of file
+); 
@@ -0,0 +1,3 @@
+ALTER TABLE validators DROP COLUMN IF EXISTS last_sync_error;
+ALTER TABLE validators DROP COLUMN IF EXISTS last_sync_attempt_at;
+ALTER TABLE validators DROP COLUMN IF EXISTS last_synced_at;
@@ -0,0 +1,3 @@
+ALTER TABLE validators ADD COLUMN IF NOT EXISTS last_synced_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE validators ADD COLUMN IF NOT EXISTS last_sync_attempt_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE validators ADD COLUMN IF NOT EXISTS last_sync_error TEXT;
@@ -0,0 +1 @@
+ALTER TABLE validators DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1 @@
+ALTER TABLE validators ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
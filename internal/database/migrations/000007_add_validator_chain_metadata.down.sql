@@ -0,0 +1,7 @@
+ALTER TABLE validators DROP COLUMN IF EXISTS metadata_updated_at;
+ALTER TABLE validators DROP COLUMN IF EXISTS tokens;
+ALTER TABLE validators DROP COLUMN IF EXISTS jailed;
+ALTER TABLE validators DROP COLUMN IF EXISTS bond_status;
+ALTER TABLE validators DROP COLUMN IF EXISTS commission_rate;
+ALTER TABLE validators DROP COLUMN IF EXISTS website;
+ALTER TABLE validators DROP COLUMN IF EXISTS moniker;
@@ -0,0 +1,7 @@
+ALTER TABLE validators ADD COLUMN IF NOT EXISTS moniker VARCHAR(255);
+ALTER TABLE validators ADD COLUMN IF NOT EXISTS website TEXT;
+ALTER TABLE validators ADD COLUMN IF NOT EXISTS commission_rate VARCHAR(64);
+ALTER TABLE validators ADD COLUMN IF NOT EXISTS bond_status VARCHAR(32);
+ALTER TABLE validators ADD COLUMN IF NOT EXISTS jailed BOOLEAN;
+ALTER TABLE validators ADD COLUMN IF NOT EXISTS tokens VARCHAR(100);
+ALTER TABLE validators ADD COLUMN IF NOT EXISTS metadata_updated_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_validators_tags;
+ALTER TABLE validators DROP COLUMN IF EXISTS tags;
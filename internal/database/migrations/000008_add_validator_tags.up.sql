@@ -0,0 +1,2 @@
+ALTER TABLE validators ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_validators_tags ON validators USING GIN (tags);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS delegation_events (
+    id BIGSERIAL PRIMARY KEY,
+    validator_address VARCHAR(255) NOT NULL REFERENCES validators(address) ON DELETE CASCADE,
+    delegator_address VARCHAR(255) NOT NULL,
+    event_type VARCHAR(16) NOT NULL,
+    previous_shares NUMERIC NOT NULL,
+    shares NUMERIC NOT NULL,
+    delta NUMERIC NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_delegation_events_validator_created_at ON delegation_events (validator_address, created_at DESC);
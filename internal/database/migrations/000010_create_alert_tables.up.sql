@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    validator_address VARCHAR(255) REFERENCES validators(address) ON DELETE CASCADE,
+    min_delta NUMERIC CHECK (min_delta > 0),
+    min_stake_percent NUMERIC CHECK (min_stake_percent > 0 AND min_stake_percent <= 100),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (min_delta IS NOT NULL OR min_stake_percent IS NOT NULL)
+);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_validator_address ON alert_rules (validator_address);
+CREATE TABLE IF NOT EXISTS alerts (
+    id BIGSERIAL PRIMARY KEY,
+    rule_id INTEGER REFERENCES alert_rules(id) ON DELETE SET NULL,
+    source_rule_id INTEGER NOT NULL,
+    rule_name VARCHAR(255) NOT NULL,
+    delegation_event_id BIGINT NOT NULL REFERENCES delegation_events(id) ON DELETE CASCADE,
+    validator_address VARCHAR(255) NOT NULL REFERENCES validators(address) ON DELETE CASCADE,
+    delegator_address VARCHAR(255) NOT NULL,
+    event_type VARCHAR(16) NOT NULL,
+    delta NUMERIC NOT NULL,
+    stake_percent NUMERIC,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (source_rule_id, delegation_event_id)
+);
+CREATE INDEX IF NOT EXISTS idx_alerts_created_at ON alerts (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_alerts_validator_created_at ON alerts (validator_address, created_at DESC);
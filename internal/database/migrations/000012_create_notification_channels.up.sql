@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('slack', 'discord', 'telegram')),
+    webhook_url TEXT NOT NULL DEFAULT '',
+    bot_token VARCHAR(255) NOT NULL DEFAULT '',
+    chat_id VARCHAR(255) NOT NULL DEFAULT '',
+    validator_addresses TEXT[] NOT NULL DEFAULT '{}',
+    event_types TEXT[] NOT NULL,
+    sync_failure_threshold INTEGER NOT NULL DEFAULT 1 CHECK (sync_failure_threshold >= 1),
+    templates JSONB NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS email_digests (
+    id SERIAL PRIMARY KEY,
+    tag VARCHAR(64) NOT NULL,
+    frequency VARCHAR(16) NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    recipients TEXT[] NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    last_attempt_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_email_digests_frequency ON email_digests (frequency) WHERE enabled;
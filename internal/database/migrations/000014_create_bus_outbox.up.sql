@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS bus_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(64) NOT NULL UNIQUE,
+    topic VARCHAR(255) NOT NULL,
+    message_key VARCHAR(255) NOT NULL DEFAULT '',
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    locked_until TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_bus_outbox_unpublished ON bus_outbox (id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_bus_outbox_published_at ON bus_outbox (published_at) WHERE published_at IS NOT NULL;
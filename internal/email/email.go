@@ -0,0 +1,302 @@
+// Package email sends multipart text and HTML emails over SMTP
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TLS modes of an SMTP connection
+const (
+	// TLSStartTLS upgrades a plain connection with STARTTLS, failing if the
+	// server does not support it
+	TLSStartTLS = "starttls"
+	// TLSImplicit connects with TLS from the start, usually on port 465
+	TLSImplicit = "tls"
+	// TLSNone never encrypts the connection. Only use it for local SMTP sinks.
+	TLSNone = "none"
+)
+
+// DefaultTimeout is the default timeout of sending an email
+const DefaultTimeout = 30 * time.Second
+
+// Message is an email with a plain text and an optional HTML body
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender sends emails
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// SMTPConfig holds the SMTP server and sender address used by SMTPSender
+type SMTPConfig struct {
+	Host string
+	Port int
+	// Username and Password authenticate with PLAIN auth unless Username is empty
+	Username string
+	Password string
+	// From is the sender address, optionally with a display name
+	From    string
+	TLS     string
+	Timeout time.Duration
+	// TLSConfig overrides the TLS configuration, for example to trust a test
+	// certificate. The server name defaults to Host.
+	TLSConfig *tls.Config
+}
+
+// SMTPSender sends emails through an SMTP server
+type SMTPSender struct {
+	config SMTPConfig
+}
+
+// NewSMTPSender creates a new SMTP sender, applying defaults for empty
+// configuration values
+func NewSMTPSender(config SMTPConfig) *SMTPSender {
+	if config.TLS == "" {
+		config.TLS = TLSStartTLS
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	return &SMTPSender{config: config}
+}
+
+// Send implements Sender. One connection is opened per message.
+func (s *SMTPSender) Send(ctx context.Context, message Message) error {
+	from, err := mail.ParseAddress(s.config.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address %q: %v", s.config.From, err)
+	}
+	if len(message.To) == 0 {
+		return errors.New("no recipients")
+	}
+	to := make([]*mail.Address, len(message.To))
+	for i, recipient := range message.To {
+		if to[i], err = mail.ParseAddress(recipient); err != nil {
+			return fmt.Errorf("invalid recipient address %q: %v", recipient, err)
+		}
+	}
+
+	body, err := Build(from, to, message, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if s.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)); err != nil {
+			return fmt.Errorf("error authenticating: %v", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("error setting sender: %v", err)
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient.Address); err != nil {
+			return fmt.Errorf("error adding recipient %s: %v", recipient.Address, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error starting message: %v", err)
+	}
+	if _, err := writer.Write(body); err != nil {
+		return fmt.Errorf("error writing message: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error sending message: %v", err)
+	}
+
+	// The message is accepted once DATA completes, so a failed QUIT is ignored
+	client.Quit()
+	return nil
+}
+
+// dial connects to the SMTP server and starts the session. The connection is
+// closed when ctx is done.
+func (s *SMTPSender) dial(ctx context.Context) (*smtp.Client, error) {
+	address := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	tlsConfig := &tls.Config{ServerName: s.config.Host}
+	if s.config.TLSConfig != nil {
+		tlsConfig = s.config.TLSConfig.Clone()
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = s.config.Host
+		}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to SMTP server: %v", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+
+	client, err := s.startSession(ctx, conn, tlsConfig)
+	if err != nil {
+		stop()
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// startSession starts the SMTP session on conn, securing it as configured
+func (s *SMTPSender) startSession(ctx context.Context, conn net.Conn, tlsConfig *tls.Config) (*smtp.Client, error) {
+	if s.config.TLS == TLSImplicit {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, fmt.Errorf("error starting TLS: %v", err)
+		}
+		conn = tlsConn
+	}
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		return nil, fmt.Errorf("error starting SMTP session: %v", err)
+	}
+	if s.config.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return nil, errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return nil, fmt.Errorf("error starting TLS: %v", err)
+		}
+	}
+	return client, nil
+}
+
+// Build encodes a message as a MIME email. Messages with an HTML body are
+// sent as multipart/alternative with the text body first.
+func Build(from *mail.Address, to []*mail.Address, message Message, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+
+	recipients := make([]string, len(to))
+	for i, recipient := range to {
+		recipients[i] = recipient.String()
+	}
+	messageID, err := newMessageID(from.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("From", from.String())
+	header.Set("To", strings.Join(recipients, ", "))
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", singleLine(message.Subject)))
+	header.Set("Date", date.Format(time.RFC1123Z))
+	header.Set("Message-ID", messageID)
+	header.Set("MIME-Version", "1.0")
+
+	if message.HTML == "" {
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeHeader(&buf, header)
+		if err := writeQuotedPrintable(&buf, message.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var parts bytes.Buffer
+	writer := multipart.NewWriter(&parts)
+	header.Set("Content-Type", "multipart/alternative; boundary="+writer.Boundary())
+	writeHeader(&buf, header)
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", message.Text},
+		{"text/html; charset=utf-8", message.HTML},
+	} {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating message part: %v", err)
+		}
+		if err := writeQuotedPrintable(partWriter, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error closing message: %v", err)
+	}
+
+	buf.Write(parts.Bytes())
+	return buf.Bytes(), nil
+}
+
+// headerOrder is the order in which message headers are written
+var headerOrder = []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type", "Content-Transfer-Encoding"}
+
+// writeHeader writes the message header and the blank line that ends it
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, key := range headerOrder {
+		if value := header.Get(key); value != "" {
+			buf.WriteString(key + ": " + value + "\r\n")
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+// writeQuotedPrintable writes text to w with quoted-printable encoding
+func writeQuotedPrintable(w io.Writer, text string) error {
+	encoder := quotedprintable.NewWriter(w)
+	if _, err := encoder.Write([]byte(text)); err != nil {
+		return fmt.Errorf("error encoding message body: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("error encoding message body: %v", err)
+	}
+	return nil
+}
+
+// singleLine replaces line breaks so that a value cannot add headers
+func singleLine(value string) string {
+	return strings.Join(strings.Fields(strings.NewReplacer("\r", " ", "\n", " ").Replace(value)), " ")
+}
+
+// newMessageID returns a unique Message-ID in the domain of the sender address
+func newMessageID(from string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generating message ID: %v", err)
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 && at < len(from)-1 {
+		domain = from[at+1:]
+	}
+	return "<" + hex.EncodeToString(buf) + "@" + domain + ">", nil
+}
@@ -0,0 +1,136 @@
+// Package events creates the events produced by the delegation sync and
+// hands them to their consumers
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+// Publisher receives the events produced by the service
+type Publisher interface {
+	Publish(ctx context.Context, events []models.Event) error
+}
+
+// New creates an event with a new ID, encoding data as its payload
+func New(eventType models.EventType, validatorAddress string, data interface{}) (models.Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return models.Event{}, fmt.Errorf("error encoding %s event: %v", eventType, err)
+	}
+	id, err := newID()
+	if err != nil {
+		return models.Event{}, err
+	}
+	return models.Event{
+		ID:               id,
+		Type:             eventType,
+		ValidatorAddress: validatorAddress,
+		CreatedAt:        time.Now().UTC(),
+		Data:             payload,
+	}, nil
+}
+
+// ForDelegationEvent creates the delegation.changed event of a stored
+// delegation event. Its ID is derived from the delegation event, so that the
+// change is always published under the same ID.
+func ForDelegationEvent(change models.DelegationEvent) (models.Event, error) {
+	return derived(fmt.Sprintf("delegation-event-%d", change.ID), models.EventDelegationChanged,
+		change.ValidatorAddress, change.CreatedAt, change)
+}
+
+// ForAlert creates the alert.fired event of a stored alert. Its ID is derived
+// from the alert, so that the alert is always published under the same ID.
+func ForAlert(alert models.Alert) (models.Event, error) {
+	return derived(fmt.Sprintf("alert-%d", alert.ID), models.EventAlertFired,
+		alert.ValidatorAddress, alert.CreatedAt, alert)
+}
+
+// derived creates an event with an ID derived from the stored record it
+// describes
+func derived(id string, eventType models.EventType, validatorAddress string, createdAt time.Time, data interface{}) (models.Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return models.Event{}, fmt.Errorf("error encoding %s event: %v", eventType, err)
+	}
+	return models.Event{
+		ID:               id,
+		Type:             eventType,
+		ValidatorAddress: validatorAddress,
+		CreatedAt:        createdAt.UTC(),
+		Data:             payload,
+	}, nil
+}
+
+// newID returns a random 128-bit event ID
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating event ID: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Multi publishes events to every publisher in order. All publishers are
+// called even if one fails; the errors are joined.
+type Multi []Publisher
+
+// Publish implements Publisher
+func (m Multi) Publish(ctx context.Context, events []models.Event) error {
+	var errs []error
+	for _, publisher := range m {
+		if err := publisher.Publish(ctx, events); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sequence publishes events to every publisher in order, stopping at the
+// first that fails, so that a publisher only gets events once the publishers
+// before it accepted them. When the events are published again, the
+// publishers before the failed one get them again.
+type Sequence []Publisher
+
+// Publish implements Publisher
+func (s Sequence) Publish(ctx context.Context, events []models.Event) error {
+	for _, publisher := range s {
+		if err := publisher.Publish(ctx, events); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BestEffort publishes events to a publisher whose failures are logged rather
+// than returned, so that they are not published again
+type BestEffort struct {
+	Name      string
+	Publisher Publisher
+}
+
+// Publish implements Publisher
+func (b BestEffort) Publish(ctx context.Context, events []models.Event) error {
+	if err := b.Publisher.Publish(ctx, events); err != nil {
+		slog.WarnContext(ctx, "Failed to publish events", "consumer", b.Name, "events", len(events), "error", err)
+	}
+	return nil
+}
+
+// IsKnownType reports whether consumers can subscribe to the event type
+func IsKnownType(eventType models.EventType) bool {
+	for _, known := range models.EventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
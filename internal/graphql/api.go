@@ -0,0 +1,860 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/novintriantonius/cosmos-validator-service/internal/address"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+const (
+	// DefaultPageSize is the number of edges of a connection when first is
+	// not given
+	DefaultPageSize = 20
+	// MaxPageSize is the largest number of edges of a connection
+	MaxPageSize = 100
+
+	// maxHourlyStakeHistory and maxDailyStakeHistory bound the period of a
+	// stake history
+	maxHourlyStakeHistory = 31 * 24 * time.Hour
+	maxDailyStakeHistory  = 731 * 24 * time.Hour
+)
+
+// API executes GraphQL queries over the validators and delegations of the
+// stores. Nested fields are loaded with per-request loaders, so a query
+// costs one store call per field and depth rather than one per object.
+type API struct {
+	schema          gql.Schema
+	validatorStore  store.ValidatorStore
+	delegationStore store.DelegationStore
+	addresses       *address.Validator
+}
+
+// NewAPI creates the GraphQL API. Addresses given as arguments are not
+// validated when addresses is nil.
+func NewAPI(validatorStore store.ValidatorStore, delegationStore store.DelegationStore, addresses *address.Validator) *API {
+	a := &API{
+		validatorStore:  validatorStore,
+		delegationStore: delegationStore,
+		addresses:       addresses,
+	}
+	schema, err := a.newSchema()
+	if err != nil {
+		// The schema is static, so this is a programming error
+		panic(fmt.Sprintf("invalid GraphQL schema: %v", err))
+	}
+	a.schema = schema
+	return a
+}
+
+// Execute executes a GraphQL request
+func (a *API) Execute(ctx context.Context, request Request) *Response {
+	ctx = context.WithValue(ctx, loadersKey{}, a.newLoaders())
+	return a.execute(ctx, request)
+}
+
+// SDL returns the schema of the API in the GraphQL schema definition language
+func (a *API) SDL() string {
+	return printSchema(a.schema)
+}
+
+// loadersKey is the context key of the loaders of a request
+type loadersKey struct{}
+
+// eventsKey identifies the arguments of a Validator.events field, which are
+// loaded together for all validators
+type eventsKey struct {
+	types    string
+	minDelta string
+	since    int64
+	until    int64
+	offset   int
+	limit    int
+}
+
+// delegationsKey identifies the page of a delegations field, which is loaded
+// together for all validators or delegators
+type delegationsKey struct {
+	byDelegator bool
+	offset      int
+	limit       int
+}
+
+// stakeHistoryKey identifies the arguments of a Validator.stakeHistory field
+type stakeHistoryKey struct {
+	interval store.StakeInterval
+	since    int64
+	until    int64
+}
+
+// loaders hold the loaders of one request
+type loaders struct {
+	api *API
+	// now is the time of the request, the default end of periods
+	now             time.Time
+	validators      *Loader[string, *models.Validator]
+	validatorTotals *Loader[string, models.DelegationTotals]
+	delegatorTotals *Loader[string, models.DelegationTotals]
+	delegations     map[delegationsKey]*Loader[string, []models.Delegation]
+	events          map[eventsKey]*Loader[string, []models.DelegationEvent]
+	stakeHistory    map[stakeHistoryKey]*Loader[string, []models.StakePoint]
+}
+
+func (a *API) newLoaders() *loaders {
+	l := &loaders{
+		api:          a,
+		now:          time.Now().UTC(),
+		delegations:  make(map[delegationsKey]*Loader[string, []models.Delegation]),
+		events:       make(map[eventsKey]*Loader[string, []models.DelegationEvent]),
+		stakeHistory: make(map[stakeHistoryKey]*Loader[string, []models.StakePoint]),
+	}
+	l.validators = NewLoader(func(ctx context.Context, addresses []string) (map[string]*models.Validator, error) {
+		validators, err := a.validatorStore.GetByAddresses(ctx, addresses)
+		if err != nil {
+			return nil, err
+		}
+		byAddress := make(map[string]*models.Validator, len(validators))
+		for i := range validators {
+			byAddress[validators[i].Address] = &validators[i]
+		}
+		return byAddress, nil
+	})
+	l.validatorTotals = NewLoader(func(ctx context.Context, addresses []string) (map[string]models.DelegationTotals, error) {
+		return a.delegationStore.GetCurrentDelegationTotals(ctx, store.CurrentDelegationFilter{ValidatorAddresses: addresses})
+	})
+	l.delegatorTotals = NewLoader(func(ctx context.Context, addresses []string) (map[string]models.DelegationTotals, error) {
+		return a.delegationStore.GetCurrentDelegationTotals(ctx, store.CurrentDelegationFilter{DelegatorAddresses: addresses})
+	})
+	return l
+}
+
+// requestLoaders returns the loaders of the request of ctx
+func requestLoaders(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// delegationsLoader returns the loader of a page of the current delegations
+// of validators, or of delegators
+func (l *loaders) delegationsLoader(key delegationsKey) *Loader[string, []models.Delegation] {
+	loader, ok := l.delegations[key]
+	if !ok {
+		loader = NewLoader(func(ctx context.Context, addresses []string) (map[string][]models.Delegation, error) {
+			filter := store.CurrentDelegationFilter{ValidatorAddresses: addresses, Offset: key.offset, Limit: key.limit}
+			if key.byDelegator {
+				filter = store.CurrentDelegationFilter{DelegatorAddresses: addresses, Offset: key.offset, Limit: key.limit}
+			}
+			delegations, err := l.api.delegationStore.GetCurrentDelegations(ctx, filter)
+			if err != nil {
+				return nil, err
+			}
+			byAddress := make(map[string][]models.Delegation, len(addresses))
+			for _, d := range delegations {
+				address := d.ValidatorAddress
+				if key.byDelegator {
+					address = d.DelegatorAddress
+				}
+				byAddress[address] = append(byAddress[address], d)
+			}
+			return byAddress, nil
+		})
+		l.delegations[key] = loader
+	}
+	return loader
+}
+
+// eventsLoader returns the loader of the delegation events matching a filter
+func (l *loaders) eventsLoader(key eventsKey, filter store.DelegationEventFilter) *Loader[string, []models.DelegationEvent] {
+	loader, ok := l.events[key]
+	if !ok {
+		loader = NewLoader(func(ctx context.Context, addresses []string) (map[string][]models.DelegationEvent, error) {
+			return l.api.delegationStore.GetDelegationEventsByValidator(ctx, addresses, filter)
+		})
+		l.events[key] = loader
+	}
+	return loader
+}
+
+// stakeHistoryLoader returns the loader of the stake series of a period
+func (l *loaders) stakeHistoryLoader(key stakeHistoryKey, since, until time.Time) *Loader[string, []models.StakePoint] {
+	loader, ok := l.stakeHistory[key]
+	if !ok {
+		loader = NewLoader(func(ctx context.Context, addresses []string) (map[string][]models.StakePoint, error) {
+			points, err := l.api.delegationStore.GetStakeSeries(ctx, addresses, key.interval, since, until)
+			if err != nil {
+				return nil, err
+			}
+			byValidator := make(map[string][]models.StakePoint, len(addresses))
+			for _, point := range points {
+				byValidator[point.ValidatorAddress] = append(byValidator[point.ValidatorAddress], point)
+			}
+			return byValidator, nil
+		})
+		l.stakeHistory[key] = loader
+	}
+	return loader
+}
+
+// delegator is the source of the Delegator type
+type delegator struct {
+	address string
+}
+
+// page is the range of a connection requested by its first and after
+// arguments
+type page struct {
+	offset int
+	limit  int
+}
+
+// pageArgs returns the arguments of connection fields and the extra
+// arguments of a field
+func pageArgs(extra gql.FieldConfigArgument) gql.FieldConfigArgument {
+	args := gql.FieldConfigArgument{
+		"first": &gql.ArgumentConfig{
+			Type:         gql.Int,
+			DefaultValue: DefaultPageSize,
+			Description:  fmt.Sprintf("Number of edges to return, at most %d.", MaxPageSize),
+		},
+		"after": &gql.ArgumentConfig{Type: gql.String, Description: "Returns the edges after the edge with this cursor."},
+	}
+	for name, arg := range extra {
+		args[name] = arg
+	}
+	return args
+}
+
+// pageOf returns the page requested by the arguments of a connection field
+func pageOf(args map[string]interface{}) (page, error) {
+	p := page{limit: DefaultPageSize}
+	if first, ok := args["first"].(int); ok {
+		if first < 0 || first > MaxPageSize {
+			return p, fmt.Errorf("first must be between 0 and %d", MaxPageSize)
+		}
+		p.limit = first
+	}
+	if after, ok := args["after"].(string); ok {
+		offset, err := decodeCursor(after)
+		if err != nil {
+			return p, err
+		}
+		p.offset = offset + 1
+	}
+	return p, nil
+}
+
+// encodeCursor returns the opaque cursor of the edge at an offset
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset of the edge of a cursor
+func decodeCursor(cursor string) (int, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err == nil {
+		if offset, err := strconv.Atoi(strings.TrimPrefix(string(decoded), "offset:")); err == nil && offset >= 0 &&
+			strings.HasPrefix(string(decoded), "offset:") {
+			return offset, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor %q", cursor)
+}
+
+// connection returns the source of a connection type for the nodes of a page
+func connection(p page, nodes []interface{}, hasNextPage bool, totalCount *int) map[string]interface{} {
+	edges := make([]interface{}, len(nodes))
+	pageInfo := map[string]interface{}{
+		"hasNextPage":     hasNextPage,
+		"hasPreviousPage": p.offset > 0,
+		"startCursor":     nil,
+		"endCursor":       nil,
+	}
+	for i, node := range nodes {
+		edges[i] = map[string]interface{}{"cursor": encodeCursor(p.offset + i), "node": node}
+	}
+	if len(nodes) > 0 {
+		pageInfo["startCursor"] = encodeCursor(p.offset)
+		pageInfo["endCursor"] = encodeCursor(p.offset + len(nodes) - 1)
+	}
+	c := map[string]interface{}{"edges": edges, "nodes": nodes, "pageInfo": pageInfo}
+	if totalCount != nil {
+		c["totalCount"] = *totalCount
+	}
+	return c
+}
+
+// connectionType returns the connection type of a node type and its edge type
+func connectionType(node *gql.Object, description string, totalCount bool) *gql.Object {
+	edge := gql.NewObject(gql.ObjectConfig{
+		Name: node.Name() + "Edge",
+		Fields: gql.Fields{
+			"cursor": &gql.Field{Type: gql.NewNonNull(gql.String), Description: "Pass as after to get the edges following this one."},
+			"node":   &gql.Field{Type: gql.NewNonNull(node)},
+		},
+	})
+	fields := gql.Fields{
+		"edges":    &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(edge)))},
+		"nodes":    &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(node))), Description: "The nodes of the edges, for clients that need no cursors."},
+		"pageInfo": &gql.Field{Type: gql.NewNonNull(pageInfoType)},
+	}
+	if totalCount {
+		fields["totalCount"] = &gql.Field{Type: gql.NewNonNull(gql.Int), Description: "Number of nodes of all pages."}
+	}
+	return gql.NewObject(gql.ObjectConfig{Name: node.Name() + "Connection", Description: description, Fields: fields})
+}
+
+// pageInfoType is the page info of connections
+var pageInfoType = gql.NewObject(gql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: gql.Fields{
+		"hasNextPage":     &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+		"hasPreviousPage": &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+		"startCursor":     &gql.Field{Type: gql.String},
+		"endCursor":       &gql.Field{Type: gql.String},
+	},
+})
+
+// optionalTime returns the time of an argument, or nil
+func optionalTime(args map[string]interface{}, name string) *time.Time {
+	if t, ok := args[name].(time.Time); ok {
+		return &t
+	}
+	return nil
+}
+
+// optionalString returns nil for an empty string, which is null in the
+// response
+func optionalString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// unixNano returns the nanoseconds of an optional time, or 0
+func unixNano(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// validateAddress checks a validator or account address argument
+func (a *API) validateAddress(addr string, validatorAddress bool) error {
+	if a.addresses == nil {
+		return nil
+	}
+	if validatorAddress {
+		return a.addresses.ValidateValidatorAddress(addr)
+	}
+	return a.addresses.ValidateAccountAddress(addr)
+}
+
+// newSchema defines the types of the API
+func (a *API) newSchema() (gql.Schema, error) {
+	// Delegations and events refer back to their validator and delegator, so
+	// their fields are defined when the schema is built
+	var validator, delegatorType *gql.Object
+
+	metadata := gql.NewObject(gql.ObjectConfig{
+		Name:        "ValidatorMetadata",
+		Description: "The on-chain details of a validator.",
+		Fields: gql.Fields{
+			"moniker":        &gql.Field{Type: gql.NewNonNull(gql.String), Resolve: metadataField(func(m *models.ValidatorMetadata) interface{} { return m.Moniker })},
+			"website":        &gql.Field{Type: gql.String, Resolve: metadataField(func(m *models.ValidatorMetadata) interface{} { return optionalString(m.Website) })},
+			"commissionRate": &gql.Field{Type: gql.NewNonNull(DecimalScalar), Resolve: metadataField(func(m *models.ValidatorMetadata) interface{} { return m.CommissionRate })},
+			"status":         &gql.Field{Type: gql.NewNonNull(gql.String), Resolve: metadataField(func(m *models.ValidatorMetadata) interface{} { return m.Status })},
+			"jailed":         &gql.Field{Type: gql.NewNonNull(gql.Boolean), Resolve: metadataField(func(m *models.ValidatorMetadata) interface{} { return m.Jailed })},
+			"tokens":         &gql.Field{Type: gql.NewNonNull(DecimalScalar), Resolve: metadataField(func(m *models.ValidatorMetadata) interface{} { return m.Tokens })},
+			"updatedAt":      &gql.Field{Type: gql.NewNonNull(TimeScalar), Resolve: metadataField(func(m *models.ValidatorMetadata) interface{} { return m.UpdatedAt })},
+		},
+	})
+
+	stake := gql.NewObject(gql.ObjectConfig{
+		Name:        "Stake",
+		Description: "The current delegations of a validator summed up.",
+		Fields: gql.Fields{
+			"delegators": &gql.Field{Type: gql.NewNonNull(gql.Int), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(models.ValidatorStake).Delegators, nil
+			}},
+			"totalShares": &gql.Field{Type: gql.NewNonNull(DecimalScalar), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(models.ValidatorStake).TotalShares, nil
+			}},
+		},
+	})
+
+	delegation := gql.NewObject(gql.ObjectConfig{
+		Name:        "Delegation",
+		Description: "The current delegation of a delegator to a validator.",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"validatorAddress": &gql.Field{Type: gql.NewNonNull(gql.String), Resolve: delegationField(func(d models.Delegation) interface{} { return d.ValidatorAddress })},
+				"delegatorAddress": &gql.Field{Type: gql.NewNonNull(gql.String), Resolve: delegationField(func(d models.Delegation) interface{} { return d.DelegatorAddress })},
+				"shares":           &gql.Field{Type: gql.NewNonNull(DecimalScalar), Resolve: delegationField(func(d models.Delegation) interface{} { return d.DelegationShares })},
+				"updatedAt":        &gql.Field{Type: gql.NewNonNull(TimeScalar), Resolve: delegationField(func(d models.Delegation) interface{} { return d.UpdatedAt })},
+				"validator": &gql.Field{Type: validator, Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return loadValidator(p, p.Source.(models.Delegation).ValidatorAddress), nil
+				}},
+				"delegator": &gql.Field{Type: gql.NewNonNull(delegatorType), Resolve: delegationField(func(d models.Delegation) interface{} {
+					return delegator{address: d.DelegatorAddress}
+				})},
+			}
+		}),
+	})
+	delegationConnection := connectionType(delegation, "A page of delegations.", true)
+	delegatorType = gql.NewObject(gql.ObjectConfig{
+		Name:        "Delegator",
+		Description: "An account delegating to tracked validators.",
+		Fields: gql.Fields{
+			"address": &gql.Field{Type: gql.NewNonNull(gql.String), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(delegator).address, nil
+			}},
+			"delegations": &gql.Field{
+				Type:        gql.NewNonNull(delegationConnection),
+				Description: "The current delegations to tracked validators, by validator.",
+				Args:        pageArgs(nil),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return resolveDelegations(p, p.Source.(delegator).address, true)
+				},
+			},
+			"totalShares": &gql.Field{
+				Type:        gql.NewNonNull(DecimalScalar),
+				Description: "The shares of the current delegations summed up.",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					load := requestLoaders(p.Context).delegatorTotals.Load(p.Context, p.Source.(delegator).address)
+					return func() (interface{}, error) {
+						totals, err := load()
+						if err != nil {
+							return nil, err
+						}
+						return totals.TotalShares, nil
+					}, nil
+				},
+			},
+		},
+	})
+	eventType := gql.NewEnum(gql.EnumConfig{
+		Name: "DelegationEventType",
+		Values: gql.EnumValueConfigMap{
+			"NEW":      &gql.EnumValueConfig{Value: models.DelegationEventNew, Description: "A delegator that did not delegate to the validator before."},
+			"INCREASE": &gql.EnumValueConfig{Value: models.DelegationEventIncrease},
+			"DECREASE": &gql.EnumValueConfig{Value: models.DelegationEventDecrease},
+			"EXIT":     &gql.EnumValueConfig{Value: models.DelegationEventExit, Description: "A delegator that no longer delegates to the validator."},
+		},
+	})
+
+	event := gql.NewObject(gql.ObjectConfig{
+		Name:        "DelegationEvent",
+		Description: "A change of a delegation between two syncs.",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":               &gql.Field{Type: gql.NewNonNull(gql.ID), Resolve: eventField(func(e models.DelegationEvent) interface{} { return e.ID })},
+				"type":             &gql.Field{Type: gql.NewNonNull(eventType), Resolve: eventField(func(e models.DelegationEvent) interface{} { return e.Type })},
+				"validatorAddress": &gql.Field{Type: gql.NewNonNull(gql.String), Resolve: eventField(func(e models.DelegationEvent) interface{} { return e.ValidatorAddress })},
+				"delegatorAddress": &gql.Field{Type: gql.NewNonNull(gql.String), Resolve: eventField(func(e models.DelegationEvent) interface{} { return e.DelegatorAddress })},
+				"previousShares":   &gql.Field{Type: gql.NewNonNull(DecimalScalar), Resolve: eventField(func(e models.DelegationEvent) interface{} { return e.PreviousShares })},
+				"shares":           &gql.Field{Type: gql.NewNonNull(DecimalScalar), Resolve: eventField(func(e models.DelegationEvent) interface{} { return e.Shares })},
+				"delta": &gql.Field{
+					Type:        gql.NewNonNull(DecimalScalar),
+					Description: "Shares minus previousShares, negative for decreases and exits.",
+					Resolve:     eventField(func(e models.DelegationEvent) interface{} { return e.Delta }),
+				},
+				"createdAt": &gql.Field{Type: gql.NewNonNull(TimeScalar), Resolve: eventField(func(e models.DelegationEvent) interface{} { return e.CreatedAt })},
+				"delegator": &gql.Field{Type: gql.NewNonNull(delegatorType), Resolve: eventField(func(e models.DelegationEvent) interface{} {
+					return delegator{address: e.DelegatorAddress}
+				})},
+				"validator": &gql.Field{Type: validator, Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return loadValidator(p, p.Source.(models.DelegationEvent).ValidatorAddress), nil
+				}},
+			}
+		}),
+	})
+
+	stakePoint := gql.NewObject(gql.ObjectConfig{
+		Name:        "StakePoint",
+		Description: "The delegation changes of a validator in an interval.",
+		Fields: gql.Fields{
+			"timestamp": &gql.Field{
+				Type:        gql.NewNonNull(TimeScalar),
+				Description: "The start of the interval.",
+				Resolve:     stakePointField(func(s models.StakePoint) interface{} { return s.Timestamp }),
+			},
+			"stakeChange": &gql.Field{
+				Type:        gql.NewNonNull(DecimalScalar),
+				Description: "The sum of the deltas of the interval.",
+				Resolve:     stakePointField(func(s models.StakePoint) interface{} { return s.StakeChange }),
+			},
+			"totalShares": &gql.Field{
+				Type:        gql.NewNonNull(DecimalScalar),
+				Description: "The stake at the end of the interval.",
+				Resolve:     stakePointField(func(s models.StakePoint) interface{} { return s.TotalShares }),
+			},
+			"newDelegators":  &gql.Field{Type: gql.NewNonNull(gql.Int), Resolve: stakePointField(func(s models.StakePoint) interface{} { return s.NewDelegators })},
+			"lostDelegators": &gql.Field{Type: gql.NewNonNull(gql.Int), Resolve: stakePointField(func(s models.StakePoint) interface{} { return s.LostDelegators })},
+			"events": &gql.Field{
+				Type:        gql.NewNonNull(gql.Int),
+				Description: "The number of delegation changes.",
+				Resolve:     stakePointField(func(s models.StakePoint) interface{} { return s.Events }),
+			},
+		},
+	})
+
+	stakeInterval := gql.NewEnum(gql.EnumConfig{
+		Name: "StakeInterval",
+		Values: gql.EnumValueConfigMap{
+			"HOUR": &gql.EnumValueConfig{Value: store.StakeIntervalHour},
+			"DAY":  &gql.EnumValueConfig{Value: store.StakeIntervalDay, Description: "Days in UTC."},
+		},
+	})
+
+	validator = gql.NewObject(gql.ObjectConfig{
+		Name:        "Validator",
+		Description: "A tracked validator.",
+		Fields: gql.Fields{
+			"address": &gql.Field{Type: gql.NewNonNull(gql.String), Resolve: validatorField(func(v *models.Validator) interface{} { return v.Address })},
+			"name":    &gql.Field{Type: gql.NewNonNull(gql.String), Resolve: validatorField(func(v *models.Validator) interface{} { return v.Name })},
+			"enabledTracking": &gql.Field{
+				Type:        gql.NewNonNull(gql.Boolean),
+				Description: "Whether the delegations of the validator are synced.",
+				Resolve:     validatorField(func(v *models.Validator) interface{} { return v.EnabledTracking }),
+			},
+			"archivedAt": &gql.Field{Type: TimeScalar, Resolve: validatorField(func(v *models.Validator) interface{} { return v.ArchivedAt })},
+			"createdAt":  &gql.Field{Type: gql.NewNonNull(TimeScalar), Resolve: validatorField(func(v *models.Validator) interface{} { return v.CreatedAt })},
+			"updatedAt":  &gql.Field{Type: gql.NewNonNull(TimeScalar), Resolve: validatorField(func(v *models.Validator) interface{} { return v.UpdatedAt })},
+			"metadata": &gql.Field{
+				Type:        metadata,
+				Description: "The on-chain details, null until they were fetched.",
+				Resolve:     validatorField(func(v *models.Validator) interface{} { return v.Metadata }),
+			},
+			"tags": &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(gql.String))), Resolve: validatorField(func(v *models.Validator) interface{} { return v.Tags })},
+			"stake": &gql.Field{
+				Type:        gql.NewNonNull(stake),
+				Description: "The current delegations summed up.",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					validatorAddress := p.Source.(*models.Validator).Address
+					load := requestLoaders(p.Context).validatorTotals.Load(p.Context, validatorAddress)
+					return func() (interface{}, error) {
+						totals, err := load()
+						if err != nil {
+							return nil, err
+						}
+						return models.ValidatorStake{
+							ValidatorAddress: validatorAddress,
+							Delegators:       totals.Delegations,
+							TotalShares:      totals.TotalShares,
+						}, nil
+					}, nil
+				},
+			},
+			"delegations": &gql.Field{
+				Type:        gql.NewNonNull(delegationConnection),
+				Description: "The current delegations, largest first.",
+				Args:        pageArgs(nil),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return resolveDelegations(p, p.Source.(*models.Validator).Address, false)
+				},
+			},
+			"events": &gql.Field{
+				Type:        gql.NewNonNull(connectionType(event, "A page of delegation events.", false)),
+				Description: "The changes of the delegations, newest first.",
+				Args: pageArgs(gql.FieldConfigArgument{
+					"types":    &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(eventType))},
+					"minDelta": &gql.ArgumentConfig{Type: DecimalScalar, Description: "Only changes of at least this many shares, in either direction."},
+					"since":    &gql.ArgumentConfig{Type: TimeScalar},
+					"until":    &gql.ArgumentConfig{Type: TimeScalar},
+				}),
+				Resolve: a.resolveValidatorEvents,
+			},
+			"stakeHistory": &gql.Field{
+				Type:        gql.NewNonNull(gql.NewList(gql.NewNonNull(stakePoint))),
+				Description: "The stake per interval, oldest first. Intervals without delegation changes are left out.",
+				Args: gql.FieldConfigArgument{
+					"interval": &gql.ArgumentConfig{Type: stakeInterval, DefaultValue: store.StakeIntervalDay},
+					"since":    &gql.ArgumentConfig{Type: TimeScalar, Description: "Defaults to 7 days before until for HOUR, and 30 days for DAY."},
+					"until":    &gql.ArgumentConfig{Type: TimeScalar, Description: "Defaults to now."},
+				},
+				Resolve: a.resolveStakeHistory,
+			},
+		},
+	})
+	validatorFilter := gql.NewInputObject(gql.InputObjectConfig{
+		Name: "ValidatorFilter",
+		Fields: gql.InputObjectConfigFieldMap{
+			"search":          &gql.InputObjectFieldConfig{Type: gql.String, Description: "Matches validators whose name contains it, ignoring case."},
+			"tag":             &gql.InputObjectFieldConfig{Type: gql.String},
+			"enabledTracking": &gql.InputObjectFieldConfig{Type: gql.Boolean},
+			"archived":        &gql.InputObjectFieldConfig{Type: gql.Boolean, DefaultValue: false, Description: "Lists archived instead of active validators."},
+		},
+	})
+
+	validatorOrder := gql.NewEnum(gql.EnumConfig{
+		Name: "ValidatorOrder",
+		Values: gql.EnumValueConfigMap{
+			"NAME":       &gql.EnumValueConfig{Value: store.ValidatorSortName},
+			"CREATED_AT": &gql.EnumValueConfig{Value: store.ValidatorSortCreatedAt},
+			"STAKE":      &gql.EnumValueConfig{Value: store.ValidatorSortStake, Description: "The on-chain tokens of the validator."},
+		},
+	})
+
+	query := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"validator": &gql.Field{
+				Type:        validator,
+				Description: "A validator by address, including archived validators.",
+				Args:        gql.FieldConfigArgument{"address": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)}},
+				Resolve:     a.resolveValidator,
+			},
+			"validators": &gql.Field{
+				Type:        gql.NewNonNull(connectionType(validator, "A page of validators.", true)),
+				Description: "The validators matching the filter.",
+				Args: pageArgs(gql.FieldConfigArgument{
+					"filter":     &gql.ArgumentConfig{Type: validatorFilter},
+					"orderBy":    &gql.ArgumentConfig{Type: validatorOrder, Description: "Defaults to NAME, or the archive time for archived validators."},
+					"descending": &gql.ArgumentConfig{Type: gql.Boolean, DefaultValue: false},
+				}),
+				Resolve: a.resolveValidators,
+			},
+			"delegator": &gql.Field{
+				Type:        gql.NewNonNull(delegatorType),
+				Description: "A delegator by account address.",
+				Args:        gql.FieldConfigArgument{"address": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)}},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					addr := p.Args["address"].(string)
+					if err := a.validateAddress(addr, false); err != nil {
+						return nil, err
+					}
+					return delegator{address: addr}, nil
+				},
+			},
+		},
+	})
+
+	return gql.NewSchema(gql.SchemaConfig{Query: query})
+}
+
+// validatorField returns a resolver reading a field of a validator
+func validatorField(get func(v *models.Validator) interface{}) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*models.Validator)), nil
+	}
+}
+
+// metadataField returns a resolver reading a field of validator metadata
+func metadataField(get func(m *models.ValidatorMetadata) interface{}) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*models.ValidatorMetadata)), nil
+	}
+}
+
+// delegationField returns a resolver reading a field of a delegation
+func delegationField(get func(d models.Delegation) interface{}) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(models.Delegation)), nil
+	}
+}
+
+// eventField returns a resolver reading a field of a delegation event
+func eventField(get func(e models.DelegationEvent) interface{}) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(models.DelegationEvent)), nil
+	}
+}
+
+// stakePointField returns a resolver reading a field of a stake point
+func stakePointField(get func(s models.StakePoint) interface{}) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(models.StakePoint)), nil
+	}
+}
+
+// loadValidator returns a thunk loading a validator by address. The executor
+// calls thunks once the other fields of their depth were resolved.
+func loadValidator(p gql.ResolveParams, validatorAddress string) func() (interface{}, error) {
+	load := requestLoaders(p.Context).validators.Load(p.Context, validatorAddress)
+	return func() (interface{}, error) {
+		return load()
+	}
+}
+
+// resolveValidator resolves Query.validator
+func (a *API) resolveValidator(p gql.ResolveParams) (interface{}, error) {
+	addr := p.Args["address"].(string)
+	if err := a.validateAddress(addr, true); err != nil {
+		return nil, err
+	}
+	return loadValidator(p, addr), nil
+}
+
+// resolveValidators resolves Query.validators
+func (a *API) resolveValidators(p gql.ResolveParams) (interface{}, error) {
+	pg, err := pageOf(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := store.ValidatorFilter{Offset: pg.offset, Limit: pg.limit}
+	if f, ok := p.Args["filter"].(map[string]interface{}); ok {
+		filter.Search, _ = f["search"].(string)
+		filter.Tag, _ = f["tag"].(string)
+		filter.Archived, _ = f["archived"].(bool)
+		if enabled, ok := f["enabledTracking"].(bool); ok {
+			filter.EnabledTracking = &enabled
+		}
+	}
+	filter.Sort, _ = p.Args["orderBy"].(store.ValidatorSort)
+	filter.Descending, _ = p.Args["descending"].(bool)
+
+	if pg.limit == 0 {
+		// The store lists every validator for a zero limit, so only count
+		filter.Limit = 1
+	}
+	validators, total, err := a.validatorStore.List(p.Context, filter)
+	if err != nil {
+		return nil, err
+	}
+	if pg.limit == 0 {
+		validators = nil
+	}
+
+	// Prime the loader, so that validators of nested delegations are not
+	// queried again
+	loaders := requestLoaders(p.Context)
+	nodes := make([]interface{}, len(validators))
+	for i := range validators {
+		v := &validators[i]
+		loaders.validators.Prime(v.Address, v)
+		nodes[i] = v
+	}
+	return connection(pg, nodes, pg.offset+len(validators) < total, &total), nil
+}
+
+// resolveDelegations resolves the delegations of a validator, or of a
+// delegator
+func resolveDelegations(p gql.ResolveParams, address string, byDelegator bool) (interface{}, error) {
+	pg, err := pageOf(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	loaders := requestLoaders(p.Context)
+	// One more delegation tells whether there is a next page
+	key := delegationsKey{byDelegator: byDelegator, offset: pg.offset, limit: pg.limit + 1}
+	load := loaders.delegationsLoader(key).Load(p.Context, address)
+	totals := loaders.validatorTotals
+	if byDelegator {
+		totals = loaders.delegatorTotals
+	}
+	loadTotals := totals.Load(p.Context, address)
+	return func() (interface{}, error) {
+		delegations, err := load()
+		if err != nil {
+			return nil, err
+		}
+		hasNextPage := len(delegations) > pg.limit
+		if hasNextPage {
+			delegations = delegations[:pg.limit]
+		}
+		nodes := make([]interface{}, len(delegations))
+		for i, d := range delegations {
+			nodes[i] = d
+		}
+		c := connection(pg, nodes, hasNextPage, nil)
+		// The totals are only loaded when totalCount is selected
+		c["totalCount"] = func() (interface{}, error) {
+			t, err := loadTotals()
+			if err != nil {
+				return nil, err
+			}
+			return t.Delegations, nil
+		}
+		return c, nil
+	}, nil
+}
+
+// resolveValidatorEvents resolves Validator.events
+func (a *API) resolveValidatorEvents(p gql.ResolveParams) (interface{}, error) {
+	pg, err := pageOf(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := store.DelegationEventFilter{
+		Since:  optionalTime(p.Args, "since"),
+		Until:  optionalTime(p.Args, "until"),
+		Offset: pg.offset,
+		// One more event tells whether there is a next page
+		Limit: pg.limit + 1,
+	}
+	filter.MinDelta, _ = p.Args["minDelta"].(string)
+	var typeNames []string
+	if types, ok := p.Args["types"].([]interface{}); ok {
+		for _, t := range types {
+			filter.Types = append(filter.Types, t.(models.DelegationEventType))
+			typeNames = append(typeNames, string(t.(models.DelegationEventType)))
+		}
+		sort.Strings(typeNames)
+	}
+
+	key := eventsKey{
+		types:    strings.Join(typeNames, ","),
+		minDelta: filter.MinDelta,
+		since:    unixNano(filter.Since),
+		until:    unixNano(filter.Until),
+		offset:   filter.Offset,
+		limit:    filter.Limit,
+	}
+	load := requestLoaders(p.Context).eventsLoader(key, filter).Load(p.Context, p.Source.(*models.Validator).Address)
+	return func() (interface{}, error) {
+		events, err := load()
+		if err != nil {
+			return nil, err
+		}
+		hasNextPage := len(events) > pg.limit
+		if hasNextPage {
+			events = events[:pg.limit]
+		}
+		nodes := make([]interface{}, len(events))
+		for i, event := range events {
+			nodes[i] = event
+		}
+		return connection(pg, nodes, hasNextPage, nil), nil
+	}, nil
+}
+
+// resolveStakeHistory resolves Validator.stakeHistory
+func (a *API) resolveStakeHistory(p gql.ResolveParams) (interface{}, error) {
+	loaders := requestLoaders(p.Context)
+	interval := p.Args["interval"].(store.StakeInterval)
+
+	until := loaders.now
+	if t := optionalTime(p.Args, "until"); t != nil {
+		until = *t
+	}
+	maxPeriod := maxDailyStakeHistory
+	since := until.Add(-30 * 24 * time.Hour)
+	if interval == store.StakeIntervalHour {
+		maxPeriod = maxHourlyStakeHistory
+		since = until.Add(-7 * 24 * time.Hour)
+	}
+	if t := optionalTime(p.Args, "since"); t != nil {
+		since = *t
+	}
+	if !since.Before(until) {
+		return nil, fmt.Errorf("since must be before until")
+	}
+	if until.Sub(since) > maxPeriod {
+		return nil, fmt.Errorf("the period of a %s stake history is at most %d days", interval, int(maxPeriod.Hours()/24))
+	}
+
+	key := stakeHistoryKey{interval: interval, since: since.UnixNano(), until: until.UnixNano()}
+	load := loaders.stakeHistoryLoader(key, since, until).Load(p.Context, p.Source.(*models.Validator).Address)
+	return func() (interface{}, error) { return load() }, nil
+}
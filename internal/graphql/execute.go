@@ -0,0 +1,190 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Request is a GraphQL request
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the result of a GraphQL request. Data is left out when the
+// request failed before execution, and is null when a non-null field of the
+// query type failed.
+type Response struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []*Error        `json:"errors,omitempty"`
+}
+
+// Location is a position in a GraphQL document, counted from 1
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is a GraphQL error as returned in the errors of a response. Errors of
+// a field carry the path of the field in the response data.
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// validationRules are the rules of the specification in two passes. The
+// library checks overlapping fields by following fragment spreads, which
+// recurses forever on fragment cycles, so that rule runs once the first pass
+// ruled out cycles.
+var validationRules = [][]gql.ValidationRuleFn{
+	{
+		gql.ArgumentsOfCorrectTypeRule,
+		gql.DefaultValuesOfCorrectTypeRule,
+		gql.FieldsOnCorrectTypeRule,
+		gql.FragmentsOnCompositeTypesRule,
+		gql.KnownArgumentNamesRule,
+		gql.KnownDirectivesRule,
+		gql.KnownFragmentNamesRule,
+		gql.KnownTypeNamesRule,
+		gql.LoneAnonymousOperationRule,
+		gql.NoFragmentCyclesRule,
+		gql.NoUndefinedVariablesRule,
+		gql.NoUnusedFragmentsRule,
+		gql.NoUnusedVariablesRule,
+		gql.PossibleFragmentSpreadsRule,
+		gql.ProvidedNonNullArgumentsRule,
+		gql.ScalarLeafsRule,
+		gql.UniqueArgumentNamesRule,
+		gql.UniqueFragmentNamesRule,
+		gql.UniqueInputFieldNamesRule,
+		gql.UniqueOperationNamesRule,
+		gql.UniqueVariableNamesRule,
+		gql.VariablesAreInputTypesRule,
+		gql.VariablesInAllowedPositionRule,
+	},
+	{gql.OverlappingFieldsCanBeMergedRule},
+}
+
+// execute parses, validates and executes a query. The document is checked
+// against the rules of the specification and the limits of the API before
+// anything is resolved.
+//
+// The executor resolves the thunks returned by resolvers breadth first, after
+// the fields of a depth were resolved, so that a Loader fetches the keys
+// requested at a depth in one batch.
+func (a *API) execute(ctx context.Context, request Request) *Response {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(request.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &Response{Errors: toErrors(gqlerrors.FormatErrors(err))}
+	}
+	for _, rules := range validationRules {
+		if result := gql.ValidateDocument(&a.schema, doc, rules); !result.IsValid {
+			return &Response{Errors: toErrors(result.Errors)}
+		}
+	}
+	op, opErr := selectOperation(doc, request.OperationName)
+	if opErr != nil {
+		return &Response{Errors: []*Error{opErr}}
+	}
+	if errs := a.checkLimits(doc, op, request.Variables); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	result := gql.Execute(gql.ExecuteParams{
+		Schema:        a.schema,
+		AST:           doc,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	})
+	response := &Response{Errors: toErrors(result.Errors)}
+	if result.Data == nil {
+		// Invalid variables fail before execution
+		return response
+	}
+	data, err := json.Marshal(result.Data)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to encode GraphQL response", "error", err)
+		return &Response{Errors: []*Error{{Message: "Internal error encoding the response."}}}
+	}
+	response.Data = data
+	return response
+}
+
+// selectOperation returns the operation of a document to execute. Only
+// queries are supported.
+func selectOperation(doc *ast.Document, operationName string) (*ast.OperationDefinition, *Error) {
+	var operations []*ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		if op, ok := definition.(*ast.OperationDefinition); ok {
+			operations = append(operations, op)
+		}
+	}
+
+	var op *ast.OperationDefinition
+	switch {
+	case len(operations) == 0:
+		return nil, &Error{Message: "Must provide an operation."}
+	case operationName == "" && len(operations) > 1:
+		return nil, &Error{Message: "Must provide operation name if query contains multiple operations."}
+	case operationName == "":
+		op = operations[0]
+	default:
+		for _, candidate := range operations {
+			if candidate.Name != nil && candidate.Name.Value == operationName {
+				op = candidate
+			}
+		}
+		if op == nil {
+			return nil, &Error{Message: fmt.Sprintf("Unknown operation named %q.", operationName)}
+		}
+	}
+
+	if op.Operation != ast.OperationTypeQuery {
+		return nil, &Error{
+			Message:   fmt.Sprintf("Only queries are supported, not %s operations.", op.Operation),
+			Locations: []Location{location(op)},
+		}
+	}
+	return op, nil
+}
+
+// location returns the location of a node of a document
+func location(node ast.Node) Location {
+	loc := gqlerrors.NewLocatedError("", []ast.Node{node}).Locations
+	if len(loc) == 0 {
+		return Location{}
+	}
+	return Location{Line: loc[0].Line, Column: loc[0].Column}
+}
+
+// toErrors converts the errors of the library
+func toErrors(formatted []gqlerrors.FormattedError) []*Error {
+	if len(formatted) == 0 {
+		return nil
+	}
+	errs := make([]*Error, len(formatted))
+	for i, err := range formatted {
+		errs[i] = &Error{Message: err.Message, Path: err.Path}
+		for _, loc := range err.Locations {
+			errs[i].Locations = append(errs[i].Locations, Location{Line: loc.Line, Column: loc.Column})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,241 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// DefaultMaxDepth is the maximum nesting of the fields of a query. The
+	// fields of the query type are at depth 1.
+	DefaultMaxDepth = 12
+	// DefaultMaxComplexity is the maximum complexity of a query. Every field
+	// counts 1, and the subfields of a connection count once per edge of the
+	// page it requests.
+	DefaultMaxComplexity = 10000
+)
+
+// checkLimits checks a valid query against the limits of the API
+func (a *API) checkLimits(doc *ast.Document, op *ast.OperationDefinition, variables map[string]interface{}) []*Error {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	depth := depthCheck{fragments: fragments, checked: make(map[string]int), maxDepth: DefaultMaxDepth}
+	if field := depth.tooDeep(op.SelectionSet, 1); field != nil {
+		return []*Error{{
+			Message:   fmt.Sprintf("Query exceeds the maximum depth of %d.", DefaultMaxDepth),
+			Locations: []Location{location(field)},
+		}}
+	}
+
+	c := complexity{
+		schema:    &a.schema,
+		fragments: fragments,
+		variables: variables,
+		defaults:  make(map[string]ast.Value),
+		costs:     make(map[string]int),
+		max:       DefaultMaxComplexity,
+	}
+	for _, definition := range op.VariableDefinitions {
+		if definition.DefaultValue != nil {
+			c.defaults[definition.Variable.Name.Value] = definition.DefaultValue
+		}
+	}
+	if c.selectionSet(a.schema.QueryType(), op.SelectionSet) > DefaultMaxComplexity {
+		return []*Error{{
+			Message:   fmt.Sprintf("Query exceeds the maximum complexity of %d.", DefaultMaxComplexity),
+			Locations: []Location{location(op)},
+		}}
+	}
+	return nil
+}
+
+// depthCheck finds the fields of a query nested too deep
+type depthCheck struct {
+	fragments map[string]*ast.FragmentDefinition
+	// checked holds the deepest depth at which a fragment was found to fit,
+	// so that a fragment spread many times is walked once per depth at most
+	checked  map[string]int
+	maxDepth int
+}
+
+// tooDeep returns the first field of a selection set nested deeper than the
+// maximum depth, expanding fragments. Validation has ruled out fragment
+// cycles.
+func (d *depthCheck) tooDeep(set *ast.SelectionSet, depth int) *ast.Field {
+	if set == nil {
+		return nil
+	}
+	for _, selection := range set.Selections {
+		var field *ast.Field
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if depth > d.maxDepth {
+				return sel
+			}
+			field = d.tooDeep(sel.SelectionSet, depth+1)
+		case *ast.InlineFragment:
+			field = d.tooDeep(sel.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			fragment, ok := d.fragments[name]
+			if !ok || d.checked[name] >= depth {
+				continue
+			}
+			if field = d.tooDeep(fragment.SelectionSet, depth); field == nil {
+				d.checked[name] = depth
+			}
+		}
+		if field != nil {
+			return field
+		}
+	}
+	return nil
+}
+
+// complexity computes the complexity of a query
+type complexity struct {
+	schema    *gql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// defaults are the default values of the variables of the operation
+	defaults map[string]ast.Value
+	// costs are the complexities of the fragments computed so far
+	costs map[string]int
+	// max bounds the computed complexities, which only need to be compared
+	// with it
+	max int
+}
+
+// selectionSet returns the complexity of the selections of an object type
+func (c *complexity) selectionSet(object *gql.Object, set *ast.SelectionSet) int {
+	if set == nil {
+		return 0
+	}
+	total := 0
+	for _, selection := range set.Selections {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			total = c.add(total, c.field(object, sel))
+		case *ast.InlineFragment:
+			condition := object
+			if sel.TypeCondition != nil {
+				condition = c.object(sel.TypeCondition.Name.Value)
+			}
+			total = c.add(total, c.selectionSet(condition, sel.SelectionSet))
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			cost, ok := c.costs[name]
+			if !ok {
+				if fragment, ok := c.fragments[name]; ok {
+					cost = c.selectionSet(c.object(fragment.TypeCondition.Name.Value), fragment.SelectionSet)
+				}
+				c.costs[name] = cost
+			}
+			total = c.add(total, cost)
+		}
+	}
+	return total
+}
+
+// field returns the complexity of a field and its subfields. The subfields of
+// a field with a first argument count once per edge of the page.
+func (c *complexity) field(object *gql.Object, field *ast.Field) int {
+	if field.SelectionSet == nil {
+		return 1
+	}
+
+	// Introspection fields are not fields of the object, and count once
+	var definition *gql.FieldDefinition
+	var subfields *gql.Object
+	if object != nil {
+		definition = object.Fields()[field.Name.Value]
+	}
+	if definition != nil {
+		subfields, _ = gql.GetNamed(definition.Type).(*gql.Object)
+	}
+	return c.add(1, c.multiply(c.pageSize(definition, field), c.selectionSet(subfields, field.SelectionSet)))
+}
+
+// pageSize returns the number of edges a field requests, or 1 for fields
+// without a first argument
+func (c *complexity) pageSize(definition *gql.FieldDefinition, field *ast.Field) int {
+	if definition == nil {
+		return 1
+	}
+	var first *gql.Argument
+	for _, arg := range definition.Args {
+		if arg.Name() == "first" {
+			first = arg
+		}
+	}
+	if first == nil {
+		return 1
+	}
+
+	size := DefaultPageSize
+	if defaultSize, ok := first.DefaultValue.(int); ok {
+		size = defaultSize
+	}
+	for _, arg := range field.Arguments {
+		if arg.Name.Value == "first" {
+			if value, ok := c.intValue(arg.Value); ok {
+				size = value
+			}
+		}
+	}
+	if size < 0 {
+		return 0
+	}
+	return size
+}
+
+// intValue returns the value of an Int argument given as a literal or a
+// variable
+func (c *complexity) intValue(value ast.Value) (int, bool) {
+	switch v := value.(type) {
+	case *ast.IntValue:
+		n, err := strconv.Atoi(v.Value)
+		return n, err == nil
+	case *ast.Variable:
+		switch n := c.variables[v.Name.Value].(type) {
+		case int:
+			return n, true
+		case float64:
+			return int(n), true
+		}
+		if defaultValue, ok := c.defaults[v.Name.Value]; ok {
+			return c.intValue(defaultValue)
+		}
+	}
+	return 0, false
+}
+
+// object returns the object type of a name, or nil
+func (c *complexity) object(name string) *gql.Object {
+	object, _ := c.schema.Type(name).(*gql.Object)
+	return object
+}
+
+// add adds complexities, stopping above the maximum
+func (c *complexity) add(a, b int) int {
+	if a+b > c.max {
+		return c.max + 1
+	}
+	return a + b
+}
+
+// multiply multiplies complexities, stopping above the maximum
+func (c *complexity) multiply(a, b int) int {
+	if a != 0 && b > c.max/a {
+		return c.max + 1
+	}
+	return a * b
+}
@@ -0,0 +1,77 @@
+package graphql
+
+import "context"
+
+// BatchFunc loads the values of keys in one call. Keys missing from the
+// result have the zero value.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader batches and caches the loading of values by key, so that resolving
+// a field of many objects does not query the store once per object. Keys
+// are queued by Load and fetched together when the first of the returned
+// thunks is called. A Loader lives for one request and is not safe for
+// concurrent use; the executor calls resolvers and thunks on one goroutine.
+type Loader[K comparable, V any] struct {
+	fetch   BatchFunc[K, V]
+	queue   []K
+	queued  map[K]bool
+	loaded  map[K]bool
+	results map[K]V
+	errs    map[K]error
+}
+
+// NewLoader creates a loader fetching values with fetch
+func NewLoader[K comparable, V any](fetch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:   fetch,
+		queued:  make(map[K]bool),
+		loaded:  make(map[K]bool),
+		results: make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+// Load queues a key and returns a function returning its value, which loads
+// all the queued keys when the value was not loaded yet
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	if !l.loaded[key] && !l.queued[key] {
+		l.queued[key] = true
+		l.queue = append(l.queue, key)
+	}
+	return func() (V, error) {
+		if !l.loaded[key] {
+			l.dispatch(ctx)
+		}
+		return l.results[key], l.errs[key]
+	}
+}
+
+// Prime stores the value of a key that was loaded by other means, unless the
+// key was loaded already
+func (l *Loader[K, V]) Prime(key K, value V) {
+	if l.loaded[key] || l.queued[key] {
+		return
+	}
+	l.loaded[key] = true
+	l.results[key] = value
+}
+
+// dispatch fetches the queued keys
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	keys := l.queue
+	l.queue = nil
+	l.queued = make(map[K]bool)
+	if len(keys) == 0 {
+		return
+	}
+
+	results, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		l.loaded[key] = true
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		l.results[key] = results[key]
+	}
+}
@@ -0,0 +1,97 @@
+package graphql
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"time"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// TimeScalar is a point in time in RFC 3339 format, such as
+// "2024-05-01T10:00:00Z"
+var TimeScalar = gql.NewScalar(gql.ScalarConfig{
+	Name:        "Time",
+	Description: "A point in time in RFC 3339 format, such as \"2024-05-01T10:00:00Z\".",
+	Serialize: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case time.Time:
+			return v.Format(time.RFC3339Nano)
+		case *time.Time:
+			if v == nil {
+				return nil
+			}
+			return v.Format(time.RFC3339Nano)
+		}
+		return nil
+	},
+	ParseValue: parseTime,
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		if s, ok := valueAST.(*ast.StringValue); ok {
+			return parseTime(s.Value)
+		}
+		return nil
+	},
+})
+
+// DecimalScalar is an arbitrary precision decimal number, serialized as a
+// string so that no precision is lost
+var DecimalScalar = gql.NewScalar(gql.ScalarConfig{
+	Name:        "Decimal",
+	Description: "An arbitrary precision decimal number serialized as a string, such as \"1500.25\".",
+	Serialize: func(value interface{}) interface{} {
+		if s, ok := value.(fmt.Stringer); ok {
+			return s.String()
+		}
+		if v := reflect.ValueOf(value); v.Kind() == reflect.String {
+			return v.String()
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case string:
+			return parseDecimal(v)
+		case float64:
+			return parseDecimal(strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		switch v := valueAST.(type) {
+		case *ast.StringValue:
+			return parseDecimal(v.Value)
+		case *ast.IntValue:
+			return parseDecimal(v.Value)
+		case *ast.FloatValue:
+			return parseDecimal(v.Value)
+		}
+		return nil
+	},
+})
+
+// parseTime parses an RFC 3339 time, or returns nil for the library to
+// report the value as invalid
+func parseTime(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil
+	}
+	return t
+}
+
+// parseDecimal checks a decimal number, or returns nil for the library to
+// report the value as invalid
+func parseDecimal(s string) interface{} {
+	if _, ok := new(big.Rat).SetString(s); !ok {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,146 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+)
+
+// printSchema returns a schema in the GraphQL schema definition language,
+// without the built-in scalars and introspection types. Types, fields and
+// arguments are sorted by name.
+func printSchema(schema gql.Schema) string {
+	var b strings.Builder
+	b.WriteString("schema {\n  query: " + schema.QueryType().Name() + "\n}\n")
+
+	typeMap := schema.TypeMap()
+	names := make([]string, 0, len(typeMap))
+	for name := range typeMap {
+		if !builtInType(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b.WriteString("\n")
+		switch t := typeMap[name].(type) {
+		case *gql.Scalar:
+			writeDescription(&b, t.Description(), "")
+			b.WriteString("scalar " + t.Name() + "\n")
+		case *gql.Enum:
+			writeDescription(&b, t.Description(), "")
+			b.WriteString("enum " + t.Name() + " {\n")
+			values := t.Values()
+			sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+			for _, v := range values {
+				writeDescription(&b, v.Description, "  ")
+				b.WriteString("  " + v.Name + describeDeprecation(v.DeprecationReason) + "\n")
+			}
+			b.WriteString("}\n")
+		case *gql.InputObject:
+			writeDescription(&b, t.Description(), "")
+			b.WriteString("input " + t.Name() + " {\n")
+			fields := t.Fields()
+			for _, fieldName := range sortedKeys(fields) {
+				f := fields[fieldName]
+				writeDescription(&b, f.Description(), "  ")
+				b.WriteString("  " + inputValue(f.Name(), f.Type, f.DefaultValue) + "\n")
+			}
+			b.WriteString("}\n")
+		case *gql.Object:
+			writeDescription(&b, t.Description(), "")
+			b.WriteString("type " + t.Name() + " {\n")
+			fields := t.Fields()
+			for _, fieldName := range sortedKeys(fields) {
+				f := fields[fieldName]
+				writeDescription(&b, f.Description, "  ")
+				b.WriteString("  " + f.Name)
+				if len(f.Args) > 0 {
+					args := make([]string, len(f.Args))
+					for i, arg := range f.Args {
+						args[i] = inputValue(arg.Name(), arg.Type, arg.DefaultValue)
+					}
+					sort.Strings(args)
+					b.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				b.WriteString(": " + f.Type.String() + describeDeprecation(f.DeprecationReason) + "\n")
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// inputValue returns an argument or input field in SDL notation
+func inputValue(name string, typ gql.Input, defaultValue interface{}) string {
+	s := name + ": " + typ.String()
+	if defaultValue != nil {
+		s += " = " + printValue(defaultValue, typ)
+	}
+	return s
+}
+
+// printValue returns a default value as a GraphQL literal
+func printValue(value interface{}, typ gql.Input) string {
+	if nonNull, ok := typ.(*gql.NonNull); ok {
+		typ = nonNull.OfType.(gql.Input)
+	}
+	switch t := typ.(type) {
+	case *gql.Enum:
+		if name, ok := t.Serialize(value).(string); ok {
+			return name
+		}
+	case *gql.Scalar:
+		if s, ok := t.Serialize(value).(string); ok {
+			return fmt.Sprintf("%q", s)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// describeDeprecation returns the deprecation directive of a reason
+func describeDeprecation(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return fmt.Sprintf(" @deprecated(reason: %q)", reason)
+}
+
+// writeDescription writes a description as a block string
+func writeDescription(b *strings.Builder, description, indent string) {
+	if description == "" {
+		return
+	}
+	if !strings.Contains(description, "\n") {
+		b.WriteString(indent + fmt.Sprintf("%q", description) + "\n")
+		return
+	}
+	b.WriteString(indent + `"""` + "\n")
+	for _, line := range strings.Split(description, "\n") {
+		b.WriteString(indent + line + "\n")
+	}
+	b.WriteString(indent + `"""` + "\n")
+}
+
+// builtInType reports whether a type is a built-in scalar or an introspection
+// type
+func builtInType(name string) bool {
+	switch name {
+	case "Int", "Float", "String", "Boolean", "ID":
+		return true
+	}
+	return strings.HasPrefix(name, "__")
+}
@@ -2,7 +2,7 @@ package handlers
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -11,76 +11,91 @@ import (
 // Task represents a function that will be executed on a schedule
 type Task func(ctx context.Context) error
 
+// DefaultTaskTimeout is the default maximum duration of a single task run
+const DefaultTaskTimeout = 55 * time.Second
+
 // Scheduler manages scheduled tasks
 type Scheduler struct {
-	cron *cron.Cron
+	cron        *cron.Cron
+	taskTimeout time.Duration
 }
 
 // NewScheduler creates a new scheduler instance
 func NewScheduler() *Scheduler {
+	return NewSchedulerWithTimeout(DefaultTaskTimeout)
+}
+
+// NewSchedulerWithTimeout creates a new scheduler instance whose task runs are
+// cancelled after taskTimeout
+func NewSchedulerWithTimeout(taskTimeout time.Duration) *Scheduler {
+	if taskTimeout <= 0 {
+		taskTimeout = DefaultTaskTimeout
+	}
+
 	// Create a new cron instance with seconds field enabled
 	c := cron.New(cron.WithSeconds())
 	
 	return &Scheduler{
-		cron: c,
+		cron:        c,
+		taskTimeout: taskTimeout,
 	}
 }
 
 // Start starts the scheduler
 func (s *Scheduler) Start() {
 	s.cron.Start()
-	log.Println("Scheduler started")
+	slog.Info("Scheduler started")
 }
 
 // Stop stops the scheduler
 func (s *Scheduler) Stop() {
 	ctx := s.cron.Stop()
 	<-ctx.Done()
-	log.Println("Scheduler stopped")
+	slog.Info("Scheduler stopped")
 }
 
 // AddHourlyTask adds a task to be executed every hour
 func (s *Scheduler) AddHourlyTask(taskName string, task Task) {
 	_, err := s.cron.AddFunc("0 0 * * * *", func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 55*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), s.taskTimeout)
 		defer cancel()
 		
-		log.Printf("Running task: %s", taskName)
+		slog.InfoContext(ctx, "Running task", "task", taskName)
 		start := time.Now()
 		
 		if err := task(ctx); err != nil {
-			log.Printf("Task %s failed: %v", taskName, err)
+			slog.ErrorContext(ctx, "Task failed", "task", taskName, "error", err)
 		} else {
-			log.Printf("Task %s completed in %v", taskName, time.Since(start))
+			slog.InfoContext(ctx, "Task completed", "task", taskName, "duration", time.Since(start))
 		}
 	})
 	
 	if err != nil {
-		log.Printf("Failed to schedule task %s: %v", taskName, err)
+		slog.Error("Failed to schedule task", "task", taskName, "error", err)
 	} else {
-		log.Printf("Task %s scheduled to run hourly", taskName)
+		slog.Info("Task scheduled to run hourly", "task", taskName)
 	}
 }
 
 // AddCustomScheduleTask adds a task with a custom cron schedule
 func (s *Scheduler) AddCustomScheduleTask(taskName string, cronSchedule string, task Task) {
 	_, err := s.cron.AddFunc(cronSchedule, func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 55*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), s.taskTimeout)
 		defer cancel()
 		
-		log.Printf("Running task: %s", taskName)
+		slog.InfoContext(ctx, "Running task", "task", taskName)
 		start := time.Now()
 		
 		if err := task(ctx); err != nil {
-			log.Printf("Task %s failed: %v", taskName, err)
+			slog.ErrorContext(ctx, "Task failed", "task", taskName, "error", err)
 		} else {
-			log.Printf("Task %s completed in %v", taskName, time.Since(start))
+			slog.InfoContext(ctx, "Task completed", "task", taskName, "duration", time.Since(start))
 		}
 	})
 	
 	if err != nil {
-		log.Printf("Failed to schedule task %s with schedule %s: %v", taskName, cronSchedule, err)
+		slog.Error("Failed to schedule task", "task", taskName, "schedule", cronSchedule, "error", err)
 	} else {
-		log.Printf("Task %s scheduled with custom schedule: %s", taskName, cronSchedule)
+		slog.Info("Task scheduled", "task", taskName, "schedule", cronSchedule)
 	}
 } 
\ No newline at end of file
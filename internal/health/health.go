@@ -0,0 +1,187 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/config"
+	"github.com/novintriantonius/cosmos-validator-service/internal/database"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+// Status is the health state of the service or a single check
+type Status string
+
+const (
+	// StatusOK means everything is working
+	StatusOK Status = "ok"
+
+	// StatusDegraded means the service is serving requests but data may be stale
+	StatusDegraded Status = "degraded"
+
+	// StatusDown means a dependency required to serve requests is unavailable
+	StatusDown Status = "down"
+)
+
+// Pinger checks that an external dependency is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// SyncStatusSource provides the delegation sync state of validators
+type SyncStatusSource interface {
+	GetSyncStatuses(ctx context.Context) ([]models.ValidatorSyncStatus, error)
+}
+
+// CheckResult is the outcome of a single dependency check
+type CheckResult struct {
+	Status   Status `json:"status"`
+	Message  string `json:"message,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// ValidatorSyncReport describes how fresh the delegation data of a validator is
+type ValidatorSyncReport struct {
+	Address        string     `json:"address"`
+	Status         Status     `json:"status"`
+	LastSyncedAt   *time.Time `json:"lastSyncedAt"`
+	SyncAgeSeconds *int64     `json:"syncAgeSeconds"`
+	LastSyncError  string     `json:"lastSyncError,omitempty"`
+}
+
+// Report is the detailed health report of the service
+type Report struct {
+	Status     Status                 `json:"status"`
+	CheckedAt  time.Time              `json:"checkedAt"`
+	Checks     map[string]CheckResult `json:"checks"`
+	Validators []ValidatorSyncReport  `json:"validators,omitempty"`
+}
+
+// Checker runs the liveness, readiness and health checks
+type Checker struct {
+	db         *sql.DB
+	cosmos     Pinger
+	validators SyncStatusSource
+	config     config.HealthConfig
+	now        func() time.Time
+}
+
+// NewChecker creates a new health checker
+func NewChecker(db *sql.DB, cosmos Pinger, validators SyncStatusSource, cfg config.HealthConfig) *Checker {
+	return &Checker{
+		db:         db,
+		cosmos:     cosmos,
+		validators: validators,
+		config:     cfg,
+		now:        time.Now,
+	}
+}
+
+// Readiness checks the dependencies required to serve requests: the database
+// connection, the migration status and the Cosmos API
+func (c *Checker) Readiness(ctx context.Context) Report {
+	report := Report{
+		Status:    StatusOK,
+		CheckedAt: c.now().UTC(),
+		Checks: map[string]CheckResult{
+			"database":   c.runCheck(ctx, c.checkDatabase),
+			"migrations": c.runCheck(ctx, c.checkMigrations),
+			"cosmos":     c.runCheck(ctx, c.cosmos.Ping),
+		},
+	}
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// Report runs the readiness checks and adds the sync freshness of every tracked
+// validator. A validator whose last successful sync is older than the configured
+// maximum age, or whose last sync failed, marks the service as degraded.
+func (c *Checker) Report(ctx context.Context) Report {
+	report := c.Readiness(ctx)
+	if report.Status == StatusDown {
+		return report
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, c.config.CheckTimeout.Std())
+	defer cancel()
+
+	start := time.Now()
+	statuses, err := c.validators.GetSyncStatuses(checkCtx)
+	if err != nil {
+		report.Checks["validators"] = CheckResult{Status: StatusDown, Message: err.Error(), Duration: time.Since(start).String()}
+		report.Status = StatusDegraded
+		return report
+	}
+	report.Checks["validators"] = CheckResult{Status: StatusOK, Duration: time.Since(start).String()}
+
+	now := c.now()
+	maxAge := c.config.MaxSyncAge.Std()
+	for _, status := range statuses {
+		if !status.EnabledTracking {
+			continue
+		}
+
+		validatorReport := ValidatorSyncReport{
+			Address:       status.Address,
+			Status:        StatusOK,
+			LastSyncedAt:  status.LastSyncedAt,
+			LastSyncError: status.LastSyncError,
+		}
+
+		// Validators that have never synced are only stale once they are older than the threshold
+		reference := status.CreatedAt
+		if status.LastSyncedAt != nil {
+			reference = *status.LastSyncedAt
+			age := int64(now.Sub(reference).Seconds())
+			validatorReport.SyncAgeSeconds = &age
+		}
+		if now.Sub(reference) > maxAge || status.LastSyncError != "" {
+			validatorReport.Status = StatusDegraded
+			report.Status = StatusDegraded
+		}
+
+		report.Validators = append(report.Validators, validatorReport)
+	}
+
+	return report
+}
+
+// runCheck runs a single check with the configured timeout
+func (c *Checker) runCheck(ctx context.Context, check func(ctx context.Context) error) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.config.CheckTimeout.Std())
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := CheckResult{Status: StatusOK, Duration: time.Since(start).String()}
+	if err != nil {
+		result.Status = StatusDown
+		result.Message = err.Error()
+	}
+	return result
+}
+
+// checkDatabase pings the database
+func (c *Checker) checkDatabase(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
+// checkMigrations fails when embedded migrations have not been applied
+func (c *Checker) checkMigrations(ctx context.Context) error {
+	pending, err := database.PendingMigrations(ctx, c.db)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
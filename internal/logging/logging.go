@@ -0,0 +1,149 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// RequestIDKey is the log attribute holding the HTTP request ID
+	RequestIDKey = "request_id"
+
+	// SyncRunIDKey is the log attribute holding the delegation sync run ID
+	SyncRunIDKey = "sync_run_id"
+
+	// TraceIDKey is the log attribute holding the OpenTelemetry trace ID
+	TraceIDKey = "trace_id"
+)
+
+type contextKey int
+
+const (
+	requestIDContextKey contextKey = iota
+	syncRunIDContextKey
+)
+
+// New creates a logger writing to w with the configured level and format
+func New(cfg config.LoggingConfig, w io.Writer) (*slog.Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text", "":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	return slog.New(&contextHandler{next: handler}), nil
+}
+
+// Setup creates a logger writing to stderr and installs it as the default
+// logger, which also routes the standard library log package through it
+func Setup(cfg config.LoggingConfig) (*slog.Logger, error) {
+	logger, err := New(cfg, os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(logger)
+	return logger, nil
+}
+
+// ParseLevel converts a level name (debug, info, warn, error) into a slog.Level
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+// NewID returns a random 16 character hex identifier
+func NewID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "0000000000000000"
+	}
+	return hex.EncodeToString(b)
+}
+
+// WithRequestID returns a context carrying the HTTP request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, id)
+}
+
+// RequestIDFromContext returns the HTTP request ID stored in ctx, if any
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+// WithSyncRunID returns a context carrying the delegation sync run ID
+func WithSyncRunID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, syncRunIDContextKey, id)
+}
+
+// SyncRunIDFromContext returns the delegation sync run ID stored in ctx, if any
+func SyncRunIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(syncRunIDContextKey).(string)
+	return id
+}
+
+// contextHandler adds correlation IDs found in the record context to every record
+type contextHandler struct {
+	next slog.Handler
+}
+
+// Enabled implements slog.Handler
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if id := RequestIDFromContext(ctx); id != "" {
+			record.AddAttrs(slog.String(RequestIDKey, id))
+		}
+		if id := SyncRunIDFromContext(ctx); id != "" {
+			record.AddAttrs(slog.String(SyncRunIDKey, id))
+		}
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+			record.AddAttrs(slog.String(TraceIDKey, spanContext.TraceID().String()))
+		}
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name)}
+}
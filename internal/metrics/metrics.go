@@ -0,0 +1,191 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric exported by the service
+const namespace = "cosmos_validator"
+
+// Registry holds all collectors exported on /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequestsTotal counts HTTP requests by method, route template and status code
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration observes HTTP request latency by method and route template
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency in seconds by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// CosmosRequestDuration observes Cosmos API call latency per endpoint
+	CosmosRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "cosmos",
+		Name:      "request_duration_seconds",
+		Help:      "Cosmos API request latency in seconds per endpoint, including retries.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"endpoint"})
+
+	// CosmosRequestErrors counts Cosmos API calls that failed after all retries
+	CosmosRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cosmos",
+		Name:      "request_errors_total",
+		Help:      "Total number of Cosmos API requests that failed after all retries, per endpoint.",
+	}, []string{"endpoint"})
+
+	// CosmosRequestRetries counts Cosmos API request retries
+	CosmosRequestRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cosmos",
+		Name:      "request_retries_total",
+		Help:      "Total number of Cosmos API request retries, per endpoint.",
+	}, []string{"endpoint"})
+
+	// SyncRunDuration observes the duration of complete delegation sync runs
+	SyncRunDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "run_duration_seconds",
+		Help:      "Duration of delegation sync runs in seconds.",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600},
+	})
+
+	// SyncDelegationsTotal counts delegations handled per validator by result (inserted, skipped, exited)
+	SyncDelegationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "delegations_total",
+		Help:      "Total number of delegations processed by the sync, per validator and result.",
+	}, []string{"validator", "result"})
+
+	// SyncErrorsTotal counts failed validator syncs
+	SyncErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "errors_total",
+		Help:      "Total number of failed delegation syncs per validator.",
+	}, []string{"validator"})
+
+	// SyncLastSuccessTimestamp records when each validator was last synced successfully
+	SyncLastSuccessTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Unix timestamp of the last successful delegation sync per validator.",
+	}, []string{"validator"})
+
+	// AlertsFiredTotal counts alerts fired by whale movement alert rules
+	AlertsFiredTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "alerts",
+		Name:      "fired_total",
+		Help:      "Total number of alerts fired per validator.",
+	}, []string{"validator"})
+
+	// WebhookDeliveriesTotal counts webhook delivery attempts by result (delivered, retry, failed)
+	WebhookDeliveriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhooks",
+		Name:      "deliveries_total",
+		Help:      "Total number of webhook delivery attempts by result.",
+	}, []string{"result"})
+
+	// NotificationsSentTotal counts chat notifications by channel type and result (sent, failed)
+	NotificationsSentTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "notifications",
+		Name:      "sent_total",
+		Help:      "Total number of chat notifications by channel type and result.",
+	}, []string{"channel_type", "result"})
+
+	// EmailDigestsSentTotal counts email digests by frequency and result (sent, failed)
+	EmailDigestsSentTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "email_digests",
+		Name:      "sent_total",
+		Help:      "Total number of email digests by frequency and result.",
+	}, []string{"frequency", "result"})
+
+	// StreamSubscribers tracks the open live delegation change streams by transport (sse, websocket)
+	StreamSubscribers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "stream",
+		Name:      "subscribers",
+		Help:      "Number of open live delegation change streams by transport.",
+	}, []string{"transport"})
+
+	// StreamSubscribersLaggedTotal counts subscribers dropped because they fell behind the change feed
+	StreamSubscribersLaggedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "stream",
+		Name:      "subscribers_lagged_total",
+		Help:      "Total number of live stream subscribers dropped because their buffer was full.",
+	})
+
+	// BusMessagesTotal counts message bus publish attempts per message by result (published, retry)
+	BusMessagesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "bus",
+		Name:      "messages_total",
+		Help:      "Total number of message bus publish attempts per message by result.",
+	}, []string{"result"})
+
+	// OutboxEventsTotal counts event outbox relay attempts per event by result (relayed, retry)
+	OutboxEventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "events_total",
+		Help:      "Total number of event outbox relay attempts per event by result.",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		CosmosRequestDuration,
+		CosmosRequestErrors,
+		CosmosRequestRetries,
+		SyncRunDuration,
+		SyncDelegationsTotal,
+		SyncErrorsTotal,
+		SyncLastSuccessTimestamp,
+		AlertsFiredTotal,
+		WebhookDeliveriesTotal,
+		NotificationsSentTotal,
+		EmailDigestsSentTotal,
+		StreamSubscribers,
+		StreamSubscribersLaggedTotal,
+		BusMessagesTotal,
+		OutboxEventsTotal,
+	)
+}
+
+// RegisterDBStats exports connection pool statistics for db
+func RegisterDBStats(db *sql.DB, dbName string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// Handler returns the HTTP handler serving the metrics in Prometheus format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
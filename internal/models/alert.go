@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// AlertRule fires an alert when a delegation moves more stake on or off a
+// validator than its thresholds. Every threshold that is set must be met.
+type AlertRule struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// ValidatorAddress limits the rule to one validator. Rules without it
+	// apply to every validator.
+	ValidatorAddress string `json:"validator_address,omitempty"`
+	// MinDelta is the smallest absolute change of shares that fires the rule
+	MinDelta string `json:"min_delta,omitempty"`
+	// MinStakePercent is the smallest absolute change of shares, as a
+	// percentage of the validator's tokens, that fires the rule
+	MinStakePercent string    `json:"min_stake_percent,omitempty"`
+	Enabled         bool      `json:"enabled"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Alert is the firing of an alert rule for a delegation event
+type Alert struct {
+	ID int64 `json:"id"`
+	// RuleID is nil once the rule has been deleted; RuleName is kept
+	RuleID            *int                `json:"rule_id"`
+	RuleName          string              `json:"rule_name"`
+	DelegationEventID int64               `json:"delegation_event_id"`
+	ValidatorAddress  string              `json:"validator_address"`
+	DelegatorAddress  string              `json:"delegator_address"`
+	EventType         DelegationEventType `json:"event_type"`
+	Delta             string              `json:"delta"`
+	// StakePercent is the absolute delta as a percentage of the validator's
+	// tokens. It is empty when the validator's tokens are unknown.
+	StakePercent string    `json:"stake_percent,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// Role is the permission level granted to an API key
+type Role string
+
+const (
+	// RoleReadOnly may only read validators and delegations
+	RoleReadOnly Role = "read-only"
+
+	// RoleOperator may additionally create, update and delete validators
+	RoleOperator Role = "operator"
+
+	// RoleAdmin may additionally manage API keys
+	RoleAdmin Role = "admin"
+)
+
+// roleRanks orders roles so that higher roles include the permissions of lower ones
+var roleRanks = map[Role]int{
+	RoleReadOnly: 1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// Valid reports whether the role is a known role
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Allows reports whether the role grants the permissions of the required role
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[required]
+}
+
+// APIKey represents an API key. The secret itself is never stored, only its hash.
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Role       Role       `json:"role"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RotatedAt  *time.Time `json:"rotatedAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction is the kind of change recorded by an audit event
+type AuditAction string
+
+const (
+	// AuditActionCreate records that an entity was created
+	AuditActionCreate AuditAction = "create"
+
+	// AuditActionUpdate records that an entity was changed
+	AuditActionUpdate AuditAction = "update"
+
+	// AuditActionDelete records that an entity was deleted
+	AuditActionDelete AuditAction = "delete"
+
+	// AuditActionArchive records that an entity was archived
+	AuditActionArchive AuditAction = "archive"
+
+	// AuditActionRestore records that an archived entity was restored
+	AuditActionRestore AuditAction = "restore"
+)
+
+// AuditEntityValidator is the entity type of validator audit events
+const AuditEntityValidator = "validator"
+
+// AuditEvent records a configuration change, who made it and the state of
+// the entity before and after the change
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	EntityType string          `json:"entityType"`
+	EntityID   string          `json:"entityId"`
+	Action     AuditAction     `json:"action"`
+	Actor      string          `json:"actor"`
+	ActorKeyID *int            `json:"actorKeyId,omitempty"`
+	RequestID  string          `json:"requestId,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BusMessage is an event queued in the outbox for, or published to, the
+// message bus
+type BusMessage struct {
+	ID      int64  `json:"id"`
+	EventID string `json:"eventId"`
+	// Topic is the Kafka topic, or the NATS subject prefix, of the message
+	Topic string `json:"topic"`
+	// Key orders and partitions the messages. It is the validator address of
+	// the event.
+	Key     string          `json:"key,omitempty"`
+	Payload json.RawMessage `json:"payload"`
+	// Attempts counts the failed and successful publish attempts
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"lastError,omitempty"`
+	PublishedAt *time.Time `json:"publishedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
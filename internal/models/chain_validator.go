@@ -0,0 +1,65 @@
+package models
+
+// Bond statuses of validators in the staking module
+const (
+	BondStatusBonded    = "BOND_STATUS_BONDED"
+	BondStatusUnbonding = "BOND_STATUS_UNBONDING"
+	BondStatusUnbonded  = "BOND_STATUS_UNBONDED"
+)
+
+// ChainValidatorsResponse represents a page of the validators API
+type ChainValidatorsResponse struct {
+	Validators []ChainValidator `json:"validators"`
+	Pagination Pagination       `json:"pagination"`
+}
+
+// ChainValidatorResponse represents the response from the validator API
+type ChainValidatorResponse struct {
+	Validator ChainValidator `json:"validator"`
+}
+
+// ChainValidator represents a validator as returned by the staking module
+type ChainValidator struct {
+	OperatorAddress string               `json:"operator_address"`
+	Jailed          bool                 `json:"jailed"`
+	Status          string               `json:"status"`
+	Tokens          string               `json:"tokens"`
+	DelegatorShares string               `json:"delegator_shares"`
+	Description     ValidatorDescription `json:"description"`
+	Commission      ValidatorCommission  `json:"commission"`
+}
+
+// ValidatorDescription represents the self-declared details of a validator
+type ValidatorDescription struct {
+	Moniker         string `json:"moniker"`
+	Identity        string `json:"identity"`
+	Website         string `json:"website"`
+	SecurityContact string `json:"security_contact"`
+	Details         string `json:"details"`
+}
+
+// ValidatorCommission represents the commission of a validator
+type ValidatorCommission struct {
+	CommissionRates CommissionRates `json:"commission_rates"`
+	UpdateTime      string          `json:"update_time"`
+}
+
+// CommissionRates represents the commission rates of a validator as decimal strings
+type CommissionRates struct {
+	Rate          string `json:"rate"`
+	MaxRate       string `json:"max_rate"`
+	MaxChangeRate string `json:"max_change_rate"`
+}
+
+// Metadata returns the details of the validator that are stored with it.
+// UpdatedAt is left for the store to set.
+func (v ChainValidator) Metadata() ValidatorMetadata {
+	return ValidatorMetadata{
+		Moniker:        v.Description.Moniker,
+		Website:        v.Description.Website,
+		CommissionRate: v.Commission.CommissionRates.Rate,
+		Status:         v.Status,
+		Jailed:         v.Jailed,
+		Tokens:         v.Tokens,
+	}
+}
@@ -14,6 +14,80 @@ type Delegation struct {
 	UpdatedAt         time.Time `json:"updated_at"`
 }
 
+// DelegationEventType is the kind of change of a delegation
+type DelegationEventType string
+
+const (
+	// DelegationEventNew is a delegator that did not delegate to the validator before
+	DelegationEventNew DelegationEventType = "new"
+	// DelegationEventIncrease is a delegation whose shares grew
+	DelegationEventIncrease DelegationEventType = "increase"
+	// DelegationEventDecrease is a delegation whose shares shrank but remain
+	DelegationEventDecrease DelegationEventType = "decrease"
+	// DelegationEventExit is a delegator that no longer delegates to the validator
+	DelegationEventExit DelegationEventType = "exit"
+)
+
+// DelegationEvent is a change of a delegation between two syncs
+type DelegationEvent struct {
+	ID               int64               `json:"id"`
+	ValidatorAddress string              `json:"validator_address"`
+	DelegatorAddress string              `json:"delegator_address"`
+	Type             DelegationEventType `json:"type"`
+	PreviousShares   string              `json:"previous_shares"`
+	Shares           string              `json:"shares"`
+	// Delta is Shares minus PreviousShares, negative for decreases and exits
+	Delta     string    `json:"delta"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ValidatorStake summarizes the current delegations of a validator, based on
+// the latest stored shares of each delegator
+type ValidatorStake struct {
+	ValidatorAddress string `json:"validator_address"`
+	Delegators       int    `json:"delegators"`
+	TotalShares      string `json:"total_shares"`
+}
+
+// DelegationTotals counts and sums the current delegations of a validator or
+// of a delegator
+type DelegationTotals struct {
+	Delegations int    `json:"delegations"`
+	TotalShares string `json:"total_shares"`
+}
+
+// StakePoint is the stake change of a validator in an interval of a stake
+// series
+type StakePoint struct {
+	ValidatorAddress string `json:"validator_address"`
+	// Timestamp is the start of the interval
+	Timestamp time.Time `json:"timestamp"`
+	// StakeChange is the sum of the deltas of the interval
+	StakeChange string `json:"stake_change"`
+	// TotalShares is the stake at the end of the interval
+	TotalShares    string `json:"total_shares"`
+	NewDelegators  int    `json:"new_delegators"`
+	LostDelegators int    `json:"lost_delegators"`
+	Events         int    `json:"events"`
+}
+
+// DelegationActivity summarizes the delegation events of a validator in a period
+type DelegationActivity struct {
+	ValidatorAddress string `json:"validator_address"`
+	// StakeChange is the sum of the deltas of the period
+	StakeChange    string `json:"stake_change"`
+	NewDelegators  int    `json:"new_delegators"`
+	LostDelegators int    `json:"lost_delegators"`
+	// TopMovers are the delegators with the largest net change, largest first
+	TopMovers []DelegatorMovement `json:"top_movers"`
+}
+
+// DelegatorMovement is the net change of a delegator's shares in a period
+type DelegatorMovement struct {
+	DelegatorAddress string `json:"delegator_address"`
+	Delta            string `json:"delta"`
+}
+
 // DelegationsResponse represents the response from the delegations API
 type DelegationsResponse struct {
 	DelegationResponses []DelegationResponse `json:"delegation_responses"`
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// DigestFrequency is how often an email digest is sent
+type DigestFrequency string
+
+const (
+	// DigestDaily is sent every day and covers the last 24 hours
+	DigestDaily DigestFrequency = "daily"
+	// DigestWeekly is sent every week and covers the last 7 days
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// Valid reports whether the frequency is a known frequency
+func (f DigestFrequency) Valid() bool {
+	return f == DigestDaily || f == DigestWeekly
+}
+
+// Period returns the length of the period covered by a digest
+func (f DigestFrequency) Period() time.Duration {
+	if f == DigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// EmailDigest is a periodic email report of the validators carrying a tag,
+// sent to a list of recipients
+type EmailDigest struct {
+	ID         int             `json:"id"`
+	Tag        string          `json:"tag"`
+	Frequency  DigestFrequency `json:"frequency"`
+	Recipients []string        `json:"recipients"`
+	Enabled    bool            `json:"enabled"`
+	LastSentAt *time.Time      `json:"lastSentAt"`
+	// LastAttemptAt is the time of the last attempt to send the digest and
+	// LastError why it failed, if it did
+	LastAttemptAt *time.Time `json:"lastAttemptAt"`
+	LastError     string     `json:"lastError,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
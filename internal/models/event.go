@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType names a kind of event produced by the service
+type EventType string
+
+const (
+	// EventDelegationChanged carries a DelegationEvent recorded by a sync
+	EventDelegationChanged EventType = "delegation.changed"
+	// EventSyncFailed carries a SyncFailure
+	EventSyncFailed EventType = "sync.failed"
+	// EventAlertFired carries an Alert fired by an alert rule
+	EventAlertFired EventType = "alert.fired"
+	// EventWebhookTest is sent by the webhook test endpoint
+	EventWebhookTest EventType = "webhook.test"
+)
+
+// EventTypes lists the event types consumers can subscribe to
+var EventTypes = []EventType{EventDelegationChanged, EventSyncFailed, EventAlertFired}
+
+// Event is an event produced by the service. Its ID is unique and lets
+// consumers discard events they already handled.
+type Event struct {
+	ID               string          `json:"id"`
+	Type             EventType       `json:"type"`
+	ValidatorAddress string          `json:"validator_address,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+	Data             json.RawMessage `json:"data"`
+}
+
+// SyncFailure describes a failed delegation sync of a validator
+type SyncFailure struct {
+	ValidatorAddress string `json:"validator_address"`
+	Error            string `json:"error"`
+	// ConsecutiveFailures counts the failed syncs of the validator in a row,
+	// including this one, since the service started
+	ConsecutiveFailures int `json:"consecutive_failures"`
+}
+
+// OutboxEvent is an event stored in the event outbox, to be relayed to the
+// event consumers
+type OutboxEvent struct {
+	ID    int64 `json:"id"`
+	Event Event `json:"event"`
+	// Attempts counts the failed and successful relay attempts
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"lastError,omitempty"`
+	RelayedAt *time.Time `json:"relayedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// NotificationChannelType is the chat service a notification channel posts to
+type NotificationChannelType string
+
+const (
+	// NotificationSlack posts to a Slack incoming webhook
+	NotificationSlack NotificationChannelType = "slack"
+	// NotificationDiscord posts to a Discord webhook
+	NotificationDiscord NotificationChannelType = "discord"
+	// NotificationTelegram sends messages to a Telegram chat through a bot
+	NotificationTelegram NotificationChannelType = "telegram"
+)
+
+// Valid reports whether the channel type is a known type
+func (t NotificationChannelType) Valid() bool {
+	switch t {
+	case NotificationSlack, NotificationDiscord, NotificationTelegram:
+		return true
+	}
+	return false
+}
+
+// NotificationChannel is a chat destination for human-readable notifications
+// of events, with the rules that route events to it
+type NotificationChannel struct {
+	ID   int                     `json:"id"`
+	Name string                  `json:"name"`
+	Type NotificationChannelType `json:"type"`
+	// WebhookURL is the Slack or Discord webhook URL. It is a credential and
+	// is never returned.
+	WebhookURL string `json:"-"`
+	// BotToken is the Telegram bot token. It is never returned.
+	BotToken string `json:"-"`
+	// ChatID is the Telegram chat the bot posts to
+	ChatID string `json:"chatId,omitempty"`
+	// ValidatorAddresses limits the channel to events of these validators.
+	// The channel receives events of every validator when empty.
+	ValidatorAddresses []string    `json:"validatorAddresses"`
+	EventTypes         []EventType `json:"eventTypes"`
+	// SyncFailureThreshold is the number of failed syncs in a row after which
+	// a sync failure is notified. Each run of failures is notified once.
+	SyncFailureThreshold int `json:"syncFailureThreshold"`
+	// Templates overrides the message template of event types
+	Templates map[EventType]string `json:"templates,omitempty"`
+	Enabled   bool                 `json:"enabled"`
+	CreatedAt time.Time            `json:"createdAt"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+}
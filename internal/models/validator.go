@@ -1,8 +1,50 @@
 package models
 
+import (
+	"time"
+)
+
 // Validator represents a cosmos validator entity
 type Validator struct {
 	Name            string `json:"name"`
 	Address         string `json:"address"`
 	EnabledTracking bool   `json:"enabledTracking"`
-} 
\ No newline at end of file
+	// ArchivedAt is set when the validator has been archived. Archived
+	// validators are hidden from lists and not synced, but keep their history.
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	// UpdatedAt changes on every update and is the basis of the validator's ETag
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Metadata is read from the chain. It is nil until it has been fetched.
+	Metadata *ValidatorMetadata `json:"metadata,omitempty"`
+	// Tags group validators, for example by client. They are sorted and
+	// managed through the tag endpoints.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// TagCount is a tag and the number of validators carrying it
+type TagCount struct {
+	Tag        string `json:"tag"`
+	Validators int    `json:"validators"`
+}
+
+// ValidatorMetadata holds the on-chain details of a validator
+type ValidatorMetadata struct {
+	Moniker        string    `json:"moniker"`
+	Website        string    `json:"website,omitempty"`
+	CommissionRate string    `json:"commissionRate"`
+	Status         string    `json:"status"`
+	Jailed         bool      `json:"jailed"`
+	Tokens         string    `json:"tokens"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// ValidatorSyncStatus describes the delegation sync state of a validator
+type ValidatorSyncStatus struct {
+	Address           string     `json:"address"`
+	EnabledTracking   bool       `json:"enabledTracking"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	LastSyncedAt      *time.Time `json:"lastSyncedAt"`
+	LastSyncAttemptAt *time.Time `json:"lastSyncAttemptAt"`
+	LastSyncError     string     `json:"lastSyncError,omitempty"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook is an endpoint that receives events as signed HTTP POST requests
+type Webhook struct {
+	ID          int    `json:"id"`
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+	// EventTypes are the event types delivered to the webhook. It receives
+	// every event type when empty.
+	EventTypes []EventType `json:"eventTypes"`
+	// Secret signs the deliveries. It is only returned when the webhook is created.
+	Secret    string    `json:"-"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Subscribes reports whether the webhook receives events of the given type
+func (w Webhook) Subscribes(eventType EventType) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, subscribed := range w.EventTypes {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus is the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending is waiting for its first or next attempt
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryDelivered was accepted by the endpoint with a 2xx response
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryFailed ran out of attempts
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is an event queued for, or delivered to, a webhook
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	WebhookID      int                   `json:"webhookId"`
+	EventID        string                `json:"eventId"`
+	EventType      EventType             `json:"eventType"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"nextAttemptAt"`
+	LastAttemptAt  *time.Time            `json:"lastAttemptAt,omitempty"`
+	LastStatusCode int                   `json:"lastStatusCode,omitempty"`
+	LastError      string                `json:"lastError,omitempty"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	// AttemptLog holds every attempt. It is only filled for a single delivery.
+	AttemptLog []WebhookDeliveryAttempt `json:"attemptLog,omitempty"`
+}
+
+// WebhookDeliveryAttempt is one HTTP request of a webhook delivery
+type WebhookDeliveryAttempt struct {
+	Attempt int `json:"attempt"`
+	// StatusCode is zero when no response was received
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
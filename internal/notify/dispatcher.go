@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+const (
+	// DefaultTimeout is the default timeout of a notification request
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultTelegramAPIURL is the base URL of the Telegram Bot API
+	DefaultTelegramAPIURL = "https://api.telegram.org"
+)
+
+// DispatcherConfig holds configuration for the notification dispatcher
+type DispatcherConfig struct {
+	Timeout        time.Duration
+	TelegramAPIURL string
+	HTTPClient     *http.Client
+}
+
+// Dispatcher sends notifications of published events to the notification
+// channels whose routing rules match them
+type Dispatcher struct {
+	store  store.NotificationChannelStore
+	config DispatcherConfig
+	client *http.Client
+}
+
+// NewDispatcher creates a new notification dispatcher, applying defaults for
+// empty configuration values
+func NewDispatcher(channelStore store.NotificationChannelStore, config DispatcherConfig) *Dispatcher {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.TelegramAPIURL == "" {
+		config.TelegramAPIURL = DefaultTelegramAPIURL
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: config.Timeout}
+	}
+
+	return &Dispatcher{
+		store:  channelStore,
+		config: config,
+		client: config.HTTPClient,
+	}
+}
+
+// Publish implements events.Publisher. Notifications are sent once, without
+// retries; every failed notification is reported in the returned error.
+func (d *Dispatcher) Publish(ctx context.Context, evts []models.Event) error {
+	if len(evts) == 0 {
+		return nil
+	}
+
+	channels, err := d.store.GetEnabled(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting notification channels: %v", err)
+	}
+
+	var errs []error
+	for _, event := range evts {
+		for _, channel := range channels {
+			if !Routes(channel, event) {
+				continue
+			}
+			if err := d.send(ctx, channel, event); err != nil {
+				errs = append(errs, fmt.Errorf("error notifying channel %q of %s event: %v", channel.Name, event.Type, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Test sends a test notification to a channel, whatever its routing rules and
+// enabled flag
+func (d *Dispatcher) Test(ctx context.Context, channelID int) error {
+	channel, err := d.store.GetByID(ctx, channelID)
+	if err != nil {
+		return err
+	}
+
+	event, err := events.New(EventNotificationTest, "", map[string]interface{}{"channel_id": channel.ID})
+	if err != nil {
+		return err
+	}
+	return d.send(ctx, *channel, event)
+}
+
+// NewNotifier creates the notifier of a channel
+func (d *Dispatcher) NewNotifier(channel models.NotificationChannel) (Notifier, error) {
+	switch channel.Type {
+	case models.NotificationSlack:
+		return NewSlackNotifier(channel.WebhookURL, d.client), nil
+	case models.NotificationDiscord:
+		return NewDiscordNotifier(channel.WebhookURL, d.client), nil
+	case models.NotificationTelegram:
+		return NewTelegramNotifier(d.config.TelegramAPIURL, channel.BotToken, channel.ChatID, d.client), nil
+	}
+	return nil, fmt.Errorf("unknown notification channel type %q", channel.Type)
+}
+
+// send renders an event with the channel's template and sends it
+func (d *Dispatcher) send(ctx context.Context, channel models.NotificationChannel, event models.Event) error {
+	message, err := Render(event, channel.Templates[event.Type])
+	if err != nil {
+		return err
+	}
+	notifier, err := d.NewNotifier(channel)
+	if err != nil {
+		return err
+	}
+
+	if err := notifier.Notify(ctx, message); err != nil {
+		metrics.NotificationsSentTotal.WithLabelValues(string(channel.Type), "failed").Inc()
+		return err
+	}
+	metrics.NotificationsSentTotal.WithLabelValues(string(channel.Type), "sent").Inc()
+	slog.DebugContext(ctx, "Sent notification", "channel", channel.Name, "type", event.Type)
+	return nil
+}
+
+// Routes reports whether the routing rules of a channel send an event to it.
+// Sync failures are only sent when the number of failures in a row reaches
+// the channel's threshold, so that each run of failures is notified once.
+func Routes(channel models.NotificationChannel, event models.Event) bool {
+	if !channel.Enabled {
+		return false
+	}
+
+	subscribed := false
+	for _, eventType := range channel.EventTypes {
+		if eventType == event.Type {
+			subscribed = true
+			break
+		}
+	}
+	if !subscribed {
+		return false
+	}
+
+	if len(channel.ValidatorAddresses) > 0 {
+		matched := false
+		for _, address := range channel.ValidatorAddresses {
+			if address == event.ValidatorAddress {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if event.Type == models.EventSyncFailed {
+		var failure models.SyncFailure
+		if err := json.Unmarshal(event.Data, &failure); err != nil {
+			return false
+		}
+		threshold := channel.SyncFailureThreshold
+		if threshold < 1 {
+			threshold = 1
+		}
+		return failure.ConsecutiveFailures == threshold
+	}
+	return true
+}
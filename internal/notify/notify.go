@@ -0,0 +1,162 @@
+// Package notify sends human-readable notifications of events to Slack,
+// Discord and Telegram
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxSlackTextLength is the longest message body sent to Slack
+	maxSlackTextLength = 3000
+
+	// maxDiscordDescriptionLength is the longest description of a Discord embed
+	maxDiscordDescriptionLength = 4096
+
+	// maxTelegramTextLength is the longest text of a Telegram message
+	maxTelegramTextLength = 4096
+
+	// maxErrorBody is the most of an error response body included in errors
+	maxErrorBody = 512
+)
+
+// Message is a notification with a short title and a body
+type Message struct {
+	Title string
+	Text  string
+}
+
+// Notifier sends messages to a chat service
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// SlackNotifier posts messages to a Slack incoming webhook
+type SlackNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackNotifier creates a notifier for a Slack incoming webhook URL
+func NewSlackNotifier(webhookURL string, client *http.Client) *SlackNotifier {
+	return &SlackNotifier{webhookURL: webhookURL, client: client}
+}
+
+// Notify implements Notifier
+func (n *SlackNotifier) Notify(ctx context.Context, message Message) error {
+	text := "*" + escapeSlack(message.Title) + "*\n" + escapeSlack(truncate(message.Text, maxSlackTextLength))
+	return postJSON(ctx, n.client, n.webhookURL, map[string]interface{}{
+		"text": text,
+	})
+}
+
+// DiscordNotifier posts messages to a Discord webhook as embeds
+type DiscordNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewDiscordNotifier creates a notifier for a Discord webhook URL
+func NewDiscordNotifier(webhookURL string, client *http.Client) *DiscordNotifier {
+	return &DiscordNotifier{webhookURL: webhookURL, client: client}
+}
+
+// Notify implements Notifier
+func (n *DiscordNotifier) Notify(ctx context.Context, message Message) error {
+	return postJSON(ctx, n.client, n.webhookURL, map[string]interface{}{
+		"embeds": []map[string]interface{}{{
+			"title":       message.Title,
+			"description": truncate(message.Text, maxDiscordDescriptionLength),
+		}},
+		// Never ping anyone from text taken from events
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	})
+}
+
+// TelegramNotifier sends messages to a Telegram chat through a bot
+type TelegramNotifier struct {
+	apiURL   string
+	botToken string
+	chatID   string
+	client   *http.Client
+}
+
+// NewTelegramNotifier creates a notifier for a Telegram bot and chat. apiURL
+// is the Bot API base URL, such as https://api.telegram.org.
+func NewTelegramNotifier(apiURL, botToken, chatID string, client *http.Client) *TelegramNotifier {
+	return &TelegramNotifier{
+		apiURL:   strings.TrimSuffix(apiURL, "/"),
+		botToken: botToken,
+		chatID:   chatID,
+		client:   client,
+	}
+}
+
+// Notify implements Notifier
+func (n *TelegramNotifier) Notify(ctx context.Context, message Message) error {
+	// The length limit applies to the text without markup
+	length := maxTelegramTextLength - utf8.RuneCountInString(message.Title) - 1
+	text := "<b>" + html.EscapeString(message.Title) + "</b>\n" + html.EscapeString(truncate(message.Text, length))
+	return postJSON(ctx, n.client, n.apiURL+"/bot"+n.botToken+"/sendMessage", map[string]interface{}{
+		"chat_id":                  n.chatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	})
+}
+
+// postJSON posts payload as JSON and fails unless the response is 2xx. Errors
+// never contain the URL, which holds credentials.
+func postJSON(ctx context.Context, client *http.Client, target string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding message: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("error creating request: invalid URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("error sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// escapeSlack escapes the characters Slack reserves for its markup
+func escapeSlack(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// truncate shortens text to at most max characters
+func truncate(text string, max int) string {
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+// EventNotificationTest is the type of the event sent by the notification
+// channel test endpoint
+const EventNotificationTest models.EventType = "notification.test"
+
+// titles are the message titles of each event type
+var titles = map[models.EventType]string{
+	models.EventDelegationChanged: "Delegation changed",
+	models.EventAlertFired:        "Whale movement alert",
+	models.EventSyncFailed:        "Delegation sync failing",
+	EventNotificationTest:         "Test notification",
+}
+
+// DefaultTemplates are the message templates used unless a channel overrides
+// them. Templates are Go text/template templates executed with TemplateData.
+var DefaultTemplates = map[models.EventType]string{
+	models.EventDelegationChanged: `{{.Data.delegator_address}} {{.Data.type}} on {{.ValidatorAddress}}: ` +
+		`{{.Data.previous_shares}} → {{.Data.shares}} shares (delta {{.Data.delta}})`,
+	models.EventAlertFired: `{{.Data.rule_name}}: {{.Data.delegator_address}} {{.Data.event_type}} on {{.ValidatorAddress}}, ` +
+		`delta {{.Data.delta}}{{with .Data.stake_percent}} ({{.}}% of stake){{end}}`,
+	models.EventSyncFailed: `Delegation sync of {{.ValidatorAddress}} failed {{.Data.consecutive_failures}} time(s) in a row: {{.Data.error}}`,
+	EventNotificationTest:  `This is a test notification from the cosmos validator service.`,
+}
+
+// TemplateData is the value message templates are executed with
+type TemplateData struct {
+	ID               string
+	Type             models.EventType
+	ValidatorAddress string
+	CreatedAt        time.Time
+	// Data holds the fields of the event data by their JSON names
+	Data map[string]interface{}
+}
+
+// ParseTemplate parses a message template
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("message").Option("missingkey=zero").Parse(text)
+}
+
+// Render builds the message of an event, using override as the template
+// unless it is empty
+func Render(event models.Event, override string) (Message, error) {
+	text := override
+	if text == "" {
+		text = DefaultTemplates[event.Type]
+	}
+	if text == "" {
+		return Message{}, fmt.Errorf("no template for %s events", event.Type)
+	}
+
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return Message{}, fmt.Errorf("error parsing %s template: %v", event.Type, err)
+	}
+
+	data := TemplateData{
+		ID:               event.ID,
+		Type:             event.Type,
+		ValidatorAddress: event.ValidatorAddress,
+		CreatedAt:        event.CreatedAt,
+	}
+	if len(event.Data) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(event.Data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&data.Data); err != nil {
+			return Message{}, fmt.Errorf("error decoding %s event data: %v", event.Type, err)
+		}
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return Message{}, fmt.Errorf("error executing %s template: %v", event.Type, err)
+	}
+
+	// text/template prints missing keys of map data as "<no value>"
+	text = strings.ReplaceAll(buf.String(), "<no value>", "")
+
+	title := titles[event.Type]
+	if title == "" {
+		title = string(event.Type)
+	}
+	return Message{Title: title, Text: strings.TrimSpace(text)}, nil
+}
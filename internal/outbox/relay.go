@@ -0,0 +1,209 @@
+// Package outbox relays the events stored in the event outbox to the event
+// consumers. Events derived from stored records are added to the outbox in
+// the transaction storing the records, so that no event is lost when the
+// service stops between storing a record and publishing its event.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/poller"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Default relay settings, used for zero values in RelayConfig
+const (
+	DefaultPollInterval   = 5 * time.Second
+	DefaultBatchSize      = 100
+	DefaultTimeout        = 30 * time.Second
+	DefaultInitialBackoff = 5 * time.Second
+	DefaultMaxBackoff     = 5 * time.Minute
+)
+
+// RelayConfig holds configuration for the event outbox relay
+type RelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Timeout bounds the relaying of one batch
+	Timeout time.Duration
+	// InitialBackoff is the delay before the first retry of the events of a
+	// validator. It doubles with every further retry up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Retention is how long relayed events are kept in the outbox. They are
+	// kept forever when it is zero.
+	Retention time.Duration
+}
+
+// Relay hands the events in the outbox to the consumers, in order per
+// validator. When a consumer fails, the events of the validator are retried
+// with exponential backoff and its later events wait for them; the events of
+// other validators go on. Consumers get every event at least once and
+// discard the event IDs they already handled.
+type Relay struct {
+	store     store.EventOutboxStore
+	consumers events.Publisher
+	config    RelayConfig
+	loop      *poller.Loop
+}
+
+// NewRelay creates a new event outbox relay, applying defaults for empty
+// configuration values
+func NewRelay(outboxStore store.EventOutboxStore, consumers events.Publisher, config RelayConfig) *Relay {
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultInitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+
+	r := &Relay{
+		store:     outboxStore,
+		consumers: consumers,
+		config:    config,
+	}
+	// Keep going while events are claimed, which includes the events the
+	// consumers derive from the relayed ones, such as fired alerts
+	r.loop = poller.New(poller.Config{
+		Name:         "Event outbox relay",
+		PollInterval: config.PollInterval,
+		Retention:    config.Retention,
+	}, func(ctx context.Context) (bool, error) {
+		claimed, err := r.RelayNext(ctx)
+		return claimed > 0, err
+	}, outboxStore.DeleteRelayed)
+	return r
+}
+
+// Publish implements events.Publisher by adding events that are not derived
+// from stored records, such as failed syncs, to the outbox
+func (r *Relay) Publish(ctx context.Context, evts []models.Event) error {
+	queued, err := r.store.Enqueue(ctx, evts)
+	if err != nil {
+		return fmt.Errorf("error queuing events: %v", err)
+	}
+	if queued > 0 {
+		r.Wake()
+	}
+	return nil
+}
+
+// Wake makes a running relay hand over the outbox without waiting for the
+// next poll
+func (r *Relay) Wake() {
+	r.loop.Wake()
+}
+
+// Run relays the events in the outbox every poll interval, and when woken up,
+// until ctx is done
+func (r *Relay) Run(ctx context.Context) {
+	r.loop.Run(ctx)
+}
+
+// RelayNext hands the next batch of outbox events to the consumers and returns
+// the number of events claimed. The events of each validator are handed over
+// in order, and are retried after a backoff when a consumer fails.
+func (r *Relay) RelayNext(ctx context.Context) (claimed int, err error) {
+	// Claimed events stay locked for a little longer than the attempt, so
+	// that no other relay hands them over meanwhile
+	claimedEvents, err := r.store.ClaimNext(ctx, r.config.BatchSize, 2*r.config.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("error claiming outbox events: %v", err)
+	}
+	if len(claimedEvents) == 0 {
+		return 0, nil
+	}
+
+	ctx, span := tracing.StartSpan(ctx, "outbox.relay",
+		attribute.Int("outbox.events", len(claimedEvents)),
+		attribute.Int64("outbox.first_event_id", claimedEvents[0].ID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	relayCtx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+
+	var errs []error
+	for _, group := range byValidator(claimedEvents) {
+		if err := r.relay(ctx, relayCtx, group); err != nil {
+			errs = append(errs, err)
+		}
+		if ctx.Err() != nil {
+			// Shutting down: the events are claimed again when their lock
+			// expires
+			return len(claimedEvents), ctx.Err()
+		}
+	}
+	return len(claimedEvents), errors.Join(errs...)
+}
+
+// relay hands the events of a validator to the consumers within relayCtx and
+// records the outcome
+func (r *Relay) relay(ctx, relayCtx context.Context, group []models.OutboxEvent) error {
+	ids := make([]int64, len(group))
+	evts := make([]models.Event, len(group))
+	for i, event := range group {
+		ids[i] = event.ID
+		evts[i] = event.Event
+	}
+
+	relayErr := r.consumers.Publish(relayCtx, evts)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	if relayErr != nil {
+		attempt := group[0].Attempts + 1
+		retryAt := time.Now().Add(r.Backoff(attempt))
+		metrics.OutboxEventsTotal.WithLabelValues("retry").Add(float64(len(group)))
+		slog.WarnContext(ctx, "Failed to relay outbox events, retrying",
+			"validator", group[0].Event.ValidatorAddress, "events", len(group), "first_event", group[0].ID,
+			"attempt", attempt, "retry_at", retryAt, "error", relayErr)
+		return r.store.MarkFailed(ctx, ids, relayErr.Error(), retryAt)
+	}
+
+	metrics.OutboxEventsTotal.WithLabelValues("relayed").Add(float64(len(group)))
+	// Events not marked relayed are relayed again once their lock expires
+	return r.store.MarkRelayed(ctx, ids)
+}
+
+// Backoff returns the delay after a failed attempt (counted from 1) before the
+// next one
+func (r *Relay) Backoff(attempt int) time.Duration {
+	return poller.Backoff(r.config.InitialBackoff, r.config.MaxBackoff, attempt)
+}
+
+// byValidator splits events into groups per validator, in the order of their
+// first event, keeping the order of the events of each validator
+func byValidator(claimedEvents []models.OutboxEvent) [][]models.OutboxEvent {
+	var groups [][]models.OutboxEvent
+	index := make(map[string]int)
+	for _, event := range claimedEvents {
+		i, ok := index[event.Event.ValidatorAddress]
+		if !ok {
+			i = len(groups)
+			index[event.Event.ValidatorAddress] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], event)
+	}
+	return groups
+}
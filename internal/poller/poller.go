@@ -0,0 +1,124 @@
+// Package poller runs the loops shared by the outbox relays and the webhook
+// delivery worker: claim due rows every poll interval or when woken up, keep
+// going while more are due, and prune the handled rows past their retention.
+package poller
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// PruneInterval is how often handled rows past the retention period are
+// deleted
+const PruneInterval = time.Hour
+
+// NextFunc handles the next batch of due rows and reports whether more are
+// due right away
+type NextFunc func(ctx context.Context) (more bool, err error)
+
+// PruneFunc deletes the rows handled before a time and returns how many it
+// deleted
+type PruneFunc func(ctx context.Context, before time.Time) (int64, error)
+
+// Config holds configuration for a loop
+type Config struct {
+	// Name names the loop in logs, such as "Event outbox relay"
+	Name         string
+	PollInterval time.Duration
+	// Retention is how long handled rows are kept. They are kept forever
+	// when it is zero.
+	Retention time.Duration
+}
+
+// Loop calls its NextFunc every poll interval, and when woken up, until its
+// context is done
+type Loop struct {
+	config     Config
+	next       NextFunc
+	prune      PruneFunc
+	wake       chan struct{}
+	lastPruned time.Time
+}
+
+// New creates a new loop. prune may be nil for rows that are never deleted.
+func New(config Config, next NextFunc, prune PruneFunc) *Loop {
+	return &Loop{
+		config: config,
+		next:   next,
+		prune:  prune,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Wake makes a running loop poll without waiting for the next poll interval
+func (l *Loop) Wake() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run polls until ctx is done
+func (l *Loop) Run(ctx context.Context) {
+	slog.Info(l.config.Name+" started", "poll_interval", l.config.PollInterval)
+	ticker := time.NewTicker(l.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			more, err := l.next(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Error(l.config.Name+" failed", "error", err)
+				}
+				break
+			}
+			if !more {
+				break
+			}
+		}
+		l.pruneHandled(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.Info(l.config.Name + " stopped")
+			return
+		case <-ticker.C:
+		case <-l.wake:
+		}
+	}
+}
+
+// pruneHandled deletes the handled rows past the retention period, at most
+// once per prune interval
+func (l *Loop) pruneHandled(ctx context.Context) {
+	if l.prune == nil || l.config.Retention <= 0 || time.Since(l.lastPruned) < PruneInterval {
+		return
+	}
+	l.lastPruned = time.Now()
+
+	deleted, err := l.prune(ctx, time.Now().Add(-l.config.Retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error(l.config.Name+" failed to prune", "error", err)
+		}
+		return
+	}
+	if deleted > 0 {
+		slog.Info(l.config.Name+" pruned", "deleted", deleted)
+	}
+}
+
+// Backoff returns the delay after a failed attempt (counted from 1) before the
+// next one: initial, doubling with every further attempt up to max
+func Backoff(initial, max time.Duration, attempt int) time.Duration {
+	backoff := initial
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= max {
+			return max
+		}
+	}
+	return backoff
+}
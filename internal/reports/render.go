@@ -0,0 +1,95 @@
+package reports
+
+import (
+	"embed"
+	htmltemplate "html/template"
+	"math/big"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/email"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// displayDecimals is the number of decimals of shares shown in reports
+const displayDecimals = 2
+
+// templateFuncs are the functions available to the report templates
+var templateFuncs = map[string]interface{}{
+	"shares": func(value string) string { return formatShares(value, false) },
+	"change": func(value string) string { return formatShares(value, true) },
+	"period": periodLabel,
+	"title":  title,
+}
+
+var (
+	textTemplate = texttemplate.Must(texttemplate.New("digest.txt.tmpl").Funcs(templateFuncs).ParseFS(templateFS, "templates/digest.txt.tmpl"))
+	htmlTemplate = htmltemplate.Must(htmltemplate.New("digest.html.tmpl").Funcs(templateFuncs).ParseFS(templateFS, "templates/digest.html.tmpl"))
+)
+
+// Render builds the email of a report, without recipients
+func Render(report *Report) (email.Message, error) {
+	var text, html strings.Builder
+	if err := textTemplate.Execute(&text, report); err != nil {
+		return email.Message{}, err
+	}
+	if err := htmlTemplate.Execute(&html, report); err != nil {
+		return email.Message{}, err
+	}
+
+	return email.Message{
+		Subject: title(report) + ", " + periodLabel(report),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// title returns the title of a report, such as "Weekly delegation digest: acme"
+func title(report *Report) string {
+	frequency := "Daily"
+	if report.Frequency == models.DigestWeekly {
+		frequency = "Weekly"
+	}
+	return frequency + " delegation digest: " + report.Tag
+}
+
+// periodLabel describes the period of a report in UTC
+func periodLabel(report *Report) string {
+	const layout = "Jan 2, 2006 15:04 MST"
+	return report.Since.UTC().Format(layout) + " – " + report.Until.UTC().Format(layout)
+}
+
+// formatShares rounds shares to displayDecimals decimals and groups thousands,
+// with a leading + for positive values if signed. Unparsable values are
+// returned unchanged.
+func formatShares(value string, signed bool) string {
+	parsed, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return value
+	}
+
+	formatted := parsed.FloatString(displayDecimals)
+	sign := ""
+	if strings.HasPrefix(formatted, "-") {
+		sign, formatted = "-", formatted[1:]
+	} else if signed && parsed.Sign() > 0 {
+		sign = "+"
+	}
+	if strings.Trim(formatted, "0.") == "" {
+		// Rounded to zero
+		sign = ""
+	}
+
+	integer, fraction, _ := strings.Cut(formatted, ".")
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + grouped.String() + "." + fraction
+}
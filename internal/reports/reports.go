@@ -0,0 +1,130 @@
+// Package reports builds the email digests that summarize the delegation
+// activity of the validators carrying a tag
+package reports
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+// DefaultTopMovers is the default number of top movers listed per validator
+const DefaultTopMovers = 5
+
+// Report is the delegation activity of the validators carrying a tag in a period
+type Report struct {
+	Tag       string
+	Frequency models.DigestFrequency
+	// Since and Until bound the period, Until excluded
+	Since      time.Time
+	Until      time.Time
+	Validators []ValidatorReport
+	// StakeChange, NewDelegators and LostDelegators are the totals of every validator
+	StakeChange    string
+	NewDelegators  int
+	LostDelegators int
+}
+
+// ValidatorReport is the delegation activity of a single validator
+type ValidatorReport struct {
+	Address string
+	Name    string
+	// Moniker is the on-chain name, empty until the chain metadata is fetched
+	Moniker string
+	// Delegators and TotalShares describe the current delegations
+	Delegators  int
+	TotalShares string
+	Activity    models.DelegationActivity
+}
+
+// Generator builds reports from the validator and delegation stores
+type Generator struct {
+	validators  store.ValidatorStore
+	delegations store.DelegationStore
+	topMovers   int
+}
+
+// NewGenerator creates a new report generator listing topMovers delegators
+// per validator, or DefaultTopMovers if it is not positive
+func NewGenerator(validators store.ValidatorStore, delegations store.DelegationStore, topMovers int) *Generator {
+	if topMovers <= 0 {
+		topMovers = DefaultTopMovers
+	}
+	return &Generator{validators: validators, delegations: delegations, topMovers: topMovers}
+}
+
+// Generate builds the report of the validators carrying tag that are not
+// archived, covering the period of the frequency that ends at until
+func (g *Generator) Generate(ctx context.Context, tag string, frequency models.DigestFrequency, until time.Time) (*Report, error) {
+	report := &Report{
+		Tag:        tag,
+		Frequency:  frequency,
+		Since:      until.Add(-frequency.Period()),
+		Until:      until,
+		Validators: []ValidatorReport{},
+	}
+
+	var validators []models.Validator
+	for {
+		page, total, err := g.validators.List(ctx, store.ValidatorFilter{
+			Tag:    tag,
+			Limit:  store.MaxValidatorLimit,
+			Offset: len(validators),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing validators tagged %q: %v", tag, err)
+		}
+		validators = append(validators, page...)
+		if len(page) == 0 || len(validators) >= total {
+			break
+		}
+	}
+
+	stakes, err := g.delegations.GetStakeSummary(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	stakeByAddress := make(map[string]models.ValidatorStake, len(stakes))
+	for _, stake := range stakes {
+		stakeByAddress[stake.ValidatorAddress] = stake
+	}
+
+	total := new(big.Rat)
+	for _, validator := range validators {
+		activity, err := g.delegations.GetDelegationActivity(ctx, validator.Address, report.Since, report.Until, g.topMovers)
+		if err != nil {
+			return nil, err
+		}
+
+		validatorReport := ValidatorReport{
+			Address:     validator.Address,
+			Name:        validator.Name,
+			Delegators:  stakeByAddress[validator.Address].Delegators,
+			TotalShares: stakeByAddress[validator.Address].TotalShares,
+			Activity:    activity,
+		}
+		if validator.Metadata != nil {
+			validatorReport.Moniker = validator.Metadata.Moniker
+		}
+		if validatorReport.TotalShares == "" {
+			validatorReport.TotalShares = "0"
+		}
+		report.Validators = append(report.Validators, validatorReport)
+
+		if change, ok := new(big.Rat).SetString(activity.StakeChange); ok {
+			total.Add(total, change)
+		}
+		report.NewDelegators += activity.NewDelegators
+		report.LostDelegators += activity.LostDelegators
+	}
+	report.StakeChange = total.FloatString(sharesDecimals)
+
+	return report, nil
+}
+
+// sharesDecimals is the number of decimals of delegation shares on the chain
+const sharesDecimals = 18
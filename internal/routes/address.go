@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/novintriantonius/cosmos-validator-service/internal/address"
+)
+
+// validatorAddressVars are the path variables holding validator operator addresses
+var validatorAddressVars = []string{"address", "validator_address"}
+
+// delegatorAddressVars are the path variables holding delegator account addresses
+var delegatorAddressVars = []string{"delegator_address"}
+
+// validateAddressVars wraps a handler so that it only runs when every address
+// path variable is a valid bech32 address of the configured chain. A nil
+// validator disables the check.
+func validateAddressVars(addresses *address.Validator, next http.HandlerFunc) http.HandlerFunc {
+	if addresses == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		var validationErrors []string
+		for _, name := range validatorAddressVars {
+			if value, ok := vars[name]; ok {
+				if err := addresses.ValidateValidatorAddress(value); err != nil {
+					validationErrors = append(validationErrors, err.Error())
+				}
+			}
+		}
+		for _, name := range delegatorAddressVars {
+			if value, ok := vars[name]; ok {
+				if err := addresses.ValidateAccountAddress(value); err != nil {
+					validationErrors = append(validationErrors, err.Error())
+				}
+			}
+		}
+
+		if len(validationErrors) > 0 {
+			respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"status": "error",
+				"code": http.StatusBadRequest,
+				"message": "Invalid address",
+				"errors": validationErrors,
+			})
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,366 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/novintriantonius/cosmos-validator-service/internal/address"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+// maxAlertRuleNameLength is the maximum length of an alert rule name
+const maxAlertRuleNameLength = 255
+
+// AlertHandler handles alert rule and alert requests
+type AlertHandler struct {
+	store     store.AlertStore
+	addresses *address.Validator
+}
+
+// NewAlertHandler creates a new alert handler. Validator addresses of rules
+// are checked with addresses unless it is nil.
+func NewAlertHandler(store store.AlertStore, addresses *address.Validator) *AlertHandler {
+	return &AlertHandler{store: store, addresses: addresses}
+}
+
+// alertRuleRequest is the body of POST /alert-rules and PUT /alert-rules/{id}.
+// Thresholds may be given as JSON numbers or decimal strings.
+type alertRuleRequest struct {
+	Name             string      `json:"name"`
+	ValidatorAddress string      `json:"validator_address"`
+	MinDelta         json.Number `json:"min_delta"`
+	MinStakePercent  json.Number `json:"min_stake_percent"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+// GetRules handles GET /alert-rules
+func (h *AlertHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.store.GetRules(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to retrieve alert rules",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Alert rules retrieved successfully",
+		"data": map[string]interface{}{
+			"rules": rules,
+			"count": len(rules),
+		},
+	})
+}
+
+// GetRule handles GET /alert-rules/{id}
+func (h *AlertHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := alertRuleIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := h.store.GetRule(r.Context(), id)
+	if !respondAlertRuleError(w, id, err, "Failed to retrieve alert rule") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Alert rule retrieved successfully",
+		"data": rule,
+	})
+}
+
+// CreateRule handles POST /alert-rules
+func (h *AlertHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.decodeRule(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.store.AddRule(r.Context(), rule)
+	if !respondAlertRuleError(w, 0, err, "Failed to create alert rule") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusCreated,
+		"message": "Alert rule created successfully",
+		"data": created,
+	})
+}
+
+// UpdateRule handles PUT /alert-rules/{id}
+// The body replaces the rule; omitted thresholds are removed
+func (h *AlertHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := alertRuleIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	rule, ok := h.decodeRule(w, r)
+	if !ok {
+		return
+	}
+	rule.ID = id
+
+	updated, err := h.store.UpdateRule(r.Context(), rule)
+	if !respondAlertRuleError(w, id, err, "Failed to update alert rule") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Alert rule updated successfully",
+		"data": updated,
+	})
+}
+
+// DeleteRule handles DELETE /alert-rules/{id}
+// The alerts fired by the rule are kept
+func (h *AlertHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := alertRuleIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.store.DeleteRule(r.Context(), id)
+	if !respondAlertRuleError(w, id, err, "Failed to delete alert rule") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Alert rule deleted successfully",
+	})
+}
+
+// GetAlerts handles GET /alerts
+// Supports the validator_address, rule_id, since, until, limit and offset query parameters
+func (h *AlertHandler) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	filter, validationErrors := h.parseAlertFilter(r)
+	if len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid query parameters",
+			"errors": validationErrors,
+		})
+		return
+	}
+
+	alerts, err := h.store.ListAlerts(r.Context(), filter)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to retrieve alerts",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Alerts retrieved successfully",
+		"data": map[string]interface{}{
+			"alerts": alerts,
+			"count": len(alerts),
+			"limit": filter.Limit,
+			"offset": filter.Offset,
+		},
+	})
+}
+
+// decodeRule reads and validates an alert rule from the request body,
+// writing a 400 response if it is invalid
+func (h *AlertHandler) decodeRule(w http.ResponseWriter, r *http.Request) (models.AlertRule, bool) {
+	var req alertRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid request body",
+			"errors": []string{err.Error()},
+		})
+		return models.AlertRule{}, false
+	}
+
+	rule := models.AlertRule{
+		Name:             strings.TrimSpace(req.Name),
+		ValidatorAddress: req.ValidatorAddress,
+		Enabled:          req.Enabled == nil || *req.Enabled,
+	}
+
+	var validationErrors []string
+	if rule.Name == "" {
+		validationErrors = append(validationErrors, "Name is required")
+	} else if len(rule.Name) > maxAlertRuleNameLength {
+		validationErrors = append(validationErrors, fmt.Sprintf("Name must be at most %d characters", maxAlertRuleNameLength))
+	}
+	if rule.ValidatorAddress != "" && h.addresses != nil {
+		if err := h.addresses.ValidateValidatorAddress(rule.ValidatorAddress); err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		}
+	}
+
+	var message string
+	if rule.MinDelta, message = parseThreshold("min_delta", req.MinDelta, nil); message != "" {
+		validationErrors = append(validationErrors, message)
+	}
+	if rule.MinStakePercent, message = parseThreshold("min_stake_percent", req.MinStakePercent, big.NewRat(100, 1)); message != "" {
+		validationErrors = append(validationErrors, message)
+	}
+	if req.MinDelta == "" && req.MinStakePercent == "" {
+		validationErrors = append(validationErrors, "At least one of min_delta and min_stake_percent is required")
+	}
+
+	if len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": validationErrors,
+		})
+		return models.AlertRule{}, false
+	}
+	return rule, true
+}
+
+// parseThreshold checks that an alert rule threshold, if set, is a positive
+// decimal number of at most max, and returns it or why it is invalid
+func parseThreshold(name string, value json.Number, max *big.Rat) (string, string) {
+	if value == "" {
+		return "", ""
+	}
+	threshold, ok := new(big.Rat).SetString(value.String())
+	if !ok || threshold.Sign() <= 0 || strings.ContainsAny(value.String(), "/eE") {
+		return "", fmt.Sprintf("%s must be a positive decimal number", name)
+	}
+	if max != nil && threshold.Cmp(max) > 0 {
+		return "", fmt.Sprintf("%s must be at most %s", name, max.FloatString(0))
+	}
+	return value.String(), ""
+}
+
+// parseAlertFilter reads the alert filter from the query string
+func (h *AlertHandler) parseAlertFilter(r *http.Request) (store.AlertFilter, []string) {
+	query := r.URL.Query()
+	filter := store.AlertFilter{
+		ValidatorAddress: query.Get("validator_address"),
+		Limit:            store.DefaultAlertLimit,
+	}
+
+	var validationErrors []string
+	if filter.ValidatorAddress != "" && h.addresses != nil {
+		if err := h.addresses.ValidateValidatorAddress(filter.ValidatorAddress); err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		}
+	}
+	if value := query.Get("rule_id"); value != "" {
+		ruleID, err := strconv.Atoi(value)
+		if err != nil || ruleID < 1 {
+			validationErrors = append(validationErrors, "rule_id must be a positive integer")
+		} else {
+			filter.RuleID = ruleID
+		}
+	}
+
+	parseTime := func(name string) *time.Time {
+		value := query.Get(name)
+		if value == "" {
+			return nil
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("%s must be an RFC 3339 timestamp", name))
+			return nil
+		}
+		return &parsed
+	}
+	filter.Since = parseTime("since")
+	filter.Until = parseTime("until")
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > store.MaxAlertLimit {
+			validationErrors = append(validationErrors, fmt.Sprintf("limit must be between 1 and %d", store.MaxAlertLimit))
+		} else {
+			filter.Limit = limit
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			validationErrors = append(validationErrors, "offset must be a non-negative integer")
+		} else {
+			filter.Offset = offset
+		}
+	}
+
+	return filter, validationErrors
+}
+
+// respondAlertRuleError writes the response for a failed alert rule store
+// call and reports whether the call succeeded
+func respondAlertRuleError(w http.ResponseWriter, id int, err error, failure string) bool {
+	if err == store.ErrAlertRuleNotFound {
+		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusNotFound,
+			"message": "Alert rule not found",
+			"errors": []string{"No alert rule found with id: " + strconv.Itoa(id)},
+		})
+		return false
+	} else if err == store.ErrValidatorNotFound {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": []string{"validator_address is not a known validator"},
+		})
+		return false
+	} else if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": failure,
+			"errors": []string{err.Error()},
+		})
+		return false
+	}
+	return true
+}
+
+// alertRuleIDFromRequest parses the {id} path variable, writing a 400 response if it is invalid
+func alertRuleIDFromRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id < 1 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid alert rule ID",
+			"errors": []string{"Alert rule ID must be a positive integer"},
+		})
+		return 0, false
+	}
+	return id, true
+}
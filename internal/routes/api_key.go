@@ -0,0 +1,213 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/novintriantonius/cosmos-validator-service/internal/auth"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+// APIKeyHandler handles API key administration requests
+type APIKeyHandler struct {
+	store store.APIKeyStore
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(store store.APIKeyStore) *APIKeyHandler {
+	return &APIKeyHandler{store: store}
+}
+
+// createAPIKeyRequest is the body of POST /admin/api-keys
+type createAPIKeyRequest struct {
+	Name string      `json:"name"`
+	Role models.Role `json:"role"`
+}
+
+// GetAll handles GET /admin/api-keys
+func (h *APIKeyHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.store.GetAll(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to retrieve API keys",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "API keys retrieved successfully",
+		"data": map[string]interface{}{
+			"apiKeys": keys,
+			"count": len(keys),
+		},
+	})
+}
+
+// Create handles POST /admin/api-keys
+// The generated key is only returned in this response
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req createAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid request body",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	var validationErrors []string
+	if req.Name == "" {
+		validationErrors = append(validationErrors, "Name is required")
+	}
+	if !req.Role.Valid() {
+		validationErrors = append(validationErrors, "Role must be one of read-only, operator, admin")
+	}
+	if len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": validationErrors,
+		})
+		return
+	}
+
+	secret, prefix, hash, err := auth.GenerateKey()
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to create API key",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	created, err := h.store.Add(r.Context(), models.APIKey{Name: req.Name, Prefix: prefix, Role: req.Role}, hash)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to create API key",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusCreated,
+		"message": "API key created successfully. Store the key now, it cannot be retrieved again",
+		"data": map[string]interface{}{
+			"apiKey": created,
+			"key": secret,
+		},
+	})
+}
+
+// Rotate handles POST /admin/api-keys/{id}/rotate
+// The old key stops working immediately and the new key is only returned in this response
+func (h *APIKeyHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiKeyIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	secret, prefix, hash, err := auth.GenerateKey()
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to rotate API key",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	rotated, err := h.store.Rotate(r.Context(), id, prefix, hash)
+	if err == store.ErrAPIKeyNotFound {
+		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusNotFound,
+			"message": "API key not found",
+			"errors": []string{"No active API key found with id: " + strconv.Itoa(id)},
+		})
+		return
+	} else if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to rotate API key",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "API key rotated successfully. Store the key now, it cannot be retrieved again",
+		"data": map[string]interface{}{
+			"apiKey": rotated,
+			"key": secret,
+		},
+	})
+}
+
+// Revoke handles DELETE /admin/api-keys/{id}
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiKeyIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.store.Revoke(r.Context(), id)
+	if err == store.ErrAPIKeyNotFound {
+		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusNotFound,
+			"message": "API key not found",
+			"errors": []string{"No active API key found with id: " + strconv.Itoa(id)},
+		})
+		return
+	} else if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to revoke API key",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "API key revoked successfully",
+	})
+}
+
+// apiKeyIDFromRequest parses the {id} path variable, writing a 400 response if it is invalid
+func apiKeyIDFromRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id < 1 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid API key ID",
+			"errors": []string{"API key ID must be a positive integer"},
+		})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,113 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+// AuditHandler handles audit log requests
+type AuditHandler struct {
+	store store.AuditStore
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(store store.AuditStore) *AuditHandler {
+	return &AuditHandler{store: store}
+}
+
+// List handles GET /audit
+// Supports the entityType, entityId, action, actor, since, until, limit and offset query parameters
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, validationErrors := parseAuditFilter(r)
+	if len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid query parameters",
+			"errors": validationErrors,
+		})
+		return
+	}
+
+	events, err := h.store.List(r.Context(), filter)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to retrieve audit events",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Audit events retrieved successfully",
+		"data": map[string]interface{}{
+			"events": events,
+			"count": len(events),
+			"limit": filter.Limit,
+			"offset": filter.Offset,
+		},
+	})
+}
+
+// parseAuditFilter reads the audit filter from the query string
+func parseAuditFilter(r *http.Request) (store.AuditFilter, []string) {
+	query := r.URL.Query()
+	filter := store.AuditFilter{
+		EntityType: query.Get("entityType"),
+		EntityID:   query.Get("entityId"),
+		Action:     models.AuditAction(query.Get("action")),
+		Actor:      query.Get("actor"),
+		Limit:      store.DefaultAuditLimit,
+	}
+
+	var validationErrors []string
+	switch filter.Action {
+	case "", models.AuditActionCreate, models.AuditActionUpdate, models.AuditActionDelete,
+		models.AuditActionArchive, models.AuditActionRestore:
+	default:
+		validationErrors = append(validationErrors, "action must be one of create, update, delete, archive, restore")
+	}
+
+	parseTime := func(name string) *time.Time {
+		value := query.Get(name)
+		if value == "" {
+			return nil
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("%s must be an RFC 3339 timestamp", name))
+			return nil
+		}
+		return &parsed
+	}
+	filter.Since = parseTime("since")
+	filter.Until = parseTime("until")
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > store.MaxAuditLimit {
+			validationErrors = append(validationErrors, fmt.Sprintf("limit must be between 1 and %d", store.MaxAuditLimit))
+		} else {
+			filter.Limit = limit
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			validationErrors = append(validationErrors, "offset must be a non-negative integer")
+		} else {
+			filter.Offset = offset
+		}
+	}
+
+	return filter, validationErrors
+}
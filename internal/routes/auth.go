@@ -0,0 +1,84 @@
+package routes
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/audit"
+	"github.com/novintriantonius/cosmos-validator-service/internal/auth"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+// APIKeyHeader is an alternative to the Authorization header for passing an API key
+const APIKeyHeader = "X-API-Key"
+
+// authorizer enforces API key authentication and per-route roles
+type authorizer struct {
+	authenticator *auth.Authenticator
+}
+
+// require wraps a handler so that it only runs for callers whose API key
+// grants at least the given role. A nil authenticator disables the check.
+func (a *authorizer) require(role models.Role, next http.HandlerFunc) http.HandlerFunc {
+	if a.authenticator == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.authenticator.Authenticate(r.Context(), apiKeyFromRequest(r))
+		if errors.Is(err, auth.ErrMissingKey) || errors.Is(err, auth.ErrInvalidKey) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="cosmos-validator-service"`)
+			respondWithJSON(w, http.StatusUnauthorized, map[string]interface{}{
+				"status": "error",
+				"code": http.StatusUnauthorized,
+				"message": "Authentication required",
+				"errors": []string{err.Error()},
+			})
+			return
+		} else if err != nil {
+			slog.ErrorContext(r.Context(), "Failed to authenticate request", "error", err)
+			respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"status": "error",
+				"code": http.StatusInternalServerError,
+				"message": "Failed to authenticate request",
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+
+		if !principal.Role.Allows(role) {
+			respondWithJSON(w, http.StatusForbidden, map[string]interface{}{
+				"status": "error",
+				"code": http.StatusForbidden,
+				"message": "Insufficient permissions",
+				"errors": []string{"This endpoint requires the " + string(role) + " role"},
+			})
+			return
+		}
+
+		ctx := auth.WithPrincipal(r.Context(), principal)
+		ctx = audit.WithActor(ctx, audit.Actor{Name: principal.Name, KeyID: principal.KeyID})
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// apiKeyFromRequest reads the API key from the Authorization bearer token
+// or the X-API-Key header
+func apiKeyFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return r.Header.Get(APIKeyHeader)
+}
+
+// hasRole reports whether the caller of an authorized request has at least
+// the given role. It is always true when authentication is disabled.
+func hasRole(r *http.Request, role models.Role) bool {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	return !ok || principal.Role.Allows(role)
+}
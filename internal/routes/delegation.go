@@ -2,13 +2,20 @@ package routes
 
 import (
 	"fmt"
+	"math/big"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/novintriantonius/cosmos-validator-service/internal/models"
 	"github.com/novintriantonius/cosmos-validator-service/internal/store"
 )
 
+// sharesPrecision is the number of decimals of delegation shares on the chain
+const sharesPrecision = 18
+
 // DelegationHandler handles delegation-related HTTP requests
 type DelegationHandler struct {
 	store store.DelegationStore
@@ -26,7 +33,7 @@ func (h *DelegationHandler) GetHourlyDelegations(w http.ResponseWriter, r *http.
 	validatorAddress := vars["validator_address"]
 
 	// Get all delegations for this validator
-	delegations, err := h.store.GetDelegations(validatorAddress)
+	delegations, err := h.store.GetDelegations(r.Context(), validatorAddress)
 	if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
 			"status":  "error",
@@ -59,7 +66,7 @@ func (h *DelegationHandler) GetDailyDelegations(w http.ResponseWriter, r *http.R
 	validatorAddress := vars["validator_address"]
 
 	// Get all delegations for this validator
-	delegations, err := h.store.GetDelegations(validatorAddress)
+	delegations, err := h.store.GetDelegations(r.Context(), validatorAddress)
 	if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
 			"status":  "error",
@@ -93,7 +100,7 @@ func (h *DelegationHandler) GetDelegatorHistory(w http.ResponseWriter, r *http.R
 	delegatorAddress := vars["delegator_address"]
 
 	// Get all delegations for this validator
-	delegations, err := h.store.GetDelegations(validatorAddress)
+	delegations, err := h.store.GetDelegations(r.Context(), validatorAddress)
 	if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
 			"status":  "error",
@@ -131,6 +138,164 @@ func (h *DelegationHandler) GetDelegatorHistory(w http.ResponseWriter, r *http.R
 	})
 }
 
+// GetDelegationEvents handles GET /api/v1/validators/{validator_address}/delegations/events
+// Returns the changes of the validator's delegations, newest first. Supports
+// the type, min_delta, since, until, limit and offset query parameters.
+func (h *DelegationHandler) GetDelegationEvents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	validatorAddress := vars["validator_address"]
+
+	filter, validationErrors := parseDelegationEventFilter(r)
+	if len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"code":    http.StatusBadRequest,
+			"message": "Invalid query parameters",
+			"errors":  validationErrors,
+		})
+		return
+	}
+	filter.ValidatorAddress = validatorAddress
+
+	events, err := h.store.GetDelegationEvents(r.Context(), filter)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"code":    http.StatusInternalServerError,
+			"message": "Failed to retrieve delegation events",
+			"errors":  []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"code":    http.StatusOK,
+		"message": "Delegation events retrieved successfully",
+		"data": map[string]interface{}{
+			"validator_address": validatorAddress,
+			"events":            events,
+			"count":             len(events),
+			"limit":             filter.Limit,
+			"offset":            filter.Offset,
+		},
+	})
+}
+
+// parseDelegationEventFilter reads the delegation event filter from the query
+// string. Types are given comma-separated or by repeating the parameter.
+func parseDelegationEventFilter(r *http.Request) (store.DelegationEventFilter, []string) {
+	query := r.URL.Query()
+	filter := store.DelegationEventFilter{Limit: store.DefaultDelegationEventLimit}
+
+	var validationErrors []string
+	for _, value := range query["type"] {
+		for _, name := range strings.Split(value, ",") {
+			switch eventType := models.DelegationEventType(strings.TrimSpace(name)); eventType {
+			case models.DelegationEventNew, models.DelegationEventIncrease, models.DelegationEventDecrease, models.DelegationEventExit:
+				filter.Types = append(filter.Types, eventType)
+			default:
+				validationErrors = append(validationErrors, fmt.Sprintf("type %q must be one of new, increase, decrease, exit", name))
+			}
+		}
+	}
+
+	if value := query.Get("min_delta"); value != "" {
+		minDelta, ok := new(big.Rat).SetString(value)
+		if !ok || minDelta.Sign() < 0 || strings.ContainsAny(value, "/eE") {
+			validationErrors = append(validationErrors, "min_delta must be a non-negative decimal number")
+		} else {
+			filter.MinDelta = value
+		}
+	}
+
+	parseTime := func(name string) *time.Time {
+		value := query.Get(name)
+		if value == "" {
+			return nil
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("%s must be an RFC 3339 timestamp", name))
+			return nil
+		}
+		return &parsed
+	}
+	filter.Since = parseTime("since")
+	filter.Until = parseTime("until")
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > store.MaxDelegationEventLimit {
+			validationErrors = append(validationErrors, fmt.Sprintf("limit must be between 1 and %d", store.MaxDelegationEventLimit))
+		} else {
+			filter.Limit = limit
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			validationErrors = append(validationErrors, "offset must be a non-negative integer")
+		} else {
+			filter.Offset = offset
+		}
+	}
+
+	return filter, validationErrors
+}
+
+// GetSummary handles GET /api/v1/delegations/summary
+// Returns the current delegations of every validator that is not archived and
+// their total. With ?tag= only the validators carrying the tag are included.
+func (h *DelegationHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	var tag string
+	if value := r.URL.Query().Get("tag"); value != "" {
+		var message string
+		if tag, message = normalizeTag(value); message != "" {
+			respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"code":    http.StatusBadRequest,
+				"message": "Invalid query parameters",
+				"errors":  []string{message},
+			})
+			return
+		}
+	}
+
+	stakes, err := h.store.GetStakeSummary(r.Context(), tag)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"code":    http.StatusInternalServerError,
+			"message": "Failed to retrieve delegation summary",
+			"errors":  []string{err.Error()},
+		})
+		return
+	}
+
+	totalDelegations := 0
+	totalShares := new(big.Rat)
+	for _, stake := range stakes {
+		totalDelegations += stake.Delegators
+		if shares, ok := new(big.Rat).SetString(stake.TotalShares); ok {
+			totalShares.Add(totalShares, shares)
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"code":    http.StatusOK,
+		"message": "Delegation summary retrieved successfully",
+		"data": map[string]interface{}{
+			"tag":               tag,
+			"validators":        stakes,
+			"count":             len(stakes),
+			"total_delegations": totalDelegations,
+			"total_shares":      totalShares.FloatString(sharesPrecision),
+		},
+	})
+}
+
 // Helper function to group delegations by hour
 func groupDelegationsByHour(delegations []models.Delegation) map[string][]models.Delegation {
 	hourlyMap := make(map[string][]models.Delegation)
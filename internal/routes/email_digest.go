@@ -0,0 +1,284 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+// maxDigestRecipients is the maximum number of recipients of an email digest
+const maxDigestRecipients = 50
+
+// EmailDigestSender sends an email digest right away
+type EmailDigestSender interface {
+	Send(ctx context.Context, digestID int) error
+}
+
+// EmailDigestHandler handles email digest administration requests
+type EmailDigestHandler struct {
+	store  store.EmailDigestStore
+	sender EmailDigestSender
+}
+
+// NewEmailDigestHandler creates a new email digest handler. Digests cannot be
+// sent on demand when sender is nil.
+func NewEmailDigestHandler(store store.EmailDigestStore, sender EmailDigestSender) *EmailDigestHandler {
+	return &EmailDigestHandler{store: store, sender: sender}
+}
+
+// emailDigestRequest is the body of POST /admin/email-digests and
+// PUT /admin/email-digests/{id}
+type emailDigestRequest struct {
+	Tag        string                 `json:"tag"`
+	Frequency  models.DigestFrequency `json:"frequency"`
+	Recipients []string               `json:"recipients"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+// GetAll handles GET /admin/email-digests
+func (h *EmailDigestHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	digests, err := h.store.GetAll(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to retrieve email digests",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Email digests retrieved successfully",
+		"data": map[string]interface{}{
+			"digests": digests,
+			"count": len(digests),
+		},
+	})
+}
+
+// GetByID handles GET /admin/email-digests/{id}
+func (h *EmailDigestHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := emailDigestIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	digest, err := h.store.GetByID(r.Context(), id)
+	if !respondEmailDigestError(w, id, err, "Failed to retrieve email digest") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Email digest retrieved successfully",
+		"data": digest,
+	})
+}
+
+// Create handles POST /admin/email-digests
+func (h *EmailDigestHandler) Create(w http.ResponseWriter, r *http.Request) {
+	digest, ok := decodeEmailDigest(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.store.Add(r.Context(), digest)
+	if !respondEmailDigestError(w, 0, err, "Failed to create email digest") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusCreated,
+		"message": "Email digest created successfully",
+		"data": created,
+	})
+}
+
+// Update handles PUT /admin/email-digests/{id}
+func (h *EmailDigestHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := emailDigestIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	digest, ok := decodeEmailDigest(w, r)
+	if !ok {
+		return
+	}
+	digest.ID = id
+
+	updated, err := h.store.Update(r.Context(), digest)
+	if !respondEmailDigestError(w, id, err, "Failed to update email digest") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Email digest updated successfully",
+		"data": updated,
+	})
+}
+
+// Delete handles DELETE /admin/email-digests/{id}
+func (h *EmailDigestHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := emailDigestIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.store.Delete(r.Context(), id)
+	if !respondEmailDigestError(w, id, err, "Failed to delete email digest") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Email digest deleted successfully",
+	})
+}
+
+// Send handles POST /admin/email-digests/{id}/send
+// The digest is sent right away, whatever its enabled flag
+func (h *EmailDigestHandler) Send(w http.ResponseWriter, r *http.Request) {
+	id, ok := emailDigestIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.sender.Send(r.Context(), id)
+	if err == store.ErrEmailDigestNotFound {
+		respondEmailDigestError(w, id, err, "")
+		return
+	} else if err != nil {
+		respondWithJSON(w, http.StatusBadGateway, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadGateway,
+			"message": "Failed to send email digest",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Email digest sent successfully",
+	})
+}
+
+// decodeEmailDigest reads and validates an email digest from the request
+// body, writing a 400 response if it is invalid
+func decodeEmailDigest(w http.ResponseWriter, r *http.Request) (models.EmailDigest, bool) {
+	var req emailDigestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid request body",
+			"errors": []string{err.Error()},
+		})
+		return models.EmailDigest{}, false
+	}
+
+	digest := models.EmailDigest{
+		Frequency:  req.Frequency,
+		Recipients: []string{},
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+
+	var validationErrors []string
+	tag, message := normalizeTag(req.Tag)
+	if message != "" {
+		validationErrors = append(validationErrors, message)
+	}
+	digest.Tag = tag
+
+	if !digest.Frequency.Valid() {
+		validationErrors = append(validationErrors, "Frequency must be one of daily, weekly")
+	}
+
+	seen := make(map[string]bool)
+	for _, recipient := range req.Recipients {
+		recipient = strings.TrimSpace(recipient)
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("Invalid recipient %q: %v", recipient, err))
+			continue
+		}
+		key := strings.ToLower(address.Address)
+		if !seen[key] {
+			seen[key] = true
+			digest.Recipients = append(digest.Recipients, recipient)
+		}
+	}
+	if len(req.Recipients) == 0 {
+		validationErrors = append(validationErrors, "At least one recipient is required")
+	} else if len(digest.Recipients) > maxDigestRecipients {
+		validationErrors = append(validationErrors, fmt.Sprintf("At most %d recipients are allowed", maxDigestRecipients))
+	}
+
+	if len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": validationErrors,
+		})
+		return models.EmailDigest{}, false
+	}
+	return digest, true
+}
+
+// respondEmailDigestError writes the response for a failed email digest store
+// call and reports whether the call succeeded
+func respondEmailDigestError(w http.ResponseWriter, id int, err error, failure string) bool {
+	if err == store.ErrEmailDigestNotFound {
+		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusNotFound,
+			"message": "Email digest not found",
+			"errors": []string{"No email digest found with id: " + strconv.Itoa(id)},
+		})
+		return false
+	} else if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": failure,
+			"errors": []string{err.Error()},
+		})
+		return false
+	}
+	return true
+}
+
+// emailDigestIDFromRequest parses the {id} path variable, writing a 400 response if it is invalid
+func emailDigestIDFromRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id < 1 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid email digest ID",
+			"errors": []string{"Email digest ID must be a positive integer"},
+		})
+		return 0, false
+	}
+	return id, true
+}
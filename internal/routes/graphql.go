@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/graphql"
+)
+
+// maxGraphQLRequestBytes bounds the body of a GraphQL request
+const maxGraphQLRequestBytes = 1 << 20
+
+// GraphQLHandler serves the GraphQL API. Its responses use the GraphQL
+// format, {"data": ..., "errors": [...]}, instead of the envelope of the
+// other routes so that GraphQL clients understand them.
+type GraphQLHandler struct {
+	api *graphql.API
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+func NewGraphQLHandler(api *graphql.API) *GraphQLHandler {
+	return &GraphQLHandler{api: api}
+}
+
+// Query handles GET and POST /api/v1/graphql and /graphql
+// GET takes the query, operationName and variables query parameters. POST
+// takes a JSON body with the same fields, or the query alone with the
+// application/graphql content type.
+func (h *GraphQLHandler) Query(w http.ResponseWriter, r *http.Request) {
+	var request graphql.Request
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				respondGraphQLError(w, http.StatusBadRequest, "Invalid variables: "+err.Error())
+				return
+			}
+		}
+	} else {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxGraphQLRequestBytes))
+		if err != nil {
+			respondGraphQLError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+			return
+		}
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "application/graphql" {
+			request.Query = string(body)
+		} else if err := json.Unmarshal(body, &request); err != nil {
+			respondGraphQLError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+	}
+
+	if request.Query == "" {
+		respondGraphQLError(w, http.StatusBadRequest, "Must provide a query")
+		return
+	}
+
+	response := h.api.Execute(r.Context(), request)
+	status := http.StatusOK
+	if response.Data == nil {
+		// The request failed before execution
+		status = http.StatusBadRequest
+	}
+	respondWithJSON(w, status, response)
+}
+
+// Schema handles GET /api/v1/graphql/schema
+// Returns the schema in the GraphQL schema definition language
+func (h *GraphQLHandler) Schema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, h.api.SDL())
+}
+
+// respondGraphQLError writes a request error in the GraphQL format
+func respondGraphQLError(w http.ResponseWriter, status int, message string) {
+	respondWithJSON(w, status, graphql.Response{Errors: []*graphql.Error{{Message: message}}})
+}
@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/health"
+)
+
+// HealthHandler handles liveness, readiness and health report requests
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+// Livez handles GET /livez
+// Returns 200 as long as the process is able to serve HTTP requests
+func (h *HealthHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": health.StatusOK,
+	})
+}
+
+// Readyz handles GET /readyz
+// Returns 200 when the database, migrations and Cosmos API checks pass, 503 otherwise
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	report := h.checker.Readiness(r.Context())
+
+	status := http.StatusOK
+	if report.Status == health.StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	respondWithJSON(w, status, report)
+}
+
+// Health handles GET /health
+// Returns the detailed health report including the sync age of every tracked validator.
+// A degraded service still returns 200; 503 is returned only when a dependency is down.
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	report := h.checker.Report(r.Context())
+
+	status := http.StatusOK
+	if report.Status == health.StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	respondWithJSON(w, status, report)
+}
@@ -0,0 +1,382 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/novintriantonius/cosmos-validator-service/internal/address"
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/notify"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+const (
+	// maxNotificationChannelNameLength is the maximum length of a notification channel name
+	maxNotificationChannelNameLength = 255
+
+	// maxNotificationTemplateLength is the maximum length of a message template
+	maxNotificationTemplateLength = 2000
+
+	// maxSyncFailureThreshold is the largest accepted sync failure threshold
+	maxSyncFailureThreshold = 1000
+)
+
+// NotificationTester sends a test notification to a channel right away
+type NotificationTester interface {
+	Test(ctx context.Context, channelID int) error
+}
+
+// NotificationHandler handles notification channel administration requests
+type NotificationHandler struct {
+	store     store.NotificationChannelStore
+	tester    NotificationTester
+	addresses *address.Validator
+}
+
+// NewNotificationHandler creates a new notification channel handler. Test
+// notifications are not available when tester is nil, and validator addresses
+// are checked with addresses unless it is nil.
+func NewNotificationHandler(store store.NotificationChannelStore, tester NotificationTester, addresses *address.Validator) *NotificationHandler {
+	return &NotificationHandler{store: store, tester: tester, addresses: addresses}
+}
+
+// notificationChannelRequest is the body of POST /admin/notification-channels
+// and PUT /admin/notification-channels/{id}
+type notificationChannelRequest struct {
+	Name string                         `json:"name"`
+	Type models.NotificationChannelType `json:"type"`
+	// WebhookURL and BotToken keep their current value when omitted on update
+	WebhookURL         string             `json:"webhookUrl"`
+	BotToken           string             `json:"botToken"`
+	ChatID             string             `json:"chatId"`
+	ValidatorAddresses []string           `json:"validatorAddresses"`
+	EventTypes         []models.EventType `json:"eventTypes"`
+	// SyncFailureThreshold defaults to 1
+	SyncFailureThreshold *int                        `json:"syncFailureThreshold"`
+	Templates            map[models.EventType]string `json:"templates"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+// GetAll handles GET /admin/notification-channels
+func (h *NotificationHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	channels, err := h.store.GetAll(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to retrieve notification channels",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Notification channels retrieved successfully",
+		"data": map[string]interface{}{
+			"channels": channels,
+			"count": len(channels),
+		},
+	})
+}
+
+// GetByID handles GET /admin/notification-channels/{id}
+func (h *NotificationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := notificationChannelIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	channel, err := h.store.GetByID(r.Context(), id)
+	if !respondNotificationChannelError(w, id, err, "Failed to retrieve notification channel") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Notification channel retrieved successfully",
+		"data": channel,
+	})
+}
+
+// Create handles POST /admin/notification-channels
+func (h *NotificationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	channel, ok := h.decodeChannel(w, r, nil)
+	if !ok {
+		return
+	}
+
+	created, err := h.store.Add(r.Context(), channel)
+	if !respondNotificationChannelError(w, 0, err, "Failed to create notification channel") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusCreated,
+		"message": "Notification channel created successfully",
+		"data": created,
+	})
+}
+
+// Update handles PUT /admin/notification-channels/{id}
+// The body replaces the channel; omitted credentials are kept if the type is unchanged
+func (h *NotificationHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := notificationChannelIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	existing, err := h.store.GetByID(r.Context(), id)
+	if !respondNotificationChannelError(w, id, err, "Failed to update notification channel") {
+		return
+	}
+
+	channel, ok := h.decodeChannel(w, r, existing)
+	if !ok {
+		return
+	}
+	channel.ID = id
+
+	updated, err := h.store.Update(r.Context(), channel)
+	if !respondNotificationChannelError(w, id, err, "Failed to update notification channel") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Notification channel updated successfully",
+		"data": updated,
+	})
+}
+
+// Delete handles DELETE /admin/notification-channels/{id}
+func (h *NotificationHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := notificationChannelIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.store.Delete(r.Context(), id)
+	if !respondNotificationChannelError(w, id, err, "Failed to delete notification channel") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Notification channel deleted successfully",
+	})
+}
+
+// Test handles POST /admin/notification-channels/{id}/test
+func (h *NotificationHandler) Test(w http.ResponseWriter, r *http.Request) {
+	id, ok := notificationChannelIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.tester.Test(r.Context(), id)
+	if err == store.ErrNotificationChannelNotFound {
+		respondNotificationChannelError(w, id, err, "")
+		return
+	} else if err != nil {
+		respondWithJSON(w, http.StatusBadGateway, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadGateway,
+			"message": "Test notification failed",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Test notification sent successfully",
+	})
+}
+
+// decodeChannel reads and validates a notification channel from the request
+// body, writing a 400 response if it is invalid. Omitted credentials are taken
+// from existing, if given and of the same type.
+func (h *NotificationHandler) decodeChannel(w http.ResponseWriter, r *http.Request, existing *models.NotificationChannel) (models.NotificationChannel, bool) {
+	var req notificationChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid request body",
+			"errors": []string{err.Error()},
+		})
+		return models.NotificationChannel{}, false
+	}
+
+	channel := models.NotificationChannel{
+		Name:                 strings.TrimSpace(req.Name),
+		Type:                 req.Type,
+		WebhookURL:           strings.TrimSpace(req.WebhookURL),
+		BotToken:             strings.TrimSpace(req.BotToken),
+		ChatID:               strings.TrimSpace(req.ChatID),
+		ValidatorAddresses:   []string{},
+		EventTypes:           []models.EventType{},
+		SyncFailureThreshold: 1,
+		Enabled:              req.Enabled == nil || *req.Enabled,
+	}
+	if req.SyncFailureThreshold != nil {
+		channel.SyncFailureThreshold = *req.SyncFailureThreshold
+	}
+	if existing != nil && existing.Type == channel.Type {
+		if channel.WebhookURL == "" {
+			channel.WebhookURL = existing.WebhookURL
+		}
+		if channel.BotToken == "" {
+			channel.BotToken = existing.BotToken
+		}
+	}
+
+	var validationErrors []string
+	if channel.Name == "" {
+		validationErrors = append(validationErrors, "Name is required")
+	} else if len(channel.Name) > maxNotificationChannelNameLength {
+		validationErrors = append(validationErrors, fmt.Sprintf("Name must be at most %d characters", maxNotificationChannelNameLength))
+	}
+
+	switch channel.Type {
+	case models.NotificationSlack, models.NotificationDiscord:
+		if channel.WebhookURL == "" {
+			validationErrors = append(validationErrors, "webhookUrl is required for "+string(channel.Type)+" channels")
+		} else if parsed, err := url.Parse(channel.WebhookURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			validationErrors = append(validationErrors, "webhookUrl must be an absolute http or https URL")
+		}
+		channel.BotToken = ""
+		channel.ChatID = ""
+	case models.NotificationTelegram:
+		if channel.BotToken == "" {
+			validationErrors = append(validationErrors, "botToken is required for telegram channels")
+		} else if strings.ContainsAny(channel.BotToken, "/?# ") {
+			validationErrors = append(validationErrors, "botToken is not a valid Telegram bot token")
+		}
+		if channel.ChatID == "" {
+			validationErrors = append(validationErrors, "chatId is required for telegram channels")
+		}
+		channel.WebhookURL = ""
+	default:
+		validationErrors = append(validationErrors, "Type must be one of slack, discord, telegram")
+	}
+
+	seenAddresses := make(map[string]bool)
+	for _, validatorAddress := range req.ValidatorAddresses {
+		if h.addresses != nil {
+			if err := h.addresses.ValidateValidatorAddress(validatorAddress); err != nil {
+				validationErrors = append(validationErrors, err.Error())
+				continue
+			}
+		}
+		if !seenAddresses[validatorAddress] {
+			seenAddresses[validatorAddress] = true
+			channel.ValidatorAddresses = append(channel.ValidatorAddresses, validatorAddress)
+		}
+	}
+
+	seenTypes := make(map[models.EventType]bool)
+	for _, eventType := range req.EventTypes {
+		if !events.IsKnownType(eventType) {
+			validationErrors = append(validationErrors, fmt.Sprintf("Unknown event type: %q", eventType))
+			continue
+		}
+		if !seenTypes[eventType] {
+			seenTypes[eventType] = true
+			channel.EventTypes = append(channel.EventTypes, eventType)
+		}
+	}
+	if len(req.EventTypes) == 0 {
+		validationErrors = append(validationErrors, "At least one event type is required")
+	}
+
+	if channel.SyncFailureThreshold < 1 || channel.SyncFailureThreshold > maxSyncFailureThreshold {
+		validationErrors = append(validationErrors, fmt.Sprintf("syncFailureThreshold must be between 1 and %d", maxSyncFailureThreshold))
+	}
+
+	for eventType, text := range req.Templates {
+		if !events.IsKnownType(eventType) {
+			validationErrors = append(validationErrors, fmt.Sprintf("Template for unknown event type: %q", eventType))
+			continue
+		}
+		if len(text) > maxNotificationTemplateLength {
+			validationErrors = append(validationErrors, fmt.Sprintf("Template for %s must be at most %d characters", eventType, maxNotificationTemplateLength))
+			continue
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if _, err := notify.ParseTemplate(text); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("Template for %s is invalid: %v", eventType, err))
+			continue
+		}
+		if channel.Templates == nil {
+			channel.Templates = make(map[models.EventType]string)
+		}
+		channel.Templates[eventType] = text
+	}
+
+	if len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": validationErrors,
+		})
+		return models.NotificationChannel{}, false
+	}
+	return channel, true
+}
+
+// respondNotificationChannelError writes the response for a failed
+// notification channel store call and reports whether the call succeeded
+func respondNotificationChannelError(w http.ResponseWriter, id int, err error, failure string) bool {
+	if err == store.ErrNotificationChannelNotFound {
+		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusNotFound,
+			"message": "Notification channel not found",
+			"errors": []string{"No notification channel found with id: " + strconv.Itoa(id)},
+		})
+		return false
+	} else if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": failure,
+			"errors": []string{err.Error()},
+		})
+		return false
+	}
+	return true
+}
+
+// notificationChannelIDFromRequest parses the {id} path variable, writing a 400 response if it is invalid
+func notificationChannelIDFromRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id < 1 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid notification channel ID",
+			"errors": []string{"Notification channel ID must be a positive integer"},
+		})
+		return 0, false
+	}
+	return id, true
+}
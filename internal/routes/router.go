@@ -2,39 +2,227 @@ package routes
 
 import (
 	"net/http"
+	"time"
+
 	"github.com/gorilla/mux"
+	"github.com/novintriantonius/cosmos-validator-service/internal/address"
+	"github.com/novintriantonius/cosmos-validator-service/internal/auth"
+	"github.com/novintriantonius/cosmos-validator-service/internal/graphql"
+	"github.com/novintriantonius/cosmos-validator-service/internal/health"
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
 	"github.com/novintriantonius/cosmos-validator-service/internal/services"
 	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/novintriantonius/cosmos-validator-service/internal/stream"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tasks"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
+// tracingServiceName names the server in HTTP request spans
+const tracingServiceName = "cosmos-validator-service"
+
+// Dependencies holds the stores and services used by the HTTP handlers
+type Dependencies struct {
+	ValidatorStore  store.ValidatorStore
+	DelegationStore store.DelegationStore
+	CosmosService   *services.CosmosService
+	HealthChecker   *health.Checker
+	APIKeyStore     store.APIKeyStore
+	AuditStore      store.AuditStore
+
+	// AlertStore holds the whale movement alert rules and their alerts. The
+	// alert routes are not registered when it is nil.
+	AlertStore store.AlertStore
+
+	// WebhookStore holds the webhooks and their delivery queue. The webhook
+	// routes are not registered when it is nil.
+	WebhookStore store.WebhookStore
+
+	// WebhookTester sends test deliveries. The webhook test route is not
+	// registered when it is nil.
+	WebhookTester WebhookTester
+
+	// NotificationStore holds the Slack, Discord and Telegram notification
+	// channels. The notification channel routes are not registered when it is nil.
+	NotificationStore store.NotificationChannelStore
+
+	// NotificationTester sends test notifications. The notification channel
+	// test route is not registered when it is nil.
+	NotificationTester NotificationTester
+
+	// EmailDigestStore holds the email digests of validator tags. The email
+	// digest routes are not registered when it is nil.
+	EmailDigestStore store.EmailDigestStore
+
+	// EmailDigestSender sends email digests on demand. The send route is not
+	// registered when it is nil.
+	EmailDigestSender EmailDigestSender
+
+	// DelegationStream feeds live delegation changes to streaming clients. The
+	// stream routes are not registered when it is nil.
+	DelegationStream *stream.Broker
+
+	// StreamHeartbeat is the interval between heartbeats on idle streams
+	StreamHeartbeat time.Duration
+
+	// Authenticator enforces API keys on /api/v1 routes. Authentication is
+	// disabled when it is nil.
+	Authenticator *auth.Authenticator
+
+	// Addresses validates bech32 addresses in paths and request bodies.
+	// Validation is disabled when it is nil.
+	Addresses *address.Validator
+
+	// ValidatorImporter imports the chain's validator set. The import route
+	// is not registered when it is nil.
+	ValidatorImporter *tasks.ValidatorImportTask
+}
+
 // SetupRouter configures all the routes for the application
-func SetupRouter(validatorStore store.ValidatorStore, delegationStore store.DelegationStore, cosmosService *services.CosmosService) *mux.Router {
+func SetupRouter(deps Dependencies) *mux.Router {
 	router := mux.NewRouter()
+	router.Use(otelmux.Middleware(tracingServiceName), requestLoggingMiddleware, metricsMiddleware)
 	
 	// Create handler instances
-	validatorHandler := NewValidatorHandler(validatorStore)
-	delegationHandler := NewDelegationHandler(delegationStore)
+	var chain ChainValidatorSource
+	if deps.CosmosService != nil {
+		chain = deps.CosmosService
+	}
+	validatorHandler := NewValidatorHandler(deps.ValidatorStore, deps.Addresses, chain)
+	delegationHandler := NewDelegationHandler(deps.DelegationStore)
+	healthHandler := NewHealthHandler(deps.HealthChecker)
+	apiKeyHandler := NewAPIKeyHandler(deps.APIKeyStore)
+	auditHandler := NewAuditHandler(deps.AuditStore)
+	authz := &authorizer{authenticator: deps.Authenticator}
+
+	// protect authenticates the caller, checks the role and validates address path variables
+	protect := func(role models.Role, handler http.HandlerFunc) http.HandlerFunc {
+		return authz.require(role, validateAddressVars(deps.Addresses, handler))
+	}
 	
 	// API routes
 	apiRouter := router.PathPrefix("/api/v1").Subrouter()
 	
 	// Validator routes
-	apiRouter.HandleFunc("/validators", validatorHandler.GetAll).Methods("GET")
-	apiRouter.HandleFunc("/validators/{address}", validatorHandler.GetByAddress).Methods("GET")
-	apiRouter.HandleFunc("/validators", validatorHandler.Create).Methods("POST")
-	apiRouter.HandleFunc("/validators/{address}", validatorHandler.Update).Methods("PUT")
-	apiRouter.HandleFunc("/validators/{address}", validatorHandler.Delete).Methods("DELETE")
+	apiRouter.HandleFunc("/validators", protect(models.RoleReadOnly, validatorHandler.GetAll)).Methods("GET")
+	apiRouter.HandleFunc("/validators/{address}", protect(models.RoleReadOnly, validatorHandler.GetByAddress)).Methods("GET")
+	apiRouter.HandleFunc("/validators", protect(models.RoleOperator, validatorHandler.Create)).Methods("POST")
+	apiRouter.HandleFunc("/validators:batch", protect(models.RoleOperator, validatorHandler.Batch)).Methods("POST")
+	apiRouter.HandleFunc("/validators/{address}", protect(models.RoleOperator, validatorHandler.Update)).Methods("PUT")
+	apiRouter.HandleFunc("/validators/{address}", protect(models.RoleOperator, validatorHandler.Patch)).Methods("PATCH")
+	apiRouter.HandleFunc("/validators/{address}", protect(models.RoleOperator, validatorHandler.Delete)).Methods("DELETE")
+	apiRouter.HandleFunc("/validators/{address}/restore", protect(models.RoleOperator, validatorHandler.Restore)).Methods("POST")
+
+	// Tag routes
+	apiRouter.HandleFunc("/tags", protect(models.RoleReadOnly, validatorHandler.GetTags)).Methods("GET")
+	apiRouter.HandleFunc("/tags/{tag}", protect(models.RoleOperator, validatorHandler.RenameTag)).Methods("PUT")
+	apiRouter.HandleFunc("/tags/{tag}", protect(models.RoleOperator, validatorHandler.DeleteTag)).Methods("DELETE")
+	apiRouter.HandleFunc("/validators/{address}/tags", protect(models.RoleReadOnly, validatorHandler.GetValidatorTags)).Methods("GET")
+	apiRouter.HandleFunc("/validators/{address}/tags", protect(models.RoleOperator, validatorHandler.SetValidatorTags)).Methods("PUT")
+	apiRouter.HandleFunc("/validators/{address}/tags/{tag}", protect(models.RoleOperator, validatorHandler.AddValidatorTag)).Methods("PUT")
+	apiRouter.HandleFunc("/validators/{address}/tags/{tag}", protect(models.RoleOperator, validatorHandler.RemoveValidatorTag)).Methods("DELETE")
 	
 	// Delegation routes
-	apiRouter.HandleFunc("/validators/{validator_address}/delegations/hourly", delegationHandler.GetHourlyDelegations).Methods("GET")
-	apiRouter.HandleFunc("/validators/{validator_address}/delegations/daily", delegationHandler.GetDailyDelegations).Methods("GET")
-	apiRouter.HandleFunc("/validators/{validator_address}/delegator/{delegator_address}/history", delegationHandler.GetDelegatorHistory).Methods("GET")
-
-	// Health check endpoint
-	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("Service is healthy"))
-	}).Methods("GET")
+	apiRouter.HandleFunc("/validators/{validator_address}/delegations/hourly", protect(models.RoleReadOnly, delegationHandler.GetHourlyDelegations)).Methods("GET")
+	apiRouter.HandleFunc("/validators/{validator_address}/delegations/daily", protect(models.RoleReadOnly, delegationHandler.GetDailyDelegations)).Methods("GET")
+	apiRouter.HandleFunc("/validators/{validator_address}/delegator/{delegator_address}/history", protect(models.RoleReadOnly, delegationHandler.GetDelegatorHistory)).Methods("GET")
+	apiRouter.HandleFunc("/validators/{validator_address}/delegations/events", protect(models.RoleReadOnly, delegationHandler.GetDelegationEvents)).Methods("GET")
+	apiRouter.HandleFunc("/delegations/summary", protect(models.RoleReadOnly, delegationHandler.GetSummary)).Methods("GET")
+
+	// GraphQL API
+	graphQLHandler := NewGraphQLHandler(graphql.NewAPI(deps.ValidatorStore, deps.DelegationStore, deps.Addresses))
+	apiRouter.HandleFunc("/graphql", protect(models.RoleReadOnly, graphQLHandler.Query)).Methods("GET", "POST")
+	apiRouter.HandleFunc("/graphql/schema", protect(models.RoleReadOnly, graphQLHandler.Schema)).Methods("GET")
+	// Also served at the conventional path GraphQL clients default to
+	router.HandleFunc("/graphql", protect(models.RoleReadOnly, graphQLHandler.Query)).Methods("GET", "POST")
+
+	// Live delegation change stream
+	if deps.DelegationStream != nil {
+		streamHandler := NewStreamHandler(deps.DelegationStream, deps.DelegationStore, deps.Addresses, deps.StreamHeartbeat)
+		apiRouter.HandleFunc("/stream/delegations", protect(models.RoleReadOnly, streamHandler.Delegations)).Methods("GET")
+
+		webSocketHandler := NewWebSocketHandler(deps.DelegationStream, deps.Addresses, deps.StreamHeartbeat)
+		apiRouter.HandleFunc("/stream/ws", protect(models.RoleReadOnly, webSocketHandler.Subscribe)).Methods("GET")
+	}
+
+	// Audit log routes
+	if deps.AuditStore != nil {
+		apiRouter.HandleFunc("/audit", protect(models.RoleOperator, auditHandler.List)).Methods("GET")
+	}
+
+	// Alert routes
+	if deps.AlertStore != nil {
+		alertHandler := NewAlertHandler(deps.AlertStore, deps.Addresses)
+		apiRouter.HandleFunc("/alert-rules", protect(models.RoleReadOnly, alertHandler.GetRules)).Methods("GET")
+		apiRouter.HandleFunc("/alert-rules", protect(models.RoleOperator, alertHandler.CreateRule)).Methods("POST")
+		apiRouter.HandleFunc("/alert-rules/{id}", protect(models.RoleReadOnly, alertHandler.GetRule)).Methods("GET")
+		apiRouter.HandleFunc("/alert-rules/{id}", protect(models.RoleOperator, alertHandler.UpdateRule)).Methods("PUT")
+		apiRouter.HandleFunc("/alert-rules/{id}", protect(models.RoleOperator, alertHandler.DeleteRule)).Methods("DELETE")
+		apiRouter.HandleFunc("/alerts", protect(models.RoleReadOnly, alertHandler.GetAlerts)).Methods("GET")
+	}
+
+	// API key administration routes
+	if deps.APIKeyStore != nil {
+		apiRouter.HandleFunc("/admin/api-keys", protect(models.RoleAdmin, apiKeyHandler.GetAll)).Methods("GET")
+		apiRouter.HandleFunc("/admin/api-keys", protect(models.RoleAdmin, apiKeyHandler.Create)).Methods("POST")
+		apiRouter.HandleFunc("/admin/api-keys/{id}/rotate", protect(models.RoleAdmin, apiKeyHandler.Rotate)).Methods("POST")
+		apiRouter.HandleFunc("/admin/api-keys/{id}", protect(models.RoleAdmin, apiKeyHandler.Revoke)).Methods("DELETE")
+	}
+
+	// Webhook administration routes
+	if deps.WebhookStore != nil {
+		webhookHandler := NewWebhookHandler(deps.WebhookStore, deps.WebhookTester)
+		apiRouter.HandleFunc("/admin/webhooks", protect(models.RoleAdmin, webhookHandler.GetAll)).Methods("GET")
+		apiRouter.HandleFunc("/admin/webhooks", protect(models.RoleAdmin, webhookHandler.Create)).Methods("POST")
+		apiRouter.HandleFunc("/admin/webhooks/{id}", protect(models.RoleAdmin, webhookHandler.GetByID)).Methods("GET")
+		apiRouter.HandleFunc("/admin/webhooks/{id}", protect(models.RoleAdmin, webhookHandler.Update)).Methods("PUT")
+		apiRouter.HandleFunc("/admin/webhooks/{id}", protect(models.RoleAdmin, webhookHandler.Delete)).Methods("DELETE")
+		apiRouter.HandleFunc("/admin/webhooks/{id}/deliveries", protect(models.RoleAdmin, webhookHandler.GetDeliveries)).Methods("GET")
+		apiRouter.HandleFunc("/admin/webhooks/{id}/deliveries/{delivery_id}", protect(models.RoleAdmin, webhookHandler.GetDelivery)).Methods("GET")
+		if deps.WebhookTester != nil {
+			apiRouter.HandleFunc("/admin/webhooks/{id}/test", protect(models.RoleAdmin, webhookHandler.Test)).Methods("POST")
+		}
+	}
+
+	// Notification channel administration routes
+	if deps.NotificationStore != nil {
+		notificationHandler := NewNotificationHandler(deps.NotificationStore, deps.NotificationTester, deps.Addresses)
+		apiRouter.HandleFunc("/admin/notification-channels", protect(models.RoleAdmin, notificationHandler.GetAll)).Methods("GET")
+		apiRouter.HandleFunc("/admin/notification-channels", protect(models.RoleAdmin, notificationHandler.Create)).Methods("POST")
+		apiRouter.HandleFunc("/admin/notification-channels/{id}", protect(models.RoleAdmin, notificationHandler.GetByID)).Methods("GET")
+		apiRouter.HandleFunc("/admin/notification-channels/{id}", protect(models.RoleAdmin, notificationHandler.Update)).Methods("PUT")
+		apiRouter.HandleFunc("/admin/notification-channels/{id}", protect(models.RoleAdmin, notificationHandler.Delete)).Methods("DELETE")
+		if deps.NotificationTester != nil {
+			apiRouter.HandleFunc("/admin/notification-channels/{id}/test", protect(models.RoleAdmin, notificationHandler.Test)).Methods("POST")
+		}
+	}
+
+	// Email digest administration routes
+	if deps.EmailDigestStore != nil {
+		emailDigestHandler := NewEmailDigestHandler(deps.EmailDigestStore, deps.EmailDigestSender)
+		apiRouter.HandleFunc("/admin/email-digests", protect(models.RoleAdmin, emailDigestHandler.GetAll)).Methods("GET")
+		apiRouter.HandleFunc("/admin/email-digests", protect(models.RoleAdmin, emailDigestHandler.Create)).Methods("POST")
+		apiRouter.HandleFunc("/admin/email-digests/{id}", protect(models.RoleAdmin, emailDigestHandler.GetByID)).Methods("GET")
+		apiRouter.HandleFunc("/admin/email-digests/{id}", protect(models.RoleAdmin, emailDigestHandler.Update)).Methods("PUT")
+		apiRouter.HandleFunc("/admin/email-digests/{id}", protect(models.RoleAdmin, emailDigestHandler.Delete)).Methods("DELETE")
+		if deps.EmailDigestSender != nil {
+			apiRouter.HandleFunc("/admin/email-digests/{id}/send", protect(models.RoleAdmin, emailDigestHandler.Send)).Methods("POST")
+		}
+	}
+
+	// Validator set import route
+	if deps.ValidatorImporter != nil {
+		validatorImportHandler := NewValidatorImportHandler(deps.ValidatorImporter)
+		apiRouter.HandleFunc("/admin/validators/import", protect(models.RoleAdmin, validatorImportHandler.Import)).Methods("POST")
+	}
+
+	// Prometheus metrics endpoint
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
+
+	// Health check endpoints
+	router.HandleFunc("/livez", healthHandler.Livez).Methods("GET")
+	router.HandleFunc("/readyz", healthHandler.Readyz).Methods("GET")
+	router.HandleFunc("/health", healthHandler.Health).Methods("GET")
 	
 	return router
-} 
\ No newline at end of file
+}
@@ -0,0 +1,225 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/address"
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/novintriantonius/cosmos-validator-service/internal/stream"
+)
+
+const (
+	// DefaultStreamHeartbeat is the default interval between heartbeats on an
+	// idle stream
+	DefaultStreamHeartbeat = 15 * time.Second
+
+	// streamRetry is the reconnection delay suggested to SSE clients
+	streamRetry = 5 * time.Second
+
+	// streamReplayBatchSize is the number of stored delegation events read at
+	// once when a client resumes a stream
+	streamReplayBatchSize = 500
+)
+
+// StreamHandler streams live delegation changes as Server-Sent Events
+type StreamHandler struct {
+	broker    *stream.Broker
+	store     store.DelegationStore
+	addresses *address.Validator
+	heartbeat time.Duration
+}
+
+// NewStreamHandler creates a new stream handler sending a heartbeat every
+// heartbeat interval, or DefaultStreamHeartbeat when it is not positive.
+// Validator addresses are not validated when addresses is nil.
+func NewStreamHandler(broker *stream.Broker, store store.DelegationStore, addresses *address.Validator, heartbeat time.Duration) *StreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = DefaultStreamHeartbeat
+	}
+	return &StreamHandler{
+		broker:    broker,
+		store:     store,
+		addresses: addresses,
+		heartbeat: heartbeat,
+	}
+}
+
+// Delegations handles GET /stream/delegations
+// Pushes every delegation change committed by the delegation sync, optionally
+// limited to one validator. Clients resume with the Last-Event-ID header or
+// the last_event_id query parameter; the missed changes are replayed from the
+// stored delegation events first.
+//
+// Only streams of one validator can be resumed. The outbox relay keeps the
+// order of the changes of each validator but retries a validator's changes
+// independently of the others, so a stream of every validator can deliver a
+// change after changes with higher IDs, and resuming it from the last ID
+// received would skip that change. Streams of every validator carry no event
+// IDs, so that EventSource clients do not try to resume them.
+func (h *StreamHandler) Delegations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	var validationErrors []string
+	validatorAddress := strings.TrimSpace(query.Get("validator"))
+	if validatorAddress != "" && h.addresses != nil {
+		if err := h.addresses.ValidateValidatorAddress(validatorAddress); err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		}
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = query.Get("last_event_id")
+	}
+	resume := lastEventID != ""
+	var replayedUpTo int64
+	if resume {
+		id, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || id < 0 {
+			validationErrors = append(validationErrors, fmt.Sprintf("Last event ID %q must be a non-negative integer", lastEventID))
+		}
+		replayedUpTo = id
+		if validatorAddress == "" {
+			validationErrors = append(validationErrors, "Resuming a stream requires a validator address")
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid query parameters",
+			"errors": validationErrors,
+		})
+		return
+	}
+
+	// Subscribe before reading the stored events so that no change committed
+	// in between is missed. Changes that are both replayed and published are
+	// skipped by ID, which holds because the changes of a validator are
+	// published in ID order.
+	var match stream.MatchFunc
+	if validatorAddress != "" {
+		match = func(event models.DelegationEvent) bool { return event.ValidatorAddress == validatorAddress }
+	}
+	subscription := h.broker.Subscribe(match)
+	defer subscription.Close()
+
+	var replay []models.DelegationEvent
+	if resume {
+		var err error
+		replay, err = h.store.GetDelegationEventsAfter(ctx, validatorAddress, replayedUpTo, streamReplayBatchSize)
+		if err != nil {
+			respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"status": "error",
+				"code": http.StatusInternalServerError,
+				"message": "Failed to retrieve delegation events",
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+	}
+
+	// Streams outlive the server's write timeout
+	controller := http.NewResponseController(w)
+	controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+	if err := controller.Flush(); err != nil {
+		slog.ErrorContext(ctx, "Failed to start delegation stream", "error", err)
+		return
+	}
+
+	metrics.StreamSubscribers.WithLabelValues("sse").Inc()
+	defer metrics.StreamSubscribers.WithLabelValues("sse").Dec()
+
+	logger := slog.With("validator", validatorAddress)
+	logger.DebugContext(ctx, "Delegation stream opened", "resume", resume, "last_event_id", replayedUpTo)
+
+	for len(replay) > 0 {
+		for _, event := range replay {
+			if err := writeDelegationEvent(w, event, true); err != nil {
+				return
+			}
+			replayedUpTo = event.ID
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+		if len(replay) < streamReplayBatchSize {
+			break
+		}
+
+		var err error
+		replay, err = h.store.GetDelegationEventsAfter(ctx, validatorAddress, replayedUpTo, streamReplayBatchSize)
+		if err != nil {
+			// The client resumes from the last replayed event when it reconnects
+			logger.ErrorContext(ctx, "Failed to replay delegation events", "error", err)
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-subscription.Events():
+			if !ok {
+				// The client resumes from the last event it received when it
+				// reconnects
+				logger.WarnContext(ctx, "Delegation stream ended", "error", subscription.Err())
+				return
+			}
+			if event.ID <= replayedUpTo {
+				continue
+			}
+			if err := writeDelegationEvent(w, event, validatorAddress != ""); err != nil {
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeDelegationEvent writes a delegation change as an SSE event. The ID of
+// the event is the delegation event ID when withID is set.
+func writeDelegationEvent(w io.Writer, event models.DelegationEvent, withID bool) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if withID {
+		if _, err := fmt.Fprintf(w, "id: %d\n", event.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", models.EventDelegationChanged, data)
+	return err
+}
@@ -1,28 +1,68 @@
 package routes
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/novintriantonius/cosmos-validator-service/internal/address"
 	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/services"
 	"github.com/novintriantonius/cosmos-validator-service/internal/store"
 )
 
+const (
+	// mergePatchContentType is the media type of JSON Merge Patch documents
+	mergePatchContentType = "application/merge-patch+json"
+
+	// maxValidatorFieldLength matches the VARCHAR(255) columns of the validators table
+	maxValidatorFieldLength = 255
+)
+
+// ChainValidatorSource looks up validators on the chain
+type ChainValidatorSource interface {
+	GetValidator(ctx context.Context, validatorAddress string) (*models.ChainValidator, error)
+}
+
 // ValidatorHandler handles validator-related HTTP requests
 type ValidatorHandler struct {
-	store store.ValidatorStore
+	store     store.ValidatorStore
+	addresses *address.Validator
+	chain     ChainValidatorSource
 }
 
-// NewValidatorHandler creates a new validator handler
-func NewValidatorHandler(store store.ValidatorStore) *ValidatorHandler {
-	return &ValidatorHandler{store: store}
+// NewValidatorHandler creates a new validator handler. Addresses in request
+// bodies are checked with addresses unless it is nil. Validators are looked
+// up on the chain when they are created unless chain is nil.
+func NewValidatorHandler(store store.ValidatorStore, addresses *address.Validator, chain ChainValidatorSource) *ValidatorHandler {
+	return &ValidatorHandler{store: store, addresses: addresses, chain: chain}
 }
 
 // GetAll handles GET /validators
+// Supports the search, tag, enabledTracking, archived, sort, limit and offset query
+// parameters. Archived validators are only returned with ?archived=true.
 func (h *ValidatorHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	validators, err := h.store.GetAll()
+	filter, validationErrors := parseValidatorFilter(r)
+	if len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid query parameters",
+			"errors": validationErrors,
+		})
+		return
+	}
+
+	validators, total, err := h.store.List(r.Context(), filter)
 	if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
 			"status": "error",
@@ -33,41 +73,92 @@ func (h *ValidatorHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	data := map[string]interface{}{
+		"validators": validators,
+		"count": len(validators),
+		"total": total,
+		"offset": filter.Offset,
+	}
+	// Without a limit every validator is listed, as before pagination
+	if filter.Limit > 0 {
+		data["limit"] = filter.Limit
+	}
 	respondWithJSON(w, http.StatusOK, map[string]interface{}{
 		"status": "success",
 		"code": http.StatusOK,
 		"message": "Validators retrieved successfully",
-		"data": map[string]interface{}{
-			"validators": validators,
-			"count": len(validators),
-		},
+		"data": data,
 	})
 }
 
+// parseValidatorFilter reads the validator list filter from the query string.
+// A sort field prefixed with "-" sorts in descending order.
+func parseValidatorFilter(r *http.Request) (store.ValidatorFilter, []string) {
+	query := r.URL.Query()
+	filter := store.ValidatorFilter{
+		Search:   strings.TrimSpace(query.Get("search")),
+		Archived: query.Get("archived") == "true",
+	}
+
+	var validationErrors []string
+	if value := query.Get("tag"); value != "" {
+		tag, message := normalizeTag(value)
+		if message != "" {
+			validationErrors = append(validationErrors, message)
+		}
+		filter.Tag = tag
+	}
+	if value := query.Get("enabledTracking"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			validationErrors = append(validationErrors, "enabledTracking must be true or false")
+		} else {
+			filter.EnabledTracking = &enabled
+		}
+	}
+
+	if value := query.Get("sort"); value != "" {
+		filter.Descending = strings.HasPrefix(value, "-")
+		filter.Sort = store.ValidatorSort(strings.TrimPrefix(value, "-"))
+		switch filter.Sort {
+		case store.ValidatorSortName, store.ValidatorSortCreatedAt, store.ValidatorSortStake:
+		default:
+			validationErrors = append(validationErrors, "sort must be one of name, createdAt, stake, optionally prefixed with -")
+		}
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > store.MaxValidatorLimit {
+			validationErrors = append(validationErrors, fmt.Sprintf("limit must be between 1 and %d", store.MaxValidatorLimit))
+		} else {
+			filter.Limit = limit
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			validationErrors = append(validationErrors, "offset must be a non-negative integer")
+		} else {
+			filter.Offset = offset
+		}
+	}
+
+	return filter, validationErrors
+}
+
 // GetByAddress handles GET /validators/{address}
+// The response carries an ETag that can be sent in If-Match when updating
 func (h *ValidatorHandler) GetByAddress(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	address := vars["address"]
 
-	validator, err := h.store.GetByAddress(address)
-	if err == store.ErrValidatorNotFound {
-		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
-			"status": "error",
-			"code": http.StatusNotFound,
-			"message": "Validator not found",
-			"errors": []string{"No validator found with address: " + address},
-		})
-		return
-	} else if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"status": "error",
-			"code": http.StatusInternalServerError,
-			"message": "Failed to retrieve validator",
-			"errors": []string{err.Error()},
-		})
+	validator, ok := h.getValidator(w, r, address)
+	if !ok {
 		return
 	}
 
+	w.Header().Set("ETag", validatorETag(validator))
 	respondWithJSON(w, http.StatusOK, map[string]interface{}{
 		"status": "success",
 		"code": http.StatusOK,
@@ -77,6 +168,9 @@ func (h *ValidatorHandler) GetByAddress(w http.ResponseWriter, r *http.Request)
 }
 
 // Create handles POST /validators
+// With ?lookup=true, or when the name is omitted, the validator is looked up
+// on the chain first. It must exist there, its metadata is stored with it and
+// its moniker is used as the name unless one is given.
 func (h *ValidatorHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var validator models.Validator
 	if err := json.NewDecoder(r.Body).Decode(&validator); err != nil {
@@ -88,31 +182,62 @@ func (h *ValidatorHandler) Create(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	// Metadata only ever comes from the chain
+	validator.Metadata = nil
+
+	lookup := r.URL.Query().Get("lookup") == "true" || strings.TrimSpace(validator.Name) == ""
+	if lookup && h.chain != nil && validator.Address != "" && h.validateAddress(validator.Address) == "" {
+		chainValidator, err := h.chain.GetValidator(r.Context(), validator.Address)
+		if errors.Is(err, services.ErrValidatorNotFoundOnChain) {
+			respondWithJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+				"status": "error",
+				"code": http.StatusUnprocessableEntity,
+				"message": "Validator not found on chain",
+				"errors": []string{"No validator with address '" + validator.Address + "' exists on the chain"},
+			})
+			return
+		} else if err != nil {
+			respondWithJSON(w, http.StatusBadGateway, map[string]interface{}{
+				"status": "error",
+				"code": http.StatusBadGateway,
+				"message": "Failed to look up validator on chain",
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+
+		metadata := chainValidator.Metadata()
+		validator.Metadata = &metadata
+		if strings.TrimSpace(validator.Name) == "" {
+			validator.Name = metadata.Moniker
+		}
+	}
 
 	// Validate required fields
-	if validator.Address == "" {
+	if validationErrors := h.validate(validator); len(validationErrors) > 0 {
 		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
 			"status": "error",
 			"code": http.StatusBadRequest,
 			"message": "Validation failed",
-			"errors": []string{"Address is required"},
+			"errors": validationErrors,
 		})
 		return
 	}
 
-	if validator.Name == "" {
-		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+	// Check if validator already exists
+	existingValidator, err := h.store.GetByAddress(r.Context(), validator.Address)
+	if err == nil && existingValidator != nil && existingValidator.ArchivedAt != nil {
+		respondWithJSON(w, http.StatusConflict, map[string]interface{}{
 			"status": "error",
-			"code": http.StatusBadRequest,
-			"message": "Validation failed",
-			"errors": []string{"Name is required"},
+			"code": http.StatusConflict,
+			"message": "Validator is archived",
+			"errors": []string{
+				fmt.Sprintf("A validator with address '%s' is archived", validator.Address),
+				"Restore it with POST /api/v1/validators/" + validator.Address + "/restore",
+			},
 		})
 		return
-	}
-
-	// Check if validator already exists
-	existingValidator, err := h.store.GetByAddress(validator.Address)
-	if err == nil && existingValidator != nil {
+	} else if err == nil && existingValidator != nil {
 		respondWithJSON(w, http.StatusConflict, map[string]interface{}{
 			"status": "error",
 			"code": http.StatusConflict,
@@ -125,7 +250,16 @@ func (h *ValidatorHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.store.Add(validator); err != nil {
+	if err := h.store.Add(r.Context(), validator); err == store.ErrValidatorAlreadyExists {
+		// Created concurrently since the check above
+		respondWithJSON(w, http.StatusConflict, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusConflict,
+			"message": "Validator already exists",
+			"errors": []string{fmt.Sprintf("A validator with address '%s' already exists", validator.Address)},
+		})
+		return
+	} else if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
 			"status": "error",
 			"code": http.StatusInternalServerError,
@@ -135,6 +269,11 @@ func (h *ValidatorHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Return the stored validator so that timestamps and the ETag are included
+	if created, err := h.store.GetByAddress(r.Context(), validator.Address); err == nil {
+		validator = *created
+		w.Header().Set("ETag", validatorETag(created))
+	}
 	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
 		"status": "success",
 		"code": http.StatusCreated,
@@ -144,6 +283,8 @@ func (h *ValidatorHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 // Update handles PUT /validators/{address}
+// The body replaces the name and enabledTracking of the validator. When an
+// If-Match header is sent the update only succeeds if it matches the ETag.
 func (h *ValidatorHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	address := vars["address"]
@@ -159,7 +300,135 @@ func (h *ValidatorHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.store.Update(address, validator); err == store.ErrValidatorNotFound {
+	if validator.Address != "" && validator.Address != address {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": []string{"Address cannot be changed"},
+		})
+		return
+	}
+	validator.Address = address
+
+	if validationErrors := h.validate(validator); len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": validationErrors,
+		})
+		return
+	}
+
+	var expectedUpdatedAt *time.Time
+	if r.Header.Get("If-Match") != "" {
+		current, ok := h.getValidator(w, r, address)
+		if !ok {
+			return
+		}
+		if !ifMatch(r, current) {
+			respondPreconditionFailed(w)
+			return
+		}
+		expectedUpdatedAt = &current.UpdatedAt
+	}
+
+	h.saveUpdate(w, r, address, validator, expectedUpdatedAt)
+}
+
+// Patch handles PATCH /validators/{address}
+// The body is a JSON Merge Patch (RFC 7396) applied to the current validator.
+// When an If-Match header is sent the update only succeeds if it matches the ETag.
+func (h *ValidatorHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil ||
+		(mediaType != mergePatchContentType && mediaType != "application/json") {
+		respondWithJSON(w, http.StatusUnsupportedMediaType, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusUnsupportedMediaType,
+			"message": "Unsupported content type",
+			"errors": []string{"Content-Type must be " + mergePatchContentType},
+		})
+		return
+	}
+
+	var patch map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		message := "request body must be a JSON object"
+		if err != nil {
+			message = err.Error()
+		}
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid request body",
+			"errors": []string{message},
+		})
+		return
+	}
+
+	var patchErrors []string
+	for _, field := range []string{"address", "archivedAt", "createdAt", "updatedAt", "metadata", "tags"} {
+		if _, ok := patch[field]; ok {
+			patchErrors = append(patchErrors, field+" is read-only")
+		}
+	}
+	for field, value := range patch {
+		if value == nil {
+			patchErrors = append(patchErrors, field+" cannot be null")
+		}
+	}
+	if len(patchErrors) > 0 {
+		sort.Strings(patchErrors)
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": patchErrors,
+		})
+		return
+	}
+
+	current, ok := h.getValidator(w, r, address)
+	if !ok {
+		return
+	}
+	if !ifMatch(r, current) {
+		respondPreconditionFailed(w)
+		return
+	}
+
+	validator, err := applyValidatorPatch(current, patch)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid request body",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	if validationErrors := h.validate(validator); len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": validationErrors,
+		})
+		return
+	}
+
+	// The patch was applied to this version, so it must still be current
+	h.saveUpdate(w, r, address, validator, &current.UpdatedAt)
+}
+
+// saveUpdate stores an updated validator and writes the response for PUT and PATCH
+func (h *ValidatorHandler) saveUpdate(w http.ResponseWriter, r *http.Request, address string, validator models.Validator, expectedUpdatedAt *time.Time) {
+	if err := h.store.Update(r.Context(), address, validator, expectedUpdatedAt); err == store.ErrValidatorNotFound {
 		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
 			"status": "error",
 			"code": http.StatusNotFound,
@@ -167,6 +436,17 @@ func (h *ValidatorHandler) Update(w http.ResponseWriter, r *http.Request) {
 			"errors": []string{"No validator found with address: " + address},
 		})
 		return
+	} else if err == store.ErrValidatorArchived {
+		respondWithJSON(w, http.StatusConflict, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusConflict,
+			"message": "Validator is archived",
+			"errors": []string{"Restore the validator before updating it"},
+		})
+		return
+	} else if err == store.ErrValidatorModified {
+		respondPreconditionFailed(w)
+		return
 	} else if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
 			"status": "error",
@@ -178,7 +458,10 @@ func (h *ValidatorHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get the updated validator to return in the response
-	updatedValidator, _ := h.store.GetByAddress(address)
+	updatedValidator, err := h.store.GetByAddress(r.Context(), address)
+	if err == nil {
+		w.Header().Set("ETag", validatorETag(updatedValidator))
+	}
 	respondWithJSON(w, http.StatusOK, map[string]interface{}{
 		"status": "success",
 		"code": http.StatusOK,
@@ -187,12 +470,142 @@ func (h *ValidatorHandler) Update(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// getValidator loads a validator, writing a 404 or 500 response if that fails
+func (h *ValidatorHandler) getValidator(w http.ResponseWriter, r *http.Request, address string) (*models.Validator, bool) {
+	validator, err := h.store.GetByAddress(r.Context(), address)
+	if err == store.ErrValidatorNotFound {
+		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusNotFound,
+			"message": "Validator not found",
+			"errors": []string{"No validator found with address: " + address},
+		})
+		return nil, false
+	} else if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to retrieve validator",
+			"errors": []string{err.Error()},
+		})
+		return nil, false
+	}
+	return validator, true
+}
+
+// validate checks the fields required on create and update
+func (h *ValidatorHandler) validate(validator models.Validator) []string {
+	var validationErrors []string
+	if validator.Address == "" {
+		validationErrors = append(validationErrors, "Address is required")
+	} else if message := h.validateAddress(validator.Address); message != "" {
+		validationErrors = append(validationErrors, message)
+	}
+	if strings.TrimSpace(validator.Name) == "" {
+		validationErrors = append(validationErrors, "Name is required")
+	} else if len(validator.Name) > maxValidatorFieldLength {
+		validationErrors = append(validationErrors, fmt.Sprintf("Name must be at most %d characters", maxValidatorFieldLength))
+	}
+	return validationErrors
+}
+
+// validateAddress returns why a non-empty validator address is invalid, or "" if it is valid
+func (h *ValidatorHandler) validateAddress(validatorAddress string) string {
+	if len(validatorAddress) > maxValidatorFieldLength {
+		return fmt.Sprintf("Address must be at most %d characters", maxValidatorFieldLength)
+	}
+	if h.addresses != nil {
+		if err := h.addresses.ValidateValidatorAddress(validatorAddress); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+// applyValidatorPatch applies a JSON Merge Patch to a copy of the validator
+func applyValidatorPatch(current *models.Validator, patch map[string]interface{}) (models.Validator, error) {
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		return models.Validator{}, err
+	}
+	var document interface{}
+	if err := json.Unmarshal(currentJSON, &document); err != nil {
+		return models.Validator{}, err
+	}
+
+	patchedJSON, err := json.Marshal(mergePatch(document, patch))
+	if err != nil {
+		return models.Validator{}, err
+	}
+
+	var patched models.Validator
+	decoder := json.NewDecoder(bytes.NewReader(patchedJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patched); err != nil {
+		return models.Validator{}, err
+	}
+	return patched, nil
+}
+
+// validatorETag derives the entity tag of a validator from its updated_at
+func validatorETag(validator *models.Validator) string {
+	return `"` + strconv.FormatInt(validator.UpdatedAt.UnixMicro(), 36) + `"`
+}
+
+// ifMatch reports whether the If-Match header of the request, if any,
+// matches the current version of the validator
+func ifMatch(r *http.Request, current *models.Validator) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return true
+	}
+	etag := validatorETag(current)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// respondPreconditionFailed writes the response for a failed If-Match check
+func respondPreconditionFailed(w http.ResponseWriter) {
+	respondWithJSON(w, http.StatusPreconditionFailed, map[string]interface{}{
+		"status": "error",
+		"code": http.StatusPreconditionFailed,
+		"message": "Precondition failed",
+		"errors": []string{"The validator was modified since it was read; fetch it again to get the current ETag"},
+	})
+}
+
 // Delete handles DELETE /validators/{address}
+// The validator is archived and its delegation history kept. With
+// ?purge=true, which requires the admin role, the validator and its history
+// are permanently deleted.
 func (h *ValidatorHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	address := vars["address"]
 
-	if err := h.store.Delete(address); err == store.ErrValidatorNotFound {
+	purge := r.URL.Query().Get("purge") == "true"
+	if purge && !hasRole(r, models.RoleAdmin) {
+		respondWithJSON(w, http.StatusForbidden, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusForbidden,
+			"message": "Insufficient permissions",
+			"errors": []string{"Purging a validator requires the admin role"},
+		})
+		return
+	}
+
+	var err error
+	if purge {
+		err = h.store.Delete(r.Context(), address)
+	} else {
+		err = h.store.Archive(r.Context(), address)
+	}
+
+	if err == store.ErrValidatorNotFound {
 		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
 			"status": "error",
 			"code": http.StatusNotFound,
@@ -200,6 +613,14 @@ func (h *ValidatorHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			"errors": []string{"No validator found with address: " + address},
 		})
 		return
+	} else if err == store.ErrValidatorArchived {
+		respondWithJSON(w, http.StatusConflict, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusConflict,
+			"message": "Validator is already archived",
+			"errors": []string{"Use ?purge=true to delete it permanently"},
+		})
+		return
 	} else if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
 			"status": "error",
@@ -210,14 +631,58 @@ func (h *ValidatorHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	message := "Validator archived successfully"
+	if purge {
+		message = "Validator and its delegation history deleted permanently"
+	}
 	respondWithJSON(w, http.StatusOK, map[string]interface{}{
 		"status": "success",
 		"code": http.StatusOK,
-		"message": "Validator deleted successfully",
+		"message": message,
 		"data": nil,
 	})
 }
 
+// Restore handles POST /validators/{address}/restore
+func (h *ValidatorHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+
+	if err := h.store.Restore(r.Context(), address); err == store.ErrValidatorNotFound {
+		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusNotFound,
+			"message": "Validator not found",
+			"errors": []string{"No validator found with address: " + address},
+		})
+		return
+	} else if err == store.ErrValidatorNotArchived {
+		respondWithJSON(w, http.StatusConflict, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusConflict,
+			"message": "Validator is not archived",
+			"errors": []string{"Only archived validators can be restored"},
+		})
+		return
+	} else if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to restore validator",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	restoredValidator, _ := h.store.GetByAddress(r.Context(), address)
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Validator restored successfully",
+		"data": restoredValidator,
+	})
+}
+
 // respondWithJSON is a helper function to write a JSON response
 func respondWithJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,129 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+// maxBatchSize is the maximum number of validators in a batch request
+const maxBatchSize = 1000
+
+// batchItemResult is the outcome of a single validator in a batch request
+type batchItemResult struct {
+	Index   int                `json:"index"`
+	Address string             `json:"address"`
+	Status  store.UpsertStatus `json:"status"`
+	Errors  []string           `json:"errors,omitempty"`
+}
+
+// Batch handles POST /validators:batch
+// The body is an array of validators. Each is created, or updated when it
+// already exists, in a single transaction. Invalid and conflicting items are
+// reported and the others written, unless ?atomic=true is set, in which case
+// nothing is written when any item fails.
+func (h *ValidatorHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	var validators []models.Validator
+	if err := json.NewDecoder(r.Body).Decode(&validators); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid request body",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+	if len(validators) == 0 || len(validators) > maxBatchSize {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": []string{fmt.Sprintf("The batch must contain between 1 and %d validators", maxBatchSize)},
+		})
+		return
+	}
+	atomic := r.URL.Query().Get("atomic") == "true"
+
+	// Validate every item before touching the store
+	results := make([]batchItemResult, len(validators))
+	var valid []models.Validator
+	var validIndexes []int
+	failed := 0
+	seen := make(map[string]int, len(validators))
+	for i, validator := range validators {
+		results[i] = batchItemResult{Index: i, Address: validator.Address}
+		if validationErrors := h.validate(validator); len(validationErrors) > 0 {
+			results[i].Status = store.UpsertInvalid
+			results[i].Errors = validationErrors
+			failed++
+			continue
+		}
+		if first, ok := seen[validator.Address]; ok {
+			results[i].Status = store.UpsertConflict
+			results[i].Errors = []string{fmt.Sprintf("Address already appears at index %d", first)}
+			failed++
+			continue
+		}
+		seen[validator.Address] = i
+		valid = append(valid, validator)
+		validIndexes = append(validIndexes, i)
+	}
+
+	applied := true
+	if atomic && failed > 0 {
+		applied = false
+	} else if len(valid) > 0 {
+		statuses, err := h.store.Upsert(r.Context(), valid, atomic)
+		if err != nil && err != store.ErrBatchConflict {
+			respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"status": "error",
+				"code": http.StatusInternalServerError,
+				"message": "Failed to save validators",
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+		applied = err == nil
+		for j, status := range statuses {
+			result := &results[validIndexes[j]]
+			result.Status = status
+			if status == store.UpsertConflict {
+				result.Errors = []string{"Validator is archived; restore it before updating it"}
+			}
+		}
+	}
+
+	counts := make(map[store.UpsertStatus]int)
+	for i := range results {
+		if !applied && (results[i].Status == "" || results[i].Status == store.UpsertCreated || results[i].Status == store.UpsertUpdated) {
+			results[i].Status = store.UpsertSkipped
+		}
+		counts[results[i].Status]++
+	}
+
+	data := map[string]interface{}{
+		"applied": applied,
+		"results": results,
+		"counts": counts,
+	}
+	if !applied {
+		respondWithJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusUnprocessableEntity,
+			"message": "Batch rejected, no validators were saved",
+			"errors": []string{"At least one validator is invalid or conflicts"},
+			"data": data,
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Batch processed",
+		"data": data,
+	})
+}
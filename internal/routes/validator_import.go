@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/tasks"
+)
+
+// ValidatorImportHandler handles imports of the chain's validator set
+type ValidatorImportHandler struct {
+	importer *tasks.ValidatorImportTask
+}
+
+// NewValidatorImportHandler creates a new validator import handler
+func NewValidatorImportHandler(importer *tasks.ValidatorImportTask) *ValidatorImportHandler {
+	return &ValidatorImportHandler{importer: importer}
+}
+
+// Import handles POST /admin/validators/import
+// The body is an import filter; an empty body imports all bonded validators
+// with tracking enabled
+func (h *ValidatorImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	var filter tasks.ImportFilter
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	// An empty body, including a chunked one without a content length,
+	// imports with the default filter
+	if err := decoder.Decode(&filter); err != nil && !errors.Is(err, io.EOF) {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid request body",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	if validationErrors := filter.Validate(); len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": validationErrors,
+		})
+		return
+	}
+
+	summary, err := h.importer.Import(r.Context(), filter)
+	if errors.Is(err, tasks.ErrListValidators) {
+		respondWithJSON(w, http.StatusBadGateway, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadGateway,
+			"message": "Failed to list validators from the chain",
+			"errors": []string{err.Error()},
+		})
+		return
+	} else if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to import validators",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Validators imported successfully",
+		"data": summary,
+	})
+}
@@ -0,0 +1,341 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+const (
+	// maxTagLength is the maximum length of a tag
+	maxTagLength = 64
+
+	// maxTagsPerValidator is the maximum number of tags on a validator
+	maxTagsPerValidator = 50
+)
+
+// tagPattern matches normalized tags: lowercase letters, digits and the
+// separators '.', '_', ':' and '-', starting with a letter or digit
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._:-]*$`)
+
+// normalizeTag lowercases and trims a tag and returns why it is invalid, if it is
+func normalizeTag(raw string) (string, string) {
+	tag := strings.ToLower(strings.TrimSpace(raw))
+	switch {
+	case tag == "":
+		return "", "Tag is required"
+	case len(tag) > maxTagLength:
+		return "", fmt.Sprintf("Tag '%s' must be at most %d characters", tag, maxTagLength)
+	case !tagPattern.MatchString(tag):
+		return "", fmt.Sprintf("Tag '%s' may only contain letters, digits, '.', '_', ':' and '-', and must start with a letter or digit", tag)
+	}
+	return tag, ""
+}
+
+// GetTags handles GET /tags
+// Returns every tag of the validators that are not archived, with the number
+// of validators carrying it
+func (h *ValidatorHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.store.ListTags(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to retrieve tags",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Tags retrieved successfully",
+		"data": map[string]interface{}{
+			"tags": tags,
+			"count": len(tags),
+		},
+	})
+}
+
+// RenameTag handles PUT /tags/{tag}
+// The body holds the new name of the tag, which replaces it on every
+// validator carrying it, archived ones included
+func (h *ValidatorHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	tag, ok := tagVar(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid request body",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+	newTag, message := normalizeTag(body.Name)
+	if message != "" {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": []string{message},
+		})
+		return
+	}
+
+	count, err := h.store.RenameTag(r.Context(), tag, newTag)
+	if !respondTagError(w, tag, err, "Failed to rename tag") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Tag renamed successfully",
+		"data": map[string]interface{}{
+			"tag": newTag,
+			"validators": count,
+		},
+	})
+}
+
+// DeleteTag handles DELETE /tags/{tag}
+// The tag is removed from every validator carrying it, archived ones included
+func (h *ValidatorHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	tag, ok := tagVar(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := h.store.DeleteTag(r.Context(), tag)
+	if !respondTagError(w, tag, err, "Failed to delete tag") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Tag deleted successfully",
+		"data": map[string]interface{}{
+			"tag": tag,
+			"validators": count,
+		},
+	})
+}
+
+// GetValidatorTags handles GET /validators/{address}/tags
+func (h *ValidatorHandler) GetValidatorTags(w http.ResponseWriter, r *http.Request) {
+	address := mux.Vars(r)["address"]
+
+	validator, ok := h.getValidator(w, r, address)
+	if !ok {
+		return
+	}
+
+	tags := validator.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Validator tags retrieved successfully",
+		"data": map[string]interface{}{
+			"address": address,
+			"tags": tags,
+		},
+	})
+}
+
+// SetValidatorTags handles PUT /validators/{address}/tags
+// The body holds the tags that replace those of the validator
+func (h *ValidatorHandler) SetValidatorTags(w http.ResponseWriter, r *http.Request) {
+	address := mux.Vars(r)["address"]
+
+	var body struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid request body",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	var validationErrors []string
+	if len(body.Tags) > maxTagsPerValidator {
+		validationErrors = append(validationErrors, fmt.Sprintf("A validator can have at most %d tags", maxTagsPerValidator))
+	}
+	tags := make([]string, 0, len(body.Tags))
+	for _, raw := range body.Tags {
+		tag, message := normalizeTag(raw)
+		if message != "" {
+			validationErrors = append(validationErrors, message)
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	if len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": validationErrors,
+		})
+		return
+	}
+
+	err := h.store.SetTags(r.Context(), address, tags)
+	h.respondValidatorTagged(w, r, address, "", err)
+}
+
+// AddValidatorTag handles PUT /validators/{address}/tags/{tag}
+// Adding a tag the validator already carries changes nothing
+func (h *ValidatorHandler) AddValidatorTag(w http.ResponseWriter, r *http.Request) {
+	address := mux.Vars(r)["address"]
+	tag, ok := tagVar(w, r)
+	if !ok {
+		return
+	}
+
+	current, ok := h.getValidator(w, r, address)
+	if !ok {
+		return
+	}
+	if len(current.Tags) >= maxTagsPerValidator && !hasTag(current.Tags, tag) {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": []string{fmt.Sprintf("A validator can have at most %d tags", maxTagsPerValidator)},
+		})
+		return
+	}
+
+	err := h.store.AddTag(r.Context(), address, tag)
+	h.respondValidatorTagged(w, r, address, tag, err)
+}
+
+// RemoveValidatorTag handles DELETE /validators/{address}/tags/{tag}
+func (h *ValidatorHandler) RemoveValidatorTag(w http.ResponseWriter, r *http.Request) {
+	address := mux.Vars(r)["address"]
+	tag, ok := tagVar(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.store.RemoveTag(r.Context(), address, tag)
+	h.respondValidatorTagged(w, r, address, tag, err)
+}
+
+// hasTag reports whether tags contains tag
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// tagVar reads and normalizes the tag path variable, writing a 400 response if it is invalid
+func tagVar(w http.ResponseWriter, r *http.Request) (string, bool) {
+	tag, message := normalizeTag(mux.Vars(r)["tag"])
+	if message != "" {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid tag",
+			"errors": []string{message},
+		})
+		return "", false
+	}
+	return tag, true
+}
+
+// respondTagError writes the response for a failed tag store call and
+// reports whether the call succeeded
+func respondTagError(w http.ResponseWriter, tag string, err error, failure string) bool {
+	if err == store.ErrTagNotFound {
+		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusNotFound,
+			"message": "Tag not found",
+			"errors": []string{"No validator carries the tag: " + tag},
+		})
+		return false
+	} else if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": failure,
+			"errors": []string{err.Error()},
+		})
+		return false
+	}
+	return true
+}
+
+// respondValidatorTagged writes the response for a change of the tags of a
+// validator, returning the updated validator and its ETag on success
+func (h *ValidatorHandler) respondValidatorTagged(w http.ResponseWriter, r *http.Request, address, tag string, err error) {
+	if err == store.ErrValidatorNotFound {
+		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusNotFound,
+			"message": "Validator not found",
+			"errors": []string{"No validator found with address: " + address},
+		})
+		return
+	} else if err == store.ErrValidatorArchived {
+		respondWithJSON(w, http.StatusConflict, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusConflict,
+			"message": "Validator is archived",
+			"errors": []string{"Restore the validator before changing its tags"},
+		})
+		return
+	} else if err == store.ErrTagNotFound {
+		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusNotFound,
+			"message": "Tag not found",
+			"errors": []string{fmt.Sprintf("Validator %s does not carry the tag: %s", address, tag)},
+		})
+		return
+	} else if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to update validator tags",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	validator, err := h.store.GetByAddress(r.Context(), address)
+	if err == nil {
+		w.Header().Set("ETag", validatorETag(validator))
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Validator tags updated successfully",
+		"data": validator,
+	})
+}
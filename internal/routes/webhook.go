@@ -0,0 +1,428 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/novintriantonius/cosmos-validator-service/internal/webhooks"
+)
+
+const (
+	// maxWebhookURLLength is the maximum length of a webhook URL
+	maxWebhookURLLength = 2048
+
+	// maxWebhookDescriptionLength is the maximum length of a webhook description
+	maxWebhookDescriptionLength = 255
+)
+
+// WebhookTester sends a test event to a webhook right away
+type WebhookTester interface {
+	TestFire(ctx context.Context, webhookID int) (*models.WebhookDelivery, error)
+}
+
+// WebhookHandler handles webhook administration requests
+type WebhookHandler struct {
+	store  store.WebhookStore
+	tester WebhookTester
+}
+
+// NewWebhookHandler creates a new webhook handler. Test deliveries are not
+// available when tester is nil.
+func NewWebhookHandler(store store.WebhookStore, tester WebhookTester) *WebhookHandler {
+	return &WebhookHandler{store: store, tester: tester}
+}
+
+// webhookRequest is the body of POST /admin/webhooks and PUT /admin/webhooks/{id}
+type webhookRequest struct {
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	// EventTypes defaults to every event type
+	EventTypes []models.EventType `json:"eventTypes"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+// GetAll handles GET /admin/webhooks
+func (h *WebhookHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.store.GetAll(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to retrieve webhooks",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Webhooks retrieved successfully",
+		"data": map[string]interface{}{
+			"webhooks": hooks,
+			"count": len(hooks),
+		},
+	})
+}
+
+// GetByID handles GET /admin/webhooks/{id}
+func (h *WebhookHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.store.GetByID(r.Context(), id)
+	if !respondWebhookError(w, id, err, "Failed to retrieve webhook") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Webhook retrieved successfully",
+		"data": webhook,
+	})
+}
+
+// Create handles POST /admin/webhooks
+// The generated signing secret is only returned in this response
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := decodeWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to create webhook",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+	webhook.Secret = secret
+
+	created, err := h.store.Add(r.Context(), webhook)
+	if !respondWebhookError(w, 0, err, "Failed to create webhook") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusCreated,
+		"message": "Webhook created successfully. Store the secret now, it cannot be retrieved again",
+		"data": map[string]interface{}{
+			"webhook": created,
+			"secret": secret,
+		},
+	})
+}
+
+// Update handles PUT /admin/webhooks/{id}
+// The body replaces the webhook; its secret is kept
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	webhook, ok := decodeWebhook(w, r)
+	if !ok {
+		return
+	}
+	webhook.ID = id
+
+	updated, err := h.store.Update(r.Context(), webhook)
+	if !respondWebhookError(w, id, err, "Failed to update webhook") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Webhook updated successfully",
+		"data": updated,
+	})
+}
+
+// Delete handles DELETE /admin/webhooks/{id}
+// Queued deliveries and the delivery log of the webhook are deleted with it
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.store.Delete(r.Context(), id)
+	if !respondWebhookError(w, id, err, "Failed to delete webhook") {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Webhook deleted successfully",
+	})
+}
+
+// Test handles POST /admin/webhooks/{id}/test
+// It responds with the test delivery, whether or not the endpoint accepted it
+func (h *WebhookHandler) Test(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.tester.TestFire(r.Context(), id)
+	if !respondWebhookError(w, id, err, "Failed to send test delivery") {
+		return
+	}
+
+	message := "Test delivery sent successfully"
+	if delivery.Status != models.WebhookDeliveryDelivered {
+		message = "Test delivery was not accepted by the webhook"
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": message,
+		"data": delivery,
+	})
+}
+
+// GetDeliveries handles GET /admin/webhooks/{id}/deliveries
+// Supports the status, limit and offset query parameters
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	filter, validationErrors := parseWebhookDeliveryFilter(r)
+	if len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid query parameters",
+			"errors": validationErrors,
+		})
+		return
+	}
+	filter.WebhookID = id
+
+	if _, err := h.store.GetByID(r.Context(), id); !respondWebhookError(w, id, err, "Failed to retrieve webhook deliveries") {
+		return
+	}
+
+	deliveries, err := h.store.ListDeliveries(r.Context(), filter)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to retrieve webhook deliveries",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Webhook deliveries retrieved successfully",
+		"data": map[string]interface{}{
+			"deliveries": deliveries,
+			"count": len(deliveries),
+			"limit": filter.Limit,
+			"offset": filter.Offset,
+		},
+	})
+}
+
+// GetDelivery handles GET /admin/webhooks/{id}/deliveries/{delivery_id}
+// The response includes every attempt of the delivery
+func (h *WebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(mux.Vars(r)["delivery_id"], 10, 64)
+	if err != nil || deliveryID < 1 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid webhook delivery ID",
+			"errors": []string{"Webhook delivery ID must be a positive integer"},
+		})
+		return
+	}
+
+	delivery, err := h.store.GetDelivery(r.Context(), id, deliveryID)
+	if err == store.ErrWebhookDeliveryNotFound {
+		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusNotFound,
+			"message": "Webhook delivery not found",
+			"errors": []string{fmt.Sprintf("No delivery found with id %d for webhook %d", deliveryID, id)},
+		})
+		return
+	} else if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": "Failed to retrieve webhook delivery",
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"code": http.StatusOK,
+		"message": "Webhook delivery retrieved successfully",
+		"data": delivery,
+	})
+}
+
+// decodeWebhook reads and validates a webhook from the request body, writing
+// a 400 response if it is invalid
+func decodeWebhook(w http.ResponseWriter, r *http.Request) (models.Webhook, bool) {
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid request body",
+			"errors": []string{err.Error()},
+		})
+		return models.Webhook{}, false
+	}
+
+	webhook := models.Webhook{
+		URL:         strings.TrimSpace(req.URL),
+		Description: strings.TrimSpace(req.Description),
+		EventTypes:  []models.EventType{},
+		Enabled:     req.Enabled == nil || *req.Enabled,
+	}
+
+	var validationErrors []string
+	if webhook.URL == "" {
+		validationErrors = append(validationErrors, "URL is required")
+	} else if len(webhook.URL) > maxWebhookURLLength {
+		validationErrors = append(validationErrors, fmt.Sprintf("URL must be at most %d characters", maxWebhookURLLength))
+	} else if parsed, err := url.Parse(webhook.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		validationErrors = append(validationErrors, "URL must be an absolute http or https URL")
+	}
+	if len(webhook.Description) > maxWebhookDescriptionLength {
+		validationErrors = append(validationErrors, fmt.Sprintf("Description must be at most %d characters", maxWebhookDescriptionLength))
+	}
+
+	seen := make(map[models.EventType]bool)
+	for _, eventType := range req.EventTypes {
+		if !events.IsKnownType(eventType) {
+			validationErrors = append(validationErrors, fmt.Sprintf("Unknown event type: %q", eventType))
+			continue
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			webhook.EventTypes = append(webhook.EventTypes, eventType)
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Validation failed",
+			"errors": validationErrors,
+		})
+		return models.Webhook{}, false
+	}
+	return webhook, true
+}
+
+// parseWebhookDeliveryFilter reads the delivery filter from the query string
+func parseWebhookDeliveryFilter(r *http.Request) (store.WebhookDeliveryFilter, []string) {
+	query := r.URL.Query()
+	filter := store.WebhookDeliveryFilter{
+		Status: models.WebhookDeliveryStatus(query.Get("status")),
+		Limit:  store.DefaultWebhookDeliveryLimit,
+	}
+
+	var validationErrors []string
+	switch filter.Status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliveryDelivered, models.WebhookDeliveryFailed:
+	default:
+		validationErrors = append(validationErrors, "status must be one of pending, delivered, failed")
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > store.MaxWebhookDeliveryLimit {
+			validationErrors = append(validationErrors, fmt.Sprintf("limit must be between 1 and %d", store.MaxWebhookDeliveryLimit))
+		} else {
+			filter.Limit = limit
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			validationErrors = append(validationErrors, "offset must be a non-negative integer")
+		} else {
+			filter.Offset = offset
+		}
+	}
+
+	return filter, validationErrors
+}
+
+// respondWebhookError writes the response for a failed webhook store call and
+// reports whether the call succeeded
+func respondWebhookError(w http.ResponseWriter, id int, err error, failure string) bool {
+	if err == store.ErrWebhookNotFound {
+		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusNotFound,
+			"message": "Webhook not found",
+			"errors": []string{"No webhook found with id: " + strconv.Itoa(id)},
+		})
+		return false
+	} else if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusInternalServerError,
+			"message": failure,
+			"errors": []string{err.Error()},
+		})
+		return false
+	}
+	return true
+}
+
+// webhookIDFromRequest parses the {id} path variable, writing a 400 response if it is invalid
+func webhookIDFromRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id < 1 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"status": "error",
+			"code": http.StatusBadRequest,
+			"message": "Invalid webhook ID",
+			"errors": []string{"Webhook ID must be a positive integer"},
+		})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,318 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/novintriantonius/cosmos-validator-service/internal/address"
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/stream"
+)
+
+const (
+	// MaxWebSocketSubscriptions is the maximum number of subscriptions on one
+	// WebSocket connection
+	MaxWebSocketSubscriptions = 50
+
+	// MaxWebSocketSubscriptionAddresses is the maximum number of validator or
+	// delegator addresses in one subscription
+	MaxWebSocketSubscriptionAddresses = 100
+
+	// maxSubscriptionIDLength bounds client chosen subscription IDs
+	maxSubscriptionIDLength = 64
+
+	// wsMaxMessageSize is the maximum size of a client message; larger
+	// messages close the connection
+	wsMaxMessageSize = 64 << 10
+
+	// wsWriteTimeout bounds every write to a client
+	wsWriteTimeout = 10 * time.Second
+)
+
+// WebSocket message types
+const (
+	wsMessageSubscribe    = "subscribe"
+	wsMessageUnsubscribe  = "unsubscribe"
+	wsMessageSubscribed   = "subscribed"
+	wsMessageUnsubscribed = "unsubscribed"
+	wsMessageError        = "error"
+)
+
+// wsClientMessage is a subscribe or unsubscribe message sent by a client
+type wsClientMessage struct {
+	Type         string   `json:"type"`
+	Subscription string   `json:"subscription"`
+	Validators   []string `json:"validators"`
+	Delegators   []string `json:"delegators"`
+	MinDelta     string   `json:"min_delta"`
+}
+
+// wsServerMessage is a reply or delegation change sent to a client
+type wsServerMessage struct {
+	Type          string                  `json:"type"`
+	Subscription  string                  `json:"subscription,omitempty"`
+	Subscriptions []string                `json:"subscriptions,omitempty"`
+	Event         *models.DelegationEvent `json:"event,omitempty"`
+	Message       string                  `json:"message,omitempty"`
+	Errors        []string                `json:"errors,omitempty"`
+}
+
+// WebSocketHandler streams live delegation changes over WebSocket connections
+// carrying any number of filtered subscriptions
+type WebSocketHandler struct {
+	broker    *stream.Broker
+	addresses *address.Validator
+	heartbeat time.Duration
+	upgrader  websocket.Upgrader
+}
+
+// NewWebSocketHandler creates a new WebSocket handler pinging clients every
+// heartbeat interval, or DefaultStreamHeartbeat when it is not positive.
+// Addresses are not validated when addresses is nil.
+func NewWebSocketHandler(broker *stream.Broker, addresses *address.Validator, heartbeat time.Duration) *WebSocketHandler {
+	if heartbeat <= 0 {
+		heartbeat = DefaultStreamHeartbeat
+	}
+	return &WebSocketHandler{
+		broker:    broker,
+		addresses: addresses,
+		heartbeat: heartbeat,
+	}
+}
+
+// wsSubscriptions holds the filters subscribed on one connection
+type wsSubscriptions struct {
+	mu      sync.RWMutex
+	filters map[string]stream.Filter
+}
+
+// matches reports whether any subscription selects a delegation change
+func (s *wsSubscriptions) matches(event models.DelegationEvent) bool {
+	return len(s.matching(event)) > 0
+}
+
+// matching returns the sorted IDs of the subscriptions selecting a delegation
+// change
+func (s *wsSubscriptions) matching(event models.DelegationEvent) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ids []string
+	for id, filter := range s.filters {
+		if filter.Matches(event) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Subscribe handles GET /stream/ws
+// Upgrades the request to a WebSocket connection on which the client adds and
+// removes filtered subscriptions. Each delegation change is sent once with
+// the IDs of every subscription it matches. Connections that fall behind the
+// change feed are closed with code 1013 (try again later).
+func (h *WebSocketHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already responded with an HTTP error
+		slog.DebugContext(ctx, "Failed to upgrade WebSocket connection", "error", err)
+		return
+	}
+	conn.SetReadLimit(wsMaxMessageSize)
+
+	subscriptions := &wsSubscriptions{filters: make(map[string]stream.Filter)}
+	subscription := h.broker.Subscribe(subscriptions.matches)
+	defer subscription.Close()
+
+	metrics.StreamSubscribers.WithLabelValues("websocket").Inc()
+	defer metrics.StreamSubscribers.WithLabelValues("websocket").Dec()
+	slog.DebugContext(ctx, "WebSocket stream opened", "remote_addr", r.RemoteAddr)
+
+	// The reader handles client messages and queues the replies; this
+	// goroutine is the only writer of data messages
+	replies := make(chan wsServerMessage, 16)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.read(conn, subscriptions, replies, stop)
+	}()
+	defer func() {
+		close(stop)
+		conn.Close()
+		<-done
+	}()
+
+	ping := time.NewTicker(h.heartbeat)
+	defer ping.Stop()
+	for {
+		select {
+		case <-done:
+			return
+
+		case reply := <-replies:
+			if err := writeWebSocketMessage(conn, reply); err != nil {
+				return
+			}
+
+		case event, ok := <-subscription.Events():
+			if !ok {
+				err := subscription.Err()
+				slog.WarnContext(ctx, "WebSocket stream ended", "remote_addr", r.RemoteAddr, "error", err)
+				code, reason := websocket.CloseGoingAway, "server shutting down"
+				if err == stream.ErrLagged {
+					code, reason = websocket.CloseTryAgainLater, "client fell behind the change feed"
+				}
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteTimeout))
+				return
+			}
+			// Subscriptions removed since the change was published no longer
+			// receive it
+			ids := subscriptions.matching(event)
+			if len(ids) == 0 {
+				continue
+			}
+			if err := writeWebSocketMessage(conn, wsServerMessage{
+				Type:          string(models.EventDelegationChanged),
+				Subscriptions: ids,
+				Event:         &event,
+			}); err != nil {
+				return
+			}
+
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// read handles client messages until the connection fails or stop is closed.
+// Clients that answer no ping within two heartbeat intervals are disconnected.
+func (h *WebSocketHandler) read(conn *websocket.Conn, subscriptions *wsSubscriptions, replies chan<- wsServerMessage, stop <-chan struct{}) {
+	timeout := 2 * h.heartbeat
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(timeout))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(timeout))
+
+		var reply wsServerMessage
+		var message wsClientMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			reply = wsServerMessage{Type: wsMessageError, Message: "Invalid message", Errors: []string{err.Error()}}
+		} else {
+			reply = h.handleMessage(subscriptions, message)
+		}
+
+		select {
+		case replies <- reply:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// handleMessage applies a subscribe or unsubscribe message and returns the
+// reply to send
+func (h *WebSocketHandler) handleMessage(subscriptions *wsSubscriptions, message wsClientMessage) wsServerMessage {
+	id := strings.TrimSpace(message.Subscription)
+
+	switch message.Type {
+	case wsMessageSubscribe:
+		filter, validationErrors := h.parseSubscription(id, message)
+		if len(validationErrors) > 0 {
+			return wsServerMessage{Type: wsMessageError, Subscription: id, Message: "Invalid subscription", Errors: validationErrors}
+		}
+
+		subscriptions.mu.Lock()
+		defer subscriptions.mu.Unlock()
+		if _, exists := subscriptions.filters[id]; !exists && len(subscriptions.filters) >= MaxWebSocketSubscriptions {
+			return wsServerMessage{Type: wsMessageError, Subscription: id, Message: "Invalid subscription",
+				Errors: []string{fmt.Sprintf("A connection can have at most %d subscriptions", MaxWebSocketSubscriptions)}}
+		}
+		// Subscribing with an existing ID replaces its filter
+		subscriptions.filters[id] = filter
+		return wsServerMessage{Type: wsMessageSubscribed, Subscription: id}
+
+	case wsMessageUnsubscribe:
+		subscriptions.mu.Lock()
+		defer subscriptions.mu.Unlock()
+		if _, exists := subscriptions.filters[id]; !exists {
+			return wsServerMessage{Type: wsMessageError, Subscription: id, Message: "Subscription not found"}
+		}
+		delete(subscriptions.filters, id)
+		return wsServerMessage{Type: wsMessageUnsubscribed, Subscription: id}
+
+	default:
+		return wsServerMessage{Type: wsMessageError, Subscription: id, Message: "Invalid message",
+			Errors: []string{fmt.Sprintf("Message type %q must be %q or %q", message.Type, wsMessageSubscribe, wsMessageUnsubscribe)}}
+	}
+}
+
+// parseSubscription validates a subscribe message and returns its filter
+func (h *WebSocketHandler) parseSubscription(id string, message wsClientMessage) (stream.Filter, []string) {
+	var validationErrors []string
+	if id == "" {
+		validationErrors = append(validationErrors, "Subscription ID is required")
+	} else if len(id) > maxSubscriptionIDLength {
+		validationErrors = append(validationErrors, fmt.Sprintf("Subscription ID must be at most %d characters", maxSubscriptionIDLength))
+	}
+
+	if len(message.Validators) > MaxWebSocketSubscriptionAddresses {
+		validationErrors = append(validationErrors, fmt.Sprintf("A subscription can have at most %d validators", MaxWebSocketSubscriptionAddresses))
+	} else if h.addresses != nil {
+		for _, validatorAddress := range message.Validators {
+			if err := h.addresses.ValidateValidatorAddress(validatorAddress); err != nil {
+				validationErrors = append(validationErrors, err.Error())
+			}
+		}
+	}
+	if len(message.Delegators) > MaxWebSocketSubscriptionAddresses {
+		validationErrors = append(validationErrors, fmt.Sprintf("A subscription can have at most %d delegators", MaxWebSocketSubscriptionAddresses))
+	} else if h.addresses != nil {
+		for _, delegatorAddress := range message.Delegators {
+			if err := h.addresses.ValidateAccountAddress(delegatorAddress); err != nil {
+				validationErrors = append(validationErrors, err.Error())
+			}
+		}
+	}
+
+	var minDelta *big.Rat
+	if message.MinDelta != "" {
+		var ok bool
+		minDelta, ok = new(big.Rat).SetString(message.MinDelta)
+		if !ok || minDelta.Sign() < 0 {
+			validationErrors = append(validationErrors, fmt.Sprintf("Minimum delta %q must be a non-negative number", message.MinDelta))
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return stream.Filter{}, validationErrors
+	}
+	return stream.NewFilter(message.Validators, message.Delegators, minDelta), nil
+}
+
+// writeWebSocketMessage writes a message as JSON within the write timeout
+func writeWebSocketMessage(conn *websocket.Conn, message wsServerMessage) error {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return conn.WriteJSON(message)
+}
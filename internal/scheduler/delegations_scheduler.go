@@ -3,9 +3,10 @@ package scheduler
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
+	"github.com/novintriantonius/cosmos-validator-service/internal/config"
 	"github.com/novintriantonius/cosmos-validator-service/internal/handlers"
 	"github.com/novintriantonius/cosmos-validator-service/internal/services"
 	"github.com/novintriantonius/cosmos-validator-service/internal/store"
@@ -13,45 +14,51 @@ import (
 	"github.com/robfig/cron/v3"
 )
 
-// RegisterDelegationTasks registers all delegation-related tasks with the scheduler
+// RegisterDelegationTasks registers all delegation-related tasks with the
+// scheduler. Failed syncs are not published when relay is nil.
 func RegisterDelegationTasks(
 	sched *handlers.Scheduler,
+	cfg config.SchedulerConfig,
 	validatorStore store.ValidatorStore,
 	delegationStore store.DelegationStore,
 	cosmosService *services.CosmosService,
+	relay tasks.EventRelay,
 ) {
 	// Initialize the delegation sync task
 	delegationSyncTask := tasks.NewDelegationSyncTask(
 		validatorStore,
 		delegationStore,
 		cosmosService,
+		relay,
 	)
 	
-	// Schedule delegation sync task to run hourly at the start of each hour (minute 0)
+	// Schedule delegation sync task, hourly at the start of each hour by default
 	// Cron format: second minute hour day month weekday
 	sched.AddCustomScheduleTask(
 		"hourly-validator-delegations-sync",
-		"0 * * * * *", // Run at minute 0 of every hour
+		cfg.DelegationSyncSchedule,
 		func(ctx context.Context) error {
 			return delegationSyncTask.SyncEnabledValidatorDelegations(ctx)
 		},
 	)
 	
-	log.Println("Scheduled hourly delegation sync task to run at the start of every hour")
+	if !cfg.InitialSyncEnabled {
+		return
+	}
 	
 	// Run the task once immediately on startup to populate initial data
 	go func() {
 		// Wait a few seconds to allow the server to start properly
-		time.Sleep(5 * time.Second)
+		time.Sleep(cfg.InitialSyncDelay.Std())
 		
-		log.Println("Running initial delegation sync...")
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		slog.Info("Running initial delegation sync")
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.InitialSyncTimeout.Std())
 		defer cancel()
 		
 		if err := delegationSyncTask.SyncEnabledValidatorDelegations(ctx); err != nil {
-			log.Printf("Initial delegation sync completed with errors: %v", err)
+			slog.Error("Initial delegation sync completed with errors", "error", err)
 		} else {
-			log.Println("Initial delegation sync completed successfully")
+			slog.Info("Initial delegation sync completed successfully")
 		}
 	}()
 }
@@ -62,11 +69,11 @@ func ScheduleDelegationSync(delegationSyncTask *tasks.DelegationSyncTask) error
 
 	// Schedule task to run at the start of every hour
 	_, err := c.AddFunc("0 * * * *", func() {
-		log.Printf("Running hourly delegation sync...")
+		slog.Info("Running hourly delegation sync")
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 		defer cancel()
 		if err := delegationSyncTask.SyncEnabledValidatorDelegations(ctx); err != nil {
-			log.Printf("Error syncing delegations: %v", err)
+			slog.Error("Error syncing delegations", "error", err)
 		}
 	})
 	if err != nil {
@@ -74,16 +81,16 @@ func ScheduleDelegationSync(delegationSyncTask *tasks.DelegationSyncTask) error
 	}
 
 	// Also run immediately on startup
-	log.Printf("Running initial delegation sync...")
+	slog.Info("Running initial delegation sync")
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 	if err := delegationSyncTask.SyncEnabledValidatorDelegations(ctx); err != nil {
-		log.Printf("Error running initial delegation sync: %v", err)
+		slog.Error("Error running initial delegation sync", "error", err)
 	}
 
 	// Start the scheduler in a goroutine
 	go c.Start()
 
-	log.Printf("Scheduled hourly delegation sync task to run at the start of every hour")
+	slog.Info("Scheduled hourly delegation sync task to run at the start of every hour")
 	return nil
 } 
\ No newline at end of file
@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/config"
+	"github.com/novintriantonius/cosmos-validator-service/internal/handlers"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tasks"
+)
+
+// RegisterEmailDigestTasks schedules the daily and weekly email digests
+func RegisterEmailDigestTasks(sched *handlers.Scheduler, cfg config.SchedulerConfig, digestTask *tasks.EmailDigestTask) {
+	sched.AddCustomScheduleTask(
+		"daily-email-digests",
+		cfg.DailyDigestSchedule,
+		func(ctx context.Context) error {
+			return digestTask.SendDigests(ctx, models.DigestDaily)
+		},
+	)
+
+	sched.AddCustomScheduleTask(
+		"weekly-email-digests",
+		cfg.WeeklyDigestSchedule,
+		func(ctx context.Context) error {
+			return digestTask.SendDigests(ctx, models.DigestWeekly)
+		},
+	)
+}
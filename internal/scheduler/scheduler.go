@@ -1,22 +1,31 @@
 package scheduler
 
 import (
+	"github.com/novintriantonius/cosmos-validator-service/internal/config"
 	"github.com/novintriantonius/cosmos-validator-service/internal/handlers"
 	"github.com/novintriantonius/cosmos-validator-service/internal/services"
 	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tasks"
 )
 
-// SetupScheduler initializes and configures all scheduled tasks
+// SetupScheduler initializes and configures all scheduled tasks. Email digests
+// are not scheduled when digestTask is nil.
 func SetupScheduler(
+	cfg config.SchedulerConfig,
 	validatorStore store.ValidatorStore,
 	delegationStore store.DelegationStore,
 	cosmosService *services.CosmosService,
+	relay tasks.EventRelay,
+	digestTask *tasks.EmailDigestTask,
 ) *handlers.Scheduler {
 	// Initialize scheduler
-	sched := handlers.NewScheduler()
+	sched := handlers.NewSchedulerWithTimeout(cfg.TaskTimeout.Std())
 	
 	// Register all tasks
-	RegisterDelegationTasks(sched, validatorStore, delegationStore, cosmosService)
+	RegisterDelegationTasks(sched, cfg, validatorStore, delegationStore, cosmosService, relay)
+	if digestTask != nil {
+		RegisterEmailDigestTasks(sched, cfg, digestTask)
+	}
 	
 	return sched
 }
\ No newline at end of file
@@ -3,13 +3,18 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
 	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -24,8 +29,29 @@ const (
 	
 	// DefaultTimeout is the default timeout for API calls in seconds
 	DefaultTimeout = 10
+
+	// validatorsPageSize is the number of validators requested per page when listing validators
+	validatorsPageSize = 200
+
+	// delegationsPageSize is the number of delegations requested per page when retrieving delegations
+	delegationsPageSize = 500
+
+	// maxErrorBodyLog is the maximum number of bytes of an error response body that are logged
+	maxErrorBodyLog = 1024
 )
 
+// ErrValidatorNotFoundOnChain is returned when the chain does not know a validator address
+var ErrValidatorNotFoundOnChain = errors.New("validator not found on chain")
+
+// statusError is returned for responses with an unexpected status code
+type statusError struct {
+	StatusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
 // CosmosServiceConfig holds configuration for the Cosmos service
 type CosmosServiceConfig struct {
 	BaseURL    string
@@ -92,51 +118,214 @@ func (s *CosmosService) GetConfig() CosmosServiceConfig {
 	return s.config
 }
 
-// RetrieveDelegations retrieves delegations for a validator
+// RetrieveDelegations retrieves all delegations for a validator, following
+// pagination. The returned response holds every page and has no next key, so
+// delegators missing from it no longer delegate.
 func (s *CosmosService) RetrieveDelegations(ctx context.Context, validatorAddress string) (*models.DelegationsResponse, error) {
-	log.Printf("[DEBUG] Starting RetrieveDelegations for validator %s", validatorAddress)
-	
-	// Build the URL
-	url := fmt.Sprintf("%s/cosmos/staking/v1beta1/validators/%s/delegations", s.config.BaseURL, validatorAddress)
-	log.Printf("[DEBUG] Making request to URL: %s", url)
+	if validatorAddress == "" {
+		return nil, fmt.Errorf("validator address is required")
+	}
+
+	var delegationsResp models.DelegationsResponse
+	nextKey := ""
+	for {
+		query := url.Values{}
+		query.Set("pagination.limit", fmt.Sprint(delegationsPageSize))
+		if nextKey != "" {
+			query.Set("pagination.key", nextKey)
+		}
+		pageURL := fmt.Sprintf("%s/cosmos/staking/v1beta1/validators/%s/delegations?%s", s.config.BaseURL, validatorAddress, query.Encode())
+
+		var page models.DelegationsResponse
+		if err := s.getJSON(ctx, "delegations", pageURL, &page); err != nil {
+			slog.ErrorContext(ctx, "Failed to retrieve delegations", "validator", validatorAddress, "error", err)
+			return nil, err
+		}
+		delegationsResp.DelegationResponses = append(delegationsResp.DelegationResponses, page.DelegationResponses...)
+		if nextKey == "" {
+			// Only the first page carries the total
+			delegationsResp.Pagination.Total = page.Pagination.Total
+		}
+
+		// A page may be empty and still have a next key, so only the next key
+		// ends the listing
+		if page.Pagination.NextKey == "" {
+			break
+		}
+		if page.Pagination.NextKey == nextKey {
+			return nil, fmt.Errorf("error retrieving delegations: next key %q repeated", nextKey)
+		}
+		nextKey = page.Pagination.NextKey
+	}
+
+	slog.DebugContext(ctx, "Retrieved validator delegations",
+		"validator", validatorAddress,
+		"delegations", len(delegationsResp.DelegationResponses),
+	)
+	return &delegationsResp, nil
+}
+
+// GetValidator retrieves the on-chain details of a validator. It returns
+// ErrValidatorNotFoundOnChain when the chain does not know the address.
+func (s *CosmosService) GetValidator(ctx context.Context, validatorAddress string) (*models.ChainValidator, error) {
+	if validatorAddress == "" {
+		return nil, fmt.Errorf("validator address is required")
+	}
+
+	url := fmt.Sprintf("%s/cosmos/staking/v1beta1/validators/%s", s.config.BaseURL, validatorAddress)
+
+	var validatorResp models.ChainValidatorResponse
+	if err := s.getJSON(ctx, "validator", url, &validatorResp); err != nil {
+		var statusErr *statusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return nil, ErrValidatorNotFoundOnChain
+		}
+		slog.ErrorContext(ctx, "Failed to retrieve validator", "validator", validatorAddress, "error", err)
+		return nil, err
+	}
+
+	slog.DebugContext(ctx, "Retrieved validator", "validator", validatorAddress, "moniker", validatorResp.Validator.Description.Moniker)
+	return &validatorResp.Validator, nil
+}
+
+// ListValidators retrieves all validators with the given bond status, such as
+// models.BondStatusBonded, following pagination. An empty status lists
+// validators of every status.
+func (s *CosmosService) ListValidators(ctx context.Context, status string) ([]models.ChainValidator, error) {
+	var validators []models.ChainValidator
+	nextKey := ""
+	for {
+		query := url.Values{}
+		query.Set("pagination.limit", fmt.Sprint(validatorsPageSize))
+		if status != "" {
+			query.Set("status", status)
+		}
+		if nextKey != "" {
+			query.Set("pagination.key", nextKey)
+		}
+		pageURL := fmt.Sprintf("%s/cosmos/staking/v1beta1/validators?%s", s.config.BaseURL, query.Encode())
+
+		var page models.ChainValidatorsResponse
+		if err := s.getJSON(ctx, "validators", pageURL, &page); err != nil {
+			slog.ErrorContext(ctx, "Failed to list validators", "status", status, "error", err)
+			return nil, err
+		}
+		validators = append(validators, page.Validators...)
+
+		if page.Pagination.NextKey == "" {
+			break
+		}
+		if page.Pagination.NextKey == nextKey {
+			return nil, fmt.Errorf("error listing validators: next key %q repeated", nextKey)
+		}
+		nextKey = page.Pagination.NextKey
+	}
+
+	slog.DebugContext(ctx, "Listed validators", "status", status, "validators", len(validators))
+	return validators, nil
+}
+
+// Ping checks that the Cosmos API is reachable with a single request
+func (s *CosmosService) Ping(ctx context.Context) error {
+	url := fmt.Sprintf("%s/cosmos/base/tendermint/v1beta1/syncing", s.config.BaseURL)
+
+	var syncingResp struct {
+		Syncing bool `json:"syncing"`
+	}
+	_, err := s.doGetJSON(ctx, url, &syncingResp)
+	return err
+}
+
+// getJSON performs a GET request against the Cosmos API and decodes the JSON
+// response into out. Network errors, 429 and 5xx responses are retried with
+// exponential backoff. The endpoint name labels the request metrics.
+func (s *CosmosService) getJSON(ctx context.Context, endpoint, url string, out interface{}) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "cosmos."+endpoint,
+		attribute.String("cosmos.endpoint", endpoint),
+		attribute.String("http.url", url),
+	)
+	start := time.Now()
+	defer func() {
+		metrics.CosmosRequestDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+		tracing.EndSpan(span, err)
+	}()
+
+	var lastErr error
+	for attempt := 0; attempt <= s.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			delay := s.config.RetryDelay * time.Duration(1<<(attempt-1))
+			slog.DebugContext(ctx, "Retrying Cosmos API request",
+				"endpoint", endpoint, "attempt", attempt, "delay", delay, "error", lastErr)
+			metrics.CosmosRequestRetries.WithLabelValues(endpoint).Inc()
+
+			select {
+			case <-ctx.Done():
+				metrics.CosmosRequestErrors.WithLabelValues(endpoint).Inc()
+				return fmt.Errorf("request cancelled after %d attempts: %v", attempt, lastErr)
+			case <-time.After(delay):
+			}
+		}
+
+		span.SetAttributes(attribute.Int("cosmos.attempts", attempt+1))
+		retryable, err := s.doGetJSON(ctx, url, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable || ctx.Err() != nil {
+			break
+		}
+	}
+
+	metrics.CosmosRequestErrors.WithLabelValues(endpoint).Inc()
+	return lastErr
+}
+
+// doGetJSON performs a single GET request and reports whether a failure is retryable
+func (s *CosmosService) doGetJSON(ctx context.Context, url string, out interface{}) (retryable bool, err error) {
+	slog.DebugContext(ctx, "Sending Cosmos API request", "url", url)
+
+	ctx, span := tracing.StartSpan(ctx, "cosmos.http_request", attribute.String("http.method", "GET"))
+	defer func() { tracing.EndSpan(span, err) }()
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		log.Printf("[ERROR] Failed to create request: %v", err)
-		return nil, fmt.Errorf("error creating request: %v", err)
+		return false, fmt.Errorf("error creating request: %v", err)
 	}
 
 	// Send request
 	resp, err := s.client.Do(req)
 	if err != nil {
-		log.Printf("[ERROR] Failed to send request: %v", err)
-		return nil, fmt.Errorf("error sending request: %v", err)
+		return true, fmt.Errorf("error sending request: %v", err)
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf("[ERROR] Unexpected status code: %d, body: %s", resp.StatusCode, string(body))
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLog))
+		slog.DebugContext(ctx, "Unexpected Cosmos API status code",
+			"url", url, "status", resp.StatusCode, "body", string(body))
+		retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		return retryable, &statusError{StatusCode: resp.StatusCode}
 	}
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("[ERROR] Failed to read response body: %v", err)
-		return nil, fmt.Errorf("error reading response body: %v", err)
+		return true, fmt.Errorf("error reading response body: %v", err)
 	}
-	log.Printf("[DEBUG] Received response body: %s", string(body))
+
+	span.SetAttributes(attribute.Int("http.response_content_length", len(body)))
 
 	// Parse response
-	var delegationsResp models.DelegationsResponse
-	if err := json.Unmarshal(body, &delegationsResp); err != nil {
-		log.Printf("[ERROR] Failed to parse response: %v", err)
-		return nil, fmt.Errorf("error parsing response: %v", err)
+	_, parseSpan := tracing.StartSpan(ctx, "cosmos.parse_json")
+	err = json.Unmarshal(body, out)
+	tracing.EndSpan(parseSpan, err)
+	if err != nil {
+		return false, fmt.Errorf("error parsing response: %v", err)
 	}
 
-	log.Printf("[DEBUG] Successfully parsed %d delegations", len(delegationsResp.DelegationResponses))
-	return &delegationsResp, nil
-} 
\ No newline at end of file
+	return false, nil
+}
@@ -0,0 +1,414 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+// ErrAlertRuleNotFound is returned when an alert rule is not found
+var ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+const (
+	// DefaultAlertLimit is the number of alerts returned when no limit is given
+	DefaultAlertLimit = 100
+
+	// MaxAlertLimit is the largest number of alerts returned at once
+	MaxAlertLimit = 1000
+)
+
+// AlertStore defines the interface for alert rule and alert storage operations
+type AlertStore interface {
+	// GetRules returns all alert rules
+	GetRules(ctx context.Context) ([]models.AlertRule, error)
+
+	// GetRule returns the alert rule with the given ID
+	GetRule(ctx context.Context, id int) (*models.AlertRule, error)
+
+	// AddRule stores a new alert rule
+	AddRule(ctx context.Context, rule models.AlertRule) (*models.AlertRule, error)
+
+	// UpdateRule replaces the alert rule with the ID of rule
+	UpdateRule(ctx context.Context, rule models.AlertRule) (*models.AlertRule, error)
+
+	// DeleteRule removes an alert rule. Its alerts are kept.
+	DeleteRule(ctx context.Context, id int) error
+
+	// GetActiveRules returns the enabled rules that apply to a validator:
+	// its own rules and the global ones
+	GetActiveRules(ctx context.Context, validatorAddress string) ([]models.AlertRule, error)
+
+	// RecordAlerts stores alerts and returns those that were new. An alert
+	// for a rule and delegation event that already fired is skipped. The
+	// alert.fired events of the new alerts are added to the event outbox.
+	RecordAlerts(ctx context.Context, alerts []models.Alert) ([]models.Alert, error)
+
+	// ListAlerts returns the alerts matching the filter, newest first
+	ListAlerts(ctx context.Context, filter AlertFilter) ([]models.Alert, error)
+}
+
+// AlertFilter narrows down the alerts returned by AlertStore.ListAlerts.
+// Empty fields are ignored.
+type AlertFilter struct {
+	ValidatorAddress string
+	RuleID           int
+	Since            *time.Time
+	Until            *time.Time
+	Limit            int
+	Offset           int
+}
+
+// AlertStoreImpl implements AlertStore with PostgreSQL storage
+type AlertStoreImpl struct {
+	db *sql.DB
+	mu sync.RWMutex
+}
+
+// NewAlertStore creates a new instance of AlertStoreImpl
+func NewAlertStore(db *sql.DB) *AlertStoreImpl {
+	return &AlertStoreImpl{
+		db: db,
+	}
+}
+
+// alertRuleColumns lists the columns scanned by scanAlertRule
+const alertRuleColumns = `id, name, validator_address, min_delta::TEXT, min_stake_percent::TEXT, enabled, created_at, updated_at`
+
+// GetRules returns all alert rules
+func (s *AlertStoreImpl) GetRules(ctx context.Context) ([]models.AlertRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetRules", "alert_rules")
+	defer span.End()
+
+	return s.queryRules(ctx, `SELECT `+alertRuleColumns+` FROM alert_rules ORDER BY id`)
+}
+
+// GetRule returns the alert rule with the given ID
+func (s *AlertStoreImpl) GetRule(ctx context.Context, id int) (*models.AlertRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetRule", "alert_rules")
+	defer span.End()
+
+	rule, err := scanAlertRule(s.db.QueryRowContext(ctx, `SELECT `+alertRuleColumns+` FROM alert_rules WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrAlertRuleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying alert rule: %v", err)
+	}
+	return rule, nil
+}
+
+// AddRule stores a new alert rule. It returns ErrValidatorNotFound when the
+// rule is limited to a validator that does not exist.
+func (s *AlertStoreImpl) AddRule(ctx context.Context, rule models.AlertRule) (*models.AlertRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "AddRule", "alert_rules")
+	defer span.End()
+
+	if err := s.checkRuleValidator(ctx, rule.ValidatorAddress); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO alert_rules (name, validator_address, min_delta, min_stake_percent, enabled)
+		VALUES ($1, $2, CAST($3 AS NUMERIC), CAST($4 AS NUMERIC), $5)
+		RETURNING ` + alertRuleColumns
+	created, err := scanAlertRule(s.db.QueryRowContext(ctx, query, rule.Name, nullString(rule.ValidatorAddress),
+		nullString(rule.MinDelta), nullString(rule.MinStakePercent), rule.Enabled))
+	if err != nil {
+		return nil, fmt.Errorf("error inserting alert rule: %v", err)
+	}
+	return created, nil
+}
+
+// UpdateRule replaces the alert rule with the ID of rule. It returns
+// ErrValidatorNotFound when the rule is limited to a validator that does not
+// exist.
+func (s *AlertStoreImpl) UpdateRule(ctx context.Context, rule models.AlertRule) (*models.AlertRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "UpdateRule", "alert_rules")
+	defer span.End()
+
+	if err := s.checkRuleValidator(ctx, rule.ValidatorAddress); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE alert_rules
+		SET name = $1, validator_address = $2, min_delta = CAST($3 AS NUMERIC), min_stake_percent = CAST($4 AS NUMERIC),
+			enabled = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6
+		RETURNING ` + alertRuleColumns
+	updated, err := scanAlertRule(s.db.QueryRowContext(ctx, query, rule.Name, nullString(rule.ValidatorAddress),
+		nullString(rule.MinDelta), nullString(rule.MinStakePercent), rule.Enabled, rule.ID))
+	if err == sql.ErrNoRows {
+		return nil, ErrAlertRuleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error updating alert rule: %v", err)
+	}
+	return updated, nil
+}
+
+// DeleteRule removes an alert rule. Its alerts are kept without a rule ID.
+func (s *AlertStoreImpl) DeleteRule(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "DeleteRule", "alert_rules")
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM alert_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting alert rule: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAlertRuleNotFound
+	}
+
+	return nil
+}
+
+// GetActiveRules returns the enabled rules that apply to a validator: its own
+// rules and the global ones
+func (s *AlertStoreImpl) GetActiveRules(ctx context.Context, validatorAddress string) ([]models.AlertRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetActiveRules", "alert_rules")
+	defer span.End()
+
+	query := `
+		SELECT ` + alertRuleColumns + `
+		FROM alert_rules
+		WHERE enabled AND (validator_address IS NULL OR validator_address = $1)
+		ORDER BY id
+	`
+	return s.queryRules(ctx, query, validatorAddress)
+}
+
+// RecordAlerts stores alerts in a single transaction and returns those that
+// were new, with their ID and creation time. The unique rule and delegation
+// event pair deduplicates alerts that already fired. The alert.fired events of
+// the new alerts are added to the event outbox in the same transaction.
+func (s *AlertStoreImpl) RecordAlerts(ctx context.Context, alerts []models.Alert) ([]models.Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "RecordAlerts", "alerts")
+	defer span.End()
+
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	// source_rule_id keeps the rule of an alert once rule_id is cleared by
+	// the deletion of the rule, so that alerts stay unique per rule and event
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO alerts (rule_id, source_rule_id, rule_name, delegation_event_id, validator_address, delegator_address, event_type, delta, stake_percent)
+		VALUES ($1, $1, $2, $3, $4, $5, $6, CAST($7 AS NUMERIC), CAST($8 AS NUMERIC))
+		ON CONFLICT (source_rule_id, delegation_event_id) DO NOTHING
+		RETURNING id, created_at
+	`)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error preparing statement: %v", err)
+	}
+	defer stmt.Close()
+
+	var recorded []models.Alert
+	for _, alert := range alerts {
+		err := stmt.QueryRowContext(ctx, alert.RuleID, alert.RuleName, alert.DelegationEventID, alert.ValidatorAddress,
+			alert.DelegatorAddress, string(alert.EventType), alert.Delta, nullString(alert.StakePercent)).
+			Scan(&alert.ID, &alert.CreatedAt)
+		if err == sql.ErrNoRows {
+			// Already fired
+			continue
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error inserting alert: %v", err)
+		}
+		recorded = append(recorded, alert)
+	}
+
+	// Queue the new alerts for the event consumers in the same transaction
+	fired := make([]models.Event, 0, len(recorded))
+	for _, alert := range recorded {
+		event, err := events.ForAlert(alert)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		fired = append(fired, event)
+	}
+	if _, err := enqueueEvents(ctx, tx, fired); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return recorded, nil
+}
+
+// ListAlerts returns the alerts matching the filter, newest first
+func (s *AlertStoreImpl) ListAlerts(ctx context.Context, filter AlertFilter) ([]models.Alert, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "ListAlerts", "alerts")
+	defer span.End()
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ValidatorAddress != "" {
+		addCondition("validator_address = $%d", filter.ValidatorAddress)
+	}
+	if filter.RuleID > 0 {
+		addCondition("rule_id = $%d", filter.RuleID)
+	}
+	if filter.Since != nil {
+		addCondition("created_at >= $%d", *filter.Since)
+	}
+	if filter.Until != nil {
+		addCondition("created_at < $%d", *filter.Until)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultAlertLimit
+	}
+	if limit > MaxAlertLimit {
+		limit = MaxAlertLimit
+	}
+
+	query := `
+		SELECT id, rule_id, rule_name, delegation_event_id, validator_address, delegator_address, event_type,
+			delta::TEXT, COALESCE(stake_percent::TEXT, ''), created_at
+		FROM alerts`
+	if len(conditions) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit, filter.Offset)
+	query += fmt.Sprintf("\n\t\tORDER BY created_at DESC, id DESC\n\t\tLIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying alerts: %v", err)
+	}
+	defer rows.Close()
+
+	alerts := []models.Alert{}
+	for rows.Next() {
+		var alert models.Alert
+		var ruleID sql.NullInt64
+		if err := rows.Scan(&alert.ID, &ruleID, &alert.RuleName, &alert.DelegationEventID, &alert.ValidatorAddress,
+			&alert.DelegatorAddress, &alert.EventType, &alert.Delta, &alert.StakePercent, &alert.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning alert row: %v", err)
+		}
+		if ruleID.Valid {
+			id := int(ruleID.Int64)
+			alert.RuleID = &id
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert rows: %v", err)
+	}
+
+	return alerts, nil
+}
+
+// queryRules runs a query selecting alertRuleColumns
+func (s *AlertStoreImpl) queryRules(ctx context.Context, query string, args ...interface{}) ([]models.AlertRule, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying alert rules: %v", err)
+	}
+	defer rows.Close()
+
+	rules := []models.AlertRule{}
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning alert rule row: %v", err)
+		}
+		rules = append(rules, *rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert rule rows: %v", err)
+	}
+
+	return rules, nil
+}
+
+// checkRuleValidator returns ErrValidatorNotFound when a rule is limited to a
+// validator that does not exist
+func (s *AlertStoreImpl) checkRuleValidator(ctx context.Context, validatorAddress string) error {
+	if validatorAddress == "" {
+		return nil
+	}
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM validators WHERE address = $1)`, validatorAddress).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("error checking validator existence: %v", err)
+	}
+	if !exists {
+		return ErrValidatorNotFound
+	}
+	return nil
+}
+
+// scanAlertRule scans a row selected with alertRuleColumns
+func scanAlertRule(row rowScanner) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	var validatorAddress, minDelta, minStakePercent sql.NullString
+	if err := row.Scan(&rule.ID, &rule.Name, &validatorAddress, &minDelta, &minStakePercent,
+		&rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
+		return nil, err
+	}
+	rule.ValidatorAddress = validatorAddress.String
+	rule.MinDelta = minDelta.String
+	rule.MinStakePercent = minStakePercent.String
+	return &rule, nil
+}
+
+// nullString stores an empty string as NULL
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,208 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+// ErrAPIKeyNotFound is returned when an API key is not found or has been revoked
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyStore defines the interface for API key storage operations
+type APIKeyStore interface {
+	// Add stores a new API key with the hash of its secret
+	Add(ctx context.Context, key models.APIKey, keyHash string) (*models.APIKey, error)
+
+	// GetByHash returns the active (not revoked) API key with the given secret hash
+	GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+
+	// GetAll returns all API keys, including revoked ones
+	GetAll(ctx context.Context) ([]models.APIKey, error)
+
+	// Rotate replaces the secret of an active API key
+	Rotate(ctx context.Context, id int, prefix, keyHash string) (*models.APIKey, error)
+
+	// Revoke disables an active API key
+	Revoke(ctx context.Context, id int) error
+
+	// TouchLastUsed records that an API key was used
+	TouchLastUsed(ctx context.Context, id int) error
+}
+
+// APIKeyStoreImpl implements APIKeyStore with PostgreSQL storage
+type APIKeyStoreImpl struct {
+	db *sql.DB
+	mu sync.RWMutex
+}
+
+// NewAPIKeyStore creates a new instance of APIKeyStoreImpl
+func NewAPIKeyStore(db *sql.DB) *APIKeyStoreImpl {
+	return &APIKeyStoreImpl{
+		db: db,
+	}
+}
+
+// apiKeyColumns lists the columns scanned by scanAPIKey
+const apiKeyColumns = `id, name, key_prefix, role, created_at, rotated_at, last_used_at, revoked_at`
+
+// Add stores a new API key with the hash of its secret
+func (s *APIKeyStoreImpl) Add(ctx context.Context, key models.APIKey, keyHash string) (*models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Add", "api_keys")
+	defer span.End()
+
+	query := `
+		INSERT INTO api_keys (name, key_prefix, key_hash, role)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + apiKeyColumns
+	created, err := scanAPIKey(s.db.QueryRowContext(ctx, query, key.Name, key.Prefix, keyHash, key.Role))
+	if err != nil {
+		return nil, fmt.Errorf("error inserting api key: %v", err)
+	}
+	return created, nil
+}
+
+// GetByHash returns the active (not revoked) API key with the given secret hash
+func (s *APIKeyStoreImpl) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetByHash", "api_keys")
+	defer span.End()
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, query, keyHash))
+	if err == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying api key: %v", err)
+	}
+	return key, nil
+}
+
+// GetAll returns all API keys, including revoked ones
+func (s *APIKeyStoreImpl) GetAll(ctx context.Context) ([]models.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetAll", "api_keys")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying api keys: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning api key row: %v", err)
+		}
+		keys = append(keys, *key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api key rows: %v", err)
+	}
+
+	return keys, nil
+}
+
+// Rotate replaces the secret of an active API key
+func (s *APIKeyStoreImpl) Rotate(ctx context.Context, id int, prefix, keyHash string) (*models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Rotate", "api_keys")
+	defer span.End()
+
+	query := `
+		UPDATE api_keys
+		SET key_prefix = $1, key_hash = $2, rotated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND revoked_at IS NULL
+		RETURNING ` + apiKeyColumns
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, query, prefix, keyHash, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error rotating api key: %v", err)
+	}
+	return key, nil
+}
+
+// Revoke disables an active API key
+func (s *APIKeyStoreImpl) Revoke(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Revoke", "api_keys")
+	defer span.End()
+
+	query := `UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("error revoking api key: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// TouchLastUsed records that an API key was used. To avoid a write on every
+// request the timestamp is only refreshed once per minute.
+func (s *APIKeyStoreImpl) TouchLastUsed(ctx context.Context, id int) error {
+	ctx, span := startSpan(ctx, "TouchLastUsed", "api_keys")
+	defer span.End()
+
+	query := `
+		UPDATE api_keys
+		SET last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')
+	`
+	if _, err := s.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("error updating api key last used time: %v", err)
+	}
+	return nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAPIKey scans a row selected with apiKeyColumns
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var rotatedAt, lastUsedAt, revokedAt sql.NullTime
+	if err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Role, &key.CreatedAt, &rotatedAt, &lastUsedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	if rotatedAt.Valid {
+		key.RotatedAt = &rotatedAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
@@ -0,0 +1,182 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/audit"
+	"github.com/novintriantonius/cosmos-validator-service/internal/logging"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+const (
+	// DefaultAuditLimit is the number of audit events returned when no limit is given
+	DefaultAuditLimit = 100
+
+	// MaxAuditLimit is the largest number of audit events returned at once
+	MaxAuditLimit = 1000
+)
+
+// AuditFilter narrows down the audit events returned by AuditStore.List.
+// Empty fields are ignored.
+type AuditFilter struct {
+	EntityType string
+	EntityID   string
+	Action     models.AuditAction
+	Actor      string
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+	Offset     int
+}
+
+// AuditStore defines the interface for reading the audit log. Events are
+// written by the stores making the audited change, in the same transaction.
+type AuditStore interface {
+	// List returns audit events matching the filter, newest first
+	List(ctx context.Context, filter AuditFilter) ([]models.AuditEvent, error)
+}
+
+// AuditStoreImpl implements AuditStore with PostgreSQL storage
+type AuditStoreImpl struct {
+	db *sql.DB
+	mu sync.RWMutex
+}
+
+// NewAuditStore creates a new instance of AuditStoreImpl
+func NewAuditStore(db *sql.DB) *AuditStoreImpl {
+	return &AuditStoreImpl{
+		db: db,
+	}
+}
+
+// List returns audit events matching the filter, newest first
+func (s *AuditStoreImpl) List(ctx context.Context, filter AuditFilter) ([]models.AuditEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "List", "audit_events")
+	defer span.End()
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.EntityType != "" {
+		addCondition("entity_type = $%d", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		addCondition("entity_id = $%d", filter.EntityID)
+	}
+	if filter.Action != "" {
+		addCondition("action = $%d", string(filter.Action))
+	}
+	if filter.Actor != "" {
+		addCondition("actor = $%d", filter.Actor)
+	}
+	if filter.Since != nil {
+		addCondition("created_at >= $%d", *filter.Since)
+	}
+	if filter.Until != nil {
+		addCondition("created_at < $%d", *filter.Until)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultAuditLimit
+	}
+	if limit > MaxAuditLimit {
+		limit = MaxAuditLimit
+	}
+
+	query := `
+		SELECT id, entity_type, entity_id, action, actor, actor_key_id, COALESCE(request_id, ''), before_state, after_state, created_at
+		FROM audit_events`
+	if len(conditions) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit, filter.Offset)
+	query += fmt.Sprintf("\n\t\tORDER BY created_at DESC, id DESC\n\t\tLIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying audit events: %v", err)
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		var event models.AuditEvent
+		var actorKeyID sql.NullInt64
+		var before, after []byte
+		if err := rows.Scan(&event.ID, &event.EntityType, &event.EntityID, &event.Action, &event.Actor,
+			&actorKeyID, &event.RequestID, &before, &after, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning audit event row: %v", err)
+		}
+		if actorKeyID.Valid {
+			id := int(actorKeyID.Int64)
+			event.ActorKeyID = &id
+		}
+		event.Before = before
+		event.After = after
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit event rows: %v", err)
+	}
+
+	return events, nil
+}
+
+// recordAuditEvent writes an audit event within tx. The actor and request ID
+// are taken from ctx. A nil before or after state is stored as NULL.
+func recordAuditEvent(ctx context.Context, tx *sql.Tx, entityType, entityID string, action models.AuditAction, before, after interface{}) error {
+	beforeJSON, err := marshalAuditState(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := marshalAuditState(after)
+	if err != nil {
+		return err
+	}
+
+	actor := audit.ActorFromContext(ctx)
+	var actorKeyID sql.NullInt64
+	if actor.KeyID > 0 {
+		actorKeyID = sql.NullInt64{Int64: int64(actor.KeyID), Valid: true}
+	}
+	var requestID sql.NullString
+	if id := logging.RequestIDFromContext(ctx); id != "" {
+		requestID = sql.NullString{String: id, Valid: true}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_events (entity_type, entity_id, action, actor, actor_key_id, request_id, before_state, after_state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, entityType, entityID, string(action), actor.Name, actorKeyID, requestID, beforeJSON, afterJSON)
+	if err != nil {
+		return fmt.Errorf("error recording audit event: %v", err)
+	}
+	return nil
+}
+
+// marshalAuditState encodes an entity state for an audit event
+func marshalAuditState(state interface{}) (interface{}, error) {
+	if state == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding audit state: %v", err)
+	}
+	return string(data), nil
+}
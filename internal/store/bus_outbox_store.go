@@ -0,0 +1,238 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+// busOutboxLockKey is the advisory lock taken while claiming outbox messages,
+// so that one relay at a time claims the head of the outbox
+const busOutboxLockKey int64 = 0x6275736f7574626f
+
+// BusOutboxStore defines the interface for the outbox of messages published
+// to the message bus
+type BusOutboxStore interface {
+	// Enqueue adds messages to the outbox and returns the number added.
+	// Messages of events already in the outbox are not added again.
+	Enqueue(ctx context.Context, messages []models.BusMessage) (int, error)
+
+	// ClaimNext returns up to limit of the oldest unpublished messages, in
+	// order, and locks them for lease. No messages are returned while an
+	// older message is locked, which keeps the messages in order.
+	ClaimNext(ctx context.Context, limit int, lease time.Duration) ([]models.BusMessage, error)
+
+	// MarkPublished records that messages were published
+	MarkPublished(ctx context.Context, ids []int64) error
+
+	// MarkFailed records a failed publish attempt of messages and locks them
+	// until retryAt
+	MarkFailed(ctx context.Context, ids []int64, attemptErr string, retryAt time.Time) error
+
+	// DeletePublished removes the messages published before a time and
+	// returns the number removed
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
+
+// BusOutboxStoreImpl implements BusOutboxStore with PostgreSQL storage
+type BusOutboxStoreImpl struct {
+	db *sql.DB
+	mu sync.RWMutex
+}
+
+// NewBusOutboxStore creates a new instance of BusOutboxStoreImpl
+func NewBusOutboxStore(db *sql.DB) *BusOutboxStoreImpl {
+	return &BusOutboxStoreImpl{
+		db: db,
+	}
+}
+
+// Enqueue adds messages to the outbox in order and returns the number added
+func (s *BusOutboxStoreImpl) Enqueue(ctx context.Context, messages []models.BusMessage) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Enqueue", "bus_outbox")
+	defer span.End()
+
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO bus_outbox (event_id, topic, message_key, payload)
+		VALUES ($1, $2, $3, CAST($4 AS JSONB))
+		ON CONFLICT (event_id) DO NOTHING
+	`)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("error preparing statement: %v", err)
+	}
+	defer stmt.Close()
+
+	queued := 0
+	for _, message := range messages {
+		result, err := stmt.ExecContext(ctx, message.EventID, message.Topic, message.Key, string(message.Payload))
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("error queuing bus message for event %s: %v", message.EventID, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("error getting rows affected: %v", err)
+		}
+		queued += int(rowsAffected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return queued, nil
+}
+
+// ClaimNext returns up to limit of the oldest unpublished messages and locks
+// them for lease. It returns no messages while another relay is claiming or
+// an older message is locked.
+func (s *BusOutboxStoreImpl) ClaimNext(ctx context.Context, limit int, lease time.Duration) ([]models.BusMessage, error) {
+	ctx, span := startSpan(ctx, "ClaimNext", "bus_outbox")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, busOutboxLockKey).Scan(&locked); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error locking bus outbox: %v", err)
+	}
+	if !locked {
+		tx.Rollback()
+		return nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, event_id, topic, message_key, payload::TEXT, attempts, created_at
+		FROM bus_outbox
+		WHERE published_at IS NULL AND id < COALESCE((
+			SELECT MIN(id) FROM bus_outbox
+			WHERE published_at IS NULL AND locked_until > CURRENT_TIMESTAMP
+		), 9223372036854775807)
+		ORDER BY id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error querying unpublished bus messages: %v", err)
+	}
+
+	var messages []models.BusMessage
+	var ids []int64
+	for rows.Next() {
+		var message models.BusMessage
+		var payload string
+		if err := rows.Scan(&message.ID, &message.EventID, &message.Topic, &message.Key, &payload,
+			&message.Attempts, &message.CreatedAt); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, fmt.Errorf("error scanning bus message row: %v", err)
+		}
+		message.Payload = json.RawMessage(payload)
+		messages = append(messages, message)
+		ids = append(ids, message.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error iterating bus message rows: %v", err)
+	}
+
+	if len(ids) > 0 {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE bus_outbox
+			SET locked_until = CURRENT_TIMESTAMP + make_interval(secs => $1)
+			WHERE id = ANY($2)
+		`, lease.Seconds(), pq.Array(ids))
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error locking bus messages: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return messages, nil
+}
+
+// MarkPublished records that messages were published
+func (s *BusOutboxStoreImpl) MarkPublished(ctx context.Context, ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "MarkPublished", "bus_outbox")
+	defer span.End()
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE bus_outbox
+		SET published_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = NULL
+		WHERE id = ANY($1)
+	`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error marking bus messages published: %v", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed publish attempt of messages and locks them
+// until retryAt
+func (s *BusOutboxStoreImpl) MarkFailed(ctx context.Context, ids []int64, attemptErr string, retryAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "MarkFailed", "bus_outbox")
+	defer span.End()
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE bus_outbox
+		SET attempts = attempts + 1, last_error = $1, locked_until = $2
+		WHERE id = ANY($3)
+	`, attemptErr, retryAt, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error marking bus messages failed: %v", err)
+	}
+	return nil
+}
+
+// DeletePublished removes the messages published before a time and returns
+// the number removed
+func (s *BusOutboxStoreImpl) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "DeletePublished", "bus_outbox")
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM bus_outbox WHERE published_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting published bus messages: %v", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error getting rows affected: %v", err)
+	}
+	return deleted, nil
+}
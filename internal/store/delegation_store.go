@@ -1,30 +1,140 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/lib/pq"
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
 	"github.com/novintriantonius/cosmos-validator-service/internal/models"
 )
 
 // DelegationStore defines the interface for delegation storage
 type DelegationStore interface {
 	// SaveDelegations saves delegations for a validator
-	SaveDelegations(validatorAddress string, data models.DelegationsResponse) error
+	SaveDelegations(ctx context.Context, validatorAddress string, data models.DelegationsResponse) (SaveResult, error)
 	
 	// GetDelegations retrieves delegations for a validator
-	GetDelegations(validatorAddress string) ([]models.Delegation, error)
+	GetDelegations(ctx context.Context, validatorAddress string) ([]models.Delegation, error)
 	
 	// GetAllDelegations retrieves all stored delegations
-	GetAllDelegations() (map[string][]models.Delegation, error)
+	GetAllDelegations(ctx context.Context) (map[string][]models.Delegation, error)
 	
 	// GetEnabledValidators gets all validators with enabled tracking
-	GetEnabledValidators() ([]string, error)
+	GetEnabledValidators(ctx context.Context) ([]string, error)
 
 	// DelegationExists checks if a delegation exists for the given validator, delegator, and shares
-	DelegationExists(validatorAddress, delegatorAddress, delegationShares string) (bool, error)
+	DelegationExists(ctx context.Context, validatorAddress, delegatorAddress, delegationShares string) (bool, error)
+
+	// GetStakeSummary sums the current delegations of every validator that is
+	// not archived, limited to the validators carrying tag unless it is empty
+	GetStakeSummary(ctx context.Context, tag string) ([]models.ValidatorStake, error)
+
+	// GetDelegationEvents returns the delegation events of a validator matching the filter, newest first
+	GetDelegationEvents(ctx context.Context, filter DelegationEventFilter) ([]models.DelegationEvent, error)
+
+	// GetDelegationEventsAfter returns up to limit delegation events with an ID
+	// greater than afterID, oldest first, limited to a validator unless
+	// validatorAddress is empty
+	GetDelegationEventsAfter(ctx context.Context, validatorAddress string, afterID int64, limit int) ([]models.DelegationEvent, error)
+
+	// GetDelegationActivity summarizes the delegation events of a validator
+	// created in [since, until), with up to topMovers delegators by net change
+	GetDelegationActivity(ctx context.Context, validatorAddress string, since, until time.Time, topMovers int) (models.DelegationActivity, error)
+
+	// GetCurrentDelegations returns the latest delegation of every delegator
+	// that still delegates, matching the filter. The limit and offset of the
+	// filter apply to each validator, or to each delegator when only
+	// delegators are given.
+	GetCurrentDelegations(ctx context.Context, filter CurrentDelegationFilter) ([]models.Delegation, error)
+
+	// GetCurrentDelegationTotals counts and sums the current delegations
+	// matching the filter per validator, or per delegator when only delegators
+	// are given. Its limit and offset are ignored.
+	GetCurrentDelegationTotals(ctx context.Context, filter CurrentDelegationFilter) (map[string]models.DelegationTotals, error)
+
+	// GetDelegationEventsByValidator returns the delegation events of each of
+	// the validators matching the filter, newest first. The limit and offset
+	// of the filter apply to each validator, and its validator is ignored.
+	GetDelegationEventsByValidator(ctx context.Context, validatorAddresses []string, filter DelegationEventFilter) (map[string][]models.DelegationEvent, error)
+
+	// GetStakeSeries returns the stake changes of the validators per interval
+	// starting in [since, until), oldest first. Intervals without delegation
+	// events are left out.
+	GetStakeSeries(ctx context.Context, validatorAddresses []string, interval StakeInterval, since, until time.Time) ([]models.StakePoint, error)
+}
+
+// CurrentDelegationFilter narrows down the delegations returned by
+// DelegationStore.GetCurrentDelegations. Empty fields are ignored.
+type CurrentDelegationFilter struct {
+	ValidatorAddresses []string
+	DelegatorAddresses []string
+	// Limit and Offset page the delegations of each validator, or of each
+	// delegator when only delegators are given. Every delegation is returned
+	// when Limit is zero.
+	Limit  int
+	Offset int
+}
+
+// partition returns the column the current delegations matching the filter
+// are grouped by, and the order of the delegations of a group: validators
+// list their largest delegations first, delegators their validators by
+// address
+func (f CurrentDelegationFilter) partition() (column, order string) {
+	if len(f.ValidatorAddresses) == 0 && len(f.DelegatorAddresses) > 0 {
+		return "delegator_address", "validator_address"
+	}
+	return "validator_address", "CAST(delegation_shares AS NUMERIC) DESC, delegator_address"
+}
+
+// StakeInterval is the length of the intervals of a stake series
+type StakeInterval string
+
+const (
+	// StakeIntervalHour groups stake changes per hour
+	StakeIntervalHour StakeInterval = "hour"
+	// StakeIntervalDay groups stake changes per day, in UTC
+	StakeIntervalDay StakeInterval = "day"
+)
+
+const (
+	// DefaultDelegationEventLimit is the number of delegation events returned when no limit is given
+	DefaultDelegationEventLimit = 100
+
+	// MaxDelegationEventLimit is the largest number of delegation events returned at once
+	MaxDelegationEventLimit = 1000
+)
+
+// DelegationEventFilter narrows down the delegation events returned by
+// DelegationStore.GetDelegationEvents. Empty fields are ignored.
+type DelegationEventFilter struct {
+	ValidatorAddress string
+	// Types matches events of any of the types
+	Types []models.DelegationEventType
+	// MinDelta matches events whose absolute delta is at least this decimal
+	MinDelta string
+	Since    *time.Time
+	Until    *time.Time
+	Limit    int
+	Offset   int
+}
+
+// SaveResult summarizes the outcome of a SaveDelegations call
+type SaveResult struct {
+	Processed int // Delegations received from the API
+	Inserted  int // Delegations stored because they are new or their shares changed
+	Skipped   int // Delegations skipped because their shares are unchanged
+	Exited    int // Delegators that no longer delegate, stored with zero shares
+
+	// Events are the delegation events recorded for the stored delegations
+	Events []models.DelegationEvent
 }
 
 // DelegationStoreImpl implements the DelegationStore interface with PostgreSQL storage
@@ -40,123 +150,235 @@ func NewDelegationStore(db *sql.DB) *DelegationStoreImpl {
 	}
 }
 
-// SaveDelegations saves delegations for a validator
-func (s *DelegationStoreImpl) SaveDelegations(validatorAddress string, data models.DelegationsResponse) error {
+// SaveDelegations saves delegations for a validator. Every new or changed
+// delegation is recorded with a delegation event holding its delta. When the
+// response is complete, delegators that no longer appear in it are stored
+// with zero shares and an exit event. The delegation.changed events of the
+// delegation events are added to the event outbox in the same transaction.
+func (s *DelegationStoreImpl) SaveDelegations(ctx context.Context, validatorAddress string, data models.DelegationsResponse) (SaveResult, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	log.Printf("[DEBUG] Starting SaveDelegations for validator %s with %d delegations", 
-		validatorAddress, len(data.DelegationResponses))
+	ctx, span := startSpan(ctx, "SaveDelegations", "delegations")
+	defer span.End()
+
+	logger := slog.With("validator", validatorAddress)
+	logger.DebugContext(ctx, "Saving delegations", "delegations", len(data.DelegationResponses))
 
 	// Start a transaction
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Printf("[ERROR] Failed to start transaction: %v", err)
-		return fmt.Errorf("error starting transaction: %v", err)
+		return SaveResult{}, fmt.Errorf("error starting transaction: %v", err)
 	}
-	log.Printf("[DEBUG] Transaction started successfully")
 
-	// Prepare the insert statement
-	stmt, err := tx.Prepare(`
+	// Prepare the insert statements
+	stmt, err := tx.PrepareContext(ctx, `
 		INSERT INTO delegations (validator_address, delegator_address, delegation_shares)
 		VALUES ($1, $2, $3)
 	`)
 	if err != nil {
-		log.Printf("[ERROR] Failed to prepare insert statement: %v", err)
 		tx.Rollback()
-		return fmt.Errorf("error preparing statement: %v", err)
+		return SaveResult{}, fmt.Errorf("error preparing statement: %v", err)
 	}
 	defer stmt.Close()
-	log.Printf("[DEBUG] Insert statement prepared successfully")
+
+	eventStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO delegation_events (validator_address, delegator_address, event_type, previous_shares, shares, delta)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`)
+	if err != nil {
+		tx.Rollback()
+		return SaveResult{}, fmt.Errorf("error preparing statement: %v", err)
+	}
+	defer eventStmt.Close()
 
 	// Get latest delegations for this validator
-	log.Printf("[DEBUG] Querying latest delegations for validator %s", validatorAddress)
 	latestDelegations := make(map[string]string) // delegator_address -> shares
-	rows, err := tx.Query(`
+	rows, err := tx.QueryContext(ctx, `
 		SELECT DISTINCT ON (delegator_address) delegator_address, delegation_shares
 		FROM delegations
 		WHERE validator_address = $1
-		ORDER BY delegator_address, created_at DESC
+		ORDER BY delegator_address, created_at DESC, id DESC
 	`, validatorAddress)
 	if err != nil {
-		log.Printf("[ERROR] Failed to query latest delegations: %v", err)
 		tx.Rollback()
-		return fmt.Errorf("error querying latest delegations: %v", err)
+		return SaveResult{}, fmt.Errorf("error querying latest delegations: %v", err)
 	}
 	defer rows.Close()
 
-	existingCount := 0
 	for rows.Next() {
 		var delegatorAddress, shares string
 		if err := rows.Scan(&delegatorAddress, &shares); err != nil {
-			log.Printf("[ERROR] Failed to scan delegation row: %v", err)
 			tx.Rollback()
-			return fmt.Errorf("error scanning delegation row: %v", err)
+			return SaveResult{}, fmt.Errorf("error scanning delegation row: %v", err)
 		}
 		latestDelegations[delegatorAddress] = shares
-		existingCount++
 	}
-	log.Printf("[DEBUG] Found %d existing delegations for validator %s", existingCount, validatorAddress)
+	logger.DebugContext(ctx, "Loaded latest stored delegations", "existing", len(latestDelegations))
+
+	// save stores the new shares of a delegator and the event describing the change
+	var recorded []models.DelegationEvent
+	save := func(delegatorAddress, newShares string) error {
+		previousShares, existed := latestDelegations[delegatorAddress]
+		if _, err := stmt.ExecContext(ctx, validatorAddress, delegatorAddress, newShares); err != nil {
+			return fmt.Errorf("error inserting delegation for delegator %s: %v", delegatorAddress, err)
+		}
+
+		eventType, previous, delta, err := delegationChange(previousShares, existed, newShares)
+		if err != nil {
+			// The delegation is kept; only its event is missing
+			logger.WarnContext(ctx, "Skipping delegation event", "delegator", delegatorAddress, "error", err)
+			return nil
+		}
+		event := models.DelegationEvent{
+			ValidatorAddress: validatorAddress,
+			DelegatorAddress: delegatorAddress,
+			Type:             eventType,
+			PreviousShares:   previous,
+			Shares:           newShares,
+			Delta:            delta,
+		}
+		err = eventStmt.QueryRowContext(ctx, validatorAddress, delegatorAddress, string(eventType), previous, newShares, delta).
+			Scan(&event.ID, &event.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("error inserting delegation event for delegator %s: %v", delegatorAddress, err)
+		}
+		recorded = append(recorded, event)
+		return nil
+	}
 
 	// Insert each delegation if shares have changed
 	successCount := 0
 	skippedCount := 0
-	for i, resp := range data.DelegationResponses {
+	seen := make(map[string]bool, len(data.DelegationResponses))
+	for _, resp := range data.DelegationResponses {
 		delegatorAddress := resp.Delegation.DelegatorAddress
 		newShares := resp.Delegation.Shares
+		seen[delegatorAddress] = true
 
-		log.Printf("[DEBUG] Processing delegation %d/%d: delegator=%s, shares=%s", 
-			i+1, len(data.DelegationResponses), delegatorAddress, newShares)
-
-		// Check if we have a previous delegation for this delegator
-		if existingShares, exists := latestDelegations[delegatorAddress]; exists {
-			// Skip if shares haven't changed
-			if existingShares == newShares {
-				log.Printf("[DEBUG] Skipping delegation for delegator %s - shares unchanged (existing=%s, new=%s)", 
-					delegatorAddress, existingShares, newShares)
-				skippedCount++
-				continue
-			}
-			log.Printf("[DEBUG] Shares changed for delegator %s: old=%s, new=%s", 
-				delegatorAddress, existingShares, newShares)
-		} else {
-			log.Printf("[DEBUG] New delegator %s with shares %s", delegatorAddress, newShares)
+		// Skip if shares haven't changed since the previous snapshot
+		if existingShares, exists := latestDelegations[delegatorAddress]; exists && existingShares == newShares {
+			skippedCount++
+			continue
 		}
 
-		// Insert new delegation
-		_, err := stmt.Exec(
-			validatorAddress,
-			delegatorAddress,
-			newShares,
-		)
-		if err != nil {
-			log.Printf("[ERROR] Failed to insert delegation %d for delegator %s: %v", 
-				i, delegatorAddress, err)
+		if err := save(delegatorAddress, newShares); err != nil {
 			tx.Rollback()
-			return fmt.Errorf("error inserting delegation: %v", err)
+			return SaveResult{}, err
 		}
 		successCount++
-		log.Printf("[DEBUG] Successfully inserted delegation for delegator %s", delegatorAddress)
 	}
 
-	log.Printf("[INFO] Delegation processing complete for validator %s: %d processed, %d skipped, %d successful", 
-		validatorAddress, len(data.DelegationResponses), skippedCount, successCount)
+	// A delegator missing from a complete response has exited. With more
+	// pages to go, absence says nothing.
+	exitedCount := 0
+	if data.Pagination.NextKey == "" {
+		var exited []string
+		for delegatorAddress, shares := range latestDelegations {
+			if !seen[delegatorAddress] && !isZeroShares(shares) {
+				exited = append(exited, delegatorAddress)
+			}
+		}
+		sort.Strings(exited)
+		for _, delegatorAddress := range exited {
+			if err := save(delegatorAddress, zeroShares); err != nil {
+				tx.Rollback()
+				return SaveResult{}, err
+			}
+			exitedCount++
+		}
+	}
+
+	// Queue the changes for the event consumers, so that they are relayed
+	// exactly when they are stored
+	changes := make([]models.Event, 0, len(recorded))
+	for _, event := range recorded {
+		change, err := events.ForDelegationEvent(event)
+		if err != nil {
+			tx.Rollback()
+			return SaveResult{}, err
+		}
+		changes = append(changes, change)
+	}
+	if _, err := enqueueEvents(ctx, tx, changes); err != nil {
+		tx.Rollback()
+		return SaveResult{}, err
+	}
 
 	// Commit the transaction
 	if err = tx.Commit(); err != nil {
-		log.Printf("[ERROR] Failed to commit transaction: %v", err)
-		return fmt.Errorf("error committing transaction: %v", err)
+		return SaveResult{}, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	result := SaveResult{
+		Processed: len(data.DelegationResponses),
+		Inserted:  successCount,
+		Skipped:   skippedCount,
+		Exited:    exitedCount,
+		Events:    recorded,
 	}
+	logger.InfoContext(ctx, "Saved delegations",
+		"processed", result.Processed,
+		"inserted", result.Inserted,
+		"skipped", result.Skipped,
+		"exited", result.Exited,
+	)
+	return result, nil
+}
+
+// zeroShares are the shares stored for a delegator that exited, in the
+// 18 decimal format of the chain
+const zeroShares = "0.000000000000000000"
+
+// isZeroShares reports whether shares are zero. Unparsable shares are not.
+func isZeroShares(shares string) bool {
+	value, ok := new(big.Rat).SetString(shares)
+	return ok && value.Sign() == 0
+}
 
-	log.Printf("[INFO] Successfully committed transaction for validator %s", validatorAddress)
-	return nil
+// delegationChange classifies the change of a delegator's shares and returns
+// the event type, the previous shares and the delta
+func delegationChange(previousShares string, existed bool, newShares string) (models.DelegationEventType, string, string, error) {
+	if !existed {
+		previousShares = zeroShares
+	}
+	previous, ok := new(big.Rat).SetString(previousShares)
+	if !ok {
+		return "", "", "", fmt.Errorf("invalid previous shares %q", previousShares)
+	}
+	current, ok := new(big.Rat).SetString(newShares)
+	if !ok {
+		return "", "", "", fmt.Errorf("invalid shares %q", newShares)
+	}
+	delta := new(big.Rat).Sub(current, previous)
+
+	var eventType models.DelegationEventType
+	switch {
+	case previous.Sign() == 0:
+		// Includes delegators returning after an exit
+		eventType = models.DelegationEventNew
+	case current.Sign() == 0:
+		eventType = models.DelegationEventExit
+	case delta.Sign() > 0:
+		eventType = models.DelegationEventIncrease
+	default:
+		eventType = models.DelegationEventDecrease
+	}
+	return eventType, previousShares, delta.FloatString(sharesDecimals), nil
 }
 
+// sharesDecimals is the number of decimals of delegation shares on the chain
+const sharesDecimals = 18
+
 // GetDelegations retrieves delegations for a validator
-func (s *DelegationStoreImpl) GetDelegations(validatorAddress string) ([]models.Delegation, error) {
+func (s *DelegationStoreImpl) GetDelegations(ctx context.Context, validatorAddress string) ([]models.Delegation, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	ctx, span := startSpan(ctx, "GetDelegations", "delegations")
+	defer span.End()
+
 	query := `
 		SELECT id, validator_address, delegator_address, delegation_shares, created_at, updated_at
 		FROM delegations
@@ -164,7 +386,7 @@ func (s *DelegationStoreImpl) GetDelegations(validatorAddress string) ([]models.
 		ORDER BY created_at DESC
 	`
 
-	rows, err := s.db.Query(query, validatorAddress)
+	rows, err := s.db.QueryContext(ctx, query, validatorAddress)
 	if err != nil {
 		return nil, fmt.Errorf("error querying delegations: %v", err)
 	}
@@ -195,17 +417,20 @@ func (s *DelegationStoreImpl) GetDelegations(validatorAddress string) ([]models.
 }
 
 // GetAllDelegations retrieves all stored delegations
-func (s *DelegationStoreImpl) GetAllDelegations() (map[string][]models.Delegation, error) {
+func (s *DelegationStoreImpl) GetAllDelegations(ctx context.Context) (map[string][]models.Delegation, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	ctx, span := startSpan(ctx, "GetAllDelegations", "delegations")
+	defer span.End()
+
 	query := `
 		SELECT id, validator_address, delegator_address, delegation_shares, created_at, updated_at
 		FROM delegations
 		ORDER BY validator_address, created_at DESC
 	`
 
-	rows, err := s.db.Query(query)
+	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying all delegations: %v", err)
 	}
@@ -236,17 +461,20 @@ func (s *DelegationStoreImpl) GetAllDelegations() (map[string][]models.Delegatio
 }
 
 // GetEnabledValidators gets all validators with enabled tracking
-func (s *DelegationStoreImpl) GetEnabledValidators() ([]string, error) {
+func (s *DelegationStoreImpl) GetEnabledValidators(ctx context.Context) ([]string, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	ctx, span := startSpan(ctx, "GetEnabledValidators", "validators")
+	defer span.End()
+
 	query := `
 		SELECT address
 		FROM validators
-		WHERE enabled_tracking = true
+		WHERE enabled_tracking = true AND archived_at IS NULL
 	`
 
-	rows, err := s.db.Query(query)
+	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying enabled validators: %v", err)
 	}
@@ -265,10 +493,13 @@ func (s *DelegationStoreImpl) GetEnabledValidators() ([]string, error) {
 }
 
 // DelegationExists checks if a delegation exists for the given validator, delegator, and shares
-func (s *DelegationStoreImpl) DelegationExists(validatorAddress, delegatorAddress, delegationShares string) (bool, error) {
+func (s *DelegationStoreImpl) DelegationExists(ctx context.Context, validatorAddress, delegatorAddress, delegationShares string) (bool, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	ctx, span := startSpan(ctx, "DelegationExists", "delegations")
+	defer span.End()
+
 	query := `
 		SELECT EXISTS (
 			SELECT 1
@@ -280,10 +511,434 @@ func (s *DelegationStoreImpl) DelegationExists(validatorAddress, delegatorAddres
 	`
 
 	var exists bool
-	err := s.db.QueryRow(query, validatorAddress, delegatorAddress, delegationShares).Scan(&exists)
+	err := s.db.QueryRowContext(ctx, query, validatorAddress, delegatorAddress, delegationShares).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("error checking delegation existence: %v", err)
 	}
 
 	return exists, nil
-} 
\ No newline at end of file
+} 
+
+// GetStakeSummary sums the current delegations of every validator that is not
+// archived, limited to the validators carrying tag unless it is empty. The
+// current delegation of a delegator is the latest stored shares; delegators
+// whose latest shares are zero are not counted.
+func (s *DelegationStoreImpl) GetStakeSummary(ctx context.Context, tag string) ([]models.ValidatorStake, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetStakeSummary", "delegations")
+	defer span.End()
+
+	var args []interface{}
+	where := "v.archived_at IS NULL"
+	if tag != "" {
+		args = append(args, tag)
+		where += " AND $1 = ANY(v.tags)"
+	}
+	query := `
+		SELECT v.address, COUNT(d.delegator_address), COALESCE(SUM(CAST(d.delegation_shares AS NUMERIC)), 0)::TEXT
+		FROM validators v
+		LEFT JOIN (
+			SELECT DISTINCT ON (validator_address, delegator_address) validator_address, delegator_address, delegation_shares
+			FROM delegations
+			ORDER BY validator_address, delegator_address, created_at DESC, id DESC
+		) d ON d.validator_address = v.address AND CAST(d.delegation_shares AS NUMERIC) > 0
+		WHERE ` + where + `
+		GROUP BY v.address
+		ORDER BY v.address
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying stake summary: %v", err)
+	}
+	defer rows.Close()
+
+	stakes := []models.ValidatorStake{}
+	for rows.Next() {
+		var stake models.ValidatorStake
+		if err := rows.Scan(&stake.ValidatorAddress, &stake.Delegators, &stake.TotalShares); err != nil {
+			return nil, fmt.Errorf("error scanning stake summary row: %v", err)
+		}
+		stakes = append(stakes, stake)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stake summary rows: %v", err)
+	}
+
+	return stakes, nil
+}
+
+// GetDelegationEvents returns the delegation events of a validator matching the filter, newest first
+func (s *DelegationStoreImpl) GetDelegationEvents(ctx context.Context, filter DelegationEventFilter) ([]models.DelegationEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetDelegationEvents", "delegation_events")
+	defer span.End()
+
+	conditions, args := delegationEventConditions(filter, filter.ValidatorAddress)
+	args = append(args, delegationEventLimit(filter.Limit), filter.Offset)
+	query := `
+		SELECT id, validator_address, delegator_address, event_type, previous_shares::TEXT, shares::TEXT, delta::TEXT, created_at
+		FROM delegation_events
+		WHERE ` + strings.Join(conditions, " AND ") +
+		fmt.Sprintf("\n\t\tORDER BY created_at DESC, id DESC\n\t\tLIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	return s.queryDelegationEvents(ctx, query, args...)
+}
+
+// GetDelegationEventsByValidator returns the delegation events of each of the
+// validators matching the filter, newest first, in a single query
+func (s *DelegationStoreImpl) GetDelegationEventsByValidator(ctx context.Context, validatorAddresses []string, filter DelegationEventFilter) (map[string][]models.DelegationEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetDelegationEventsByValidator", "delegation_events")
+	defer span.End()
+
+	conditions, args := delegationEventConditions(filter, pq.Array(validatorAddresses))
+	conditions[0] = strings.Replace(conditions[0], "= $1", "= ANY($1)", 1)
+	args = append(args, filter.Offset, filter.Offset+delegationEventLimit(filter.Limit))
+	query := `
+		SELECT id, validator_address, delegator_address, event_type, previous_shares, shares, delta, created_at
+		FROM (
+			SELECT id, validator_address, delegator_address, event_type, previous_shares::TEXT, shares::TEXT, delta::TEXT, created_at,
+				ROW_NUMBER() OVER (PARTITION BY validator_address ORDER BY created_at DESC, id DESC) AS position
+			FROM delegation_events
+			WHERE ` + strings.Join(conditions, " AND ") + `
+		) e` +
+		fmt.Sprintf("\n\t\tWHERE position > $%d AND position <= $%d\n\t\tORDER BY validator_address, position", len(args)-1, len(args))
+
+	events, err := s.queryDelegationEvents(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byValidator := make(map[string][]models.DelegationEvent, len(validatorAddresses))
+	for _, event := range events {
+		byValidator[event.ValidatorAddress] = append(byValidator[event.ValidatorAddress], event)
+	}
+	return byValidator, nil
+}
+
+// delegationEventConditions returns the SQL conditions of a delegation event
+// filter and their arguments, starting with the validator condition on
+// validator
+func delegationEventConditions(filter DelegationEventFilter, validator interface{}) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	addCondition("validator_address = $%d", validator)
+	if len(filter.Types) > 0 {
+		types := make([]string, len(filter.Types))
+		for i, eventType := range filter.Types {
+			types[i] = string(eventType)
+		}
+		addCondition("event_type = ANY($%d)", pq.Array(types))
+	}
+	if filter.MinDelta != "" {
+		addCondition("ABS(delta) >= CAST($%d AS NUMERIC)", filter.MinDelta)
+	}
+	if filter.Since != nil {
+		addCondition("created_at >= $%d", *filter.Since)
+	}
+	if filter.Until != nil {
+		addCondition("created_at < $%d", *filter.Until)
+	}
+	return conditions, args
+}
+
+// delegationEventLimit returns the number of delegation events to return for
+// a requested limit
+func delegationEventLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultDelegationEventLimit
+	}
+	if limit > MaxDelegationEventLimit {
+		return MaxDelegationEventLimit
+	}
+	return limit
+}
+
+// currentDelegationsQuery selects the latest delegation of every delegator
+// that still delegates, limited to the validators in $1 and the delegators in
+// $2 unless they are empty
+const currentDelegationsQuery = `
+			SELECT id, validator_address, delegator_address, delegation_shares, created_at, updated_at
+			FROM (
+				SELECT DISTINCT ON (validator_address, delegator_address)
+					id, validator_address, delegator_address, delegation_shares, created_at, updated_at
+				FROM delegations
+				WHERE (COALESCE(cardinality($1::TEXT[]), 0) = 0 OR validator_address = ANY($1))
+					AND (COALESCE(cardinality($2::TEXT[]), 0) = 0 OR delegator_address = ANY($2))
+				ORDER BY validator_address, delegator_address, created_at DESC, id DESC
+			) latest
+			WHERE CAST(delegation_shares AS NUMERIC) > 0`
+
+// GetCurrentDelegations returns the latest delegation of every delegator that
+// still delegates, matching the filter, in a single query. The delegations of
+// a validator are ordered by shares, largest first, and those of a delegator
+// by validator.
+func (s *DelegationStoreImpl) GetCurrentDelegations(ctx context.Context, filter CurrentDelegationFilter) ([]models.Delegation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetCurrentDelegations", "delegations")
+	defer span.End()
+
+	partition, order := filter.partition()
+	args := []interface{}{pq.Array(filter.ValidatorAddresses), pq.Array(filter.DelegatorAddresses)}
+	page := ""
+	if filter.Limit > 0 {
+		args = append(args, filter.Offset, filter.Offset+filter.Limit)
+		page = "\n\t\tWHERE position > $3 AND position <= $4"
+	}
+	query := `
+		SELECT id, validator_address, delegator_address, delegation_shares, created_at, updated_at
+		FROM (
+			SELECT id, validator_address, delegator_address, delegation_shares, created_at, updated_at,
+				ROW_NUMBER() OVER (PARTITION BY ` + partition + ` ORDER BY ` + order + `) AS position
+			FROM (` + currentDelegationsQuery + `
+			) c
+		) d` + page + `
+		ORDER BY ` + partition + `, position
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying current delegations: %v", err)
+	}
+	defer rows.Close()
+
+	delegations := []models.Delegation{}
+	for rows.Next() {
+		var d models.Delegation
+		if err := rows.Scan(&d.ID, &d.ValidatorAddress, &d.DelegatorAddress, &d.DelegationShares, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning delegation row: %v", err)
+		}
+		delegations = append(delegations, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating delegation rows: %v", err)
+	}
+	return delegations, nil
+}
+
+// GetCurrentDelegationTotals counts and sums the current delegations matching
+// the filter per validator, or per delegator when only delegators are given.
+// Addresses of the filter without delegations have zero totals.
+func (s *DelegationStoreImpl) GetCurrentDelegationTotals(ctx context.Context, filter CurrentDelegationFilter) (map[string]models.DelegationTotals, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetCurrentDelegationTotals", "delegations")
+	defer span.End()
+
+	partition, _ := filter.partition()
+	query := `
+		SELECT ` + partition + `, COUNT(*), COALESCE(SUM(CAST(delegation_shares AS NUMERIC)), 0)::TEXT
+		FROM (` + currentDelegationsQuery + `
+		) d
+		GROUP BY ` + partition + `
+		ORDER BY ` + partition + `
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(filter.ValidatorAddresses), pq.Array(filter.DelegatorAddresses))
+	if err != nil {
+		return nil, fmt.Errorf("error querying current delegation totals: %v", err)
+	}
+	defer rows.Close()
+
+	addresses := filter.ValidatorAddresses
+	if partition == "delegator_address" {
+		addresses = filter.DelegatorAddresses
+	}
+	totals := make(map[string]models.DelegationTotals, len(addresses))
+	for _, address := range addresses {
+		totals[address] = models.DelegationTotals{TotalShares: "0"}
+	}
+	for rows.Next() {
+		var address string
+		var total models.DelegationTotals
+		if err := rows.Scan(&address, &total.Delegations, &total.TotalShares); err != nil {
+			return nil, fmt.Errorf("error scanning delegation totals row: %v", err)
+		}
+		totals[address] = total
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating delegation totals rows: %v", err)
+	}
+	return totals, nil
+}
+
+// GetStakeSeries returns the stake changes of the validators per interval
+// starting in [since, until), oldest first. The total shares of an interval
+// sum every delta up to its end, which is the stake once the first sync of a
+// validator recorded its delegators as new.
+func (s *DelegationStoreImpl) GetStakeSeries(ctx context.Context, validatorAddresses []string, interval StakeInterval, since, until time.Time) ([]models.StakePoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetStakeSeries", "delegation_events")
+	defer span.End()
+
+	if interval != StakeIntervalHour && interval != StakeIntervalDay {
+		return nil, fmt.Errorf("invalid stake interval %q", interval)
+	}
+
+	query := `
+		SELECT validator_address, bucket, stake_change::TEXT, total_shares::TEXT, new_delegators, lost_delegators, events
+		FROM (
+			SELECT validator_address,
+				date_trunc($2, created_at AT TIME ZONE 'UTC') AS bucket,
+				SUM(delta) AS stake_change,
+				SUM(SUM(delta)) OVER (PARTITION BY validator_address ORDER BY date_trunc($2, created_at AT TIME ZONE 'UTC')) AS total_shares,
+				COUNT(*) FILTER (WHERE event_type = 'new') AS new_delegators,
+				COUNT(*) FILTER (WHERE event_type = 'exit') AS lost_delegators,
+				COUNT(*) AS events
+			FROM delegation_events
+			WHERE validator_address = ANY($1) AND created_at < $4
+			GROUP BY validator_address, bucket
+		) s
+		WHERE bucket >= date_trunc($2, $3 AT TIME ZONE 'UTC')
+		ORDER BY validator_address, bucket
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(validatorAddresses), string(interval), since, until)
+	if err != nil {
+		return nil, fmt.Errorf("error querying stake series: %v", err)
+	}
+	defer rows.Close()
+
+	points := []models.StakePoint{}
+	for rows.Next() {
+		var point models.StakePoint
+		if err := rows.Scan(&point.ValidatorAddress, &point.Timestamp, &point.StakeChange, &point.TotalShares,
+			&point.NewDelegators, &point.LostDelegators, &point.Events); err != nil {
+			return nil, fmt.Errorf("error scanning stake series row: %v", err)
+		}
+		point.Timestamp = point.Timestamp.UTC()
+		points = append(points, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stake series rows: %v", err)
+	}
+	return points, nil
+}
+
+// GetDelegationEventsAfter returns up to limit delegation events with an ID
+// greater than afterID, oldest first. Events of every validator are returned
+// when validatorAddress is empty.
+func (s *DelegationStoreImpl) GetDelegationEventsAfter(ctx context.Context, validatorAddress string, afterID int64, limit int) ([]models.DelegationEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetDelegationEventsAfter", "delegation_events")
+	defer span.End()
+
+	if limit <= 0 || limit > MaxDelegationEventLimit {
+		limit = MaxDelegationEventLimit
+	}
+
+	return s.queryDelegationEvents(ctx, `
+		SELECT id, validator_address, delegator_address, event_type, previous_shares::TEXT, shares::TEXT, delta::TEXT, created_at
+		FROM delegation_events
+		WHERE id > $1 AND ($2 = '' OR validator_address = $2)
+		ORDER BY id
+		LIMIT $3
+	`, afterID, validatorAddress, limit)
+}
+
+// queryDelegationEvents runs a query selecting the columns of delegation events
+func (s *DelegationStoreImpl) queryDelegationEvents(ctx context.Context, query string, args ...interface{}) ([]models.DelegationEvent, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying delegation events: %v", err)
+	}
+	defer rows.Close()
+
+	events := []models.DelegationEvent{}
+	for rows.Next() {
+		var event models.DelegationEvent
+		if err := rows.Scan(&event.ID, &event.ValidatorAddress, &event.DelegatorAddress, &event.Type,
+			&event.PreviousShares, &event.Shares, &event.Delta, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning delegation event row: %v", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating delegation event rows: %v", err)
+	}
+
+	return events, nil
+}
+
+// GetDelegationActivity summarizes the delegation events of a validator
+// created in [since, until). New and lost delegators are counted once each,
+// however often they joined or left; top movers are the delegators with the
+// largest absolute net change, largest first.
+func (s *DelegationStoreImpl) GetDelegationActivity(ctx context.Context, validatorAddress string, since, until time.Time, topMovers int) (models.DelegationActivity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetDelegationActivity", "delegation_events")
+	defer span.End()
+
+	activity := models.DelegationActivity{
+		ValidatorAddress: validatorAddress,
+		TopMovers:        []models.DelegatorMovement{},
+	}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(delta), 0)::TEXT,
+			COUNT(DISTINCT delegator_address) FILTER (WHERE event_type = 'new'),
+			COUNT(DISTINCT delegator_address) FILTER (WHERE event_type = 'exit')
+		FROM delegation_events
+		WHERE validator_address = $1 AND created_at >= $2 AND created_at < $3
+	`, validatorAddress, since, until).Scan(&activity.StakeChange, &activity.NewDelegators, &activity.LostDelegators)
+	if err != nil {
+		return models.DelegationActivity{}, fmt.Errorf("error querying delegation activity: %v", err)
+	}
+
+	if topMovers <= 0 {
+		return activity, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT delegator_address, SUM(delta)::TEXT
+		FROM delegation_events
+		WHERE validator_address = $1 AND created_at >= $2 AND created_at < $3
+		GROUP BY delegator_address
+		HAVING SUM(delta) <> 0
+		ORDER BY ABS(SUM(delta)) DESC, delegator_address
+		LIMIT $4
+	`, validatorAddress, since, until, topMovers)
+	if err != nil {
+		return models.DelegationActivity{}, fmt.Errorf("error querying top movers: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movement models.DelegatorMovement
+		if err := rows.Scan(&movement.DelegatorAddress, &movement.Delta); err != nil {
+			return models.DelegationActivity{}, fmt.Errorf("error scanning top mover row: %v", err)
+		}
+		activity.TopMovers = append(activity.TopMovers, movement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return models.DelegationActivity{}, fmt.Errorf("error iterating top mover rows: %v", err)
+	}
+
+	return activity, nil
+}
@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+// ErrEmailDigestNotFound is returned when an email digest is not found
+var ErrEmailDigestNotFound = errors.New("email digest not found")
+
+// EmailDigestStore defines the interface for email digest storage
+type EmailDigestStore interface {
+	// GetAll returns all email digests
+	GetAll(ctx context.Context) ([]models.EmailDigest, error)
+
+	// GetEnabled returns the enabled email digests sent with the given frequency
+	GetEnabled(ctx context.Context, frequency models.DigestFrequency) ([]models.EmailDigest, error)
+
+	// GetByID returns the email digest with the given ID
+	GetByID(ctx context.Context, id int) (*models.EmailDigest, error)
+
+	// Add stores a new email digest
+	Add(ctx context.Context, digest models.EmailDigest) (*models.EmailDigest, error)
+
+	// Update replaces the tag, frequency, recipients and enabled flag of an email digest
+	Update(ctx context.Context, digest models.EmailDigest) (*models.EmailDigest, error)
+
+	// Delete removes an email digest
+	Delete(ctx context.Context, id int) error
+
+	// RecordSend records an attempt to send an email digest at attemptedAt.
+	// A nil sendErr marks the digest as sent.
+	RecordSend(ctx context.Context, id int, attemptedAt time.Time, sendErr error) error
+}
+
+// EmailDigestStoreImpl implements EmailDigestStore with PostgreSQL storage
+type EmailDigestStoreImpl struct {
+	db *sql.DB
+	mu sync.RWMutex
+}
+
+// NewEmailDigestStore creates a new instance of EmailDigestStoreImpl
+func NewEmailDigestStore(db *sql.DB) *EmailDigestStoreImpl {
+	return &EmailDigestStoreImpl{
+		db: db,
+	}
+}
+
+// emailDigestColumns lists the columns scanned by scanEmailDigest
+const emailDigestColumns = `id, tag, frequency, recipients, enabled, last_sent_at, last_attempt_at, last_error, created_at, updated_at`
+
+// GetAll returns all email digests
+func (s *EmailDigestStoreImpl) GetAll(ctx context.Context) ([]models.EmailDigest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetAll", "email_digests")
+	defer span.End()
+
+	return s.queryDigests(ctx, `SELECT `+emailDigestColumns+` FROM email_digests ORDER BY id`)
+}
+
+// GetEnabled returns the enabled email digests sent with the given frequency
+func (s *EmailDigestStoreImpl) GetEnabled(ctx context.Context, frequency models.DigestFrequency) ([]models.EmailDigest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetEnabled", "email_digests")
+	defer span.End()
+
+	return s.queryDigests(ctx, `SELECT `+emailDigestColumns+` FROM email_digests WHERE enabled AND frequency = $1 ORDER BY id`,
+		string(frequency))
+}
+
+// GetByID returns the email digest with the given ID
+func (s *EmailDigestStoreImpl) GetByID(ctx context.Context, id int) (*models.EmailDigest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetByID", "email_digests")
+	defer span.End()
+
+	digest, err := scanEmailDigest(s.db.QueryRowContext(ctx, `SELECT `+emailDigestColumns+` FROM email_digests WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrEmailDigestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying email digest: %v", err)
+	}
+	return digest, nil
+}
+
+// Add stores a new email digest
+func (s *EmailDigestStoreImpl) Add(ctx context.Context, digest models.EmailDigest) (*models.EmailDigest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Add", "email_digests")
+	defer span.End()
+
+	query := `
+		INSERT INTO email_digests (tag, frequency, recipients, enabled)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + emailDigestColumns
+	created, err := scanEmailDigest(s.db.QueryRowContext(ctx, query, digest.Tag, string(digest.Frequency),
+		pq.Array(digest.Recipients), digest.Enabled))
+	if err != nil {
+		return nil, fmt.Errorf("error inserting email digest: %v", err)
+	}
+	return created, nil
+}
+
+// Update replaces the tag, frequency, recipients and enabled flag of an email digest
+func (s *EmailDigestStoreImpl) Update(ctx context.Context, digest models.EmailDigest) (*models.EmailDigest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Update", "email_digests")
+	defer span.End()
+
+	query := `
+		UPDATE email_digests
+		SET tag = $1, frequency = $2, recipients = $3, enabled = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+		RETURNING ` + emailDigestColumns
+	updated, err := scanEmailDigest(s.db.QueryRowContext(ctx, query, digest.Tag, string(digest.Frequency),
+		pq.Array(digest.Recipients), digest.Enabled, digest.ID))
+	if err == sql.ErrNoRows {
+		return nil, ErrEmailDigestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error updating email digest: %v", err)
+	}
+	return updated, nil
+}
+
+// Delete removes an email digest
+func (s *EmailDigestStoreImpl) Delete(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Delete", "email_digests")
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM email_digests WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting email digest: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrEmailDigestNotFound
+	}
+
+	return nil
+}
+
+// RecordSend records an attempt to send an email digest at attemptedAt.
+// A nil sendErr marks the digest as sent.
+func (s *EmailDigestStoreImpl) RecordSend(ctx context.Context, id int, attemptedAt time.Time, sendErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "RecordSend", "email_digests")
+	defer span.End()
+
+	var err error
+	if sendErr == nil {
+		_, err = s.db.ExecContext(ctx, `
+			UPDATE email_digests
+			SET last_sent_at = $1, last_attempt_at = $1, last_error = ''
+			WHERE id = $2
+		`, attemptedAt, id)
+	} else {
+		_, err = s.db.ExecContext(ctx, `
+			UPDATE email_digests
+			SET last_attempt_at = $1, last_error = $2
+			WHERE id = $3
+		`, attemptedAt, sendErr.Error(), id)
+	}
+	if err != nil {
+		return fmt.Errorf("error recording email digest send: %v", err)
+	}
+
+	return nil
+}
+
+// queryDigests runs a query selecting emailDigestColumns
+func (s *EmailDigestStoreImpl) queryDigests(ctx context.Context, query string, args ...interface{}) ([]models.EmailDigest, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying email digests: %v", err)
+	}
+	defer rows.Close()
+
+	digests := []models.EmailDigest{}
+	for rows.Next() {
+		digest, err := scanEmailDigest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning email digest row: %v", err)
+		}
+		digests = append(digests, *digest)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating email digest rows: %v", err)
+	}
+
+	return digests, nil
+}
+
+// scanEmailDigest scans a row selected with emailDigestColumns
+func scanEmailDigest(row rowScanner) (*models.EmailDigest, error) {
+	var digest models.EmailDigest
+	var lastSentAt, lastAttemptAt sql.NullTime
+	if err := row.Scan(&digest.ID, &digest.Tag, &digest.Frequency, pq.Array(&digest.Recipients), &digest.Enabled,
+		&lastSentAt, &lastAttemptAt, &digest.LastError, &digest.CreatedAt, &digest.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if lastSentAt.Valid {
+		digest.LastSentAt = &lastSentAt.Time
+	}
+	if lastAttemptAt.Valid {
+		digest.LastAttemptAt = &lastAttemptAt.Time
+	}
+	if digest.Recipients == nil {
+		digest.Recipients = []string{}
+	}
+	return &digest, nil
+}
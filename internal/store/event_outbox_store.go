@@ -0,0 +1,260 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+// eventOutboxLockKey is the advisory lock taken while claiming outbox events,
+// so that one relay at a time claims them
+const eventOutboxLockKey int64 = 0x6576656e746f7574
+
+// EventOutboxStore defines the interface for the outbox of events relayed to
+// the event consumers. Events derived from stored records are added to the
+// outbox in the transaction storing the records.
+type EventOutboxStore interface {
+	// Enqueue adds events to the outbox and returns the number added. Events
+	// already in the outbox are not added again.
+	Enqueue(ctx context.Context, events []models.Event) (int, error)
+
+	// ClaimNext returns up to limit of the oldest unrelayed events, in order,
+	// and locks them for lease. No events of a validator are returned while
+	// an older event of it is locked, which keeps the events of each
+	// validator in order.
+	ClaimNext(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+
+	// MarkRelayed records that events were relayed
+	MarkRelayed(ctx context.Context, ids []int64) error
+
+	// MarkFailed records a failed relay attempt of events and locks them
+	// until retryAt
+	MarkFailed(ctx context.Context, ids []int64, attemptErr string, retryAt time.Time) error
+
+	// DeleteRelayed removes the events relayed before a time and returns the
+	// number removed
+	DeleteRelayed(ctx context.Context, before time.Time) (int64, error)
+}
+
+// EventOutboxStoreImpl implements EventOutboxStore with PostgreSQL storage
+type EventOutboxStoreImpl struct {
+	db *sql.DB
+	mu sync.RWMutex
+}
+
+// NewEventOutboxStore creates a new instance of EventOutboxStoreImpl
+func NewEventOutboxStore(db *sql.DB) *EventOutboxStoreImpl {
+	return &EventOutboxStoreImpl{
+		db: db,
+	}
+}
+
+// Enqueue adds events to the outbox in order and returns the number added
+func (s *EventOutboxStoreImpl) Enqueue(ctx context.Context, events []models.Event) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Enqueue", "event_outbox")
+	defer span.End()
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	queued, err := enqueueEvents(ctx, tx, events)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return queued, nil
+}
+
+// enqueueEvents adds events to the outbox within tx and returns the number
+// added
+func enqueueEvents(ctx context.Context, tx *sql.Tx, events []models.Event) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO event_outbox (event_id, event_type, validator_address, payload)
+		VALUES ($1, $2, $3, CAST($4 AS JSONB))
+		ON CONFLICT (event_id) DO NOTHING
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("error preparing outbox statement: %v", err)
+	}
+	defer stmt.Close()
+
+	queued := 0
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return 0, fmt.Errorf("error encoding event %s: %v", event.ID, err)
+		}
+		result, err := stmt.ExecContext(ctx, event.ID, string(event.Type), event.ValidatorAddress, string(payload))
+		if err != nil {
+			return 0, fmt.Errorf("error queuing event %s: %v", event.ID, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("error getting rows affected: %v", err)
+		}
+		queued += int(rowsAffected)
+	}
+	return queued, nil
+}
+
+// ClaimNext returns up to limit of the oldest unrelayed events and locks them
+// for lease. It returns no events while another relay is claiming, and none
+// of a validator while an older event of it is locked.
+func (s *EventOutboxStoreImpl) ClaimNext(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	ctx, span := startSpan(ctx, "ClaimNext", "event_outbox")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, eventOutboxLockKey).Scan(&locked); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error locking event outbox: %v", err)
+	}
+	if !locked {
+		tx.Rollback()
+		return nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, payload::TEXT, attempts, created_at
+		FROM event_outbox o
+		WHERE relayed_at IS NULL AND NOT EXISTS (
+			SELECT 1 FROM event_outbox b
+			WHERE b.validator_address = o.validator_address AND b.relayed_at IS NULL
+				AND b.id <= o.id AND b.locked_until > CURRENT_TIMESTAMP
+		)
+		ORDER BY id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error querying unrelayed events: %v", err)
+	}
+
+	var events []models.OutboxEvent
+	var ids []int64
+	for rows.Next() {
+		var event models.OutboxEvent
+		var payload string
+		if err := rows.Scan(&event.ID, &payload, &event.Attempts, &event.CreatedAt); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, fmt.Errorf("error scanning outbox event row: %v", err)
+		}
+		if err := json.Unmarshal([]byte(payload), &event.Event); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, fmt.Errorf("error decoding outbox event %d: %v", event.ID, err)
+		}
+		events = append(events, event)
+		ids = append(ids, event.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error iterating outbox event rows: %v", err)
+	}
+
+	if len(ids) > 0 {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE event_outbox
+			SET locked_until = CURRENT_TIMESTAMP + make_interval(secs => $1)
+			WHERE id = ANY($2)
+		`, lease.Seconds(), pq.Array(ids))
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error locking outbox events: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return events, nil
+}
+
+// MarkRelayed records that events were relayed
+func (s *EventOutboxStoreImpl) MarkRelayed(ctx context.Context, ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "MarkRelayed", "event_outbox")
+	defer span.End()
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE event_outbox
+		SET relayed_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = NULL
+		WHERE id = ANY($1)
+	`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error marking outbox events relayed: %v", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed relay attempt of events and locks them until
+// retryAt
+func (s *EventOutboxStoreImpl) MarkFailed(ctx context.Context, ids []int64, attemptErr string, retryAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "MarkFailed", "event_outbox")
+	defer span.End()
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE event_outbox
+		SET attempts = attempts + 1, last_error = $1, locked_until = $2
+		WHERE id = ANY($3)
+	`, attemptErr, retryAt, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error marking outbox events failed: %v", err)
+	}
+	return nil
+}
+
+// DeleteRelayed removes the events relayed before a time and returns the
+// number removed
+func (s *EventOutboxStoreImpl) DeleteRelayed(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "DeleteRelayed", "event_outbox")
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM event_outbox WHERE relayed_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting relayed events: %v", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error getting rows affected: %v", err)
+	}
+	return deleted, nil
+}
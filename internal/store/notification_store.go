@@ -0,0 +1,239 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/lib/pq"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+// ErrNotificationChannelNotFound is returned when a notification channel is not found
+var ErrNotificationChannelNotFound = errors.New("notification channel not found")
+
+// NotificationChannelStore defines the interface for notification channel storage
+type NotificationChannelStore interface {
+	// GetAll returns all notification channels
+	GetAll(ctx context.Context) ([]models.NotificationChannel, error)
+
+	// GetEnabled returns the enabled notification channels
+	GetEnabled(ctx context.Context) ([]models.NotificationChannel, error)
+
+	// GetByID returns the notification channel with the given ID, including its credentials
+	GetByID(ctx context.Context, id int) (*models.NotificationChannel, error)
+
+	// Add stores a new notification channel
+	Add(ctx context.Context, channel models.NotificationChannel) (*models.NotificationChannel, error)
+
+	// Update replaces a notification channel, including its credentials
+	Update(ctx context.Context, channel models.NotificationChannel) (*models.NotificationChannel, error)
+
+	// Delete removes a notification channel
+	Delete(ctx context.Context, id int) error
+}
+
+// NotificationChannelStoreImpl implements NotificationChannelStore with PostgreSQL storage
+type NotificationChannelStoreImpl struct {
+	db *sql.DB
+	mu sync.RWMutex
+}
+
+// NewNotificationChannelStore creates a new instance of NotificationChannelStoreImpl
+func NewNotificationChannelStore(db *sql.DB) *NotificationChannelStoreImpl {
+	return &NotificationChannelStoreImpl{
+		db: db,
+	}
+}
+
+// notificationChannelColumns lists the columns scanned by scanNotificationChannel
+const notificationChannelColumns = `id, name, type, webhook_url, bot_token, chat_id, validator_addresses, event_types,
+			sync_failure_threshold, templates::TEXT, enabled, created_at, updated_at`
+
+// GetAll returns all notification channels
+func (s *NotificationChannelStoreImpl) GetAll(ctx context.Context) ([]models.NotificationChannel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetAll", "notification_channels")
+	defer span.End()
+
+	return s.queryChannels(ctx, `SELECT `+notificationChannelColumns+` FROM notification_channels ORDER BY id`)
+}
+
+// GetEnabled returns the enabled notification channels
+func (s *NotificationChannelStoreImpl) GetEnabled(ctx context.Context) ([]models.NotificationChannel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetEnabled", "notification_channels")
+	defer span.End()
+
+	return s.queryChannels(ctx, `SELECT `+notificationChannelColumns+` FROM notification_channels WHERE enabled ORDER BY id`)
+}
+
+// GetByID returns the notification channel with the given ID, including its credentials
+func (s *NotificationChannelStoreImpl) GetByID(ctx context.Context, id int) (*models.NotificationChannel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetByID", "notification_channels")
+	defer span.End()
+
+	query := `SELECT ` + notificationChannelColumns + ` FROM notification_channels WHERE id = $1`
+	channel, err := scanNotificationChannel(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotificationChannelNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying notification channel: %v", err)
+	}
+	return channel, nil
+}
+
+// Add stores a new notification channel
+func (s *NotificationChannelStoreImpl) Add(ctx context.Context, channel models.NotificationChannel) (*models.NotificationChannel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Add", "notification_channels")
+	defer span.End()
+
+	templates, err := encodeTemplates(channel.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO notification_channels (name, type, webhook_url, bot_token, chat_id, validator_addresses, event_types,
+			sync_failure_threshold, templates, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CAST($9 AS JSONB), $10)
+		RETURNING ` + notificationChannelColumns
+	created, err := scanNotificationChannel(s.db.QueryRowContext(ctx, query, channel.Name, string(channel.Type), channel.WebhookURL,
+		channel.BotToken, channel.ChatID, pq.Array(channel.ValidatorAddresses), pq.Array(eventTypeStrings(channel.EventTypes)),
+		channel.SyncFailureThreshold, templates, channel.Enabled))
+	if err != nil {
+		return nil, fmt.Errorf("error inserting notification channel: %v", err)
+	}
+	return created, nil
+}
+
+// Update replaces a notification channel, including its credentials
+func (s *NotificationChannelStoreImpl) Update(ctx context.Context, channel models.NotificationChannel) (*models.NotificationChannel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Update", "notification_channels")
+	defer span.End()
+
+	templates, err := encodeTemplates(channel.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE notification_channels
+		SET name = $1, type = $2, webhook_url = $3, bot_token = $4, chat_id = $5, validator_addresses = $6, event_types = $7,
+			sync_failure_threshold = $8, templates = CAST($9 AS JSONB), enabled = $10, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $11
+		RETURNING ` + notificationChannelColumns
+	updated, err := scanNotificationChannel(s.db.QueryRowContext(ctx, query, channel.Name, string(channel.Type), channel.WebhookURL,
+		channel.BotToken, channel.ChatID, pq.Array(channel.ValidatorAddresses), pq.Array(eventTypeStrings(channel.EventTypes)),
+		channel.SyncFailureThreshold, templates, channel.Enabled, channel.ID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotificationChannelNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error updating notification channel: %v", err)
+	}
+	return updated, nil
+}
+
+// Delete removes a notification channel
+func (s *NotificationChannelStoreImpl) Delete(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Delete", "notification_channels")
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM notification_channels WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting notification channel: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotificationChannelNotFound
+	}
+
+	return nil
+}
+
+// queryChannels runs a query selecting notificationChannelColumns
+func (s *NotificationChannelStoreImpl) queryChannels(ctx context.Context, query string, args ...interface{}) ([]models.NotificationChannel, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying notification channels: %v", err)
+	}
+	defer rows.Close()
+
+	channels := []models.NotificationChannel{}
+	for rows.Next() {
+		channel, err := scanNotificationChannel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning notification channel row: %v", err)
+		}
+		channels = append(channels, *channel)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification channel rows: %v", err)
+	}
+
+	return channels, nil
+}
+
+// scanNotificationChannel scans a row selected with notificationChannelColumns
+func scanNotificationChannel(row rowScanner) (*models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	var eventTypes []string
+	var templates string
+	if err := row.Scan(&channel.ID, &channel.Name, &channel.Type, &channel.WebhookURL, &channel.BotToken, &channel.ChatID,
+		pq.Array(&channel.ValidatorAddresses), pq.Array(&eventTypes), &channel.SyncFailureThreshold, &templates,
+		&channel.Enabled, &channel.CreatedAt, &channel.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if channel.ValidatorAddresses == nil {
+		channel.ValidatorAddresses = []string{}
+	}
+	channel.EventTypes = make([]models.EventType, len(eventTypes))
+	for i, eventType := range eventTypes {
+		channel.EventTypes[i] = models.EventType(eventType)
+	}
+	if err := json.Unmarshal([]byte(templates), &channel.Templates); err != nil {
+		return nil, fmt.Errorf("error decoding templates: %v", err)
+	}
+	if len(channel.Templates) == 0 {
+		channel.Templates = nil
+	}
+	return &channel, nil
+}
+
+// encodeTemplates encodes message templates for storage in a JSONB column
+func encodeTemplates(templates map[models.EventType]string) (string, error) {
+	if len(templates) == 0 {
+		return "{}", nil
+	}
+	encoded, err := json.Marshal(templates)
+	if err != nil {
+		return "", fmt.Errorf("error encoding templates: %v", err)
+	}
+	return string(encoded), nil
+}
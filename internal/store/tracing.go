@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan starts a span for a store operation on the given table
+func startSpan(ctx context.Context, operation, table string) (context.Context, trace.Span) {
+	return tracing.StartSpan(ctx, "store."+operation,
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", operation),
+		attribute.String("db.sql.table", table),
+	)
+}
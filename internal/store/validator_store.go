@@ -1,12 +1,16 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/novintriantonius/cosmos-validator-service/internal/models"
 )
 
@@ -16,16 +20,106 @@ var (
 	
 	// ErrValidatorAlreadyExists is returned when trying to add a validator with an address that already exists
 	ErrValidatorAlreadyExists = errors.New("validator with this address already exists")
+
+	// ErrValidatorArchived is returned when trying to change an archived validator
+	ErrValidatorArchived = errors.New("validator is archived")
+
+	// ErrValidatorNotArchived is returned when trying to restore a validator that is not archived
+	ErrValidatorNotArchived = errors.New("validator is not archived")
+
+	// ErrValidatorModified is returned when a validator changed since the version an update was based on
+	ErrValidatorModified = errors.New("validator was modified since it was read")
+
+	// ErrBatchConflict is returned when an all-or-nothing upsert is rolled back because of a conflict
+	ErrBatchConflict = errors.New("batch has conflicts")
+
+	// ErrTagNotFound is returned when no validator carries the tag
+	ErrTagNotFound = errors.New("tag not found")
 )
 
+// MaxValidatorLimit is the largest page of validators listed at once
+const MaxValidatorLimit = 1000
+
+// ValidatorSort is a field validators can be listed by
+type ValidatorSort string
+
+const (
+	// ValidatorSortName sorts by name, ignoring case
+	ValidatorSortName ValidatorSort = "name"
+	// ValidatorSortCreatedAt sorts by creation time
+	ValidatorSortCreatedAt ValidatorSort = "createdAt"
+	// ValidatorSortStake sorts by the bonded tokens of the chain metadata.
+	// Validators without metadata come last.
+	ValidatorSortStake ValidatorSort = "stake"
+)
+
+// ValidatorFilter narrows down and orders the validators returned by
+// ValidatorStore.List. Empty fields are ignored.
+type ValidatorFilter struct {
+	// Search matches validators whose name contains it, ignoring case
+	Search          string
+	EnabledTracking *bool
+	// Tag matches validators carrying the tag
+	Tag string
+	// Archived lists archived instead of active validators
+	Archived bool
+	// Sort defaults to the name, or the archive time for archived validators
+	Sort       ValidatorSort
+	Descending bool
+	// Limit is the size of a page of validators. Every validator is listed
+	// when it is zero.
+	Limit  int
+	Offset int
+}
+
 // ValidatorStore defines the interface for validator storage operations
 type ValidatorStore interface {
-	GetAll() ([]models.Validator, error)
-	GetByAddress(address string) (*models.Validator, error)
-	GetEnabledValidators() ([]string, error)
-	Add(validator models.Validator) error
-	Update(address string, validator models.Validator) error
-	Delete(address string) error
+	GetAll(ctx context.Context) ([]models.Validator, error)
+	List(ctx context.Context, filter ValidatorFilter) ([]models.Validator, int, error)
+	GetByAddress(ctx context.Context, address string) (*models.Validator, error)
+	GetByAddresses(ctx context.Context, addresses []string) ([]models.Validator, error)
+	GetEnabledValidators(ctx context.Context) ([]string, error)
+	Add(ctx context.Context, validator models.Validator) error
+	Update(ctx context.Context, address string, validator models.Validator, expectedUpdatedAt *time.Time) error
+	Archive(ctx context.Context, address string) error
+	Restore(ctx context.Context, address string) error
+	Delete(ctx context.Context, address string) error
+	GetSyncStatuses(ctx context.Context) ([]models.ValidatorSyncStatus, error)
+	UpdateSyncStatus(ctx context.Context, address string, syncErr error) error
+	UpdateMetadata(ctx context.Context, address string, metadata models.ValidatorMetadata) error
+	Import(ctx context.Context, validators []models.Validator) (ImportResult, error)
+	Upsert(ctx context.Context, validators []models.Validator, atomic bool) ([]UpsertStatus, error)
+	ListTags(ctx context.Context) ([]models.TagCount, error)
+	SetTags(ctx context.Context, address string, tags []string) error
+	AddTag(ctx context.Context, address, tag string) error
+	RemoveTag(ctx context.Context, address, tag string) error
+	RenameTag(ctx context.Context, tag, newTag string) (int, error)
+	DeleteTag(ctx context.Context, tag string) (int, error)
+}
+
+// UpsertStatus is the outcome of upserting a single validator
+type UpsertStatus string
+
+const (
+	// UpsertCreated means the validator did not exist and was created
+	UpsertCreated UpsertStatus = "created"
+	// UpsertUpdated means the existing validator was updated
+	UpsertUpdated UpsertStatus = "updated"
+	// UpsertConflict means the validator is archived, or listed twice in a batch
+	UpsertConflict UpsertStatus = "conflict"
+	// UpsertInvalid means the validator failed validation and was not sent to the store
+	UpsertInvalid UpsertStatus = "invalid"
+	// UpsertSkipped means the validator was not written because its all-or-nothing batch was rejected
+	UpsertSkipped UpsertStatus = "skipped"
+)
+
+// ImportResult counts the outcome of importing validators
+type ImportResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	// Archived counts validators that were skipped because they are archived
+	Archived int `json:"archived"`
 }
 
 // ValidatorStoreImpl implements ValidatorStore with PostgreSQL storage
@@ -41,13 +135,105 @@ func NewValidatorStore(db *sql.DB) *ValidatorStoreImpl {
 	}
 }
 
-// GetAll returns all validators from the database
-func (s *ValidatorStoreImpl) GetAll() ([]models.Validator, error) {
+// validatorColumns lists the columns scanned by scanValidator
+const validatorColumns = `address, name, enabled_tracking, archived_at, created_at, updated_at,
+	moniker, website, commission_rate, bond_status, jailed, tokens, metadata_updated_at, tags`
+
+// GetAll returns all validators that are not archived
+func (s *ValidatorStoreImpl) GetAll(ctx context.Context) ([]models.Validator, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	query := `SELECT address, name, enabled_tracking FROM validators`
-	rows, err := s.db.Query(query)
+	ctx, span := startSpan(ctx, "GetAll", "validators")
+	defer span.End()
+
+	query := `SELECT ` + validatorColumns + ` FROM validators WHERE archived_at IS NULL`
+	return s.queryValidators(ctx, query)
+}
+
+// List returns a page of the validators matching the filter, and the total
+// number of matching validators
+func (s *ValidatorStoreImpl) List(ctx context.Context, filter ValidatorFilter) ([]models.Validator, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "List", "validators")
+	defer span.End()
+
+	conditions := []string{"archived_at IS NULL"}
+	if filter.Archived {
+		conditions = []string{"archived_at IS NOT NULL"}
+	}
+	var args []interface{}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Search != "" {
+		addCondition(`name ILIKE '%%' || $%d || '%%' ESCAPE '\'`, escapeLike(filter.Search))
+	}
+	if filter.EnabledTracking != nil {
+		addCondition("enabled_tracking = $%d", *filter.EnabledTracking)
+	}
+	if filter.Tag != "" {
+		addCondition("$%d = ANY(tags)", filter.Tag)
+	}
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM validators`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error counting validators: %v", err)
+	}
+
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+	var orderBy string
+	switch filter.Sort {
+	case ValidatorSortCreatedAt:
+		orderBy = "created_at " + direction
+	case ValidatorSortStake:
+		orderBy = "CAST(tokens AS NUMERIC) " + direction + " NULLS LAST"
+	case ValidatorSortName:
+		orderBy = "LOWER(name) " + direction
+	default:
+		orderBy = "LOWER(name) " + direction
+		if filter.Archived {
+			orderBy = "archived_at DESC"
+		}
+	}
+
+	query := `SELECT ` + validatorColumns + ` FROM validators` + where + " ORDER BY " + orderBy + ", address"
+	if filter.Limit > 0 {
+		limit := filter.Limit
+		if limit > MaxValidatorLimit {
+			limit = MaxValidatorLimit
+		}
+		args = append(args, limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	args = append(args, filter.Offset)
+	query += fmt.Sprintf(" OFFSET $%d", len(args))
+	validators, err := s.queryValidators(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if validators == nil {
+		validators = []models.Validator{}
+	}
+	return validators, total, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so that it matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// queryValidators runs a query selecting validatorColumns
+func (s *ValidatorStoreImpl) queryValidators(ctx context.Context, query string, args ...interface{}) ([]models.Validator, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error querying validators: %v", err)
 	}
@@ -55,111 +241,323 @@ func (s *ValidatorStoreImpl) GetAll() ([]models.Validator, error) {
 
 	var validators []models.Validator
 	for rows.Next() {
-		var v models.Validator
-		if err := rows.Scan(&v.Address, &v.Name, &v.EnabledTracking); err != nil {
+		v, err := scanValidator(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error scanning validator row: %v", err)
 		}
-		validators = append(validators, v)
+		validators = append(validators, *v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating validator rows: %v", err)
 	}
 
 	return validators, nil
 }
 
-// GetByAddress returns a validator by its address
-func (s *ValidatorStoreImpl) GetByAddress(address string) (*models.Validator, error) {
+// GetByAddress returns a validator by its address, including archived validators
+func (s *ValidatorStoreImpl) GetByAddress(ctx context.Context, address string) (*models.Validator, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	query := `SELECT address, name, enabled_tracking FROM validators WHERE address = $1`
-	var v models.Validator
-	err := s.db.QueryRow(query, address).Scan(&v.Address, &v.Name, &v.EnabledTracking)
+	ctx, span := startSpan(ctx, "GetByAddress", "validators")
+	defer span.End()
+
+	query := `SELECT ` + validatorColumns + ` FROM validators WHERE address = $1`
+	v, err := scanValidator(s.db.QueryRowContext(ctx, query, address))
 	if err == sql.ErrNoRows {
 		return nil, ErrValidatorNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error querying validator: %v", err)
 	}
-	return &v, nil
+	return v, nil
 }
 
-// Add adds a new validator to the database
-func (s *ValidatorStoreImpl) Add(validator models.Validator) error {
+// GetByAddresses returns the validators with the given addresses, including
+// archived validators, ordered by address. Unknown addresses are left out.
+func (s *ValidatorStoreImpl) GetByAddresses(ctx context.Context, addresses []string) ([]models.Validator, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetByAddresses", "validators")
+	defer span.End()
+
+	query := `SELECT ` + validatorColumns + ` FROM validators WHERE address = ANY($1) ORDER BY address`
+	validators, err := s.queryValidators(ctx, query, pq.Array(addresses))
+	if err != nil {
+		return nil, err
+	}
+	if validators == nil {
+		validators = []models.Validator{}
+	}
+	return validators, nil
+}
+
+// Add adds a new validator to the database and records an audit event.
+// The chain metadata of the validator is stored as well when it is set.
+// ErrValidatorAlreadyExists is returned when the address is taken.
+func (s *ValidatorStoreImpl) Add(ctx context.Context, validator models.Validator) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	query := `
-		INSERT INTO validators (address, name, enabled_tracking)
-		VALUES ($1, $2, $3)
-	`
-	_, err := s.db.Exec(query, validator.Address, validator.Name, validator.EnabledTracking)
+	ctx, span := startSpan(ctx, "Add", "validators")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("error inserting validator: %v", err)
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := insertValidator(ctx, tx, validator); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
 	}
 	return nil
 }
 
-// Update updates an existing validator in the database
-func (s *ValidatorStoreImpl) Update(address string, validator models.Validator) error {
+// Update updates an existing validator in the database and records an audit
+// event holding the previous and new state. When expectedUpdatedAt is set the
+// update only succeeds if the validator has not changed since that time,
+// otherwise ErrValidatorModified is returned.
+func (s *ValidatorStoreImpl) Update(ctx context.Context, address string, validator models.Validator, expectedUpdatedAt *time.Time) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	ctx, span := startSpan(ctx, "Update", "validators")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	before, err := lockValidator(ctx, tx, address)
+	if err != nil {
+		return err
+	}
+	if before.ArchivedAt != nil {
+		return ErrValidatorArchived
+	}
+	if expectedUpdatedAt != nil && !before.UpdatedAt.Equal(*expectedUpdatedAt) {
+		return ErrValidatorModified
+	}
+
+	if err := updateValidator(ctx, tx, before, validator); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	return nil
+}
+
+// Delete permanently removes a validator, including its delegation history,
+// and records an audit event holding its last state. Use Archive to keep the
+// history.
+func (s *ValidatorStoreImpl) Delete(ctx context.Context, address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Delete", "validators")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	before, err := lockValidator(ctx, tx, address)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM validators WHERE address = $1`, address); err != nil {
+		return fmt.Errorf("error deleting validator: %v", err)
+	}
+
+	if err := recordAuditEvent(ctx, tx, models.AuditEntityValidator, address, models.AuditActionDelete, before, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	return nil
+}
+
+// insertValidator inserts a validator within tx and records an audit event.
+// It returns ErrValidatorAlreadyExists when the address is taken, also when
+// the validator was created concurrently.
+func insertValidator(ctx context.Context, tx *sql.Tx, validator models.Validator) (*models.Validator, error) {
+	query := `
+		INSERT INTO validators (address, name, enabled_tracking)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (address) DO NOTHING
+		RETURNING ` + validatorColumns
+	args := []interface{}{validator.Address, validator.Name, validator.EnabledTracking}
+	if m := validator.Metadata; m != nil {
+		query = `
+			INSERT INTO validators (address, name, enabled_tracking,
+				moniker, website, commission_rate, bond_status, jailed, tokens, metadata_updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP)
+			ON CONFLICT (address) DO NOTHING
+			RETURNING ` + validatorColumns
+		args = append(args, m.Moniker, m.Website, m.CommissionRate, m.Status, m.Jailed, m.Tokens)
+	}
+
+	created, err := scanValidator(tx.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, ErrValidatorAlreadyExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error inserting validator: %v", err)
+	}
+
+	if err := recordAuditEvent(ctx, tx, models.AuditEntityValidator, validator.Address, models.AuditActionCreate, nil, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// updateValidator sets the name and tracking flag of a validator locked
+// within tx and records an audit event
+func updateValidator(ctx context.Context, tx *sql.Tx, before *models.Validator, validator models.Validator) error {
 	query := `
 		UPDATE validators
 		SET name = $1, enabled_tracking = $2, updated_at = CURRENT_TIMESTAMP
 		WHERE address = $3
-	`
-	result, err := s.db.Exec(query, validator.Name, validator.EnabledTracking, address)
+		RETURNING ` + validatorColumns
+	after, err := scanValidator(tx.QueryRowContext(ctx, query, validator.Name, validator.EnabledTracking, before.Address))
 	if err != nil {
 		return fmt.Errorf("error updating validator: %v", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return recordAuditEvent(ctx, tx, models.AuditEntityValidator, before.Address, models.AuditActionUpdate, before, after)
+}
+
+// lockValidator reads a validator within tx and locks its row until the
+// transaction ends
+func lockValidator(ctx context.Context, tx *sql.Tx, address string) (*models.Validator, error) {
+	query := `SELECT ` + validatorColumns + ` FROM validators WHERE address = $1 FOR UPDATE`
+	v, err := scanValidator(tx.QueryRowContext(ctx, query, address))
+	if err == sql.ErrNoRows {
+		return nil, ErrValidatorNotFound
+	}
 	if err != nil {
-		return fmt.Errorf("error getting rows affected: %v", err)
+		return nil, fmt.Errorf("error querying validator: %v", err)
 	}
-	if rowsAffected == 0 {
-		return ErrValidatorNotFound
+	return v, nil
+}
+
+// scanValidator scans a row selected with validatorColumns
+func scanValidator(row rowScanner) (*models.Validator, error) {
+	var v models.Validator
+	var archivedAt, metadataUpdatedAt sql.NullTime
+	var moniker, website, commissionRate, status, tokens sql.NullString
+	var jailed sql.NullBool
+	if err := row.Scan(&v.Address, &v.Name, &v.EnabledTracking, &archivedAt, &v.CreatedAt, &v.UpdatedAt,
+		&moniker, &website, &commissionRate, &status, &jailed, &tokens, &metadataUpdatedAt, pq.Array(&v.Tags)); err != nil {
+		return nil, err
+	}
+	if archivedAt.Valid {
+		v.ArchivedAt = &archivedAt.Time
 	}
+	if metadataUpdatedAt.Valid {
+		v.Metadata = &models.ValidatorMetadata{
+			Moniker:        moniker.String,
+			Website:        website.String,
+			CommissionRate: commissionRate.String,
+			Status:         status.String,
+			Jailed:         jailed.Bool,
+			Tokens:         tokens.String,
+			UpdatedAt:      metadataUpdatedAt.Time,
+		}
+	}
+	return &v, nil
+}
 
-	return nil
+// Archive hides a validator from lists and stops syncing it while keeping
+// its delegation history, and records an audit event
+func (s *ValidatorStoreImpl) Archive(ctx context.Context, address string) error {
+	return s.setArchived(ctx, "Archive", address, true)
 }
 
-// Delete removes a validator from the database
-func (s *ValidatorStoreImpl) Delete(address string) error {
+// Restore brings back an archived validator, and records an audit event.
+// Tracking resumes with the enabledTracking value it had when archived.
+func (s *ValidatorStoreImpl) Restore(ctx context.Context, address string) error {
+	return s.setArchived(ctx, "Restore", address, false)
+}
+
+// setArchived archives or restores a validator within a transaction
+func (s *ValidatorStoreImpl) setArchived(ctx context.Context, operation, address string, archive bool) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	query := `DELETE FROM validators WHERE address = $1`
-	result, err := s.db.Exec(query, address)
+	ctx, span := startSpan(ctx, operation, "validators")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("error deleting validator: %v", err)
+		return fmt.Errorf("error starting transaction: %v", err)
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
+	before, err := lockValidator(ctx, tx, address)
 	if err != nil {
-		return fmt.Errorf("error getting rows affected: %v", err)
+		return err
 	}
-	if rowsAffected == 0 {
-		return ErrValidatorNotFound
+
+	query := `UPDATE validators SET archived_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE address = $1 RETURNING ` + validatorColumns
+	action := models.AuditActionRestore
+	if archive {
+		if before.ArchivedAt != nil {
+			return ErrValidatorArchived
+		}
+		query = `UPDATE validators SET archived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE address = $1 RETURNING ` + validatorColumns
+		action = models.AuditActionArchive
+	} else if before.ArchivedAt == nil {
+		return ErrValidatorNotArchived
+	}
+
+	after, err := scanValidator(tx.QueryRowContext(ctx, query, address))
+	if err != nil {
+		return fmt.Errorf("error updating validator archive state: %v", err)
+	}
+
+	if err := recordAuditEvent(ctx, tx, models.AuditEntityValidator, address, action, before, after); err != nil {
+		return err
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
 	return nil
 }
 
 // GetEnabledValidators returns a list of validator addresses that have enabled tracking
-func (s *ValidatorStoreImpl) GetEnabledValidators() ([]string, error) {
+func (s *ValidatorStoreImpl) GetEnabledValidators(ctx context.Context) ([]string, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	ctx, span := startSpan(ctx, "GetEnabledValidators", "validators")
+	defer span.End()
+
 	query := `
-		SELECT address 
-		FROM validators 
-		WHERE enabled_tracking = true
+		SELECT address
+		FROM validators
+		WHERE enabled_tracking = true AND archived_at IS NULL
 	`
 
-	rows, err := s.db.Query(query)
+	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
-		log.Printf("[ERROR] Failed to query enabled validators: %v", err)
 		return nil, fmt.Errorf("error querying enabled validators: %v", err)
 	}
 	defer rows.Close()
@@ -168,12 +566,468 @@ func (s *ValidatorStoreImpl) GetEnabledValidators() ([]string, error) {
 	for rows.Next() {
 		var address string
 		if err := rows.Scan(&address); err != nil {
-			log.Printf("[ERROR] Failed to scan validator row: %v", err)
 			return nil, fmt.Errorf("error scanning validator row: %v", err)
 		}
 		addresses = append(addresses, address)
 	}
 
-	log.Printf("[DEBUG] Found %d enabled validators", len(addresses))
 	return addresses, nil
-} 
\ No newline at end of file
+}
+
+// GetSyncStatuses returns the delegation sync state of every validator that is not archived
+func (s *ValidatorStoreImpl) GetSyncStatuses(ctx context.Context) ([]models.ValidatorSyncStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetSyncStatuses", "validators")
+	defer span.End()
+
+	query := `
+		SELECT address, enabled_tracking, created_at, last_synced_at, last_sync_attempt_at, COALESCE(last_sync_error, '')
+		FROM validators
+		WHERE archived_at IS NULL
+		ORDER BY address
+	`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying validator sync statuses: %v", err)
+	}
+	defer rows.Close()
+
+	var statuses []models.ValidatorSyncStatus
+	for rows.Next() {
+		var status models.ValidatorSyncStatus
+		var lastSyncedAt, lastSyncAttemptAt sql.NullTime
+		if err := rows.Scan(&status.Address, &status.EnabledTracking, &status.CreatedAt,
+			&lastSyncedAt, &lastSyncAttemptAt, &status.LastSyncError); err != nil {
+			return nil, fmt.Errorf("error scanning validator sync status: %v", err)
+		}
+		if lastSyncedAt.Valid {
+			status.LastSyncedAt = &lastSyncedAt.Time
+		}
+		if lastSyncAttemptAt.Valid {
+			status.LastSyncAttemptAt = &lastSyncAttemptAt.Time
+		}
+		statuses = append(statuses, status)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating validator sync statuses: %v", err)
+	}
+
+	return statuses, nil
+}
+
+// UpdateSyncStatus records the outcome of a delegation sync for a validator.
+// A nil syncErr marks the sync as successful.
+func (s *ValidatorStoreImpl) UpdateSyncStatus(ctx context.Context, address string, syncErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "UpdateSyncStatus", "validators")
+	defer span.End()
+
+	var err error
+	if syncErr == nil {
+		_, err = s.db.ExecContext(ctx, `
+			UPDATE validators
+			SET last_synced_at = CURRENT_TIMESTAMP, last_sync_attempt_at = CURRENT_TIMESTAMP, last_sync_error = NULL
+			WHERE address = $1
+		`, address)
+	} else {
+		_, err = s.db.ExecContext(ctx, `
+			UPDATE validators
+			SET last_sync_attempt_at = CURRENT_TIMESTAMP, last_sync_error = $1
+			WHERE address = $2
+		`, syncErr.Error(), address)
+	}
+	if err != nil {
+		return fmt.Errorf("error updating validator sync status: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateMetadata replaces the chain metadata of a validator. It is not an
+// update by a user, so no audit event is recorded and updated_at, and with it
+// the ETag, stays the same.
+func (s *ValidatorStoreImpl) UpdateMetadata(ctx context.Context, address string, metadata models.ValidatorMetadata) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "UpdateMetadata", "validators")
+	defer span.End()
+
+	query := `
+		UPDATE validators
+		SET moniker = $1, website = $2, commission_rate = $3, bond_status = $4, jailed = $5, tokens = $6,
+			metadata_updated_at = CURRENT_TIMESTAMP
+		WHERE address = $7
+	`
+	result, err := s.db.ExecContext(ctx, query, metadata.Moniker, metadata.Website, metadata.CommissionRate,
+		metadata.Status, metadata.Jailed, metadata.Tokens, address)
+	if err != nil {
+		return fmt.Errorf("error updating validator metadata: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrValidatorNotFound
+	}
+
+	return nil
+}
+
+// Import upserts validators within a single transaction. New validators are
+// inserted with their name, tracking flag and metadata. Existing validators
+// keep their name and get the tracking flag and metadata of the import;
+// archived validators are left alone. Creates and tracking changes record
+// audit events.
+func (s *ValidatorStoreImpl) Import(ctx context.Context, validators []models.Validator) (ImportResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Import", "validators")
+	defer span.End()
+
+	var result ImportResult
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, validator := range validators {
+		m := validator.Metadata
+		if m == nil {
+			m = &models.ValidatorMetadata{}
+		}
+
+		validator.Metadata = m
+		if _, err := insertValidator(ctx, tx, validator); err == nil {
+			result.Created++
+			continue
+		} else if err != ErrValidatorAlreadyExists {
+			return ImportResult{}, err
+		}
+
+		before, err := lockValidator(ctx, tx, validator.Address)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		if before.ArchivedAt != nil {
+			result.Archived++
+			continue
+		}
+
+		// Only a change of the tracking flag is an update by the import;
+		// refreshing the metadata alone keeps updated_at and the ETag
+		trackingChanged := before.EnabledTracking != validator.EnabledTracking
+		args := []interface{}{m.Moniker, m.Website, m.CommissionRate, m.Status, m.Jailed, m.Tokens, validator.Address}
+		setTracking := ""
+		if trackingChanged {
+			setTracking = ", enabled_tracking = $8, updated_at = CURRENT_TIMESTAMP"
+			args = append(args, validator.EnabledTracking)
+		}
+		updateQuery := `
+			UPDATE validators
+			SET moniker = $1, website = $2, commission_rate = $3, bond_status = $4, jailed = $5, tokens = $6,
+				metadata_updated_at = CURRENT_TIMESTAMP` + setTracking + `
+			WHERE address = $7
+			RETURNING ` + validatorColumns
+		after, err := scanValidator(tx.QueryRowContext(ctx, updateQuery, args...))
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("error updating validator %s: %v", validator.Address, err)
+		}
+
+		if !trackingChanged {
+			result.Unchanged++
+			continue
+		}
+		if err := recordAuditEvent(ctx, tx, models.AuditEntityValidator, validator.Address, models.AuditActionUpdate, before, after); err != nil {
+			return ImportResult{}, err
+		}
+		result.Updated++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ImportResult{}, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return result, nil
+}
+
+// Upsert creates or updates validators within a single transaction and
+// returns the status of each. Existing validators get the name and tracking
+// flag of the upsert; archived validators are a conflict and left alone.
+// When atomic is set and any validator conflicts, nothing is written and
+// ErrBatchConflict is returned along with the statuses.
+func (s *ValidatorStoreImpl) Upsert(ctx context.Context, validators []models.Validator, atomic bool) ([]UpsertStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Upsert", "validators")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	statuses := make([]UpsertStatus, len(validators))
+	conflicts := 0
+	for i, validator := range validators {
+		validator.Metadata = nil
+		if _, err := insertValidator(ctx, tx, validator); err == nil {
+			statuses[i] = UpsertCreated
+			continue
+		} else if err != ErrValidatorAlreadyExists {
+			return nil, err
+		}
+
+		before, err := lockValidator(ctx, tx, validator.Address)
+		if err != nil {
+			return nil, err
+		}
+		if before.ArchivedAt != nil {
+			statuses[i] = UpsertConflict
+			conflicts++
+			continue
+		}
+
+		if err := updateValidator(ctx, tx, before, validator); err != nil {
+			return nil, err
+		}
+		statuses[i] = UpsertUpdated
+	}
+
+	if atomic && conflicts > 0 {
+		return statuses, ErrBatchConflict
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return statuses, nil
+}
+
+// ListTags returns the tags of validators that are not archived, with the
+// number of validators carrying each
+func (s *ValidatorStoreImpl) ListTags(ctx context.Context) ([]models.TagCount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "ListTags", "validators")
+	defer span.End()
+
+	query := `
+		SELECT tag, COUNT(*)
+		FROM validators, unnest(tags) AS tag
+		WHERE archived_at IS NULL
+		GROUP BY tag
+		ORDER BY tag
+	`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tags: %v", err)
+	}
+	defer rows.Close()
+
+	tags := []models.TagCount{}
+	for rows.Next() {
+		var tag models.TagCount
+		if err := rows.Scan(&tag.Tag, &tag.Validators); err != nil {
+			return nil, fmt.Errorf("error scanning tag row: %v", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tag rows: %v", err)
+	}
+
+	return tags, nil
+}
+
+// SetTags replaces the tags of a validator and records an audit event
+func (s *ValidatorStoreImpl) SetTags(ctx context.Context, address string, tags []string) error {
+	return s.changeTags(ctx, "SetTags", address, func([]string) ([]string, error) {
+		return tags, nil
+	})
+}
+
+// AddTag adds a tag to a validator and records an audit event. Adding a tag
+// the validator already carries changes nothing.
+func (s *ValidatorStoreImpl) AddTag(ctx context.Context, address, tag string) error {
+	return s.changeTags(ctx, "AddTag", address, func(current []string) ([]string, error) {
+		return append(current, tag), nil
+	})
+}
+
+// RemoveTag removes a tag from a validator and records an audit event.
+// ErrTagNotFound is returned when the validator does not carry the tag.
+func (s *ValidatorStoreImpl) RemoveTag(ctx context.Context, address, tag string) error {
+	return s.changeTags(ctx, "RemoveTag", address, func(current []string) ([]string, error) {
+		remaining := withoutTag(current, tag)
+		if len(remaining) == len(current) {
+			return nil, ErrTagNotFound
+		}
+		return remaining, nil
+	})
+}
+
+// changeTags applies change to the tags of a validator within a transaction.
+// Archived validators cannot be changed.
+func (s *ValidatorStoreImpl) changeTags(ctx context.Context, operation, address string, change func([]string) ([]string, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, operation, "validators")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	before, err := lockValidator(ctx, tx, address)
+	if err != nil {
+		return err
+	}
+	if before.ArchivedAt != nil {
+		return ErrValidatorArchived
+	}
+
+	tags, err := change(append([]string(nil), before.Tags...))
+	if err != nil {
+		return err
+	}
+	if err := updateTags(ctx, tx, before, tags); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	return nil
+}
+
+// RenameTag renames a tag on every validator carrying it, archived ones
+// included, and returns the number of validators changed. Validators that
+// already carry newTag keep it once. ErrTagNotFound is returned when no
+// validator carries the tag.
+func (s *ValidatorStoreImpl) RenameTag(ctx context.Context, tag, newTag string) (int, error) {
+	return s.retag(ctx, "RenameTag", tag, func(current []string) []string {
+		return append(withoutTag(current, tag), newTag)
+	})
+}
+
+// DeleteTag removes a tag from every validator carrying it, archived ones
+// included, and returns the number of validators changed. ErrTagNotFound is
+// returned when no validator carries the tag.
+func (s *ValidatorStoreImpl) DeleteTag(ctx context.Context, tag string) (int, error) {
+	return s.retag(ctx, "DeleteTag", tag, func(current []string) []string {
+		return withoutTag(current, tag)
+	})
+}
+
+// retag applies change to the tags of every validator carrying tag within a
+// single transaction, recording an audit event for each
+func (s *ValidatorStoreImpl) retag(ctx context.Context, operation, tag string, change func([]string) []string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, operation, "validators")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + validatorColumns + ` FROM validators WHERE $1 = ANY(tags) ORDER BY address FOR UPDATE`
+	rows, err := tx.QueryContext(ctx, query, tag)
+	if err != nil {
+		return 0, fmt.Errorf("error querying validators: %v", err)
+	}
+	var tagged []*models.Validator
+	for rows.Next() {
+		v, err := scanValidator(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error scanning validator row: %v", err)
+		}
+		tagged = append(tagged, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating validator rows: %v", err)
+	}
+	if len(tagged) == 0 {
+		return 0, ErrTagNotFound
+	}
+
+	for _, before := range tagged {
+		if err := updateTags(ctx, tx, before, change(append([]string(nil), before.Tags...))); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return len(tagged), nil
+}
+
+// updateTags stores the sorted, deduplicated tags of a validator locked
+// within tx and records an audit event. Nothing is written when the tags are
+// unchanged.
+func updateTags(ctx context.Context, tx *sql.Tx, before *models.Validator, tags []string) error {
+	tags = sortedTags(tags)
+	if strings.Join(tags, ",") == strings.Join(sortedTags(before.Tags), ",") {
+		return nil
+	}
+
+	query := `
+		UPDATE validators
+		SET tags = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE address = $2
+		RETURNING ` + validatorColumns
+	after, err := scanValidator(tx.QueryRowContext(ctx, query, pq.Array(tags), before.Address))
+	if err != nil {
+		return fmt.Errorf("error updating validator tags: %v", err)
+	}
+
+	return recordAuditEvent(ctx, tx, models.AuditEntityValidator, before.Address, models.AuditActionUpdate, before, after)
+}
+
+// sortedTags returns the tags sorted and without duplicates, never nil so
+// that it is stored as an empty array
+func sortedTags(tags []string) []string {
+	sorted := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			sorted = append(sorted, tag)
+		}
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// withoutTag returns the tags other than tag
+func withoutTag(tags []string, tag string) []string {
+	var remaining []string
+	for _, t := range tags {
+		if t != tag {
+			remaining = append(remaining, t)
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,576 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+var (
+	// ErrWebhookNotFound is returned when a webhook is not found
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	// ErrWebhookDeliveryNotFound is returned when a webhook delivery is not found
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+const (
+	// DefaultWebhookDeliveryLimit is the number of deliveries returned when no limit is given
+	DefaultWebhookDeliveryLimit = 100
+
+	// MaxWebhookDeliveryLimit is the largest number of deliveries returned at once
+	MaxWebhookDeliveryLimit = 1000
+)
+
+// WebhookStore defines the interface for webhook and delivery queue storage
+type WebhookStore interface {
+	// GetAll returns all webhooks
+	GetAll(ctx context.Context) ([]models.Webhook, error)
+
+	// GetByID returns the webhook with the given ID, including its secret
+	GetByID(ctx context.Context, id int) (*models.Webhook, error)
+
+	// Add stores a new webhook with its secret
+	Add(ctx context.Context, webhook models.Webhook) (*models.Webhook, error)
+
+	// Update replaces the URL, description, event types and enabled flag of a webhook
+	Update(ctx context.Context, webhook models.Webhook) (*models.Webhook, error)
+
+	// Delete removes a webhook and its deliveries
+	Delete(ctx context.Context, id int) error
+
+	// Enqueue queues a delivery of every event to each enabled webhook
+	// subscribed to its type and returns the number of deliveries queued
+	Enqueue(ctx context.Context, events []models.Event) (int, error)
+
+	// EnqueueTo queues a delivery of an event to one webhook, whatever its
+	// event types and enabled flag. The delivery is leased to the caller:
+	// workers do not claim it until lease has passed.
+	EnqueueTo(ctx context.Context, webhookID int, event models.Event, lease time.Duration) (*models.WebhookDelivery, error)
+
+	// ClaimDue returns up to limit pending deliveries of enabled webhooks
+	// that are due, and postpones them by lease so that no other worker
+	// claims them while they are attempted
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]WebhookJob, error)
+
+	// CompleteAttempt logs an attempt of a delivery and moves the delivery to
+	// status, to be attempted again at nextAttemptAt if it is still pending
+	CompleteAttempt(ctx context.Context, deliveryID int64, attempt models.WebhookDeliveryAttempt,
+		status models.WebhookDeliveryStatus, nextAttemptAt time.Time) error
+
+	// ListDeliveries returns the deliveries matching the filter, newest first
+	ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]models.WebhookDelivery, error)
+
+	// GetDelivery returns a delivery of a webhook with its attempt log
+	GetDelivery(ctx context.Context, webhookID int, deliveryID int64) (*models.WebhookDelivery, error)
+}
+
+// WebhookJob is a claimed delivery with the endpoint it goes to
+type WebhookJob struct {
+	Delivery models.WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// WebhookDeliveryFilter narrows down the deliveries returned by
+// WebhookStore.ListDeliveries. Empty fields are ignored.
+type WebhookDeliveryFilter struct {
+	WebhookID int
+	Status    models.WebhookDeliveryStatus
+	Limit     int
+	Offset    int
+}
+
+// WebhookStoreImpl implements WebhookStore with PostgreSQL storage
+type WebhookStoreImpl struct {
+	db *sql.DB
+	mu sync.RWMutex
+}
+
+// NewWebhookStore creates a new instance of WebhookStoreImpl
+func NewWebhookStore(db *sql.DB) *WebhookStoreImpl {
+	return &WebhookStoreImpl{
+		db: db,
+	}
+}
+
+const (
+	// webhookColumns lists the columns scanned by scanWebhook
+	webhookColumns = `id, url, description, event_types, secret, enabled, created_at, updated_at`
+
+	// webhookDeliveryColumns lists the columns scanned by scanWebhookDelivery
+	webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload::TEXT, status, attempts, next_attempt_at,
+			last_attempt_at, last_status_code, last_error, delivered_at, created_at`
+)
+
+// GetAll returns all webhooks
+func (s *WebhookStoreImpl) GetAll(ctx context.Context) ([]models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetAll", "webhooks")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying webhooks: %v", err)
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook row: %v", err)
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook rows: %v", err)
+	}
+
+	return webhooks, nil
+}
+
+// GetByID returns the webhook with the given ID, including its secret
+func (s *WebhookStoreImpl) GetByID(ctx context.Context, id int) (*models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetByID", "webhooks")
+	defer span.End()
+
+	webhook, err := scanWebhook(s.db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying webhook: %v", err)
+	}
+	return webhook, nil
+}
+
+// Add stores a new webhook with its secret
+func (s *WebhookStoreImpl) Add(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Add", "webhooks")
+	defer span.End()
+
+	query := `
+		INSERT INTO webhooks (url, description, event_types, secret, enabled)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + webhookColumns
+	created, err := scanWebhook(s.db.QueryRowContext(ctx, query, webhook.URL, webhook.Description,
+		pq.Array(eventTypeStrings(webhook.EventTypes)), webhook.Secret, webhook.Enabled))
+	if err != nil {
+		return nil, fmt.Errorf("error inserting webhook: %v", err)
+	}
+	return created, nil
+}
+
+// Update replaces the URL, description, event types and enabled flag of a webhook
+func (s *WebhookStoreImpl) Update(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Update", "webhooks")
+	defer span.End()
+
+	query := `
+		UPDATE webhooks
+		SET url = $1, description = $2, event_types = $3, enabled = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+		RETURNING ` + webhookColumns
+	updated, err := scanWebhook(s.db.QueryRowContext(ctx, query, webhook.URL, webhook.Description,
+		pq.Array(eventTypeStrings(webhook.EventTypes)), webhook.Enabled, webhook.ID))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error updating webhook: %v", err)
+	}
+	return updated, nil
+}
+
+// Delete removes a webhook and its deliveries
+func (s *WebhookStoreImpl) Delete(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Delete", "webhooks")
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting webhook: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// Enqueue queues a delivery of every event to each enabled webhook subscribed
+// to its type and returns the number of deliveries queued. An event already
+// queued for a webhook is not queued again.
+func (s *WebhookStoreImpl) Enqueue(ctx context.Context, events []models.Event) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "Enqueue", "webhook_deliveries")
+	defer span.End()
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT id, $1, $2, CAST($3 AS JSONB)
+		FROM webhooks
+		WHERE enabled AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+		ON CONFLICT (webhook_id, event_id) DO NOTHING
+	`)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("error preparing statement: %v", err)
+	}
+	defer stmt.Close()
+
+	queued := 0
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("error encoding event %s: %v", event.ID, err)
+		}
+		result, err := stmt.ExecContext(ctx, event.ID, string(event.Type), string(payload))
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("error queuing webhook deliveries for event %s: %v", event.ID, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("error getting rows affected: %v", err)
+		}
+		queued += int(rowsAffected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return queued, nil
+}
+
+// EnqueueTo queues a delivery of an event to one webhook, whatever its event
+// types and enabled flag, leased to the caller for lease
+func (s *WebhookStoreImpl) EnqueueTo(ctx context.Context, webhookID int, event models.Event, lease time.Duration) (*models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, span := startSpan(ctx, "EnqueueTo", "webhook_deliveries")
+	defer span.End()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding event %s: %v", event.ID, err)
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, next_attempt_at)
+		SELECT id, $2, $3, CAST($4 AS JSONB), CURRENT_TIMESTAMP + make_interval(secs => $5)
+		FROM webhooks
+		WHERE id = $1
+		RETURNING ` + webhookDeliveryColumns
+	delivery, err := scanWebhookDelivery(s.db.QueryRowContext(ctx, query, webhookID, event.ID, string(event.Type),
+		string(payload), lease.Seconds()))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error queuing webhook delivery: %v", err)
+	}
+	return delivery, nil
+}
+
+// ClaimDue returns up to limit pending deliveries of enabled webhooks that are
+// due, oldest first, and postpones them by lease. Rows locked by another
+// worker are skipped.
+func (s *WebhookStoreImpl) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]WebhookJob, error) {
+	ctx, span := startSpan(ctx, "ClaimDue", "webhook_deliveries")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT d.id, d.webhook_id, d.event_id, d.event_type, d.payload::TEXT, d.attempts, d.created_at, w.url, w.secret
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP AND w.enabled
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT $1
+		FOR UPDATE OF d SKIP LOCKED
+	`, limit)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error querying due webhook deliveries: %v", err)
+	}
+
+	var jobs []WebhookJob
+	var ids []int64
+	for rows.Next() {
+		var job WebhookJob
+		var payload string
+		if err := rows.Scan(&job.Delivery.ID, &job.Delivery.WebhookID, &job.Delivery.EventID, &job.Delivery.EventType,
+			&payload, &job.Delivery.Attempts, &job.Delivery.CreatedAt, &job.URL, &job.Secret); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, fmt.Errorf("error scanning webhook delivery row: %v", err)
+		}
+		job.Delivery.Payload = json.RawMessage(payload)
+		job.Delivery.Status = models.WebhookDeliveryPending
+		jobs = append(jobs, job)
+		ids = append(ids, job.Delivery.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error iterating webhook delivery rows: %v", err)
+	}
+
+	if len(ids) > 0 {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $1)
+			WHERE id = ANY($2)
+		`, lease.Seconds(), pq.Array(ids))
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error leasing webhook deliveries: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return jobs, nil
+}
+
+// CompleteAttempt logs an attempt of a delivery and moves the delivery to
+// status, to be attempted again at nextAttemptAt if it is still pending
+func (s *WebhookStoreImpl) CompleteAttempt(ctx context.Context, deliveryID int64, attempt models.WebhookDeliveryAttempt,
+	status models.WebhookDeliveryStatus, nextAttemptAt time.Time) error {
+	ctx, span := startSpan(ctx, "CompleteAttempt", "webhook_deliveries")
+	defer span.End()
+
+	statusCode := sql.NullInt64{Int64: int64(attempt.StatusCode), Valid: attempt.StatusCode != 0}
+	attemptError := nullString(attempt.Error)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, deliveryID, attempt.Attempt, statusCode, attemptError, attempt.DurationMs, attempt.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error recording webhook delivery attempt: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, last_attempt_at = $3, last_status_code = $4, last_error = $5, next_attempt_at = $6,
+			delivered_at = CASE WHEN $1 = 'delivered' THEN $3 ELSE NULL END
+		WHERE id = $7
+	`, string(status), attempt.Attempt, attempt.CreatedAt, statusCode, attemptError, nextAttemptAt, deliveryID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error updating webhook delivery: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the deliveries matching the filter, newest first
+func (s *WebhookStoreImpl) ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "ListDeliveries", "webhook_deliveries")
+	defer span.End()
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.WebhookID > 0 {
+		addCondition("webhook_id = $%d", filter.WebhookID)
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", string(filter.Status))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultWebhookDeliveryLimit
+	}
+	if limit > MaxWebhookDeliveryLimit {
+		limit = MaxWebhookDeliveryLimit
+	}
+
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries`
+	if len(conditions) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit, filter.Offset)
+	query += fmt.Sprintf("\n\t\tORDER BY created_at DESC, id DESC\n\t\tLIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying webhook deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook delivery row: %v", err)
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook delivery rows: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// GetDelivery returns a delivery of a webhook with its attempt log
+func (s *WebhookStoreImpl) GetDelivery(ctx context.Context, webhookID int, deliveryID int64) (*models.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := startSpan(ctx, "GetDelivery", "webhook_deliveries")
+	defer span.End()
+
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2`
+	delivery, err := scanWebhookDelivery(s.db.QueryRowContext(ctx, query, deliveryID, webhookID))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying webhook delivery: %v", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT attempt, COALESCE(status_code, 0), COALESCE(error, ''), duration_ms, created_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY attempt
+	`, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying webhook delivery attempts: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attempt models.WebhookDeliveryAttempt
+		if err := rows.Scan(&attempt.Attempt, &attempt.StatusCode, &attempt.Error, &attempt.DurationMs, &attempt.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning webhook delivery attempt row: %v", err)
+		}
+		delivery.AttemptLog = append(delivery.AttemptLog, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook delivery attempt rows: %v", err)
+	}
+
+	return delivery, nil
+}
+
+// scanWebhook scans a row selected with webhookColumns
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var webhook models.Webhook
+	var eventTypes []string
+	if err := row.Scan(&webhook.ID, &webhook.URL, &webhook.Description, pq.Array(&eventTypes), &webhook.Secret,
+		&webhook.Enabled, &webhook.CreatedAt, &webhook.UpdatedAt); err != nil {
+		return nil, err
+	}
+	webhook.EventTypes = make([]models.EventType, len(eventTypes))
+	for i, eventType := range eventTypes {
+		webhook.EventTypes[i] = models.EventType(eventType)
+	}
+	return &webhook, nil
+}
+
+// scanWebhookDelivery scans a row selected with webhookDeliveryColumns
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var payload string
+	var lastAttemptAt, deliveredAt sql.NullTime
+	var lastStatusCode sql.NullInt64
+	var lastError sql.NullString
+	if err := row.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &payload, &delivery.Status,
+		&delivery.Attempts, &delivery.NextAttemptAt, &lastAttemptAt, &lastStatusCode, &lastError, &deliveredAt,
+		&delivery.CreatedAt); err != nil {
+		return nil, err
+	}
+	delivery.Payload = json.RawMessage(payload)
+	if lastAttemptAt.Valid {
+		delivery.LastAttemptAt = &lastAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	delivery.LastStatusCode = int(lastStatusCode.Int64)
+	delivery.LastError = lastError.String
+	return &delivery, nil
+}
+
+// eventTypeStrings converts event types for storage in a TEXT[] column
+func eventTypeStrings(eventTypes []models.EventType) []string {
+	values := make([]string, len(eventTypes))
+	for i, eventType := range eventTypes {
+		values[i] = string(eventType)
+	}
+	return values
+}
@@ -0,0 +1,168 @@
+// Package stream fans out the delegation changes committed by the delegation
+// sync to live subscribers, such as Server-Sent Events clients
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+// DefaultBufferSize is the default number of delegation changes buffered per
+// subscriber
+const DefaultBufferSize = 256
+
+var (
+	// ErrLagged ends a subscription whose buffer overflowed because its
+	// subscriber did not keep up. The subscriber can resume from the last
+	// event it handled using the stored delegation events.
+	ErrLagged = errors.New("subscriber fell behind the delegation change feed")
+
+	// ErrClosed ends the subscriptions of a closed broker
+	ErrClosed = errors.New("delegation change feed closed")
+)
+
+// MatchFunc reports whether a subscriber wants a delegation change. It is
+// called while the broker publishes, so it must be fast and must not block.
+type MatchFunc func(event models.DelegationEvent) bool
+
+// Broker receives the delegation changes published by the delegation sync and
+// hands them to its subscribers. Publishing never blocks: a subscriber whose
+// buffer is full is dropped with ErrLagged.
+type Broker struct {
+	bufferSize int
+
+	mu            sync.Mutex
+	subscriptions map[*Subscription]struct{}
+	closed        bool
+}
+
+// NewBroker creates a new broker buffering up to bufferSize delegation
+// changes per subscriber. DefaultBufferSize is used when bufferSize is not
+// positive.
+func NewBroker(bufferSize int) *Broker {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Broker{
+		bufferSize:    bufferSize,
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives the delegation changes matched by its MatchFunc, in
+// the order they were published
+type Subscription struct {
+	broker *Broker
+	match  MatchFunc
+	events chan models.DelegationEvent
+	// err is set before events is closed
+	err error
+}
+
+// Subscribe registers a new subscriber. A nil match receives every
+// delegation change. The subscription must be closed when it is no longer
+// used.
+func (b *Broker) Subscribe(match MatchFunc) *Subscription {
+	subscription := &Subscription{
+		broker: b,
+		match:  match,
+		events: make(chan models.DelegationEvent, b.bufferSize),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		subscription.err = ErrClosed
+		close(subscription.events)
+		return subscription
+	}
+	b.subscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+// Events returns the delegation changes of the subscription. The channel is
+// closed when the subscription ends; Err then tells why.
+func (s *Subscription) Events() <-chan models.DelegationEvent {
+	return s.events
+}
+
+// Err returns ErrLagged or ErrClosed once the broker ended the subscription,
+// and nil while it is active or after Close
+func (s *Subscription) Err() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.err
+}
+
+// Close ends the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s, nil)
+}
+
+// Publish implements events.Publisher. Only delegation.changed events are
+// handed to subscribers.
+func (b *Broker) Publish(ctx context.Context, events []models.Event) error {
+	changes := make([]models.DelegationEvent, 0, len(events))
+	for _, event := range events {
+		if event.Type != models.EventDelegationChanged {
+			continue
+		}
+		var change models.DelegationEvent
+		if err := json.Unmarshal(event.Data, &change); err != nil {
+			return fmt.Errorf("error decoding delegation change %s: %v", event.ID, err)
+		}
+		changes = append(changes, change)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for subscription := range b.subscriptions {
+		for _, change := range changes {
+			if subscription.match != nil && !subscription.match(change) {
+				continue
+			}
+			select {
+			case subscription.events <- change:
+			default:
+				metrics.StreamSubscribersLaggedTotal.Inc()
+				b.remove(subscription, ErrLagged)
+			}
+			if subscription.err != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Close ends every subscription with ErrClosed and rejects new ones. Call it
+// before shutting down the HTTP server so that streaming responses finish.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for subscription := range b.subscriptions {
+		b.remove(subscription, ErrClosed)
+	}
+}
+
+// remove ends an active subscription with err. The caller holds b.mu.
+func (b *Broker) remove(subscription *Subscription, err error) {
+	if _, ok := b.subscriptions[subscription]; !ok {
+		return
+	}
+	delete(b.subscriptions, subscription)
+	subscription.err = err
+	close(subscription.events)
+}
@@ -0,0 +1,57 @@
+package stream
+
+import (
+	"math/big"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+)
+
+// Filter selects delegation changes by validator, delegator and size. A
+// change matches when every set field matches it; an empty filter matches
+// every change.
+type Filter struct {
+	Validators map[string]bool
+	Delegators map[string]bool
+	// MinDelta matches changes whose absolute delta is at least this value
+	MinDelta *big.Rat
+}
+
+// NewFilter creates a filter of the given validator and delegator addresses
+// and minimum absolute delta. Nil and empty values match every change.
+func NewFilter(validators, delegators []string, minDelta *big.Rat) Filter {
+	return Filter{
+		Validators: addressSet(validators),
+		Delegators: addressSet(delegators),
+		MinDelta:   minDelta,
+	}
+}
+
+// Matches reports whether the filter selects a delegation change. Changes
+// with an unparsable delta never match a minimum delta.
+func (f Filter) Matches(event models.DelegationEvent) bool {
+	if len(f.Validators) > 0 && !f.Validators[event.ValidatorAddress] {
+		return false
+	}
+	if len(f.Delegators) > 0 && !f.Delegators[event.DelegatorAddress] {
+		return false
+	}
+	if f.MinDelta != nil && f.MinDelta.Sign() > 0 {
+		delta, ok := new(big.Rat).SetString(event.Delta)
+		if !ok || delta.Abs(delta).Cmp(f.MinDelta) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// addressSet returns the set of addresses, or nil when there are none
+func addressSet(addresses []string) map[string]bool {
+	if len(addresses) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		set[address] = true
+	}
+	return set
+}
@@ -0,0 +1,94 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/email"
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/reports"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+// EmailDigestTask sends the email digests of validator tags
+type EmailDigestTask struct {
+	store     store.EmailDigestStore
+	generator *reports.Generator
+	sender    email.Sender
+}
+
+// NewEmailDigestTask creates a new email digest task
+func NewEmailDigestTask(digestStore store.EmailDigestStore, generator *reports.Generator, sender email.Sender) *EmailDigestTask {
+	return &EmailDigestTask{
+		store:     digestStore,
+		generator: generator,
+		sender:    sender,
+	}
+}
+
+// SendDigests sends every enabled digest of the frequency, covering the period
+// that ends now. A failed digest does not stop the others; every failure is
+// recorded on its digest and reported in the returned error.
+func (t *EmailDigestTask) SendDigests(ctx context.Context, frequency models.DigestFrequency) error {
+	digests, err := t.store.GetEnabled(ctx, frequency)
+	if err != nil {
+		return fmt.Errorf("error getting %s email digests: %v", frequency, err)
+	}
+
+	until := time.Now().UTC().Truncate(time.Minute)
+	var errs []error
+	for _, digest := range digests {
+		if err := t.send(ctx, digest, until); err != nil {
+			errs = append(errs, fmt.Errorf("error sending email digest %d of tag %q: %v", digest.ID, digest.Tag, err))
+		}
+	}
+
+	slog.InfoContext(ctx, "Sent email digests", "frequency", frequency, "digests", len(digests), "failed", len(errs))
+	return errors.Join(errs...)
+}
+
+// Send sends a digest right away, whatever its enabled flag, covering the
+// period of its frequency that ends now
+func (t *EmailDigestTask) Send(ctx context.Context, digestID int) error {
+	digest, err := t.store.GetByID(ctx, digestID)
+	if err != nil {
+		return err
+	}
+	return t.send(ctx, *digest, time.Now().UTC().Truncate(time.Minute))
+}
+
+// send generates, sends and records a digest
+func (t *EmailDigestTask) send(ctx context.Context, digest models.EmailDigest, until time.Time) error {
+	err := t.deliver(ctx, digest, until)
+
+	result := "sent"
+	if err != nil {
+		result = "failed"
+	}
+	metrics.EmailDigestsSentTotal.WithLabelValues(string(digest.Frequency), result).Inc()
+
+	if recordErr := t.store.RecordSend(ctx, digest.ID, time.Now().UTC(), err); recordErr != nil {
+		slog.ErrorContext(ctx, "Failed to record email digest send", "digest", digest.ID, "error", recordErr)
+	}
+	return err
+}
+
+// deliver generates the report of a digest and emails it to the recipients
+func (t *EmailDigestTask) deliver(ctx context.Context, digest models.EmailDigest, until time.Time) error {
+	report, err := t.generator.Generate(ctx, digest.Tag, digest.Frequency, until)
+	if err != nil {
+		return err
+	}
+
+	message, err := reports.Render(report)
+	if err != nil {
+		return fmt.Errorf("error rendering report: %v", err)
+	}
+	message.To = digest.Recipients
+
+	return t.sender.Send(ctx, message)
+}
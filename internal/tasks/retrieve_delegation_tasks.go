@@ -3,11 +3,19 @@ package tasks
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/logging"
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
 	"github.com/novintriantonius/cosmos-validator-service/internal/services"
 	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // DelegationSyncTask handles the periodic syncing of delegations for validators
@@ -15,6 +23,10 @@ type DelegationSyncTask struct {
 	validatorStore      store.ValidatorStore
 	delegationStore     store.DelegationStore
 	cosmosService       *services.CosmosService
+	relay               EventRelay
+	// syncFailures counts the failed syncs in a row per validator
+	syncFailures        map[string]int
+	mu                  sync.RWMutex
 	lastRunStats        SyncStats
 	totalDelegationsSynced int
 }
@@ -30,66 +42,195 @@ type SyncStats struct {
 	TotalDelegationsProcessed int
 }
 
-// NewDelegationSyncTask creates a new delegation sync task
+// EventRelay relays the events in the event outbox to the event consumers
+type EventRelay interface {
+	// Publish adds events to the outbox
+	events.Publisher
+	// Wake makes the relay hand over the outbox right away
+	Wake()
+}
+
+// NewDelegationSyncTask creates a new delegation sync task. The delegation
+// changes of a sync are added to the event outbox with the delegations;
+// relay is woken up to hand them over and receives the failed syncs. Failed
+// syncs are not published when relay is nil.
 func NewDelegationSyncTask(
 	validatorStore store.ValidatorStore,
 	delegationStore store.DelegationStore,
 	cosmosService *services.CosmosService,
+	relay EventRelay,
 ) *DelegationSyncTask {
 	return &DelegationSyncTask{
 		validatorStore:  validatorStore,
 		delegationStore: delegationStore,
 		cosmosService:   cosmosService,
+		relay:           relay,
+		syncFailures:    make(map[string]int),
 	}
 }
 
-// SyncEnabledValidatorDelegations syncs delegations for all enabled validators
+// SyncEnabledValidatorDelegations syncs delegations for all enabled validators.
+// Every log line emitted during the run carries the same sync run ID.
 func (t *DelegationSyncTask) SyncEnabledValidatorDelegations(ctx context.Context) error {
-	log.Printf("[DEBUG] Starting SyncEnabledValidatorDelegations")
+	runID := logging.NewID()
+	ctx = logging.WithSyncRunID(ctx, runID)
+	start := time.Now()
+
+	ctx, span := tracing.StartSpan(ctx, "delegation_sync.run", attribute.String("sync.run_id", runID))
+	defer span.End()
+
+	slog.InfoContext(ctx, "Starting delegation sync")
 	
 	// Get all enabled validators
-	validators, err := t.validatorStore.GetEnabledValidators()
+	validators, err := t.validatorStore.GetEnabledValidators(ctx)
 	if err != nil {
-		log.Printf("[ERROR] Failed to get enabled validators: %v", err)
-		return fmt.Errorf("error getting enabled validators: %v", err)
+		slog.ErrorContext(ctx, "Failed to get enabled validators", "error", err)
+		err = fmt.Errorf("error getting enabled validators: %v", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
 	}
-	log.Printf("[DEBUG] Found %d enabled validators", len(validators))
+	span.SetAttributes(attribute.Int("sync.validators", len(validators)))
+	slog.DebugContext(ctx, "Found enabled validators", "count", len(validators))
 
+	var success, failed, skipped, processed, inserted int
 	for _, validatorAddress := range validators {
-		log.Printf("[DEBUG] Processing validator: %s", validatorAddress)
-		
-		// Get delegations from API
-		delegations, err := t.cosmosService.RetrieveDelegations(ctx, validatorAddress)
+		result, err := t.syncValidator(ctx, validatorAddress)
+		if statusErr := t.validatorStore.UpdateSyncStatus(ctx, validatorAddress, err); statusErr != nil {
+			slog.ErrorContext(ctx, "Failed to record sync status", "validator", validatorAddress, "error", statusErr)
+		}
+		failures := t.recordSyncOutcome(validatorAddress, err)
 		if err != nil {
-			log.Printf("[ERROR] Failed to get delegations for validator %s: %v", validatorAddress, err)
+			metrics.SyncErrorsTotal.WithLabelValues(validatorAddress).Inc()
+			t.publish(ctx, validatorAddress, models.EventSyncFailed,
+				models.SyncFailure{ValidatorAddress: validatorAddress, Error: err.Error(), ConsecutiveFailures: failures})
+			failed++
 			continue
 		}
-		log.Printf("[DEBUG] Retrieved %d delegations from API for validator %s", 
-			len(delegations.DelegationResponses), validatorAddress)
-		
-		// Log delegation details for debugging
-		for i, resp := range delegations.DelegationResponses {
-			log.Printf("[DEBUG] Delegation %d: delegator=%s, shares=%s", 
-				i+1, resp.Delegation.DelegatorAddress, resp.Delegation.Shares)
-		}
 
-		// Save delegations to store
-		if err := t.delegationStore.SaveDelegations(validatorAddress, *delegations); err != nil {
-			log.Printf("[ERROR] Failed to save delegations for validator %s: %v", validatorAddress, err)
-			continue
+		metrics.SyncDelegationsTotal.WithLabelValues(validatorAddress, "inserted").Add(float64(result.Inserted))
+		metrics.SyncDelegationsTotal.WithLabelValues(validatorAddress, "skipped").Add(float64(result.Skipped))
+		metrics.SyncDelegationsTotal.WithLabelValues(validatorAddress, "exited").Add(float64(result.Exited))
+		metrics.SyncLastSuccessTimestamp.WithLabelValues(validatorAddress).SetToCurrentTime()
+
+		success++
+		processed += result.Processed
+		inserted += result.Inserted
+		if result.Inserted == 0 {
+			skipped++
 		}
-		log.Printf("[INFO] Successfully processed delegations for validator %s", validatorAddress)
 	}
 
+	duration := time.Since(start)
+	metrics.SyncRunDuration.Observe(duration.Seconds())
+
+	t.mu.Lock()
+	t.lastRunStats.TotalRuns++
+	t.lastRunStats.SuccessCount += success
+	t.lastRunStats.ErrorCount += failed
+	t.lastRunStats.SkippedCount += skipped
+	t.lastRunStats.LastRunTime = start
+	t.lastRunStats.LastRunDuration = duration
+	t.lastRunStats.TotalDelegationsProcessed += processed
+	t.totalDelegationsSynced += inserted
+	t.mu.Unlock()
+
+	span.SetAttributes(attribute.Int("sync.failed", failed))
+	slog.InfoContext(ctx, "Delegation sync finished",
+		"validators", len(validators),
+		"failed", failed,
+		"duration", duration,
+	)
 	return nil
 }
 
+// syncValidator retrieves and stores the delegations of a single validator
+func (t *DelegationSyncTask) syncValidator(ctx context.Context, validatorAddress string) (result store.SaveResult, err error) {
+	ctx, span := tracing.StartSpan(ctx, "delegation_sync.validator", attribute.String("validator.address", validatorAddress))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	// Get delegations from API
+	delegations, err := t.cosmosService.RetrieveDelegations(ctx, validatorAddress)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get delegations", "validator", validatorAddress, "error", err)
+		return store.SaveResult{}, err
+	}
+
+	// Save delegations to store
+	result, err = t.delegationStore.SaveDelegations(ctx, validatorAddress, *delegations)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save delegations", "validator", validatorAddress, "error", err)
+		return store.SaveResult{}, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("sync.inserted", result.Inserted),
+		attribute.Int("sync.skipped", result.Skipped),
+		attribute.Int("sync.exited", result.Exited),
+	)
+
+	// The changes are in the event outbox; alert rules are evaluated as the
+	// relay hands them over
+	if len(result.Events) > 0 && t.relay != nil {
+		t.relay.Wake()
+	}
+
+	// Stale metadata does not fail the sync; it is refreshed on the next run
+	t.refreshMetadata(ctx, validatorAddress)
+	return result, nil
+}
+
+// recordSyncOutcome updates the number of failed syncs in a row of a
+// validator and returns it
+func (t *DelegationSyncTask) recordSyncOutcome(validatorAddress string, syncErr error) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if syncErr == nil {
+		delete(t.syncFailures, validatorAddress)
+		return 0
+	}
+	t.syncFailures[validatorAddress]++
+	return t.syncFailures[validatorAddress]
+}
+
+// publish adds an event about a validator to the event outbox
+func (t *DelegationSyncTask) publish(ctx context.Context, validatorAddress string, eventType models.EventType, data interface{}) {
+	if t.relay == nil {
+		return
+	}
+
+	event, err := events.New(eventType, validatorAddress, data)
+	if err == nil {
+		err = t.relay.Publish(ctx, []models.Event{event})
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to publish event", "validator", validatorAddress, "type", eventType, "error", err)
+	}
+}
+
+// refreshMetadata updates the stored chain metadata of a validator
+func (t *DelegationSyncTask) refreshMetadata(ctx context.Context, validatorAddress string) {
+	chainValidator, err := t.cosmosService.GetValidator(ctx, validatorAddress)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get validator metadata", "validator", validatorAddress, "error", err)
+		return
+	}
+
+	if err := t.validatorStore.UpdateMetadata(ctx, validatorAddress, chainValidator.Metadata()); err != nil {
+		slog.WarnContext(ctx, "Failed to save validator metadata", "validator", validatorAddress, "error", err)
+	}
+}
+
 // GetSyncStats returns the statistics about delegation syncing
 func (t *DelegationSyncTask) GetSyncStats() SyncStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
 	return t.lastRunStats
 }
 
 // GetTotalDelegationsSynced returns the total number of delegations synced
 func (t *DelegationSyncTask) GetTotalDelegationsSynced() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
 	return t.totalDelegationsSynced
 } 
\ No newline at end of file
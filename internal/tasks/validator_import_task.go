@@ -0,0 +1,157 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/services"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+// PowerReduction is the number of tokens that make up one unit of voting power
+const PowerReduction = 1_000_000
+
+// maxImportedNameLength matches the VARCHAR(255) name column of the validators table
+const maxImportedNameLength = 255
+
+// ErrListValidators is returned by Import when the validators cannot be listed from the chain
+var ErrListValidators = errors.New("error listing validators")
+
+// ImportFilter selects the validators to import and which of them to track
+type ImportFilter struct {
+	// Status is the bond status of the validators to import. It defaults to
+	// models.BondStatusBonded.
+	Status string `json:"status"`
+	// MinVotingPower is the voting power a validator needs to be tracked
+	MinVotingPower int64 `json:"minVotingPower"`
+	// TopN limits tracking to the N validators with the most voting power on
+	// the chain, archived ones included. Zero tracks every validator that
+	// meets MinVotingPower.
+	TopN int `json:"topN"`
+}
+
+// Validate checks the filter values
+func (f ImportFilter) Validate() []string {
+	var errs []string
+	switch f.Status {
+	case "", models.BondStatusBonded, models.BondStatusUnbonding, models.BondStatusUnbonded:
+	default:
+		errs = append(errs, fmt.Sprintf("status must be one of %s, %s, %s",
+			models.BondStatusBonded, models.BondStatusUnbonding, models.BondStatusUnbonded))
+	}
+	if f.MinVotingPower < 0 {
+		errs = append(errs, "minVotingPower must not be negative")
+	}
+	if f.TopN < 0 {
+		errs = append(errs, "topN must not be negative")
+	}
+	return errs
+}
+
+// ImportSummary describes the outcome of an import
+type ImportSummary struct {
+	store.ImportResult
+	// Listed is the number of validators returned by the chain
+	Listed int `json:"listed"`
+	// Tracked is the number of listed validators selected for tracking
+	Tracked int `json:"tracked"`
+}
+
+// ValidatorImportTask imports the validator set of the chain into the store
+type ValidatorImportTask struct {
+	validatorStore store.ValidatorStore
+	cosmosService  *services.CosmosService
+}
+
+// NewValidatorImportTask creates a new validator import task
+func NewValidatorImportTask(validatorStore store.ValidatorStore, cosmosService *services.CosmosService) *ValidatorImportTask {
+	return &ValidatorImportTask{
+		validatorStore: validatorStore,
+		cosmosService:  cosmosService,
+	}
+}
+
+// Import lists the validators with the filter's bond status and upserts them.
+// Validators that meet the voting power and top N criteria are tracked, the
+// others are imported with tracking disabled.
+func (t *ValidatorImportTask) Import(ctx context.Context, filter ImportFilter) (ImportSummary, error) {
+	status := filter.Status
+	if status == "" {
+		status = models.BondStatusBonded
+	}
+
+	chainValidators, err := t.cosmosService.ListValidators(ctx, status)
+	if err != nil {
+		return ImportSummary{}, fmt.Errorf("%w: %v", ErrListValidators, err)
+	}
+
+	// Rank by voting power so that the top N can be selected
+	powers := make(map[string]*big.Int, len(chainValidators))
+	for _, v := range chainValidators {
+		powers[v.OperatorAddress] = votingPower(v.Tokens)
+	}
+	sort.SliceStable(chainValidators, func(i, j int) bool {
+		return powers[chainValidators[i].OperatorAddress].Cmp(powers[chainValidators[j].OperatorAddress]) > 0
+	})
+
+	summary := ImportSummary{Listed: len(chainValidators)}
+	minPower := big.NewInt(filter.MinVotingPower)
+	validators := make([]models.Validator, 0, len(chainValidators))
+	for _, v := range chainValidators {
+		track := powers[v.OperatorAddress].Cmp(minPower) >= 0 && (filter.TopN == 0 || summary.Tracked < filter.TopN)
+		if track {
+			summary.Tracked++
+		}
+
+		metadata := v.Metadata()
+		validators = append(validators, models.Validator{
+			Name:            importedName(v),
+			Address:         v.OperatorAddress,
+			EnabledTracking: track,
+			Metadata:        &metadata,
+		})
+	}
+
+	summary.ImportResult, err = t.validatorStore.Import(ctx, validators)
+	if err != nil {
+		return ImportSummary{}, err
+	}
+
+	slog.InfoContext(ctx, "Imported validators",
+		"status", status,
+		"listed", summary.Listed,
+		"tracked", summary.Tracked,
+		"created", summary.Created,
+		"updated", summary.Updated,
+		"archived", summary.Archived,
+	)
+	return summary, nil
+}
+
+// votingPower converts a token amount into voting power. Unparsable amounts have no power.
+func votingPower(tokens string) *big.Int {
+	amount, ok := new(big.Int).SetString(tokens, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return amount.Quo(amount, big.NewInt(PowerReduction))
+}
+
+// importedName returns the name of an imported validator: its moniker, or
+// its address when it has none
+func importedName(v models.ChainValidator) string {
+	name := strings.TrimSpace(v.Description.Moniker)
+	if name == "" {
+		name = v.OperatorAddress
+	}
+	if runes := []rune(name); len(runes) > maxImportedNameLength {
+		name = string(runes[:maxImportedNameLength])
+	}
+	return name
+}
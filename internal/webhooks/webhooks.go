@@ -0,0 +1,89 @@
+// Package webhooks queues events for the configured webhooks and delivers
+// them as signed HTTP POST requests
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+)
+
+// Headers sent with every delivery
+const (
+	// SignatureHeader holds "sha256=" followed by the hex HMAC-SHA256 of
+	// "<timestamp>.<body>", keyed with the webhook secret
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader holds the Unix time at which the request was signed
+	TimestampHeader = "X-Webhook-Timestamp"
+	// EventHeader holds the event type
+	EventHeader = "X-Webhook-Event"
+	// EventIDHeader holds the event ID, which stays the same across retries
+	EventIDHeader = "X-Webhook-Event-ID"
+	// DeliveryHeader holds the delivery ID
+	DeliveryHeader = "X-Webhook-Delivery"
+)
+
+// signaturePrefix names the algorithm in the signature header
+const signaturePrefix = "sha256="
+
+// secretPrefix marks webhook signing secrets
+const secretPrefix = "whsec_"
+
+// GenerateSecret returns a new random webhook signing secret
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating webhook secret: %v", err)
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
+
+// Sign returns the value of the signature header for a request body sent at
+// timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is a valid signature header for a request
+// body sent at timestamp. Receivers should also reject old timestamps.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Dispatcher queues published events for delivery to the subscribed webhooks
+type Dispatcher struct {
+	store store.WebhookStore
+}
+
+// NewDispatcher creates a new webhook dispatcher
+func NewDispatcher(webhookStore store.WebhookStore) *Dispatcher {
+	return &Dispatcher{store: webhookStore}
+}
+
+// Publish implements events.Publisher
+func (d *Dispatcher) Publish(ctx context.Context, events []models.Event) error {
+	queued, err := d.store.Enqueue(ctx, events)
+	if err != nil {
+		return fmt.Errorf("error queuing webhook deliveries: %v", err)
+	}
+	if queued > 0 {
+		slog.DebugContext(ctx, "Queued webhook deliveries", "events", len(events), "deliveries", queued)
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/poller"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Default worker settings, used for zero values in WorkerConfig
+const (
+	DefaultPollInterval   = 5 * time.Second
+	DefaultBatchSize      = 50
+	DefaultTimeout        = 10 * time.Second
+	DefaultMaxAttempts    = 8
+	DefaultInitialBackoff = 30 * time.Second
+	DefaultMaxBackoff     = time.Hour
+)
+
+// maxResponseBody is the most of a response body read before the connection
+// is reused
+const maxResponseBody = 64 << 10
+
+// WorkerConfig holds configuration for the delivery worker
+type WorkerConfig struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	Timeout        time.Duration
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// HTTPClient sends the deliveries. The default client does not follow
+	// redirects.
+	HTTPClient *http.Client
+}
+
+// Worker delivers queued webhook deliveries and retries failed ones with
+// exponential backoff
+type Worker struct {
+	store  store.WebhookStore
+	config WorkerConfig
+	client *http.Client
+	loop   *poller.Loop
+}
+
+// NewWorker creates a new delivery worker, applying defaults for empty
+// configuration values
+func NewWorker(webhookStore store.WebhookStore, config WorkerConfig) *Worker {
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultInitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{
+			Timeout: config.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+
+	w := &Worker{
+		store:  webhookStore,
+		config: config,
+		client: config.HTTPClient,
+	}
+	// Keep going without waiting while full batches are due. Deliveries make
+	// up the delivery log of their webhook and are deleted with it, so they
+	// are not pruned.
+	w.loop = poller.New(poller.Config{
+		Name:         "Webhook delivery worker",
+		PollInterval: config.PollInterval,
+	}, func(ctx context.Context) (bool, error) {
+		claimed, err := w.DeliverDue(ctx)
+		return claimed >= config.BatchSize, err
+	}, nil)
+	return w
+}
+
+// Run delivers due deliveries every poll interval until ctx is done
+func (w *Worker) Run(ctx context.Context) {
+	w.loop.Run(ctx)
+}
+
+// DeliverDue attempts one batch of due deliveries and returns the number of
+// deliveries claimed
+func (w *Worker) DeliverDue(ctx context.Context) (int, error) {
+	// Deliveries are attempted one after another, so the lease covers the
+	// whole batch
+	lease := time.Duration(w.config.BatchSize+1) * w.config.Timeout
+	jobs, err := w.store.ClaimDue(ctx, w.config.BatchSize, lease)
+	if err != nil {
+		return 0, fmt.Errorf("error claiming webhook deliveries: %v", err)
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			// Unattempted deliveries are claimed again when their lease expires
+			return len(jobs), ctx.Err()
+		}
+		if _, err := w.deliver(ctx, job); err != nil {
+			slog.ErrorContext(ctx, "Failed to record webhook delivery attempt",
+				"delivery", job.Delivery.ID, "webhook", job.Delivery.WebhookID, "error", err)
+		}
+	}
+	return len(jobs), nil
+}
+
+// TestFire sends a webhook.test event to a webhook right away, whatever its
+// event types and enabled flag, and returns the delivery with its attempt log.
+// A failed test delivery is retried like any other.
+func (w *Worker) TestFire(ctx context.Context, webhookID int) (*models.WebhookDelivery, error) {
+	webhook, err := w.store.GetByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := events.New(models.EventWebhookTest, "", map[string]interface{}{
+		"webhook_id": webhook.ID,
+		"message":    "This is a test delivery.",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Lease the delivery for longer than the attempt so that the worker does
+	// not send it as well
+	delivery, err := w.store.EnqueueTo(ctx, webhook.ID, event, 2*w.config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.deliver(ctx, store.WebhookJob{Delivery: *delivery, URL: webhook.URL, Secret: webhook.Secret}); err != nil {
+		return nil, err
+	}
+	return w.store.GetDelivery(ctx, webhook.ID, delivery.ID)
+}
+
+// Backoff returns the delay after a failed attempt (counted from 1) before the
+// next one
+func (w *Worker) Backoff(attempt int) time.Duration {
+	return poller.Backoff(w.config.InitialBackoff, w.config.MaxBackoff, attempt)
+}
+
+// deliver sends one attempt of a delivery and records its outcome. It returns
+// the new status of the delivery.
+func (w *Worker) deliver(ctx context.Context, job store.WebhookJob) (status models.WebhookDeliveryStatus, err error) {
+	ctx, span := tracing.StartSpan(ctx, "webhooks.deliver",
+		attribute.Int64("webhook.delivery_id", job.Delivery.ID),
+		attribute.Int("webhook.id", job.Delivery.WebhookID),
+		attribute.String("webhook.event_type", string(job.Delivery.EventType)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	attempt := models.WebhookDeliveryAttempt{
+		Attempt:   job.Delivery.Attempts + 1,
+		CreatedAt: time.Now().UTC(),
+	}
+	statusCode, sendErr := w.send(ctx, job)
+	attempt.DurationMs = time.Since(attempt.CreatedAt).Milliseconds()
+	attempt.StatusCode = statusCode
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+	}
+	span.SetAttributes(attribute.Int("webhook.attempt", attempt.Attempt), attribute.Int("http.status_code", statusCode))
+
+	if ctx.Err() != nil {
+		// Shutting down: leave the delivery to be claimed again without
+		// counting the interrupted attempt
+		return models.WebhookDeliveryPending, ctx.Err()
+	}
+
+	nextAttemptAt := attempt.CreatedAt
+	switch {
+	case sendErr == nil:
+		status = models.WebhookDeliveryDelivered
+	case attempt.Attempt >= w.config.MaxAttempts:
+		status = models.WebhookDeliveryFailed
+		slog.WarnContext(ctx, "Webhook delivery failed",
+			"delivery", job.Delivery.ID, "webhook", job.Delivery.WebhookID, "attempts", attempt.Attempt, "error", sendErr)
+	default:
+		status = models.WebhookDeliveryPending
+		nextAttemptAt = attempt.CreatedAt.Add(w.Backoff(attempt.Attempt))
+		slog.InfoContext(ctx, "Webhook delivery attempt failed, retrying",
+			"delivery", job.Delivery.ID, "webhook", job.Delivery.WebhookID, "attempt", attempt.Attempt,
+			"next_attempt_at", nextAttemptAt, "error", sendErr)
+	}
+
+	result := string(status)
+	if status == models.WebhookDeliveryPending {
+		result = "retry"
+	}
+	metrics.WebhookDeliveriesTotal.WithLabelValues(result).Inc()
+
+	if err := w.store.CompleteAttempt(ctx, job.Delivery.ID, attempt, status, nextAttemptAt); err != nil {
+		return status, err
+	}
+	return status, nil
+}
+
+// send posts the payload of a delivery to its webhook. It returns the response
+// status code, or zero when no response was received, and an error unless the
+// status code is 2xx.
+func (w *Worker) send(ctx context.Context, job store.WebhookJob) (int, error) {
+	body := []byte(job.Delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cosmos-validator-service-webhooks")
+	req.Header.Set(SignatureHeader, Sign(job.Secret, timestamp, body))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(EventHeader, string(job.Delivery.EventType))
+	req.Header.Set(EventIDHeader, job.Delivery.EventID)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(job.Delivery.ID, 10))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
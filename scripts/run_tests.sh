@@ -12,6 +12,54 @@ NC='\033[0m' # No Color
 echo -e "${BLUE}Running Unit Tests...${NC}"
 
 # Run all unit tests
+echo -e "${BLUE}Running Config Tests${NC}"
+go test -v ./tests/unit/config/...
+
+echo -e "${BLUE}Running Logging Tests${NC}"
+go test -v ./tests/unit/logging/...
+
+echo -e "${BLUE}Running Tracing Tests${NC}"
+go test -v ./tests/unit/tracing/...
+
+echo -e "${BLUE}Running Address Tests${NC}"
+go test -v ./tests/unit/address/...
+
+echo -e "${BLUE}Running Auth Tests${NC}"
+go test -v ./tests/unit/auth/...
+
+echo -e "${BLUE}Running Health Tests${NC}"
+go test -v ./tests/unit/health/...
+
+echo -e "${BLUE}Running Alert Tests${NC}"
+go test -v ./tests/unit/alerts/...
+
+echo -e "${BLUE}Running Webhook Tests${NC}"
+go test -v ./tests/unit/webhooks/...
+
+echo -e "${BLUE}Running Notification Tests${NC}"
+go test -v ./tests/unit/notify/...
+
+echo -e "${BLUE}Running Email Digest Tests${NC}"
+go test -v ./tests/unit/email/... ./tests/unit/reports/...
+
+echo -e "${BLUE}Running Stream Tests${NC}"
+go test -v ./tests/unit/stream/...
+
+echo -e "${BLUE}Running Poller Tests${NC}"
+go test -v ./tests/unit/poller/...
+
+echo -e "${BLUE}Running Message Bus Tests${NC}"
+go test -v ./tests/unit/bus/...
+
+echo -e "${BLUE}Running Event Outbox Tests${NC}"
+go test -v ./tests/unit/outbox/...
+
+echo -e "${BLUE}Running GraphQL Tests${NC}"
+go test -v ./tests/unit/graphql/...
+
+echo -e "${BLUE}Running Route Tests${NC}"
+go test -v ./tests/unit/routes/...
+
 echo -e "${BLUE}Running Service Tests${NC}"
 go test -v ./tests/unit/services/...
 
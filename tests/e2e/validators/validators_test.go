@@ -48,7 +48,11 @@ func TestValidatorCRUDE2E(t *testing.T) {
 	validatorStore := store.NewValidatorStore(db)
 	delegationStore := store.NewDelegationStore(db)
 	cosmosService := services.NewCosmosService()
-	router := routes.SetupRouter(validatorStore, delegationStore, cosmosService)
+	router := routes.SetupRouter(routes.Dependencies{
+		ValidatorStore:  validatorStore,
+		DelegationStore: delegationStore,
+		CosmosService:   cosmosService,
+	})
 	
 	// Create an HTTP test server
 	server := httptest.NewServer(router)
@@ -185,7 +189,7 @@ func TestValidatorCRUDE2E(t *testing.T) {
 		t.Errorf("Expected status 'success', got %v", updateResponse["status"])
 	}
 	
-	// 5. Delete validator
+	// 5. Archive validator
 	deleteURL := fmt.Sprintf("%s/api/v1/validators/%s", baseURL, validator.Address)
 	deleteReq, err := http.NewRequest(http.MethodDelete, deleteURL, nil)
 	if err != nil {
@@ -215,7 +219,52 @@ func TestValidatorCRUDE2E(t *testing.T) {
 		t.Errorf("Expected status 'success', got %v", deleteResponse["status"])
 	}
 	
-	// 6. Verify validator is deleted
+	// 6. Verify validator is archived
+	checkArchivedURL := fmt.Sprintf("%s/api/v1/validators/%s", baseURL, validator.Address)
+	checkArchivedResp, err := http.Get(checkArchivedURL)
+	if err != nil {
+		t.Fatalf("Failed to check archived validator: %v", err)
+	}
+	defer checkArchivedResp.Body.Close()
+	
+	// Check status code - archived validators are still returned
+	if checkArchivedResp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, checkArchivedResp.StatusCode)
+	}
+	
+	// Parse response
+	var checkArchivedResponse struct {
+		Data models.Validator `json:"data"`
+	}
+	if err := json.NewDecoder(checkArchivedResp.Body).Decode(&checkArchivedResponse); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	
+	// Check archivedAt is set
+	if checkArchivedResponse.Data.ArchivedAt == nil {
+		t.Errorf("Expected archivedAt to be set, got %+v", checkArchivedResponse.Data)
+	}
+	
+	// 7. Purge validator
+	purgeURL := fmt.Sprintf("%s/api/v1/validators/%s?purge=true", baseURL, validator.Address)
+	purgeReq, err := http.NewRequest(http.MethodDelete, purgeURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create purge request: %v", err)
+	}
+	
+	// Send purge request
+	purgeResp, err := client.Do(purgeReq)
+	if err != nil {
+		t.Fatalf("Failed to purge validator: %v", err)
+	}
+	defer purgeResp.Body.Close()
+	
+	// Check status code
+	if purgeResp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, purgeResp.StatusCode)
+	}
+	
+	// 8. Verify validator is deleted
 	checkDeletedURL := fmt.Sprintf("%s/api/v1/validators/%s", baseURL, validator.Address)
 	checkDeletedResp, err := http.Get(checkDeletedURL)
 	if err != nil {
@@ -227,4 +276,4 @@ func TestValidatorCRUDE2E(t *testing.T) {
 	if checkDeletedResp.StatusCode != http.StatusNotFound {
 		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, checkDeletedResp.StatusCode)
 	}
-} 
\ No newline at end of file
+}
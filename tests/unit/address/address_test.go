@@ -0,0 +1,107 @@
+package address_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validValidatorAddress = "cosmosvaloper18ruzecmqj9pv8ac0gvkgryuc7u004te9rh7w5s"
+
+func TestDecode_BIP173Vectors(t *testing.T) {
+	valid := []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+		"11qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqc8247j",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+		"?1ezyfcl",
+		// Longer than the 90 characters of BIP-173, allowed by the Cosmos SDK
+		"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx",
+	}
+	for _, s := range valid {
+		_, _, err := address.Decode(s)
+		assert.NoError(t, err, s)
+	}
+
+	invalid := []struct {
+		s       string
+		message string
+	}{
+		{"\x201nwldj5", "invalid character"},  // prefix character out of range
+		{"\x7f1axkwrx", "invalid character"},  // prefix character out of range
+		{"\x801eym55h", "invalid character"},  // prefix character out of range
+		{"pzry9x0s0muk", "separator"},         // missing separator
+		{"1pzry9x0s0muk", "separator"},        // empty prefix
+		{"x1b4n0q5v", "invalid character"},    // invalid data character
+		{"li1dgmt3", "too short"},             // checksum too short
+		{"de1lg7wt\xff", "invalid character"}, // invalid character in checksum
+		{"A1G7SGD8", "invalid checksum"},      // checksum calculated with upper case prefix
+		{"10a06t8", "too short"},              // empty prefix
+		{"1qzzfhee", "separator"},             // empty prefix
+		{"a12UEL5L", "mixes upper and lower case"},
+		{"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxx", "invalid checksum"},
+		// Valid bech32m strings, whose checksum differs from bech32's
+		{"a1lqfn3a", "invalid checksum"},
+		{"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx", "invalid checksum"},
+	}
+	for _, tt := range invalid {
+		_, _, err := address.Decode(tt.s)
+		if assert.Error(t, err, tt.s) {
+			assert.Contains(t, err.Error(), tt.message, tt.s)
+		}
+	}
+
+	_, _, err := address.Decode(strings.Repeat("q", 1024))
+	assert.ErrorContains(t, err, "too long")
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	hrp, data, err := address.Decode(validValidatorAddress)
+	require.NoError(t, err)
+	assert.Equal(t, "cosmosvaloper", hrp)
+	assert.Len(t, data, 20)
+
+	encoded, err := address.Encode(hrp, data)
+	require.NoError(t, err)
+	assert.Equal(t, validValidatorAddress, encoded)
+}
+
+func TestValidator_ValidateAddresses(t *testing.T) {
+	addresses := address.NewValidator(address.DefaultAccountPrefix, address.DefaultValidatorPrefix)
+
+	_, data, err := address.Decode(validValidatorAddress)
+	require.NoError(t, err)
+	accountAddress, err := address.Encode("cosmos", data)
+	require.NoError(t, err)
+
+	assert.NoError(t, addresses.ValidateValidatorAddress(validValidatorAddress))
+	assert.NoError(t, addresses.ValidateAccountAddress(accountAddress))
+
+	tests := []struct {
+		name     string
+		validate func(string) error
+		address  string
+		message  string
+	}{
+		{"empty", addresses.ValidateValidatorAddress, "", "validator address is required"},
+		{"account address as validator", addresses.ValidateValidatorAddress, accountAddress, `has prefix "cosmos", expected "cosmosvaloper"`},
+		{"validator address as account", addresses.ValidateAccountAddress, validValidatorAddress, `has prefix "cosmosvaloper", expected "cosmos"`},
+		{"typo", addresses.ValidateValidatorAddress, "cosmosvaloper18ruzecmqj9pv8ac0gvkgryuc7u004te9rh7w5t", "invalid checksum"},
+		{"invalid character", addresses.ValidateValidatorAddress, "cosmosvaloper18ruzecmqj9pv8ac0gvkgryuc7u004te9rh7wbs", `invalid character 'b'`},
+		{"upper case", addresses.ValidateValidatorAddress, "COSMOSVALOPER18RUZECMQJ9PV8AC0GVKGRYUC7U004TE9RH7W5S", "must be lower case"},
+		{"too short", addresses.ValidateValidatorAddress, "cosmosvaloper1abc", "too short"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validate(tt.address)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
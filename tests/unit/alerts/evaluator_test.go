@@ -0,0 +1,163 @@
+package alerts_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/alerts"
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAlertStore returns fixed rules and deduplicates recorded alerts by rule and event
+type fakeAlertStore struct {
+	store.AlertStore
+	rules    []models.AlertRule
+	recorded map[[2]int64]bool
+	nextID   int64
+}
+
+func (s *fakeAlertStore) GetActiveRules(ctx context.Context, validatorAddress string) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	for _, rule := range s.rules {
+		if rule.Enabled && (rule.ValidatorAddress == "" || rule.ValidatorAddress == validatorAddress) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (s *fakeAlertStore) RecordAlerts(ctx context.Context, alerts []models.Alert) ([]models.Alert, error) {
+	if s.recorded == nil {
+		s.recorded = make(map[[2]int64]bool)
+	}
+	var recorded []models.Alert
+	for _, alert := range alerts {
+		key := [2]int64{int64(*alert.RuleID), alert.DelegationEventID}
+		if s.recorded[key] {
+			continue
+		}
+		s.recorded[key] = true
+		s.nextID++
+		alert.ID = s.nextID
+		recorded = append(recorded, alert)
+	}
+	return recorded, nil
+}
+
+// fakeValidatorStore returns validators with fixed metadata
+type fakeValidatorStore struct {
+	store.ValidatorStore
+	validators map[string]*models.Validator
+}
+
+func (s *fakeValidatorStore) GetByAddress(ctx context.Context, address string) (*models.Validator, error) {
+	validator, ok := s.validators[address]
+	if !ok {
+		return nil, store.ErrValidatorNotFound
+	}
+	return validator, nil
+}
+
+func TestEvaluate(t *testing.T) {
+	alertStore := &fakeAlertStore{rules: []models.AlertRule{
+		{ID: 1, Name: "global whales", MinDelta: "1000", Enabled: true},
+		{ID: 2, Name: "val1 share", ValidatorAddress: "val1", MinStakePercent: "10", Enabled: true},
+		{ID: 3, Name: "val2 share", ValidatorAddress: "val2", MinStakePercent: "1", Enabled: true},
+		{ID: 4, Name: "disabled", MinDelta: "1", Enabled: false},
+		{ID: 5, Name: "both", MinDelta: "1000", MinStakePercent: "50", Enabled: true},
+	}}
+	validatorStore := &fakeValidatorStore{validators: map[string]*models.Validator{
+		"val1": {Address: "val1", Metadata: &models.ValidatorMetadata{Tokens: "20000"}},
+	}}
+	evaluator := alerts.NewEvaluator(alertStore, validatorStore)
+
+	events := []models.DelegationEvent{
+		{ID: 1, DelegatorAddress: "small", Type: models.DelegationEventIncrease, Delta: "999.999999999999999999"},
+		{ID: 2, DelegatorAddress: "whale", Type: models.DelegationEventExit, Delta: "-1500.000000000000000000"},
+		{ID: 3, DelegatorAddress: "mover", Type: models.DelegationEventNew, Delta: "2500.000000000000000000"},
+	}
+
+	fired, err := evaluator.Evaluate(context.Background(), "val1", events)
+	require.NoError(t, err)
+
+	type firing struct {
+		rule    string
+		event   int64
+		percent string
+	}
+	var got []firing
+	for _, alert := range fired {
+		assert.Equal(t, "val1", alert.ValidatorAddress)
+		got = append(got, firing{alert.RuleName, alert.DelegationEventID, alert.StakePercent})
+	}
+	assert.Equal(t, []firing{
+		{"global whales", 2, "7.5000"},
+		{"global whales", 3, "12.5000"},
+		{"val1 share", 3, "12.5000"},
+	}, got)
+
+	// Evaluating the same events again fires nothing new
+	fired, err = evaluator.Evaluate(context.Background(), "val1", events)
+	require.NoError(t, err)
+	assert.Empty(t, fired)
+}
+
+func TestEvaluate_UnknownStakeOnlyFiresDeltaRules(t *testing.T) {
+	alertStore := &fakeAlertStore{rules: []models.AlertRule{
+		{ID: 1, Name: "global whales", MinDelta: "1000", Enabled: true},
+		{ID: 2, Name: "any share", MinStakePercent: "0.01", Enabled: true},
+	}}
+	validatorStore := &fakeValidatorStore{validators: map[string]*models.Validator{"val1": {Address: "val1"}}}
+	evaluator := alerts.NewEvaluator(alertStore, validatorStore)
+
+	fired, err := evaluator.Evaluate(context.Background(), "val1", []models.DelegationEvent{
+		{ID: 1, DelegatorAddress: "whale", Type: models.DelegationEventNew, Delta: "5000"},
+	})
+	require.NoError(t, err)
+	require.Len(t, fired, 1)
+	assert.Equal(t, "global whales", fired[0].RuleName)
+	assert.Empty(t, fired[0].StakePercent)
+}
+
+func TestPublish_EvaluatesDelegationChanges(t *testing.T) {
+	alertStore := &fakeAlertStore{rules: []models.AlertRule{
+		{ID: 1, Name: "global whales", MinDelta: "1000", Enabled: true},
+	}}
+	evaluator := alerts.NewEvaluator(alertStore, &fakeValidatorStore{})
+
+	var relayed []models.Event
+	for _, change := range []models.DelegationEvent{
+		{ID: 1, ValidatorAddress: "val1", DelegatorAddress: "whale", Type: models.DelegationEventNew, Delta: "5000"},
+		{ID: 2, ValidatorAddress: "val2", DelegatorAddress: "whale", Type: models.DelegationEventExit, Delta: "-5000"},
+		{ID: 3, ValidatorAddress: "val2", DelegatorAddress: "small", Type: models.DelegationEventNew, Delta: "5"},
+	} {
+		event, err := events.ForDelegationEvent(change)
+		require.NoError(t, err)
+		relayed = append(relayed, event)
+	}
+	failure, err := events.New(models.EventSyncFailed, "val1", models.SyncFailure{ValidatorAddress: "val1"})
+	require.NoError(t, err)
+	relayed = append(relayed, failure)
+
+	require.NoError(t, evaluator.Publish(context.Background(), relayed))
+	assert.Equal(t, map[[2]int64]bool{{1, 1}: true, {1, 2}: true}, alertStore.recorded)
+
+	// Relaying the changes again fires nothing new
+	require.NoError(t, evaluator.Publish(context.Background(), relayed))
+	assert.Len(t, alertStore.recorded, 2)
+}
+
+func TestMatches(t *testing.T) {
+	rule := models.AlertRule{MinDelta: "100", MinStakePercent: "5"}
+
+	assert.True(t, alerts.Matches(rule, big.NewRat(100, 1), big.NewRat(5, 1)))
+	assert.False(t, alerts.Matches(rule, big.NewRat(99, 1), big.NewRat(50, 1)))
+	assert.False(t, alerts.Matches(rule, big.NewRat(1000, 1), big.NewRat(4, 1)))
+	assert.False(t, alerts.Matches(rule, big.NewRat(1000, 1), nil))
+	assert.False(t, alerts.Matches(models.AlertRule{}, big.NewRat(1000, 1), big.NewRat(100, 1)))
+}
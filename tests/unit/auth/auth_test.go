@@ -0,0 +1,190 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/auth"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/routes"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bootstrapKey = "bootstrap-key-with-at-least-32-characters"
+
+// fakeKeyStore is an in-memory APIKeyStore keyed by secret hash
+type fakeKeyStore struct {
+	keys    map[string]models.APIKey
+	touched []int
+}
+
+func newFakeKeyStore() *fakeKeyStore {
+	return &fakeKeyStore{keys: make(map[string]models.APIKey)}
+}
+
+func (f *fakeKeyStore) addKey(t *testing.T, role models.Role) string {
+	secret, prefix, hash, err := auth.GenerateKey()
+	require.NoError(t, err)
+	f.keys[hash] = models.APIKey{ID: len(f.keys) + 1, Name: string(role) + "-key", Prefix: prefix, Role: role, CreatedAt: time.Now()}
+	return secret
+}
+
+func (f *fakeKeyStore) Add(ctx context.Context, key models.APIKey, keyHash string) (*models.APIKey, error) {
+	key.ID = len(f.keys) + 1
+	f.keys[keyHash] = key
+	return &key, nil
+}
+
+func (f *fakeKeyStore) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	key, ok := f.keys[keyHash]
+	if !ok || key.RevokedAt != nil {
+		return nil, store.ErrAPIKeyNotFound
+	}
+	return &key, nil
+}
+
+func (f *fakeKeyStore) GetAll(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	for _, key := range f.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (f *fakeKeyStore) Rotate(ctx context.Context, id int, prefix, keyHash string) (*models.APIKey, error) {
+	return nil, store.ErrAPIKeyNotFound
+}
+
+func (f *fakeKeyStore) Revoke(ctx context.Context, id int) error {
+	for hash, key := range f.keys {
+		if key.ID == id && key.RevokedAt == nil {
+			now := time.Now()
+			key.RevokedAt = &now
+			f.keys[hash] = key
+			return nil
+		}
+	}
+	return store.ErrAPIKeyNotFound
+}
+
+func (f *fakeKeyStore) TouchLastUsed(ctx context.Context, id int) error {
+	f.touched = append(f.touched, id)
+	return nil
+}
+
+// fakeValidatorStore returns no validators and accepts every write
+type fakeValidatorStore struct {
+	store.ValidatorStore
+}
+
+func (fakeValidatorStore) List(ctx context.Context, filter store.ValidatorFilter) ([]models.Validator, int, error) {
+	return nil, 0, nil
+}
+
+func (fakeValidatorStore) GetByAddress(ctx context.Context, address string) (*models.Validator, error) {
+	return nil, store.ErrValidatorNotFound
+}
+
+func (fakeValidatorStore) Add(ctx context.Context, validator models.Validator) error {
+	return nil
+}
+
+func TestGenerateKey(t *testing.T) {
+	secret, prefix, hash, err := auth.GenerateKey()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(secret, auth.KeyPrefix))
+	assert.True(t, strings.HasPrefix(secret, prefix))
+	assert.LessOrEqual(t, len(prefix), 16)
+	assert.Equal(t, auth.HashKey(secret), hash)
+	assert.Len(t, hash, 64)
+
+	other, _, _, err := auth.GenerateKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+}
+
+func TestAuthenticate(t *testing.T) {
+	keys := newFakeKeyStore()
+	operatorKey := keys.addKey(t, models.RoleOperator)
+	authenticator := auth.NewAuthenticator(keys, bootstrapKey)
+	ctx := context.Background()
+
+	principal, err := authenticator.Authenticate(ctx, operatorKey)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleOperator, principal.Role)
+	assert.Equal(t, []int{1}, keys.touched)
+
+	principal, err = authenticator.Authenticate(ctx, bootstrapKey)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, principal.Role)
+	assert.True(t, principal.Bootstrap)
+
+	_, err = authenticator.Authenticate(ctx, "")
+	assert.ErrorIs(t, err, auth.ErrMissingKey)
+
+	_, err = authenticator.Authenticate(ctx, "cvs_unknown")
+	assert.ErrorIs(t, err, auth.ErrInvalidKey)
+
+	require.NoError(t, keys.Revoke(ctx, 1))
+	_, err = authenticator.Authenticate(ctx, operatorKey)
+	assert.ErrorIs(t, err, auth.ErrInvalidKey)
+}
+
+func TestRoleAllows(t *testing.T) {
+	assert.True(t, models.RoleAdmin.Allows(models.RoleOperator))
+	assert.True(t, models.RoleOperator.Allows(models.RoleReadOnly))
+	assert.False(t, models.RoleReadOnly.Allows(models.RoleOperator))
+	assert.False(t, models.RoleOperator.Allows(models.RoleAdmin))
+	assert.False(t, models.Role("superuser").Allows(models.RoleReadOnly))
+}
+
+func TestRouterEnforcesRoles(t *testing.T) {
+	keys := newFakeKeyStore()
+	readOnlyKey := keys.addKey(t, models.RoleReadOnly)
+	operatorKey := keys.addKey(t, models.RoleOperator)
+
+	router := routes.SetupRouter(routes.Dependencies{
+		ValidatorStore: fakeValidatorStore{},
+		APIKeyStore:    keys,
+		Authenticator:  auth.NewAuthenticator(keys, bootstrapKey),
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		setHeader      func(r *http.Request)
+		expectedStatus int
+	}{
+		{"missing key", "GET", "/api/v1/validators", "", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"unknown key", "GET", "/api/v1/validators", "", func(r *http.Request) { r.Header.Set("Authorization", "Bearer cvs_unknown") }, http.StatusUnauthorized},
+		{"read-only can read", "GET", "/api/v1/validators", "", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+readOnlyKey) }, http.StatusOK},
+		{"X-API-Key header", "GET", "/api/v1/validators", "", func(r *http.Request) { r.Header.Set(routes.APIKeyHeader, readOnlyKey) }, http.StatusOK},
+		{"read-only cannot write", "POST", "/api/v1/validators", `{"address":"val1","name":"Validator 1"}`, func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+readOnlyKey) }, http.StatusForbidden},
+		{"operator can write", "POST", "/api/v1/validators", `{"address":"val1","name":"Validator 1"}`, func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+operatorKey) }, http.StatusCreated},
+		{"operator cannot purge", "DELETE", "/api/v1/validators/val1?purge=true", "", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+operatorKey) }, http.StatusForbidden},
+		{"operator cannot manage keys", "GET", "/api/v1/admin/api-keys", "", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+operatorKey) }, http.StatusForbidden},
+		{"bootstrap key can manage keys", "POST", "/api/v1/admin/api-keys", `{"name":"ci","role":"operator"}`, func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+bootstrapKey) }, http.StatusCreated},
+		{"invalid role is rejected", "POST", "/api/v1/admin/api-keys", `{"name":"ci","role":"root"}`, func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+bootstrapKey) }, http.StatusBadRequest},
+		{"liveness stays open", "GET", "/livez", "", func(r *http.Request) {}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			tt.setHeader(req)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package bus_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/novintriantonius/cosmos-validator-service/internal/bus"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// startNATSServer starts an in-process NATS server with JetStream. A stream
+// captures the "changes" subjects unless withStream is false.
+func startNATSServer(t *testing.T, options server.Options, withStream bool) (*server.Server, jetstream.Stream) {
+	options.Host = "127.0.0.1"
+	options.Port = -1
+	options.JetStream = true
+	options.StoreDir = t.TempDir()
+	options.NoLog = true
+	options.NoSigs = true
+	natsServer, err := server.NewServer(&options)
+	require.NoError(t, err)
+	go natsServer.Start()
+	t.Cleanup(natsServer.Shutdown)
+	require.True(t, natsServer.ReadyForConnections(5*time.Second))
+	if !withStream {
+		return natsServer, nil
+	}
+
+	connectOptions := []nats.Option{nats.Token(options.Authorization)}
+	if options.Username != "" {
+		connectOptions = []nats.Option{nats.UserInfo(options.Username, options.Password)}
+	}
+	conn, err := nats.Connect(natsServer.ClientURL(), connectOptions...)
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+	stream, err := js.CreateStream(publishContext(t), jetstream.StreamConfig{Name: "CHANGES", Subjects: []string{"changes", "changes.>"}})
+	require.NoError(t, err)
+	return natsServer, stream
+}
+
+// streamMessages returns the messages stored by a stream
+func streamMessages(t *testing.T, stream jetstream.Stream) []*jetstream.RawStreamMsg {
+	info, err := stream.Info(publishContext(t))
+	require.NoError(t, err)
+	var messages []*jetstream.RawStreamMsg
+	for seq := info.State.FirstSeq; seq <= info.State.LastSeq && info.State.Msgs > 0; seq++ {
+		message, err := stream.GetMsg(publishContext(t), seq)
+		require.NoError(t, err)
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func busMessage(id int64, key, payload string) models.BusMessage {
+	return models.BusMessage{ID: id, EventID: fmt.Sprintf("event%d", id), Topic: "changes", Key: key, Payload: []byte(payload)}
+}
+
+func publishContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestNATSPublisher_PublishesWithMessageIDs(t *testing.T) {
+	natsServer, stream := startNATSServer(t, server.Options{Authorization: "s3cret"}, true)
+	publisher, err := bus.NewNATSPublisher(bus.NATSConfig{URL: natsServer.ClientURL(), Token: "s3cret"})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	batch := []models.BusMessage{
+		busMessage(1, "val1", `{"id":"event1"}`),
+		busMessage(2, "", `{"id":"event2"}`),
+	}
+	require.NoError(t, publisher.Publish(publishContext(t), batch))
+
+	messages := streamMessages(t, stream)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "changes.val1", messages[0].Subject)
+	assert.Equal(t, "event1", messages[0].Header.Get(bus.NATSMessageIDHeader))
+	assert.Equal(t, `{"id":"event1"}`, string(messages[0].Data))
+	assert.Equal(t, "changes", messages[1].Subject)
+	assert.Equal(t, "event2", messages[1].Header.Get(bus.NATSMessageIDHeader))
+
+	// A retried batch is discarded by the stream
+	require.NoError(t, publisher.Publish(publishContext(t), batch))
+	assert.Len(t, streamMessages(t, stream), 2)
+
+	// The connection is reused for later batches
+	require.NoError(t, publisher.Publish(publishContext(t), []models.BusMessage{busMessage(3, "val1", `{}`)}))
+	assert.Len(t, streamMessages(t, stream), 3)
+	// One connection is the test's own
+	assert.Equal(t, 2, natsServer.NumClients())
+}
+
+func TestNATSPublisher_AuthenticatesWithURLCredentials(t *testing.T) {
+	natsServer, stream := startNATSServer(t, server.Options{Username: "alice", Password: "pa55"}, true)
+	address := natsServer.Addr().String()
+	publisher, err := bus.NewNATSPublisher(bus.NATSConfig{URL: "nats://alice:pa55@" + address})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	require.NoError(t, publisher.Publish(publishContext(t), []models.BusMessage{busMessage(1, "val1", `{"id":"event1"}`)}))
+	assert.Len(t, streamMessages(t, stream), 1)
+}
+
+func TestNATSPublisher_ReportsAuthorizationErrors(t *testing.T) {
+	natsServer, _ := startNATSServer(t, server.Options{Authorization: "s3cret"}, false)
+	publisher, err := bus.NewNATSPublisher(bus.NATSConfig{URL: natsServer.ClientURL(), Token: "wrong"})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	err = publisher.Publish(publishContext(t), []models.BusMessage{busMessage(1, "val1", `{}`)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Authorization Violation")
+}
+
+func TestNATSPublisher_ReportsMissingStream(t *testing.T) {
+	natsServer, _ := startNATSServer(t, server.Options{}, false)
+	publisher, err := bus.NewNATSPublisher(bus.NATSConfig{URL: natsServer.ClientURL()})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	err = publisher.Publish(publishContext(t), []models.BusMessage{busMessage(1, "val1", `{}`)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no JetStream stream captures subject changes.val1")
+}
+
+func TestNATSPublisher_ReportsUnreachableServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	publisher, err := bus.NewNATSPublisher(bus.NATSConfig{URL: "nats://" + address, Timeout: time.Second})
+	require.NoError(t, err)
+	err = publisher.Publish(publishContext(t), []models.BusMessage{busMessage(1, "val1", `{}`)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error connecting to NATS")
+}
+
+func TestNewNATSPublisher_RejectsInvalidURL(t *testing.T) {
+	_, err := bus.NewNATSPublisher(bus.NATSConfig{URL: "http://localhost:4222"})
+	assert.Error(t, err)
+}
+
+// startKafkaCluster starts an in-process Kafka cluster with the "changes" and
+// "other" topics
+func startKafkaCluster(t *testing.T, options ...kfake.Opt) *kfake.Cluster {
+	options = append(options, kfake.NumBrokers(1), kfake.SeedTopics(3, "changes", "other"))
+	cluster, err := kfake.NewCluster(options...)
+	require.NoError(t, err)
+	t.Cleanup(cluster.Close)
+	return cluster
+}
+
+// topicRecords consumes count records of a topic from the start
+func topicRecords(t *testing.T, cluster *kfake.Cluster, topic string, count int, options ...kgo.Opt) []*kgo.Record {
+	options = append(options,
+		kgo.SeedBrokers(cluster.ListenAddrs()...),
+		kgo.ConsumeTopics(topic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	consumer, err := kgo.NewClient(options...)
+	require.NoError(t, err)
+	defer consumer.Close()
+
+	ctx := publishContext(t)
+	var records []*kgo.Record
+	for len(records) < count {
+		fetches := consumer.PollFetches(ctx)
+		require.NoError(t, ctx.Err())
+		records = append(records, fetches.Records()...)
+	}
+	return records
+}
+
+func TestKafkaPublisher_ProducesKeyedRecords(t *testing.T) {
+	cluster := startKafkaCluster(t, kfake.EnableSASL(), kfake.Superuser("SCRAM-SHA-256", "svc", "pw"))
+	publisher, err := bus.NewKafkaPublisher(bus.KafkaConfig{
+		Brokers:       cluster.ListenAddrs(),
+		Username:      "svc",
+		Password:      "pw",
+		SASLMechanism: bus.KafkaSASLSCRAMSHA256,
+	})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	other := busMessage(3, "val2", `{"id":"event3"}`)
+	other.Topic = "other"
+	err = publisher.Publish(publishContext(t), []models.BusMessage{
+		busMessage(1, "val1", `{"id":"event1"}`),
+		busMessage(2, "val1", `{"id":"event2"}`),
+		other,
+	})
+	require.NoError(t, err)
+
+	auth := kgo.SASL(scram.Auth{User: "svc", Pass: "pw"}.AsSha256Mechanism())
+	changes := topicRecords(t, cluster, "changes", 2, auth)
+	require.Len(t, changes, 2)
+	for i, record := range changes {
+		assert.Equal(t, "val1", string(record.Key))
+		assert.Equal(t, fmt.Sprintf(`{"id":"event%d"}`, i+1), string(record.Value))
+		require.Len(t, record.Headers, 1)
+		assert.Equal(t, bus.KafkaEventIDHeader, record.Headers[0].Key)
+		assert.Equal(t, fmt.Sprintf("event%d", i+1), string(record.Headers[0].Value))
+	}
+	// The changes of a validator share a partition, in order
+	assert.Equal(t, changes[0].Partition, changes[1].Partition)
+	assert.Less(t, changes[0].Offset, changes[1].Offset)
+	// Records are written by an idempotent producer
+	assert.GreaterOrEqual(t, changes[0].ProducerID, int64(0))
+
+	others := topicRecords(t, cluster, "other", 1, auth)
+	require.Len(t, others, 1)
+	assert.Equal(t, "val2", string(others[0].Key))
+}
+
+func TestKafkaPublisher_FailsWithRejectedCredentials(t *testing.T) {
+	cluster := startKafkaCluster(t, kfake.EnableSASL(), kfake.Superuser("PLAIN", "svc", "pw"))
+	publisher, err := bus.NewKafkaPublisher(bus.KafkaConfig{Brokers: cluster.ListenAddrs(), Username: "svc", Password: "wrong"})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	// The client retries the connection until the publish context ends
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = publisher.Publish(ctx, []models.BusMessage{busMessage(1, "val1", `{}`)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error producing message 1 to Kafka topic changes")
+}
+
+func TestKafkaPublisher_ReportsUnknownTopic(t *testing.T) {
+	cluster := startKafkaCluster(t)
+	publisher, err := bus.NewKafkaPublisher(bus.KafkaConfig{Brokers: cluster.ListenAddrs()})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	message := busMessage(1, "val1", `{}`)
+	message.Topic = "missing"
+	err = publisher.Publish(publishContext(t), []models.BusMessage{message})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "UNKNOWN_TOPIC_OR_PARTITION")
+}
+
+func TestNewKafkaPublisher_RejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config bus.KafkaConfig
+	}{
+		{"no brokers", bus.KafkaConfig{}},
+		{"broker without port", bus.KafkaConfig{Brokers: []string{"localhost"}}},
+		{"unknown SASL mechanism", bus.KafkaConfig{Brokers: []string{"localhost:9092"}, Username: "svc", SASLMechanism: "GSSAPI"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := bus.NewKafkaPublisher(tt.config)
+			assert.Error(t, err)
+		})
+	}
+}
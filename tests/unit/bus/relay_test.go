@@ -0,0 +1,250 @@
+package bus_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/bus"
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outboxEntry is a message in the memory outbox with its lock
+type outboxEntry struct {
+	message     models.BusMessage
+	lockedUntil time.Time
+	published   bool
+}
+
+// memoryOutboxStore keeps the outbox in memory with the claiming rules of the
+// PostgreSQL store
+type memoryOutboxStore struct {
+	store.BusOutboxStore
+	mu      sync.Mutex
+	entries []*outboxEntry
+}
+
+func (s *memoryOutboxStore) Enqueue(ctx context.Context, messages []models.BusMessage) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queued := 0
+	for _, message := range messages {
+		if s.find(message.EventID) != nil {
+			continue
+		}
+		message.ID = int64(len(s.entries) + 1)
+		s.entries = append(s.entries, &outboxEntry{message: message})
+		queued++
+	}
+	return queued, nil
+}
+
+func (s *memoryOutboxStore) ClaimNext(ctx context.Context, limit int, lease time.Duration) ([]models.BusMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var messages []models.BusMessage
+	for _, entry := range s.entries {
+		if entry.published {
+			continue
+		}
+		if entry.lockedUntil.After(time.Now()) || len(messages) == limit {
+			break
+		}
+		messages = append(messages, entry.message)
+	}
+	for _, message := range messages {
+		s.entries[message.ID-1].lockedUntil = time.Now().Add(lease)
+	}
+	return messages, nil
+}
+
+func (s *memoryOutboxStore) MarkPublished(ctx context.Context, ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.entries[id-1].published = true
+		s.entries[id-1].message.Attempts++
+		s.entries[id-1].message.LastError = ""
+	}
+	return nil
+}
+
+func (s *memoryOutboxStore) MarkFailed(ctx context.Context, ids []int64, attemptErr string, retryAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.entries[id-1].message.Attempts++
+		s.entries[id-1].message.LastError = attemptErr
+		s.entries[id-1].lockedUntil = retryAt
+	}
+	return nil
+}
+
+func (s *memoryOutboxStore) find(eventID string) *outboxEntry {
+	for _, entry := range s.entries {
+		if entry.message.EventID == eventID {
+			return entry
+		}
+	}
+	return nil
+}
+
+// unlock makes every locked message due again
+func (s *memoryOutboxStore) unlock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range s.entries {
+		entry.lockedUntil = time.Time{}
+	}
+}
+
+func delegationChanged(t *testing.T, id int64, validatorAddress string) models.Event {
+	event, err := events.New(models.EventDelegationChanged, validatorAddress, models.DelegationEvent{
+		ID: id, ValidatorAddress: validatorAddress, DelegatorAddress: "del1", Type: models.DelegationEventIncrease,
+		PreviousShares: "100", Shares: "150", Delta: "50",
+	})
+	require.NoError(t, err)
+	return event
+}
+
+func eventIDs(messages []models.BusMessage) []string {
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.EventID
+	}
+	return ids
+}
+
+func TestDispatcher_QueuesDelegationChanges(t *testing.T) {
+	outbox := &memoryOutboxStore{}
+	dispatcher := bus.NewDispatcher(outbox, "changes", nil)
+
+	change := delegationChanged(t, 1, "val1")
+	alert, err := events.New(models.EventAlertFired, "val1", models.Alert{ID: 1})
+	require.NoError(t, err)
+
+	require.NoError(t, dispatcher.Publish(context.Background(), []models.Event{change, alert}))
+	// Events already in the outbox are not queued twice
+	require.NoError(t, dispatcher.Publish(context.Background(), []models.Event{change}))
+
+	require.Len(t, outbox.entries, 1)
+	message := outbox.entries[0].message
+	assert.Equal(t, change.ID, message.EventID)
+	assert.Equal(t, "changes", message.Topic)
+	assert.Equal(t, "val1", message.Key)
+
+	var payload models.Event
+	require.NoError(t, json.Unmarshal(message.Payload, &payload))
+	assert.Equal(t, change.ID, payload.ID)
+	assert.Equal(t, models.EventDelegationChanged, payload.Type)
+	assert.JSONEq(t, string(change.Data), string(payload.Data))
+}
+
+func TestRelay_PublishesInOrder(t *testing.T) {
+	outbox := &memoryOutboxStore{}
+	publisher := bus.NewMemory()
+	relay := bus.NewRelay(outbox, publisher, bus.RelayConfig{BatchSize: 2})
+	dispatcher := bus.NewDispatcher(outbox, "changes", relay)
+
+	var queued []models.Event
+	for id := int64(1); id <= 3; id++ {
+		queued = append(queued, delegationChanged(t, id, "val1"))
+	}
+	require.NoError(t, dispatcher.Publish(context.Background(), queued))
+
+	claimed, err := relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, claimed)
+	claimed, err = relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+	claimed, err = relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, claimed)
+
+	assert.Equal(t, []string{queued[0].ID, queued[1].ID, queued[2].ID}, eventIDs(publisher.Messages()))
+	for _, entry := range outbox.entries {
+		assert.True(t, entry.published)
+		assert.Equal(t, 1, entry.message.Attempts)
+	}
+}
+
+func TestRelay_RetriesWhileBusIsDown(t *testing.T) {
+	outbox := &memoryOutboxStore{}
+	publisher := bus.NewMemory()
+	relay := bus.NewRelay(outbox, publisher, bus.RelayConfig{BatchSize: 1, InitialBackoff: time.Minute, MaxBackoff: time.Hour})
+	dispatcher := bus.NewDispatcher(outbox, "changes", relay)
+
+	first, second := delegationChanged(t, 1, "val1"), delegationChanged(t, 2, "val2")
+	require.NoError(t, dispatcher.Publish(context.Background(), []models.Event{first, second}))
+
+	publisher.SetError(errors.New("connection refused"))
+	start := time.Now()
+	claimed, err := relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+
+	entry := outbox.entries[0]
+	assert.False(t, entry.published)
+	assert.Equal(t, 1, entry.message.Attempts)
+	assert.Equal(t, "connection refused", entry.message.LastError)
+	assert.WithinDuration(t, start.Add(time.Minute), entry.lockedUntil, 5*time.Second)
+
+	// Later messages wait for the failed one
+	claimed, err = relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, claimed)
+
+	// Once the bus is back, the messages are published in order
+	publisher.SetError(nil)
+	outbox.unlock()
+	for i := 0; i < 2; i++ {
+		_, err := relay.RelayNext(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{first.ID, second.ID}, eventIDs(publisher.Messages()))
+	assert.Equal(t, 2, outbox.entries[0].message.Attempts)
+	assert.Empty(t, outbox.entries[0].message.LastError)
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	relay := bus.NewRelay(&memoryOutboxStore{}, bus.NewMemory(), bus.RelayConfig{
+		InitialBackoff: 5 * time.Second,
+		MaxBackoff:     time.Minute,
+	})
+
+	assert.Equal(t, 5*time.Second, relay.Backoff(1))
+	assert.Equal(t, 10*time.Second, relay.Backoff(2))
+	assert.Equal(t, 40*time.Second, relay.Backoff(4))
+	assert.Equal(t, time.Minute, relay.Backoff(5))
+	assert.Equal(t, time.Minute, relay.Backoff(50))
+}
+
+func TestRelay_RunPublishesWhenWoken(t *testing.T) {
+	outbox := &memoryOutboxStore{}
+	publisher := bus.NewMemory()
+	relay := bus.NewRelay(outbox, publisher, bus.RelayConfig{PollInterval: time.Hour})
+	dispatcher := bus.NewDispatcher(outbox, "changes", relay)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	change := delegationChanged(t, 1, "val1")
+	require.NoError(t, dispatcher.Publish(context.Background(), []models.Event{change}))
+	assert.Eventually(t, func() bool { return len(publisher.Messages()) == 1 }, 2*time.Second, 10*time.Millisecond)
+}
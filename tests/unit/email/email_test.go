@@ -0,0 +1,233 @@
+package email_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/email"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// received is a message accepted by the SMTP sink
+type received struct {
+	From string
+	To   []string
+	Data string
+}
+
+// smtpSink is a minimal SMTP server that accepts every message
+type smtpSink struct {
+	listener net.Listener
+	// extensions are advertised in the EHLO response
+	extensions []string
+	mu         sync.Mutex
+	messages   []received
+}
+
+func newSMTPSink(t *testing.T, extensions ...string) *smtpSink {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	sink := &smtpSink{listener: listener, extensions: extensions}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go sink.serve(conn)
+		}
+	}()
+	return sink
+}
+
+func (s *smtpSink) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) received() []received {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]received(nil), s.messages...)
+}
+
+func (s *smtpSink) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 sink ESMTP")
+	var message received
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			for _, extension := range s.extensions {
+				reply("250-" + extension)
+			}
+			reply("250 sink")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			message = received{From: strings.Trim(strings.TrimSpace(line)[10:], "<>")}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			message.To = append(message.To, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			message.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			s.mu.Unlock()
+			reply("250 OK: queued")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func newSender(port int, tls string) *email.SMTPSender {
+	return email.NewSMTPSender(email.SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    port,
+		From:    "Digests <digests@example.com>",
+		TLS:     tls,
+		Timeout: 5 * time.Second,
+	})
+}
+
+func TestSMTPSender_SendsMultipartMessage(t *testing.T) {
+	sink := newSMTPSink(t)
+	sender := newSender(sink.port(), email.TLSNone)
+
+	err := sender.Send(context.Background(), email.Message{
+		To:      []string{"Alice <alice@example.com>", "bob@example.com"},
+		Subject: "Weekly digest – acme",
+		Text:    "Stake change: +1,000.00",
+		HTML:    "<p>Stake change: <strong>+1,000.00</strong></p>",
+	})
+	require.NoError(t, err)
+
+	messages := sink.received()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "digests@example.com", messages[0].From)
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com"}, messages[0].To)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Weekly digest – acme", subject)
+	assert.Equal(t, `"Alice" <alice@example.com>, <bob@example.com>`, parsed.Header.Get("To"))
+	assert.Regexp(t, `^<[0-9a-f]{32}@example\.com>$`, parsed.Header.Get("Message-ID"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		// multipart.Reader decodes quoted-printable parts itself
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		bodies = append(bodies, part.Header.Get("Content-Type")+": "+string(body))
+	}
+	assert.Equal(t, []string{
+		"text/plain; charset=utf-8: Stake change: +1,000.00",
+		"text/html; charset=utf-8: <p>Stake change: <strong>+1,000.00</strong></p>",
+	}, bodies)
+}
+
+func TestSMTPSender_RequiresSTARTTLS(t *testing.T) {
+	sink := newSMTPSink(t)
+	sender := newSender(sink.port(), email.TLSStartTLS)
+
+	err := sender.Send(context.Background(), email.Message{To: []string{"alice@example.com"}, Subject: "s", Text: "t"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support STARTTLS")
+	assert.Empty(t, sink.received())
+}
+
+func TestSMTPSender_RejectsInvalidAddresses(t *testing.T) {
+	sink := newSMTPSink(t)
+	sender := newSender(sink.port(), email.TLSNone)
+
+	err := sender.Send(context.Background(), email.Message{To: []string{"not an address"}, Subject: "s", Text: "t"})
+	assert.ErrorContains(t, err, "invalid recipient address")
+
+	err = sender.Send(context.Background(), email.Message{Subject: "s", Text: "t"})
+	assert.ErrorContains(t, err, "no recipients")
+	assert.Empty(t, sink.received())
+}
+
+func TestSMTPSender_ConnectionError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	err = newSender(port, email.TLSNone).Send(context.Background(), email.Message{To: []string{"alice@example.com"}, Text: "t"})
+	assert.ErrorContains(t, err, "error connecting to SMTP server")
+}
+
+func TestBuild_TextOnlyMessage(t *testing.T) {
+	from := &mail.Address{Name: "Digests", Address: "digests@example.com"}
+	to := []*mail.Address{{Address: "alice@example.com"}}
+	date := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+
+	body, err := email.Build(from, to, email.Message{
+		Subject: "Digest\r\nBcc: mallory@example.com",
+		Text:    "Total: " + strings.Repeat("x", 100),
+	}, date)
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(body)))
+	require.NoError(t, err)
+	assert.Equal(t, "Digest Bcc: mallory@example.com", parsed.Header.Get("Subject"))
+	assert.Empty(t, parsed.Header.Get("Bcc"))
+	assert.Equal(t, "Wed, 01 May 2024 07:00:00 +0000", parsed.Header.Get("Date"))
+	assert.Equal(t, "text/plain; charset=utf-8", parsed.Header.Get("Content-Type"))
+
+	text, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	require.NoError(t, err)
+	assert.Equal(t, "Total: "+strings.Repeat("x", 100), string(text))
+	for _, line := range strings.Split(string(body), "\r\n") {
+		assert.LessOrEqual(t, len(line), 78, "line "+strconv.Quote(line))
+	}
+}
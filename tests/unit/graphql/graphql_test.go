@@ -0,0 +1,471 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/graphql"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+// fakeValidatorStore serves fixed validators and counts the store calls
+type fakeValidatorStore struct {
+	store.ValidatorStore
+	validators []models.Validator
+	calls      map[string]int
+	filters    []store.ValidatorFilter
+}
+
+func (s *fakeValidatorStore) List(ctx context.Context, filter store.ValidatorFilter) ([]models.Validator, int, error) {
+	s.calls["List"]++
+	s.filters = append(s.filters, filter)
+	end := filter.Offset + filter.Limit
+	if end > len(s.validators) {
+		end = len(s.validators)
+	}
+	if filter.Offset > end {
+		return []models.Validator{}, len(s.validators), nil
+	}
+	return append([]models.Validator(nil), s.validators[filter.Offset:end]...), len(s.validators), nil
+}
+
+func (s *fakeValidatorStore) GetByAddresses(ctx context.Context, addresses []string) ([]models.Validator, error) {
+	s.calls["GetByAddresses"]++
+	var validators []models.Validator
+	for _, v := range s.validators {
+		for _, addr := range addresses {
+			if v.Address == addr {
+				validators = append(validators, v)
+			}
+		}
+	}
+	return validators, nil
+}
+
+// fakeDelegationStore serves fixed delegations and events and counts the
+// store calls
+type fakeDelegationStore struct {
+	store.DelegationStore
+	delegations []models.Delegation
+	events      []models.DelegationEvent
+	points      []models.StakePoint
+	err         error
+	calls       map[string]int
+	eventFilter store.DelegationEventFilter
+	// delegationFilters are the filters of the GetCurrentDelegations calls
+	delegationFilters []store.CurrentDelegationFilter
+	series            []interface{}
+}
+
+func (s *fakeDelegationStore) GetCurrentDelegations(ctx context.Context, filter store.CurrentDelegationFilter) ([]models.Delegation, error) {
+	s.calls["GetCurrentDelegations"]++
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.delegationFilters = append(s.delegationFilters, filter)
+	var delegations []models.Delegation
+	positions := make(map[string]int)
+	for _, d := range s.delegations {
+		key, ok := d.ValidatorAddress, contains(filter.ValidatorAddresses, d.ValidatorAddress)
+		if len(filter.ValidatorAddresses) == 0 {
+			key, ok = d.DelegatorAddress, contains(filter.DelegatorAddresses, d.DelegatorAddress)
+		}
+		if !ok {
+			continue
+		}
+		positions[key]++
+		if positions[key] > filter.Offset && (filter.Limit == 0 || positions[key] <= filter.Offset+filter.Limit) {
+			delegations = append(delegations, d)
+		}
+	}
+	return delegations, nil
+}
+
+func (s *fakeDelegationStore) GetCurrentDelegationTotals(ctx context.Context, filter store.CurrentDelegationFilter) (map[string]models.DelegationTotals, error) {
+	s.calls["GetCurrentDelegationTotals"]++
+	if s.err != nil {
+		return nil, s.err
+	}
+	totals := make(map[string]models.DelegationTotals)
+	sums := make(map[string]*big.Rat)
+	for _, address := range append(append([]string(nil), filter.ValidatorAddresses...), filter.DelegatorAddresses...) {
+		sums[address] = new(big.Rat)
+	}
+	for _, d := range s.delegations {
+		key := d.ValidatorAddress
+		if len(filter.ValidatorAddresses) == 0 {
+			key = d.DelegatorAddress
+		}
+		if sum, ok := sums[key]; ok {
+			shares, _ := new(big.Rat).SetString(d.DelegationShares)
+			sum.Add(sum, shares)
+			totals[key] = models.DelegationTotals{Delegations: totals[key].Delegations + 1}
+		}
+	}
+	for key, sum := range sums {
+		// Sums of the numeric shares column have 18 decimals
+		total := models.DelegationTotals{Delegations: totals[key].Delegations, TotalShares: "0"}
+		if total.Delegations > 0 {
+			total.TotalShares = sum.FloatString(18)
+		}
+		totals[key] = total
+	}
+	return totals, nil
+}
+
+func (s *fakeDelegationStore) GetDelegationEventsByValidator(ctx context.Context, validatorAddresses []string, filter store.DelegationEventFilter) (map[string][]models.DelegationEvent, error) {
+	s.calls["GetDelegationEventsByValidator"]++
+	s.eventFilter = filter
+	byValidator := make(map[string][]models.DelegationEvent)
+	for _, event := range s.events {
+		if contains(validatorAddresses, event.ValidatorAddress) && len(byValidator[event.ValidatorAddress]) < filter.Limit {
+			byValidator[event.ValidatorAddress] = append(byValidator[event.ValidatorAddress], event)
+		}
+	}
+	return byValidator, nil
+}
+
+func (s *fakeDelegationStore) GetStakeSeries(ctx context.Context, validatorAddresses []string, interval store.StakeInterval, since, until time.Time) ([]models.StakePoint, error) {
+	s.calls["GetStakeSeries"]++
+	s.series = []interface{}{validatorAddresses, interval, since, until}
+	return s.points, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func newAPI() (*graphql.API, *fakeValidatorStore, *fakeDelegationStore) {
+	validators := &fakeValidatorStore{calls: map[string]int{}, validators: []models.Validator{
+		{Name: "Alpha", Address: "valA", EnabledTracking: true, CreatedAt: testTime, UpdatedAt: testTime, Tags: []string{"acme"},
+			Metadata: &models.ValidatorMetadata{Moniker: "alpha-node", CommissionRate: "0.05", Tokens: "1000", UpdatedAt: testTime}},
+		{Name: "Beta", Address: "valB", CreatedAt: testTime, UpdatedAt: testTime},
+		{Name: "Gamma", Address: "valC", CreatedAt: testTime, UpdatedAt: testTime},
+	}}
+	delegations := &fakeDelegationStore{calls: map[string]int{},
+		delegations: []models.Delegation{
+			{ValidatorAddress: "valA", DelegatorAddress: "del1", DelegationShares: "300.5", UpdatedAt: testTime},
+			{ValidatorAddress: "valA", DelegatorAddress: "del2", DelegationShares: "100", UpdatedAt: testTime},
+			{ValidatorAddress: "valB", DelegatorAddress: "del1", DelegationShares: "50", UpdatedAt: testTime},
+		},
+		events: []models.DelegationEvent{
+			{ID: 7, ValidatorAddress: "valA", DelegatorAddress: "del2", Type: models.DelegationEventNew, PreviousShares: "0", Shares: "100", Delta: "100", CreatedAt: testTime},
+			{ID: 3, ValidatorAddress: "valA", DelegatorAddress: "del1", Type: models.DelegationEventNew, PreviousShares: "0", Shares: "300.5", Delta: "300.5", CreatedAt: testTime},
+			{ID: 5, ValidatorAddress: "valB", DelegatorAddress: "del1", Type: models.DelegationEventNew, PreviousShares: "0", Shares: "50", Delta: "50", CreatedAt: testTime},
+		},
+	}
+	return graphql.NewAPI(validators, delegations, nil), validators, delegations
+}
+
+// execute runs a query and returns the decoded data and the errors
+func execute(t *testing.T, api *graphql.API, query string, variables map[string]interface{}) (map[string]interface{}, []*graphql.Error) {
+	t.Helper()
+	response := api.Execute(context.Background(), graphql.Request{Query: query, Variables: variables})
+	var data map[string]interface{}
+	if response.Data != nil {
+		require.NoError(t, json.Unmarshal(response.Data, &data))
+	}
+	return data, response.Errors
+}
+
+func TestExecute_BatchesNestedFields(t *testing.T) {
+	api, validators, delegations := newAPI()
+
+	data, errs := execute(t, api, `{
+		validators(first: 3) {
+			totalCount
+			nodes {
+				address
+				stake { delegators totalShares }
+				delegations(first: 1) {
+					totalCount
+					pageInfo { hasNextPage }
+					edges { node { delegatorAddress shares validator { name } delegator { totalShares } } }
+				}
+				events(first: 1, types: [NEW]) { edges { node { id type delta } } pageInfo { hasNextPage } }
+			}
+		}
+	}`, nil)
+	require.Empty(t, errs)
+
+	// One call per field and depth, however many validators are listed
+	assert.Equal(t, map[string]int{"List": 1}, validators.calls)
+	assert.Equal(t, map[string]int{"GetCurrentDelegations": 1, "GetCurrentDelegationTotals": 2, "GetDelegationEventsByValidator": 1}, delegations.calls)
+	// The page of every validator is read in the query
+	assert.Equal(t, []store.CurrentDelegationFilter{{ValidatorAddresses: []string{"valA", "valB", "valC"}, Limit: 2}}, delegations.delegationFilters)
+	assert.Equal(t, []models.DelegationEventType{models.DelegationEventNew}, delegations.eventFilter.Types)
+	assert.Equal(t, 2, delegations.eventFilter.Limit)
+
+	nodes := data["validators"].(map[string]interface{})["nodes"].([]interface{})
+	require.Len(t, nodes, 3)
+	alpha := nodes[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"delegators": float64(2), "totalShares": "400.500000000000000000"}, alpha["stake"])
+
+	alphaDelegations := alpha["delegations"].(map[string]interface{})
+	assert.Equal(t, float64(2), alphaDelegations["totalCount"])
+	assert.Equal(t, map[string]interface{}{"hasNextPage": true}, alphaDelegations["pageInfo"])
+	edge := alphaDelegations["edges"].([]interface{})[0].(map[string]interface{})["node"]
+	assert.Equal(t, map[string]interface{}{
+		"delegatorAddress": "del1",
+		"shares":           "300.5",
+		"validator":        map[string]interface{}{"name": "Alpha"},
+		"delegator":        map[string]interface{}{"totalShares": "350.500000000000000000"},
+	}, edge)
+
+	events := alpha["events"].(map[string]interface{})
+	assert.Equal(t, []interface{}{map[string]interface{}{"node": map[string]interface{}{"id": "7", "type": "NEW", "delta": "100"}}}, events["edges"])
+	assert.Equal(t, map[string]interface{}{"hasNextPage": true}, events["pageInfo"])
+
+	gamma := nodes[2].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"delegators": float64(0), "totalShares": "0"}, gamma["stake"])
+}
+
+func TestExecute_ValidatorByAddress(t *testing.T) {
+	api, validators, _ := newAPI()
+
+	data, errs := execute(t, api, `query Validator($address: String!) {
+		validator(address: $address) { ...details metadata { moniker tokens website } }
+		missing: validator(address: "valX") { name }
+	}
+	fragment details on Validator { __typename name tags createdAt archivedAt }`, map[string]interface{}{"address": "valA"})
+	require.Empty(t, errs)
+
+	assert.Equal(t, map[string]interface{}{
+		"__typename": "Validator",
+		"name":       "Alpha",
+		"tags":       []interface{}{"acme"},
+		"createdAt":  "2024-05-01T10:00:00Z",
+		"archivedAt": nil,
+		"metadata":   map[string]interface{}{"moniker": "alpha-node", "tokens": "1000", "website": nil},
+	}, data["validator"])
+	assert.Nil(t, data["missing"])
+	assert.Equal(t, 1, validators.calls["GetByAddresses"])
+}
+
+func TestExecute_ValidatorsPagination(t *testing.T) {
+	api, validators, _ := newAPI()
+
+	query := `query Page($after: String) {
+		validators(first: 2, after: $after, filter: {tag: "acme", enabledTracking: true}, orderBy: STAKE, descending: true) {
+			edges { cursor node { name } }
+			pageInfo { hasNextPage hasPreviousPage endCursor }
+		}
+	}`
+	data, errs := execute(t, api, query, nil)
+	require.Empty(t, errs)
+	page := data["validators"].(map[string]interface{})
+	pageInfo := page["pageInfo"].(map[string]interface{})
+	assert.Equal(t, true, pageInfo["hasNextPage"])
+	assert.Equal(t, false, pageInfo["hasPreviousPage"])
+
+	enabled := true
+	assert.Equal(t, store.ValidatorFilter{Tag: "acme", EnabledTracking: &enabled, Sort: store.ValidatorSortStake, Descending: true, Limit: 2},
+		validators.filters[0])
+
+	data, errs = execute(t, api, query, map[string]interface{}{"after": pageInfo["endCursor"]})
+	require.Empty(t, errs)
+	page = data["validators"].(map[string]interface{})
+	edges := page["edges"].([]interface{})
+	require.Len(t, edges, 1)
+	gamma := edges[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"name": "Gamma"}, gamma["node"])
+	assert.NotEqual(t, pageInfo["endCursor"], gamma["cursor"])
+	assert.Equal(t, map[string]interface{}{"hasNextPage": false, "hasPreviousPage": true, "endCursor": gamma["cursor"]}, page["pageInfo"])
+	assert.Equal(t, 2, validators.filters[1].Offset)
+
+	_, errs = execute(t, api, `{ validators(after: "bogus") { totalCount } }`, nil)
+	require.Len(t, errs, 1)
+	assert.Equal(t, `invalid cursor "bogus"`, errs[0].Message)
+	assert.Equal(t, []interface{}{"validators"}, errs[0].Path)
+}
+
+func TestExecute_DelegatorAndStakeHistory(t *testing.T) {
+	api, _, delegations := newAPI()
+	delegations.points = []models.StakePoint{
+		{ValidatorAddress: "valA", Timestamp: testTime, StakeChange: "400.5", TotalShares: "400.5", NewDelegators: 2, Events: 2},
+	}
+
+	data, errs := execute(t, api, `{
+		delegator(address: "del1") { address totalShares delegations(first: 1) { totalCount pageInfo { hasNextPage } nodes { validatorAddress shares } } }
+		validator(address: "valA") {
+			stakeHistory(interval: HOUR, since: "2024-05-01T00:00:00Z", until: "2024-05-02T00:00:00Z") {
+				timestamp stakeChange totalShares newDelegators lostDelegators events
+			}
+		}
+	}`, nil)
+	require.Empty(t, errs)
+
+	assert.Equal(t, map[string]interface{}{
+		"address":     "del1",
+		"totalShares": "350.500000000000000000",
+		"delegations": map[string]interface{}{
+			"totalCount": float64(2),
+			"pageInfo":   map[string]interface{}{"hasNextPage": true},
+			"nodes":      []interface{}{map[string]interface{}{"validatorAddress": "valA", "shares": "300.5"}},
+		},
+	}, data["delegator"])
+	assert.Equal(t, []store.CurrentDelegationFilter{{DelegatorAddresses: []string{"del1"}, Limit: 2}}, delegations.delegationFilters)
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"timestamp": "2024-05-01T10:00:00Z", "stakeChange": "400.5", "totalShares": "400.5",
+		"newDelegators": float64(2), "lostDelegators": float64(0), "events": float64(2),
+	}}, data["validator"].(map[string]interface{})["stakeHistory"])
+	assert.Equal(t, []interface{}{[]string{"valA"}, store.StakeIntervalHour,
+		time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)}, delegations.series)
+
+	_, errs = execute(t, api, `{ validator(address: "valA") { stakeHistory(interval: HOUR, since: "2024-01-01T00:00:00Z", until: "2024-05-01T00:00:00Z") { events } } }`, nil)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "the period of a hour stake history is at most 31 days", errs[0].Message)
+}
+
+func TestExecute_FieldErrorsOfLoadedFields(t *testing.T) {
+	api, _, delegations := newAPI()
+	delegations.err = errors.New("database unavailable")
+
+	data, errs := execute(t, api, `{ validator(address: "valA") { name stake { delegators } } }`, nil)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "database unavailable", errs[0].Message)
+	assert.Equal(t, []interface{}{"validator", "stake"}, errs[0].Path)
+	assert.Equal(t, []graphql.Location{{Line: 1, Column: 37}}, errs[0].Locations)
+	// Errors of fields resolved after their depth, such as the non-null stake,
+	// null the whole data rather than the closest nullable field
+	assert.Nil(t, data)
+}
+
+func TestExecute_RejectsInvalidDocuments(t *testing.T) {
+	api, validators, _ := newAPI()
+
+	tests := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{"syntax error", `{ validators {`, "Syntax Error GraphQL request (1:15) Expected Name, found EOF\n\n1: { validators {\n                 ^\n"},
+		{"unknown field", `{ validators { count } }`, `Cannot query field "count" on type "ValidatorConnection".`},
+		{"missing subfields", `{ validators }`, `Field "validators" of type "ValidatorConnection!" must have a sub selection.`},
+		{"subfields of leaf", `{ validator(address: "valA") { name { first } } }`, `Field "name" of type "String!" must not have a sub selection.`},
+		{"unknown argument", `{ validators(last: 2) { totalCount } }`, `Unknown argument "last" on field "validators" of type "Query".`},
+		{"missing argument", `{ validator { name } }`, `Field "validator" argument "address" of type "String!" is required but not provided.`},
+		{"invalid enum", `{ validators(orderBy: SIZE) { totalCount } }`, "Argument \"orderBy\" has invalid value SIZE.\nExpected type \"ValidatorOrder\", found SIZE."},
+		{"undefined variable", `{ validator(address: $address) { name } }`, `Variable "$address" is not defined.`},
+		{"mutation", `mutation { validators { totalCount } }`, `Only queries are supported, not mutation operations.`},
+		{"fragment cycle", `{ validators { ...a } } fragment a on ValidatorConnection { ...a }`, `Cannot spread fragment "a" within itself.`},
+		{"too deep", `{ validators { nodes { delegations { nodes { validator { delegations { nodes { validator { delegations { nodes { validator { delegations { totalCount } } } } } } } } } } } } }`,
+			`Query exceeds the maximum depth of 12.`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := api.Execute(context.Background(), graphql.Request{Query: tt.query})
+			require.NotEmpty(t, response.Errors)
+			assert.Equal(t, tt.message, response.Errors[0].Message)
+			assert.Nil(t, response.Data)
+		})
+	}
+
+	response := api.Execute(context.Background(), graphql.Request{
+		Query:     `query ($first: Int) { validators(first: $first) { totalCount } }`,
+		Variables: map[string]interface{}{"first": "ten"},
+	})
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "Variable \"$first\" got invalid value \"ten\".\nExpected type \"Int\", found \"ten\".", response.Errors[0].Message)
+	assert.Empty(t, validators.calls)
+}
+
+func TestExecute_RejectsComplexQueries(t *testing.T) {
+	api, validators, _ := newAPI()
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+	}{
+		{"large pages", `{ validators(first: 100) { nodes { delegations(first: 100) { nodes { shares } } } } }`, nil},
+		{"default page sizes", `{ validators { nodes { delegations { nodes { validator { delegations { nodes { shares } } } } } } } }`, nil},
+		{"variable default", `query ($n: Int = 100) { validators(first: 100) { nodes { delegations(first: $n) { nodes { shares } } } } }`, nil},
+		{"fragments", `{ validators(first: 100) { ...page } } fragment page on ValidatorConnection { nodes { delegations(first: 100) { nodes { shares } } } }`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := api.Execute(context.Background(), graphql.Request{Query: tt.query, Variables: tt.variables})
+			require.Len(t, response.Errors, 1)
+			assert.Equal(t, "Query exceeds the maximum complexity of 10000.", response.Errors[0].Message)
+			assert.Nil(t, response.Data)
+		})
+	}
+	assert.Empty(t, validators.calls)
+
+	_, errs := execute(t, api, `query ($n: Int = 100) { validators(first: 100) { nodes { delegations(first: $n) { nodes { shares } } } } }`,
+		map[string]interface{}{"n": 10})
+	assert.Empty(t, errs)
+}
+
+func TestExecute_IncludeAndSkip(t *testing.T) {
+	api, _, _ := newAPI()
+
+	data, errs := execute(t, api, `query ($full: Boolean!) {
+		validator(address: "valA") { name tags @include(if: $full) ... on Validator @skip(if: $full) { address } }
+	}`, map[string]interface{}{"full": false})
+	require.Empty(t, errs)
+	assert.Equal(t, map[string]interface{}{"name": "Alpha", "address": "valA"}, data["validator"])
+}
+
+func TestExecute_Introspection(t *testing.T) {
+	api, _, _ := newAPI()
+
+	data, errs := execute(t, api, `{
+		__schema { queryType { name } directives { name } }
+		__type(name: "Validator") {
+			kind
+			fields { name type { kind name ofType { kind name } } }
+		}
+		events: __type(name: "DelegationEventType") { enumValues { name } }
+	}`, nil)
+	require.Empty(t, errs)
+
+	schema := data["__schema"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"name": "Query"}, schema["queryType"])
+	assert.Len(t, schema["directives"], 3)
+
+	validatorType := data["__type"].(map[string]interface{})
+	assert.Equal(t, "OBJECT", validatorType["kind"])
+	var stake interface{}
+	for _, f := range validatorType["fields"].([]interface{}) {
+		if f.(map[string]interface{})["name"] == "stake" {
+			stake = f.(map[string]interface{})["type"]
+		}
+	}
+	assert.Equal(t, map[string]interface{}{"kind": "NON_NULL", "name": nil, "ofType": map[string]interface{}{"kind": "OBJECT", "name": "Stake"}}, stake)
+
+	var values []string
+	for _, v := range data["events"].(map[string]interface{})["enumValues"].([]interface{}) {
+		values = append(values, v.(map[string]interface{})["name"].(string))
+	}
+	sort.Strings(values)
+	assert.Equal(t, []string{"DECREASE", "EXIT", "INCREASE", "NEW"}, values)
+}
+
+func TestAPI_SDL(t *testing.T) {
+	api, _, _ := newAPI()
+
+	sdl := api.SDL()
+	assert.True(t, strings.HasPrefix(sdl, "schema {\n  query: Query\n}\n"))
+	assert.Contains(t, sdl, "  validators(after: String, descending: Boolean = false, filter: ValidatorFilter, first: Int = 20, orderBy: ValidatorOrder): ValidatorConnection!\n")
+	assert.Contains(t, sdl, "  stakeHistory(interval: StakeInterval = DAY, since: Time, until: Time): [StakePoint!]!\n")
+	assert.Contains(t, sdl, "scalar Decimal\n")
+	assert.NotContains(t, sdl, "__Schema")
+}
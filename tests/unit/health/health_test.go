@@ -0,0 +1,116 @@
+package health_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/novintriantonius/cosmos-validator-service/internal/config"
+	"github.com/novintriantonius/cosmos-validator-service/internal/database"
+	"github.com/novintriantonius/cosmos-validator-service/internal/health"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePinger struct {
+	err error
+}
+
+func (p fakePinger) Ping(ctx context.Context) error {
+	return p.err
+}
+
+type fakeSyncSource struct {
+	statuses []models.ValidatorSyncStatus
+}
+
+func (f fakeSyncSource) GetSyncStatuses(ctx context.Context) ([]models.ValidatorSyncStatus, error) {
+	return f.statuses, nil
+}
+
+func allMigrations(t *testing.T) []string {
+	versions, err := database.MigrationVersions()
+	require.NoError(t, err)
+	return versions
+}
+
+func setupMockDB(t *testing.T, applied []string) *sql.DB {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectPing()
+	rows := sqlmock.NewRows([]string{"version"})
+	for _, version := range applied {
+		rows.AddRow(version)
+	}
+	mock.ExpectQuery("SELECT version FROM schema_migrations").WillReturnRows(rows)
+	return db
+}
+
+func healthConfig() config.HealthConfig {
+	return config.HealthConfig{
+		MaxSyncAge:   config.Duration(2 * time.Hour),
+		CheckTimeout: config.Duration(time.Second),
+	}
+}
+
+func TestReadiness_AllChecksPass(t *testing.T) {
+	db := setupMockDB(t, allMigrations(t))
+	checker := health.NewChecker(db, fakePinger{}, fakeSyncSource{}, healthConfig())
+
+	report := checker.Readiness(context.Background())
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+	assert.Equal(t, health.StatusOK, report.Checks["migrations"].Status)
+	assert.Equal(t, health.StatusOK, report.Checks["cosmos"].Status)
+}
+
+func TestReadiness_CosmosUnreachable(t *testing.T) {
+	db := setupMockDB(t, allMigrations(t))
+	checker := health.NewChecker(db, fakePinger{err: errors.New("connection refused")}, fakeSyncSource{}, healthConfig())
+
+	report := checker.Readiness(context.Background())
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Equal(t, "connection refused", report.Checks["cosmos"].Message)
+}
+
+func TestReadiness_PendingMigrations(t *testing.T) {
+	db := setupMockDB(t, allMigrations(t)[:1])
+	checker := health.NewChecker(db, fakePinger{}, fakeSyncSource{}, healthConfig())
+
+	report := checker.Readiness(context.Background())
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Contains(t, report.Checks["migrations"].Message, "000002_create_delegations_table")
+}
+
+func TestReport_StaleValidatorDegradesService(t *testing.T) {
+	db := setupMockDB(t, allMigrations(t))
+	recent := time.Now().Add(-10 * time.Minute)
+	stale := time.Now().Add(-10 * time.Hour)
+	source := fakeSyncSource{statuses: []models.ValidatorSyncStatus{
+		{Address: "fresh", EnabledTracking: true, CreatedAt: stale, LastSyncedAt: &recent},
+		{Address: "stale", EnabledTracking: true, CreatedAt: stale, LastSyncedAt: &stale},
+		{Address: "disabled", EnabledTracking: false, CreatedAt: stale},
+		{Address: "new", EnabledTracking: true, CreatedAt: recent},
+	}}
+	checker := health.NewChecker(db, fakePinger{}, source, healthConfig())
+
+	report := checker.Report(context.Background())
+	assert.Equal(t, health.StatusDegraded, report.Status)
+	require.Len(t, report.Validators, 3)
+
+	byAddress := map[string]health.ValidatorSyncReport{}
+	for _, v := range report.Validators {
+		byAddress[v.Address] = v
+	}
+	assert.Equal(t, health.StatusOK, byAddress["fresh"].Status)
+	assert.Equal(t, health.StatusDegraded, byAddress["stale"].Status)
+	assert.Equal(t, health.StatusOK, byAddress["new"].Status)
+	assert.Nil(t, byAddress["new"].SyncAgeSeconds)
+	assert.InDelta(t, 36000, *byAddress["stale"].SyncAgeSeconds, 5)
+}
@@ -0,0 +1,82 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/config"
+	"github.com/novintriantonius/cosmos-validator-service/internal/logging"
+	"github.com/novintriantonius/cosmos-validator-service/internal/routes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_LevelFiltering(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(config.LoggingConfig{Level: "warn", Format: "text"}, &buf)
+	require.NoError(t, err)
+
+	logger.Info("hidden message")
+	logger.Warn("visible message")
+
+	assert.NotContains(t, buf.String(), "hidden message")
+	assert.Contains(t, buf.String(), "visible message")
+}
+
+func TestNew_InvalidSettings(t *testing.T) {
+	_, err := logging.New(config.LoggingConfig{Level: "chatty", Format: "text"}, &bytes.Buffer{})
+	assert.Error(t, err)
+
+	_, err = logging.New(config.LoggingConfig{Level: "info", Format: "xml"}, &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestNew_JSONWithCorrelationIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(config.LoggingConfig{Level: "debug", Format: "json"}, &buf)
+	require.NoError(t, err)
+
+	ctx := logging.WithSyncRunID(context.Background(), "run-1")
+	ctx = logging.WithRequestID(ctx, "req-1")
+	logger.DebugContext(ctx, "syncing", "validator", "val1")
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "syncing", record["msg"])
+	assert.Equal(t, "DEBUG", record["level"])
+	assert.Equal(t, "val1", record["validator"])
+	assert.Equal(t, "run-1", record[logging.SyncRunIDKey])
+	assert.Equal(t, "req-1", record[logging.RequestIDKey])
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(config.LoggingConfig{Level: "info", Format: "json"}, &buf)
+	require.NoError(t, err)
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(previous)
+
+	router := routes.SetupRouter(routes.Dependencies{})
+
+	// A request ID is generated when the client does not send one
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	generated := rec.Header().Get(routes.RequestIDHeader)
+	assert.Len(t, generated, 16)
+	assert.Contains(t, buf.String(), `"request_id":"`+generated+`"`)
+
+	// A client supplied request ID is propagated
+	req := httptest.NewRequest(http.MethodGet, "/livez", nil)
+	req.Header.Set(routes.RequestIDHeader, "client-abc")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, "client-abc", rec.Header().Get(routes.RequestIDHeader))
+	assert.True(t, strings.Contains(buf.String(), `"request_id":"client-abc"`))
+}
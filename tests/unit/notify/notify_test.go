@@ -0,0 +1,273 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/notify"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testValidator = "cosmosvaloper1clpqr4nrk4khgkxj78fcwwh6dl3uw4epsluffn"
+
+// memoryChannelStore keeps notification channels in memory
+type memoryChannelStore struct {
+	store.NotificationChannelStore
+	channels []models.NotificationChannel
+}
+
+func (s *memoryChannelStore) GetEnabled(ctx context.Context) ([]models.NotificationChannel, error) {
+	var enabled []models.NotificationChannel
+	for _, channel := range s.channels {
+		if channel.Enabled {
+			enabled = append(enabled, channel)
+		}
+	}
+	return enabled, nil
+}
+
+func (s *memoryChannelStore) GetByID(ctx context.Context, id int) (*models.NotificationChannel, error) {
+	for _, channel := range s.channels {
+		if channel.ID == id {
+			return &channel, nil
+		}
+	}
+	return nil, store.ErrNotificationChannelNotFound
+}
+
+// request is a request received by a fake chat service
+type request struct {
+	Path string
+	Body map[string]interface{}
+}
+
+// chatServer fakes a chat service, answering every request with status
+type chatServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []request
+}
+
+func newChatServer(t *testing.T, status int) *chatServer {
+	s := &chatServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		s.mu.Lock()
+		s.requests = append(s.requests, request{Path: r.URL.Path, Body: body})
+		s.mu.Unlock()
+		w.WriteHeader(status)
+		w.Write([]byte(`{"ok":false,"description":"chat not found"}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *chatServer) received() []request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]request(nil), s.requests...)
+}
+
+func delegationEvent(t *testing.T, validator string) models.Event {
+	event, err := events.New(models.EventDelegationChanged, validator, models.DelegationEvent{
+		ValidatorAddress: validator,
+		DelegatorAddress: "cosmos1delegator",
+		Type:             models.DelegationEventIncrease,
+		PreviousShares:   "100",
+		Shares:           "250",
+		Delta:            "150",
+	})
+	require.NoError(t, err)
+	return event
+}
+
+func syncFailedEvent(t *testing.T, consecutive int) models.Event {
+	event, err := events.New(models.EventSyncFailed, testValidator, models.SyncFailure{
+		ValidatorAddress:    testValidator,
+		Error:               "connection refused",
+		ConsecutiveFailures: consecutive,
+	})
+	require.NoError(t, err)
+	return event
+}
+
+func TestSlackNotifier(t *testing.T) {
+	server := newChatServer(t, http.StatusOK)
+
+	notifier := notify.NewSlackNotifier(server.URL+"/services/T/B/X", server.Client())
+	err := notifier.Notify(context.Background(), notify.Message{Title: "Title", Text: "a < b & c"})
+	require.NoError(t, err)
+
+	requests := server.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/services/T/B/X", requests[0].Path)
+	assert.Equal(t, "*Title*\na &lt; b &amp; c", requests[0].Body["text"])
+}
+
+func TestDiscordNotifier(t *testing.T) {
+	server := newChatServer(t, http.StatusNoContent)
+
+	notifier := notify.NewDiscordNotifier(server.URL+"/api/webhooks/1/token", server.Client())
+	err := notifier.Notify(context.Background(), notify.Message{Title: "Title", Text: "@everyone body"})
+	require.NoError(t, err)
+
+	requests := server.received()
+	require.Len(t, requests, 1)
+	embeds := requests[0].Body["embeds"].([]interface{})
+	require.Len(t, embeds, 1)
+	embed := embeds[0].(map[string]interface{})
+	assert.Equal(t, "Title", embed["title"])
+	assert.Equal(t, "@everyone body", embed["description"])
+	assert.Equal(t, map[string]interface{}{"parse": []interface{}{}}, requests[0].Body["allowed_mentions"])
+}
+
+func TestTelegramNotifier(t *testing.T) {
+	server := newChatServer(t, http.StatusOK)
+
+	notifier := notify.NewTelegramNotifier(server.URL+"/", "123:secret", "-100200", server.Client())
+	err := notifier.Notify(context.Background(), notify.Message{Title: "<Title>", Text: "x > y"})
+	require.NoError(t, err)
+
+	requests := server.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/bot123:secret/sendMessage", requests[0].Path)
+	assert.Equal(t, "-100200", requests[0].Body["chat_id"])
+	assert.Equal(t, "HTML", requests[0].Body["parse_mode"])
+	assert.Equal(t, "<b>&lt;Title&gt;</b>\nx &gt; y", requests[0].Body["text"])
+}
+
+func TestNotifierErrorsHideCredentials(t *testing.T) {
+	server := newChatServer(t, http.StatusBadRequest)
+
+	notifier := notify.NewTelegramNotifier(server.URL, "123:secret", "-100200", server.Client())
+	err := notifier.Notify(context.Background(), notify.Message{Title: "Title", Text: "body"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status code 400")
+	assert.Contains(t, err.Error(), "chat not found")
+	assert.NotContains(t, err.Error(), "secret")
+
+	server.Close()
+	err = notifier.Notify(context.Background(), notify.Message{Title: "Title", Text: "body"})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+func TestRender(t *testing.T) {
+	message, err := notify.Render(delegationEvent(t, testValidator), "")
+	require.NoError(t, err)
+	assert.Equal(t, "Delegation changed", message.Title)
+	assert.Equal(t, "cosmos1delegator increase on "+testValidator+": 100 → 250 shares (delta 150)", message.Text)
+
+	message, err = notify.Render(syncFailedEvent(t, 3), "")
+	require.NoError(t, err)
+	assert.Equal(t, "Delegation sync of "+testValidator+" failed 3 time(s) in a row: connection refused", message.Text)
+
+	message, err = notify.Render(delegationEvent(t, testValidator), "{{.Data.delegator_address}} moved {{.Data.delta}} {{.Data.missing}}")
+	require.NoError(t, err)
+	assert.Equal(t, "cosmos1delegator moved 150", message.Text)
+
+	for eventType := range notify.DefaultTemplates {
+		_, err := notify.ParseTemplate(notify.DefaultTemplates[eventType])
+		assert.NoError(t, err, eventType)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	channel := models.NotificationChannel{
+		Enabled:              true,
+		EventTypes:           []models.EventType{models.EventDelegationChanged, models.EventSyncFailed},
+		SyncFailureThreshold: 3,
+	}
+
+	assert.True(t, notify.Routes(channel, delegationEvent(t, testValidator)))
+	assert.False(t, notify.Routes(channel, syncFailedEvent(t, 2)))
+	assert.True(t, notify.Routes(channel, syncFailedEvent(t, 3)))
+	assert.False(t, notify.Routes(channel, syncFailedEvent(t, 4)), "a run of failures is notified once")
+
+	alert, err := events.New(models.EventAlertFired, testValidator, models.Alert{})
+	require.NoError(t, err)
+	assert.False(t, notify.Routes(channel, alert), "not subscribed")
+
+	channel.ValidatorAddresses = []string{"cosmosvaloper1other"}
+	assert.False(t, notify.Routes(channel, delegationEvent(t, testValidator)))
+	channel.ValidatorAddresses = append(channel.ValidatorAddresses, testValidator)
+	assert.True(t, notify.Routes(channel, delegationEvent(t, testValidator)))
+
+	channel.Enabled = false
+	assert.False(t, notify.Routes(channel, delegationEvent(t, testValidator)))
+}
+
+func TestDispatcher_Publish(t *testing.T) {
+	slack := newChatServer(t, http.StatusOK)
+	telegram := newChatServer(t, http.StatusOK)
+	channels := &memoryChannelStore{channels: []models.NotificationChannel{
+		{
+			ID: 1, Name: "slack", Type: models.NotificationSlack, WebhookURL: slack.URL + "/hook", Enabled: true,
+			EventTypes: []models.EventType{models.EventDelegationChanged},
+			Templates:  map[models.EventType]string{models.EventDelegationChanged: "custom {{.Data.delta}}"},
+		},
+		{
+			ID: 2, Name: "telegram", Type: models.NotificationTelegram, BotToken: "1:abc", ChatID: "42", Enabled: true,
+			EventTypes: []models.EventType{models.EventSyncFailed}, SyncFailureThreshold: 1,
+		},
+		{
+			ID: 3, Name: "disabled", Type: models.NotificationSlack, WebhookURL: slack.URL + "/disabled",
+			EventTypes: []models.EventType{models.EventDelegationChanged},
+		},
+	}}
+	dispatcher := notify.NewDispatcher(channels, notify.DispatcherConfig{TelegramAPIURL: telegram.URL})
+
+	err := dispatcher.Publish(context.Background(), []models.Event{delegationEvent(t, testValidator), syncFailedEvent(t, 1)})
+	require.NoError(t, err)
+
+	slackRequests := slack.received()
+	require.Len(t, slackRequests, 1)
+	assert.Equal(t, "/hook", slackRequests[0].Path)
+	assert.Equal(t, "*Delegation changed*\ncustom 150", slackRequests[0].Body["text"])
+
+	telegramRequests := telegram.received()
+	require.Len(t, telegramRequests, 1)
+	assert.Equal(t, "/bot1:abc/sendMessage", telegramRequests[0].Path)
+	assert.True(t, strings.HasPrefix(telegramRequests[0].Body["text"].(string), "<b>Delegation sync failing</b>"))
+}
+
+func TestDispatcher_PublishReportsFailures(t *testing.T) {
+	failing := newChatServer(t, http.StatusInternalServerError)
+	channels := &memoryChannelStore{channels: []models.NotificationChannel{{
+		ID: 1, Name: "discord", Type: models.NotificationDiscord, WebhookURL: failing.URL, Enabled: true,
+		EventTypes: []models.EventType{models.EventDelegationChanged},
+	}}}
+	dispatcher := notify.NewDispatcher(channels, notify.DispatcherConfig{})
+
+	err := dispatcher.Publish(context.Background(), []models.Event{delegationEvent(t, testValidator)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"discord"`)
+	assert.Contains(t, err.Error(), "unexpected status code 500")
+}
+
+func TestDispatcher_Test(t *testing.T) {
+	server := newChatServer(t, http.StatusOK)
+	channels := &memoryChannelStore{channels: []models.NotificationChannel{{
+		ID: 1, Name: "slack", Type: models.NotificationSlack, WebhookURL: server.URL,
+		EventTypes: []models.EventType{models.EventAlertFired},
+	}}}
+	dispatcher := notify.NewDispatcher(channels, notify.DispatcherConfig{})
+
+	require.NoError(t, dispatcher.Test(context.Background(), 1), "disabled channels can be tested")
+	requests := server.received()
+	require.Len(t, requests, 1)
+	assert.Contains(t, requests[0].Body["text"], "Test notification")
+
+	assert.Equal(t, store.ErrNotificationChannelNotFound, dispatcher.Test(context.Background(), 2))
+}
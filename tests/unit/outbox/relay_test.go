@@ -0,0 +1,302 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/outbox"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outboxEntry is an event in the memory outbox with its lock
+type outboxEntry struct {
+	event       models.OutboxEvent
+	lockedUntil time.Time
+	relayed     bool
+}
+
+// memoryOutboxStore keeps the outbox in memory with the claiming rules of the
+// PostgreSQL store
+type memoryOutboxStore struct {
+	store.EventOutboxStore
+	mu      sync.Mutex
+	entries []*outboxEntry
+}
+
+func (s *memoryOutboxStore) Enqueue(ctx context.Context, evts []models.Event) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queued := 0
+	for _, event := range evts {
+		if s.find(event.ID) != nil {
+			continue
+		}
+		s.entries = append(s.entries, &outboxEntry{event: models.OutboxEvent{ID: int64(len(s.entries) + 1), Event: event}})
+		queued++
+	}
+	return queued, nil
+}
+
+func (s *memoryOutboxStore) ClaimNext(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var claimed []models.OutboxEvent
+	blocked := make(map[string]bool)
+	for _, entry := range s.entries {
+		if entry.relayed {
+			continue
+		}
+		validatorAddress := entry.event.Event.ValidatorAddress
+		if entry.lockedUntil.After(time.Now()) {
+			blocked[validatorAddress] = true
+		}
+		if blocked[validatorAddress] {
+			continue
+		}
+		if len(claimed) == limit {
+			break
+		}
+		claimed = append(claimed, entry.event)
+	}
+	for _, event := range claimed {
+		s.entries[event.ID-1].lockedUntil = time.Now().Add(lease)
+	}
+	return claimed, nil
+}
+
+func (s *memoryOutboxStore) MarkRelayed(ctx context.Context, ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.entries[id-1].relayed = true
+		s.entries[id-1].event.Attempts++
+		s.entries[id-1].event.LastError = ""
+	}
+	return nil
+}
+
+func (s *memoryOutboxStore) MarkFailed(ctx context.Context, ids []int64, attemptErr string, retryAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.entries[id-1].event.Attempts++
+		s.entries[id-1].event.LastError = attemptErr
+		s.entries[id-1].lockedUntil = retryAt
+	}
+	return nil
+}
+
+func (s *memoryOutboxStore) find(eventID string) *outboxEntry {
+	for _, entry := range s.entries {
+		if entry.event.Event.ID == eventID {
+			return entry
+		}
+	}
+	return nil
+}
+
+// unlock makes every locked event due again
+func (s *memoryOutboxStore) unlock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range s.entries {
+		entry.lockedUntil = time.Time{}
+	}
+}
+
+// recordingConsumer records the events handed to it, failing for the
+// validators in failing
+type recordingConsumer struct {
+	mu       sync.Mutex
+	received []string
+	failing  map[string]bool
+	// derive is called with the accepted events, like a consumer storing
+	// events derived from them
+	derive func(evts []models.Event)
+}
+
+func (c *recordingConsumer) Publish(ctx context.Context, evts []models.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, event := range evts {
+		if c.failing[event.ValidatorAddress] {
+			return errors.New("consumer unavailable")
+		}
+	}
+	for _, event := range evts {
+		c.received = append(c.received, event.ID)
+	}
+	if c.derive != nil {
+		c.derive(evts)
+	}
+	return nil
+}
+
+func (c *recordingConsumer) setFailing(validatorAddress string, failing bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failing[validatorAddress] = failing
+}
+
+func (c *recordingConsumer) events() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.received...)
+}
+
+func change(t *testing.T, id int64, validatorAddress string) models.Event {
+	event, err := events.ForDelegationEvent(models.DelegationEvent{
+		ID: id, ValidatorAddress: validatorAddress, DelegatorAddress: "del1", Type: models.DelegationEventIncrease,
+		PreviousShares: "100", Shares: "150", Delta: "50",
+	})
+	require.NoError(t, err)
+	return event
+}
+
+func TestRelay_RelaysInOrder(t *testing.T) {
+	outboxStore := &memoryOutboxStore{}
+	consumer := &recordingConsumer{failing: map[string]bool{}}
+	relay := outbox.NewRelay(outboxStore, consumer, outbox.RelayConfig{BatchSize: 2})
+
+	queued := []models.Event{change(t, 1, "val1"), change(t, 2, "val2"), change(t, 3, "val1")}
+	_, err := outboxStore.Enqueue(context.Background(), queued)
+	require.NoError(t, err)
+
+	claimed, err := relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, claimed)
+	claimed, err = relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+	claimed, err = relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, claimed)
+
+	assert.Equal(t, []string{"delegation-event-1", "delegation-event-2", "delegation-event-3"}, consumer.events())
+	for _, entry := range outboxStore.entries {
+		assert.True(t, entry.relayed)
+		assert.Equal(t, 1, entry.event.Attempts)
+	}
+}
+
+func TestRelay_RetriesOnlyTheFailedValidator(t *testing.T) {
+	outboxStore := &memoryOutboxStore{}
+	consumer := &recordingConsumer{failing: map[string]bool{"val1": true}}
+	relay := outbox.NewRelay(outboxStore, consumer, outbox.RelayConfig{InitialBackoff: time.Minute, MaxBackoff: time.Hour})
+
+	_, err := outboxStore.Enqueue(context.Background(), []models.Event{change(t, 1, "val1"), change(t, 2, "val2")})
+	require.NoError(t, err)
+
+	start := time.Now()
+	claimed, err := relay.RelayNext(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, claimed)
+	assert.Equal(t, []string{"delegation-event-2"}, consumer.events())
+
+	entry := outboxStore.entries[0]
+	assert.False(t, entry.relayed)
+	assert.Equal(t, 1, entry.event.Attempts)
+	assert.Equal(t, "consumer unavailable", entry.event.LastError)
+	assert.WithinDuration(t, start.Add(time.Minute), entry.lockedUntil, 5*time.Second)
+
+	// Later events of the failed validator wait for the failed one; those of
+	// other validators go on
+	_, err = outboxStore.Enqueue(context.Background(), []models.Event{change(t, 3, "val1"), change(t, 4, "val2")})
+	require.NoError(t, err)
+	claimed, err = relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+	assert.Equal(t, []string{"delegation-event-2", "delegation-event-4"}, consumer.events())
+
+	// Once the consumer is back, the events of the validator follow in order
+	consumer.setFailing("val1", false)
+	outboxStore.unlock()
+	_, err = relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"delegation-event-2", "delegation-event-4", "delegation-event-1", "delegation-event-3"},
+		consumer.events())
+	assert.Equal(t, 2, outboxStore.entries[0].event.Attempts)
+	assert.Empty(t, outboxStore.entries[0].event.LastError)
+}
+
+func TestRelay_PublishQueuesEventsOnce(t *testing.T) {
+	outboxStore := &memoryOutboxStore{}
+	relay := outbox.NewRelay(outboxStore, &recordingConsumer{failing: map[string]bool{}}, outbox.RelayConfig{})
+
+	failure, err := events.New(models.EventSyncFailed, "val1", models.SyncFailure{ValidatorAddress: "val1", Error: "timeout"})
+	require.NoError(t, err)
+	require.NoError(t, relay.Publish(context.Background(), []models.Event{failure}))
+	require.NoError(t, relay.Publish(context.Background(), []models.Event{failure}))
+
+	require.Len(t, outboxStore.entries, 1)
+	assert.Equal(t, failure.ID, outboxStore.entries[0].event.Event.ID)
+}
+
+func TestRelay_RunRelaysDerivedEvents(t *testing.T) {
+	outboxStore := &memoryOutboxStore{}
+	consumer := &recordingConsumer{failing: map[string]bool{}}
+	// Like the alert rules, the consumer stores an alert for every change
+	consumer.derive = func(evts []models.Event) {
+		for _, event := range evts {
+			if event.Type != models.EventDelegationChanged {
+				continue
+			}
+			alert, err := events.ForAlert(models.Alert{ID: 1, ValidatorAddress: event.ValidatorAddress})
+			if err == nil {
+				outboxStore.Enqueue(context.Background(), []models.Event{alert})
+			}
+		}
+	}
+	relay := outbox.NewRelay(outboxStore, consumer, outbox.RelayConfig{PollInterval: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	_, err := outboxStore.Enqueue(context.Background(), []models.Event{change(t, 1, "val1")})
+	require.NoError(t, err)
+	relay.Wake()
+	assert.Eventually(t, func() bool { return len(consumer.events()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"delegation-event-1", "alert-1"}, consumer.events())
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	relay := outbox.NewRelay(&memoryOutboxStore{}, events.Sequence{}, outbox.RelayConfig{
+		InitialBackoff: 5 * time.Second,
+		MaxBackoff:     time.Minute,
+	})
+
+	assert.Equal(t, 5*time.Second, relay.Backoff(1))
+	assert.Equal(t, 20*time.Second, relay.Backoff(3))
+	assert.Equal(t, time.Minute, relay.Backoff(5))
+}
+
+func TestSequence_StopsAtFirstFailure(t *testing.T) {
+	failing := &recordingConsumer{failing: map[string]bool{"val1": true}}
+	first, last := &recordingConsumer{failing: map[string]bool{}}, &recordingConsumer{failing: map[string]bool{}}
+	evts := []models.Event{change(t, 1, "val1")}
+
+	err := events.Sequence{first, failing, last}.Publish(context.Background(), evts)
+	assert.EqualError(t, err, "consumer unavailable")
+	assert.Equal(t, []string{"delegation-event-1"}, first.events())
+	assert.Empty(t, last.events())
+
+	// Best-effort consumers do not stop the sequence
+	err = events.Sequence{events.BestEffort{Name: "chat", Publisher: failing}, last}.Publish(context.Background(), evts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"delegation-event-1"}, last.events())
+}
@@ -0,0 +1,123 @@
+package poller_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/poller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// counter counts the calls of a loop's functions
+type counter struct {
+	mu      sync.Mutex
+	next    int
+	pruned  []time.Time
+	pending int
+	err     error
+}
+
+func (c *counter) Next(ctx context.Context) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.next++
+	if c.err != nil {
+		return false, c.err
+	}
+	if c.pending > 0 {
+		c.pending--
+	}
+	return c.pending > 0, nil
+}
+
+func (c *counter) Prune(ctx context.Context, before time.Time) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruned = append(c.pruned, before)
+	return 1, nil
+}
+
+func (c *counter) calls() (int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.next, len(c.pruned)
+}
+
+// run runs a loop until the test ends
+func run(t *testing.T, loop *poller.Loop) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		loop.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestLoop_KeepsGoingWhileMoreIsDue(t *testing.T) {
+	c := &counter{pending: 3}
+	loop := poller.New(poller.Config{Name: "Test loop", PollInterval: time.Hour}, c.Next, nil)
+	run(t, loop)
+
+	assert.Eventually(t, func() bool { next, _ := c.calls(); return next == 3 }, 2*time.Second, 10*time.Millisecond)
+	// Nothing more is due until the loop is woken up
+	time.Sleep(50 * time.Millisecond)
+	next, _ := c.calls()
+	assert.Equal(t, 3, next)
+
+	loop.Wake()
+	assert.Eventually(t, func() bool { next, _ := c.calls(); return next == 4 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestLoop_WaitsAfterErrors(t *testing.T) {
+	c := &counter{pending: 3, err: errors.New("database is down")}
+	loop := poller.New(poller.Config{Name: "Test loop", PollInterval: time.Hour}, c.Next, nil)
+	run(t, loop)
+
+	assert.Eventually(t, func() bool { next, _ := c.calls(); return next == 1 }, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	next, _ := c.calls()
+	assert.Equal(t, 1, next)
+}
+
+func TestLoop_PrunesOncePerInterval(t *testing.T) {
+	c := &counter{}
+	loop := poller.New(poller.Config{Name: "Test loop", PollInterval: time.Hour, Retention: 24 * time.Hour}, c.Next, c.Prune)
+	run(t, loop)
+
+	assert.Eventually(t, func() bool { _, pruned := c.calls(); return pruned == 1 }, 2*time.Second, 10*time.Millisecond)
+	loop.Wake()
+	assert.Eventually(t, func() bool { next, _ := c.calls(); return next == 2 }, 2*time.Second, 10*time.Millisecond)
+	_, pruned := c.calls()
+	assert.Equal(t, 1, pruned)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	require.Len(t, c.pruned, 1)
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), c.pruned[0], time.Minute)
+}
+
+func TestLoop_KeepsRowsWithoutRetention(t *testing.T) {
+	c := &counter{}
+	loop := poller.New(poller.Config{Name: "Test loop", PollInterval: time.Hour}, c.Next, c.Prune)
+	run(t, loop)
+
+	assert.Eventually(t, func() bool { next, _ := c.calls(); return next == 1 }, 2*time.Second, 10*time.Millisecond)
+	_, pruned := c.calls()
+	assert.Equal(t, 0, pruned)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, poller.Backoff(5*time.Second, time.Minute, 1))
+	assert.Equal(t, 10*time.Second, poller.Backoff(5*time.Second, time.Minute, 2))
+	assert.Equal(t, 40*time.Second, poller.Backoff(5*time.Second, time.Minute, 4))
+	assert.Equal(t, time.Minute, poller.Backoff(5*time.Second, time.Minute, 5))
+	assert.Equal(t, time.Minute, poller.Backoff(5*time.Second, time.Minute, 50))
+}
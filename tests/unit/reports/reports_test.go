@@ -0,0 +1,244 @@
+package reports_test
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/email"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/reports"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	validatorA = "cosmosvaloper1aaaa"
+	validatorB = "cosmosvaloper1bbbb"
+)
+
+var until = time.Date(2024, 5, 8, 7, 0, 0, 0, time.UTC)
+
+// fakeValidatorStore lists fixed validators, one per page
+type fakeValidatorStore struct {
+	store.ValidatorStore
+	validators []models.Validator
+	filters    []store.ValidatorFilter
+}
+
+func (s *fakeValidatorStore) List(ctx context.Context, filter store.ValidatorFilter) ([]models.Validator, int, error) {
+	s.filters = append(s.filters, filter)
+	if filter.Offset >= len(s.validators) {
+		return []models.Validator{}, len(s.validators), nil
+	}
+	return s.validators[filter.Offset : filter.Offset+1], len(s.validators), nil
+}
+
+// fakeDelegationStore returns fixed stakes and activity
+type fakeDelegationStore struct {
+	store.DelegationStore
+	stakes   []models.ValidatorStake
+	activity map[string]models.DelegationActivity
+	periods  [][2]time.Time
+	err      error
+}
+
+func (s *fakeDelegationStore) GetStakeSummary(ctx context.Context, tag string) ([]models.ValidatorStake, error) {
+	return s.stakes, nil
+}
+
+func (s *fakeDelegationStore) GetDelegationActivity(ctx context.Context, validatorAddress string, since, until time.Time, topMovers int) (models.DelegationActivity, error) {
+	if s.err != nil {
+		return models.DelegationActivity{}, s.err
+	}
+	s.periods = append(s.periods, [2]time.Time{since, until})
+	activity, ok := s.activity[validatorAddress]
+	if !ok {
+		activity = models.DelegationActivity{ValidatorAddress: validatorAddress, StakeChange: "0", TopMovers: []models.DelegatorMovement{}}
+	}
+	return activity, nil
+}
+
+func newStores() (*fakeValidatorStore, *fakeDelegationStore) {
+	validators := &fakeValidatorStore{validators: []models.Validator{
+		{Name: "Alpha", Address: validatorA, Tags: []string{"acme"}, Metadata: &models.ValidatorMetadata{Moniker: "alpha-node"}},
+		{Name: "Beta", Address: validatorB, Tags: []string{"acme"}},
+	}}
+	delegations := &fakeDelegationStore{
+		stakes: []models.ValidatorStake{
+			{ValidatorAddress: validatorA, Delegators: 120, TotalShares: "1234567.891000000000000000"},
+		},
+		activity: map[string]models.DelegationActivity{
+			validatorA: {
+				ValidatorAddress: validatorA,
+				StakeChange:      "2500.500000000000000000",
+				NewDelegators:    3,
+				LostDelegators:   1,
+				TopMovers: []models.DelegatorMovement{
+					{DelegatorAddress: "cosmos1whale", Delta: "5000.000000000000000000"},
+					{DelegatorAddress: "cosmos1leaver", Delta: "-2499.500000000000000000"},
+				},
+			},
+			validatorB: {ValidatorAddress: validatorB, StakeChange: "-1000", LostDelegators: 2, TopMovers: []models.DelegatorMovement{}},
+		},
+	}
+	return validators, delegations
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	validators, delegations := newStores()
+	generator := reports.NewGenerator(validators, delegations, 0)
+
+	report, err := generator.Generate(context.Background(), "acme", models.DigestWeekly, until)
+	require.NoError(t, err)
+
+	assert.Equal(t, until.Add(-7*24*time.Hour), report.Since)
+	assert.Equal(t, until, report.Until)
+	assert.Equal(t, [2]time.Time{report.Since, until}, delegations.periods[0])
+	assert.Equal(t, "acme", validators.filters[0].Tag)
+	assert.Len(t, validators.filters, 2, "every page is listed")
+
+	require.Len(t, report.Validators, 2)
+	assert.Equal(t, "alpha-node", report.Validators[0].Moniker)
+	assert.Equal(t, 120, report.Validators[0].Delegators)
+	assert.Equal(t, "0", report.Validators[1].TotalShares)
+	assert.Equal(t, "1500.500000000000000000", report.StakeChange)
+	assert.Equal(t, 3, report.NewDelegators)
+	assert.Equal(t, 3, report.LostDelegators)
+}
+
+func TestGenerator_GenerateFailsOnStoreError(t *testing.T) {
+	validators, delegations := newStores()
+	delegations.err = errors.New("connection refused")
+
+	_, err := reports.NewGenerator(validators, delegations, 5).Generate(context.Background(), "acme", models.DigestDaily, until)
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func TestRender(t *testing.T) {
+	validators, delegations := newStores()
+	report, err := reports.NewGenerator(validators, delegations, 5).Generate(context.Background(), "acme", models.DigestDaily, until)
+	require.NoError(t, err)
+
+	message, err := reports.Render(report)
+	require.NoError(t, err)
+	assert.Equal(t, "Daily delegation digest: acme, May 7, 2024 07:00 UTC – May 8, 2024 07:00 UTC", message.Subject)
+
+	// html/template escapes "+" as "&#43;"
+	for _, body := range []string{message.Text, html.UnescapeString(message.HTML)} {
+		assert.Contains(t, body, "Alpha")
+		assert.Contains(t, body, "alpha-node")
+		assert.Contains(t, body, "1,234,567.89")
+		assert.Contains(t, body, "+2,500.50")
+		assert.Contains(t, body, "-1,000.00")
+		assert.Contains(t, body, "+1,500.50")
+		assert.Contains(t, body, "cosmos1whale")
+		assert.Contains(t, body, "-2,499.50")
+	}
+	assert.Contains(t, message.Text, "  Top movers:\n    +5,000.00  cosmos1whale\n")
+	assert.True(t, strings.HasPrefix(message.HTML, "<!DOCTYPE html>"))
+}
+
+func TestRender_EscapesHTML(t *testing.T) {
+	report := &reports.Report{
+		Tag:         "acme",
+		Frequency:   models.DigestWeekly,
+		Since:       until.Add(-7 * 24 * time.Hour),
+		Until:       until,
+		StakeChange: "0",
+		Validators:  []reports.ValidatorReport{{Name: "<script>alert(1)</script>", Address: validatorA, TotalShares: "0"}},
+	}
+
+	message, err := reports.Render(report)
+	require.NoError(t, err)
+	assert.NotContains(t, message.HTML, "<script>")
+	assert.Contains(t, message.HTML, "&lt;script&gt;")
+	assert.Contains(t, message.Text, "<script>alert(1)</script>")
+}
+
+func TestRender_NoValidators(t *testing.T) {
+	report := &reports.Report{Tag: "empty", Frequency: models.DigestDaily, Since: until.Add(-24 * time.Hour), Until: until, StakeChange: "0"}
+
+	message, err := reports.Render(report)
+	require.NoError(t, err)
+	assert.Contains(t, message.Text, "No validators carry the tag empty.")
+	assert.Contains(t, message.Text, "Stake change:    0.00")
+}
+
+// memoryDigestStore keeps email digests and their send attempts in memory
+type memoryDigestStore struct {
+	store.EmailDigestStore
+	digests []models.EmailDigest
+	sends   map[int]error
+}
+
+func (s *memoryDigestStore) GetEnabled(ctx context.Context, frequency models.DigestFrequency) ([]models.EmailDigest, error) {
+	var enabled []models.EmailDigest
+	for _, digest := range s.digests {
+		if digest.Enabled && digest.Frequency == frequency {
+			enabled = append(enabled, digest)
+		}
+	}
+	return enabled, nil
+}
+
+func (s *memoryDigestStore) GetByID(ctx context.Context, id int) (*models.EmailDigest, error) {
+	for _, digest := range s.digests {
+		if digest.ID == id {
+			return &digest, nil
+		}
+	}
+	return nil, store.ErrEmailDigestNotFound
+}
+
+func (s *memoryDigestStore) RecordSend(ctx context.Context, id int, attemptedAt time.Time, sendErr error) error {
+	s.sends[id] = sendErr
+	return nil
+}
+
+// fakeSender records messages and fails for the recipients in failFor
+type fakeSender struct {
+	messages []email.Message
+	failFor  string
+}
+
+func (s *fakeSender) Send(ctx context.Context, message email.Message) error {
+	if message.To[0] == s.failFor {
+		return errors.New("550 mailbox unavailable")
+	}
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+func TestEmailDigestTask_SendDigests(t *testing.T) {
+	validators, delegations := newStores()
+	digests := &memoryDigestStore{sends: make(map[int]error), digests: []models.EmailDigest{
+		{ID: 1, Tag: "acme", Frequency: models.DigestWeekly, Recipients: []string{"am@example.com"}, Enabled: true},
+		{ID: 2, Tag: "acme", Frequency: models.DigestWeekly, Recipients: []string{"broken@example.com"}, Enabled: true},
+		{ID: 3, Tag: "acme", Frequency: models.DigestDaily, Recipients: []string{"daily@example.com"}, Enabled: true},
+		{ID: 4, Tag: "acme", Frequency: models.DigestWeekly, Recipients: []string{"off@example.com"}},
+	}}
+	sender := &fakeSender{failFor: "broken@example.com"}
+	task := tasks.NewEmailDigestTask(digests, reports.NewGenerator(validators, delegations, 5), sender)
+
+	err := task.SendDigests(context.Background(), models.DigestWeekly)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "550 mailbox unavailable")
+
+	require.Len(t, sender.messages, 1)
+	assert.Equal(t, []string{"am@example.com"}, sender.messages[0].To)
+	assert.Contains(t, sender.messages[0].Subject, "Weekly delegation digest: acme")
+	assert.Len(t, digests.sends, 2)
+	assert.NoError(t, digests.sends[1])
+	assert.Error(t, digests.sends[2])
+
+	// Sending on demand ignores the enabled flag
+	require.NoError(t, task.Send(context.Background(), 4))
+	assert.Equal(t, []string{"off@example.com"}, sender.messages[1].To)
+	assert.Equal(t, store.ErrEmailDigestNotFound, task.Send(context.Background(), 9))
+}
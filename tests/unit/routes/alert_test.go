@@ -0,0 +1,188 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/routes"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAlertStore keeps alert rules in memory and returns fixed alerts
+type memoryAlertStore struct {
+	validators  map[string]bool
+	rules       map[int]models.AlertRule
+	nextID      int
+	alerts      []models.Alert
+	alertFilter store.AlertFilter
+}
+
+func newMemoryAlertStore(validators ...string) *memoryAlertStore {
+	s := &memoryAlertStore{validators: make(map[string]bool), rules: make(map[int]models.AlertRule)}
+	for _, address := range validators {
+		s.validators[address] = true
+	}
+	return s
+}
+
+func (s *memoryAlertStore) GetRules(ctx context.Context) ([]models.AlertRule, error) {
+	rules := []models.AlertRule{}
+	for id := 1; id <= s.nextID; id++ {
+		if rule, ok := s.rules[id]; ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (s *memoryAlertStore) GetRule(ctx context.Context, id int) (*models.AlertRule, error) {
+	rule, ok := s.rules[id]
+	if !ok {
+		return nil, store.ErrAlertRuleNotFound
+	}
+	return &rule, nil
+}
+
+func (s *memoryAlertStore) AddRule(ctx context.Context, rule models.AlertRule) (*models.AlertRule, error) {
+	if rule.ValidatorAddress != "" && !s.validators[rule.ValidatorAddress] {
+		return nil, store.ErrValidatorNotFound
+	}
+	s.nextID++
+	rule.ID = s.nextID
+	s.rules[rule.ID] = rule
+	return &rule, nil
+}
+
+func (s *memoryAlertStore) UpdateRule(ctx context.Context, rule models.AlertRule) (*models.AlertRule, error) {
+	if rule.ValidatorAddress != "" && !s.validators[rule.ValidatorAddress] {
+		return nil, store.ErrValidatorNotFound
+	}
+	if _, ok := s.rules[rule.ID]; !ok {
+		return nil, store.ErrAlertRuleNotFound
+	}
+	s.rules[rule.ID] = rule
+	return &rule, nil
+}
+
+func (s *memoryAlertStore) DeleteRule(ctx context.Context, id int) error {
+	if _, ok := s.rules[id]; !ok {
+		return store.ErrAlertRuleNotFound
+	}
+	delete(s.rules, id)
+	return nil
+}
+
+func (s *memoryAlertStore) GetActiveRules(ctx context.Context, validatorAddress string) ([]models.AlertRule, error) {
+	return nil, nil
+}
+
+func (s *memoryAlertStore) RecordAlerts(ctx context.Context, alerts []models.Alert) ([]models.Alert, error) {
+	return alerts, nil
+}
+
+func (s *memoryAlertStore) ListAlerts(ctx context.Context, filter store.AlertFilter) ([]models.Alert, error) {
+	s.alertFilter = filter
+	return s.alerts, nil
+}
+
+func setupAlertRouter(alertStore *memoryAlertStore) http.Handler {
+	return routes.SetupRouter(routes.Dependencies{
+		ValidatorStore: newMemoryValidatorStore(),
+		AlertStore:     alertStore,
+	})
+}
+
+func TestAlertRules(t *testing.T) {
+	alertStore := newMemoryAlertStore("val1")
+	router := setupAlertRouter(alertStore)
+
+	rec := doRequest(router, "POST", "/api/v1/alert-rules", `{"name": "Whales", "min_delta": 1000000}`, nil)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, models.AlertRule{ID: 1, Name: "Whales", MinDelta: "1000000", Enabled: true}, alertStore.rules[1])
+
+	rec = doRequest(router, "POST", "/api/v1/alert-rules",
+		`{"name": "val1 movers", "validator_address": "val1", "min_delta": "500.5", "min_stake_percent": "2.5", "enabled": false}`, nil)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, models.AlertRule{ID: 2, Name: "val1 movers", ValidatorAddress: "val1", MinDelta: "500.5", MinStakePercent: "2.5"}, alertStore.rules[2])
+
+	rec = doRequest(router, "PUT", "/api/v1/alert-rules/2", `{"name": "val1 movers", "validator_address": "val1", "min_stake_percent": 5}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, models.AlertRule{ID: 2, Name: "val1 movers", ValidatorAddress: "val1", MinStakePercent: "5", Enabled: true}, alertStore.rules[2])
+
+	rec = doRequest(router, "GET", "/api/v1/alert-rules", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var list struct {
+		Data struct {
+			Rules []models.AlertRule `json:"rules"`
+			Count int                `json:"count"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Equal(t, 2, list.Data.Count)
+
+	rec = doRequest(router, "DELETE", "/api/v1/alert-rules/1", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotContains(t, alertStore.rules, 1)
+
+	rec = doRequest(router, "GET", "/api/v1/alert-rules/1", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestAlertRules_RejectsInvalidRules(t *testing.T) {
+	router := setupAlertRouter(newMemoryAlertStore("val1"))
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"missing name", "POST", "/api/v1/alert-rules", `{"min_delta": 10}`, http.StatusBadRequest},
+		{"missing thresholds", "POST", "/api/v1/alert-rules", `{"name": "Whales"}`, http.StatusBadRequest},
+		{"negative delta", "POST", "/api/v1/alert-rules", `{"name": "Whales", "min_delta": -10}`, http.StatusBadRequest},
+		{"exponent delta", "POST", "/api/v1/alert-rules", `{"name": "Whales", "min_delta": 1e6}`, http.StatusBadRequest},
+		{"percent above 100", "POST", "/api/v1/alert-rules", `{"name": "Whales", "min_stake_percent": 101}`, http.StatusBadRequest},
+		{"unknown validator", "POST", "/api/v1/alert-rules", `{"name": "Whales", "validator_address": "val2", "min_delta": 10}`, http.StatusBadRequest},
+		{"unknown rule", "PUT", "/api/v1/alert-rules/9", `{"name": "Whales", "min_delta": 10}`, http.StatusNotFound},
+		{"invalid rule ID", "DELETE", "/api/v1/alert-rules/abc", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(router, tt.method, tt.path, tt.body, nil)
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestAlerts(t *testing.T) {
+	ruleID := 1
+	alertStore := newMemoryAlertStore()
+	alertStore.alerts = []models.Alert{{ID: 7, RuleID: &ruleID, RuleName: "Whales", DelegationEventID: 3, ValidatorAddress: "val1",
+		DelegatorAddress: "del1", EventType: models.DelegationEventExit, Delta: "-2000000", StakePercent: "1.5000"}}
+	router := setupAlertRouter(alertStore)
+
+	rec := doRequest(router, "GET", "/api/v1/alerts?validator_address=val1&rule_id=1&since=2024-05-01T00:00:00Z&limit=10", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, store.AlertFilter{ValidatorAddress: "val1", RuleID: 1, Since: &since, Limit: 10}, alertStore.alertFilter)
+
+	var response struct {
+		Data struct {
+			Alerts []models.Alert `json:"alerts"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, alertStore.alerts, response.Data.Alerts)
+
+	rec = doRequest(router, "GET", "/api/v1/alerts?rule_id=0&limit=5000", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+}
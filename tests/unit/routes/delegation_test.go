@@ -0,0 +1,114 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/routes"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDelegationStore returns fixed stake summaries and delegation events
+type fakeDelegationStore struct {
+	store.DelegationStore
+	stakes      map[string][]models.ValidatorStake
+	events      []models.DelegationEvent
+	eventFilter store.DelegationEventFilter
+}
+
+func (s *fakeDelegationStore) GetStakeSummary(ctx context.Context, tag string) ([]models.ValidatorStake, error) {
+	return s.stakes[tag], nil
+}
+
+func (s *fakeDelegationStore) GetDelegationEvents(ctx context.Context, filter store.DelegationEventFilter) ([]models.DelegationEvent, error) {
+	s.eventFilter = filter
+	return s.events, nil
+}
+
+func setupDelegationRouter(delegationStore *fakeDelegationStore) http.Handler {
+	return routes.SetupRouter(routes.Dependencies{
+		ValidatorStore:  newMemoryValidatorStore(),
+		DelegationStore: delegationStore,
+	})
+}
+
+func TestDelegationEvents(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	delegationStore := &fakeDelegationStore{events: []models.DelegationEvent{
+		{ID: 2, ValidatorAddress: "val1", DelegatorAddress: "del1", Type: models.DelegationEventExit,
+			PreviousShares: "5.000000000000000000", Shares: "0.000000000000000000", Delta: "-5.000000000000000000", CreatedAt: createdAt},
+	}}
+	router := setupDelegationRouter(delegationStore)
+
+	rec := doRequest(router, "GET", "/api/v1/validators/val1/delegations/events?type=exit,decrease&type=new"+
+		"&min_delta=2.5&since=2024-05-01T00:00:00Z&until=2024-05-02T00:00:00Z&limit=10&offset=5", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+	assert.Equal(t, store.DelegationEventFilter{
+		ValidatorAddress: "val1",
+		Types:            []models.DelegationEventType{models.DelegationEventExit, models.DelegationEventDecrease, models.DelegationEventNew},
+		MinDelta:         "2.5",
+		Since:            &since,
+		Until:            &until,
+		Limit:            10,
+		Offset:           5,
+	}, delegationStore.eventFilter)
+
+	var resp struct {
+		Data struct {
+			Events []models.DelegationEvent `json:"events"`
+			Count  int                      `json:"count"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, delegationStore.events, resp.Data.Events)
+	assert.Equal(t, 1, resp.Data.Count)
+}
+
+func TestDelegationEvents_RejectsInvalidFilters(t *testing.T) {
+	router := setupDelegationRouter(&fakeDelegationStore{})
+
+	for _, query := range []string{"type=grow", "min_delta=-1", "min_delta=1/2", "min_delta=abc", "since=yesterday", "limit=0", "offset=-1"} {
+		t.Run(query, func(t *testing.T) {
+			rec := doRequest(router, "GET", "/api/v1/validators/val1/delegations/events?"+query, "", nil)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestDelegationSummary(t *testing.T) {
+	delegationStore := &fakeDelegationStore{stakes: map[string][]models.ValidatorStake{
+		"client-a": {
+			{ValidatorAddress: "val1", Delegators: 2, TotalShares: "1000.500000000000000000"},
+			{ValidatorAddress: "val2", Delegators: 1, TotalShares: "0.000000000000000001"},
+		},
+	}}
+	router := setupDelegationRouter(delegationStore)
+
+	rec := doRequest(router, "GET", "/api/v1/delegations/summary?tag=Client-A", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp struct {
+		Data struct {
+			Tag              string                  `json:"tag"`
+			Validators       []models.ValidatorStake `json:"validators"`
+			TotalDelegations int                     `json:"total_delegations"`
+			TotalShares      string                  `json:"total_shares"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "client-a", resp.Data.Tag)
+	assert.Len(t, resp.Data.Validators, 2)
+	assert.Equal(t, 3, resp.Data.TotalDelegations)
+	assert.Equal(t, "1000.500000000000000001", resp.Data.TotalShares)
+
+	rec = doRequest(router, "GET", "/api/v1/delegations/summary?tag=a%20b", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+}
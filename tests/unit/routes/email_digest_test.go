@@ -0,0 +1,193 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/routes"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryEmailDigestStore keeps email digests in memory
+type memoryEmailDigestStore struct {
+	store.EmailDigestStore
+	digests map[int]models.EmailDigest
+	nextID  int
+}
+
+func newMemoryEmailDigestStore() *memoryEmailDigestStore {
+	return &memoryEmailDigestStore{digests: make(map[int]models.EmailDigest)}
+}
+
+func (s *memoryEmailDigestStore) GetAll(ctx context.Context) ([]models.EmailDigest, error) {
+	digests := []models.EmailDigest{}
+	for id := 1; id <= s.nextID; id++ {
+		if digest, ok := s.digests[id]; ok {
+			digests = append(digests, digest)
+		}
+	}
+	return digests, nil
+}
+
+func (s *memoryEmailDigestStore) GetByID(ctx context.Context, id int) (*models.EmailDigest, error) {
+	digest, ok := s.digests[id]
+	if !ok {
+		return nil, store.ErrEmailDigestNotFound
+	}
+	return &digest, nil
+}
+
+func (s *memoryEmailDigestStore) Add(ctx context.Context, digest models.EmailDigest) (*models.EmailDigest, error) {
+	s.nextID++
+	digest.ID = s.nextID
+	s.digests[digest.ID] = digest
+	return &digest, nil
+}
+
+func (s *memoryEmailDigestStore) Update(ctx context.Context, digest models.EmailDigest) (*models.EmailDigest, error) {
+	if _, ok := s.digests[digest.ID]; !ok {
+		return nil, store.ErrEmailDigestNotFound
+	}
+	s.digests[digest.ID] = digest
+	return &digest, nil
+}
+
+func (s *memoryEmailDigestStore) Delete(ctx context.Context, id int) error {
+	if _, ok := s.digests[id]; !ok {
+		return store.ErrEmailDigestNotFound
+	}
+	delete(s.digests, id)
+	return nil
+}
+
+// fakeEmailDigestSender records sent digests and fails with err
+type fakeEmailDigestSender struct {
+	store *memoryEmailDigestStore
+	err   error
+	sent  []int
+}
+
+func (s *fakeEmailDigestSender) Send(ctx context.Context, digestID int) error {
+	if _, err := s.store.GetByID(ctx, digestID); err != nil {
+		return err
+	}
+	s.sent = append(s.sent, digestID)
+	return s.err
+}
+
+func setupEmailDigestRouter(digestStore *memoryEmailDigestStore, sender routes.EmailDigestSender) http.Handler {
+	return routes.SetupRouter(routes.Dependencies{
+		ValidatorStore:    newMemoryValidatorStore(),
+		EmailDigestStore:  digestStore,
+		EmailDigestSender: sender,
+	})
+}
+
+func TestEmailDigests(t *testing.T) {
+	digestStore := newMemoryEmailDigestStore()
+	router := setupEmailDigestRouter(digestStore, nil)
+
+	rec := doRequest(router, "POST", "/api/v1/admin/email-digests",
+		`{"tag": " Acme ", "frequency": "weekly",
+		  "recipients": ["Ops <ops@example.com>", "OPS@example.com", " dev@example.com "]}`, nil)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	stored := digestStore.digests[1]
+	assert.Equal(t, "acme", stored.Tag)
+	assert.Equal(t, models.DigestWeekly, stored.Frequency)
+	assert.Equal(t, []string{"Ops <ops@example.com>", "dev@example.com"}, stored.Recipients)
+	assert.True(t, stored.Enabled)
+
+	rec = doRequest(router, "PUT", "/api/v1/admin/email-digests/1",
+		`{"tag": "acme", "frequency": "daily", "recipients": ["ops@example.com"], "enabled": false}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	stored = digestStore.digests[1]
+	assert.Equal(t, models.DigestDaily, stored.Frequency)
+	assert.Equal(t, []string{"ops@example.com"}, stored.Recipients)
+	assert.False(t, stored.Enabled)
+
+	rec = doRequest(router, "GET", "/api/v1/admin/email-digests", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var list struct {
+		Data struct {
+			Digests []models.EmailDigest `json:"digests"`
+			Count   int                  `json:"count"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.Data.Count)
+	assert.Equal(t, "acme", list.Data.Digests[0].Tag)
+
+	// Email is not configured
+	rec = doRequest(router, "POST", "/api/v1/admin/email-digests/1/send", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+
+	rec = doRequest(router, "DELETE", "/api/v1/admin/email-digests/1", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotContains(t, digestStore.digests, 1)
+
+	rec = doRequest(router, "GET", "/api/v1/admin/email-digests/1", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestEmailDigests_RejectsInvalidDigests(t *testing.T) {
+	router := setupEmailDigestRouter(newMemoryEmailDigestStore(), nil)
+	recipients := make([]string, 51)
+	for i := range recipients {
+		recipients[i] = fmt.Sprintf(`"user%d@example.com"`, i)
+	}
+	tooMany := strings.Join(recipients, ", ")
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"missing tag", "POST", "/api/v1/admin/email-digests", `{"frequency": "daily", "recipients": ["a@example.com"]}`, http.StatusBadRequest},
+		{"invalid tag", "POST", "/api/v1/admin/email-digests", `{"tag": "a b", "frequency": "daily", "recipients": ["a@example.com"]}`, http.StatusBadRequest},
+		{"unknown frequency", "POST", "/api/v1/admin/email-digests", `{"tag": "acme", "frequency": "monthly", "recipients": ["a@example.com"]}`, http.StatusBadRequest},
+		{"no recipients", "POST", "/api/v1/admin/email-digests", `{"tag": "acme", "frequency": "daily", "recipients": []}`, http.StatusBadRequest},
+		{"invalid recipient", "POST", "/api/v1/admin/email-digests", `{"tag": "acme", "frequency": "daily", "recipients": ["not an address"]}`, http.StatusBadRequest},
+		{"too many recipients", "POST", "/api/v1/admin/email-digests", `{"tag": "acme", "frequency": "daily", "recipients": [` + tooMany + `]}`, http.StatusBadRequest},
+		{"invalid body", "POST", "/api/v1/admin/email-digests", `{"tag":`, http.StatusBadRequest},
+		{"unknown digest", "PUT", "/api/v1/admin/email-digests/9", `{"tag": "acme", "frequency": "daily", "recipients": ["a@example.com"]}`, http.StatusNotFound},
+		{"invalid digest ID", "DELETE", "/api/v1/admin/email-digests/abc", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(router, tt.method, tt.path, tt.body, nil)
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestEmailDigests_Send(t *testing.T) {
+	digestStore := newMemoryEmailDigestStore()
+	digestStore.Add(context.Background(), models.EmailDigest{Tag: "acme", Frequency: models.DigestDaily,
+		Recipients: []string{"ops@example.com"}})
+	sender := &fakeEmailDigestSender{store: digestStore}
+	router := setupEmailDigestRouter(digestStore, sender)
+
+	rec := doRequest(router, "POST", "/api/v1/admin/email-digests/1/send", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, []int{1}, sender.sent)
+
+	sender.err = errors.New("error authenticating: 535 authentication failed")
+	rec = doRequest(router, "POST", "/api/v1/admin/email-digests/1/send", "", nil)
+	assert.Equal(t, http.StatusBadGateway, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "authentication failed")
+
+	rec = doRequest(router, "POST", "/api/v1/admin/email-digests/2/send", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
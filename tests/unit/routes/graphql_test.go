@@ -0,0 +1,101 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/routes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupGraphQLRouter() http.Handler {
+	return routes.SetupRouter(routes.Dependencies{
+		ValidatorStore:  newMemoryValidatorStore(models.Validator{Address: "val1", Name: "Validator 1", EnabledTracking: true}),
+		DelegationStore: &fakeDelegationStore{},
+	})
+}
+
+// graphQLResponse is the body of a GraphQL response
+type graphQLResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func TestGraphQL_Post(t *testing.T) {
+	router := setupGraphQLRouter()
+
+	rec := doRequest(router, "POST", "/api/v1/graphql",
+		`{"query": "query ($address: String!) { validator(address: $address) { name enabledTracking } }", "variables": {"address": "val1"}}`,
+		map[string]string{"Content-Type": "application/json"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var resp graphQLResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, map[string]interface{}{"validator": map[string]interface{}{"name": "Validator 1", "enabledTracking": true}}, resp.Data)
+
+	rec = doRequest(router, "POST", "/api/v1/graphql", `{ validator(address: "val1") { name } }`,
+		map[string]string{"Content-Type": "application/graphql"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// GraphQL clients default to /graphql
+	rec = doRequest(router, "POST", "/graphql", `{"query": "{ validator(address: \"val1\") { name } }"}`,
+		map[string]string{"Content-Type": "application/json"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"data": {"validator": {"name": "Validator 1"}}}`, rec.Body.String())
+}
+
+func TestGraphQL_Get(t *testing.T) {
+	router := setupGraphQLRouter()
+
+	query := url.Values{
+		"query":     {"query ($address: String!) { validator(address: $address) { name } }"},
+		"variables": {`{"address": "val1"}`},
+	}
+	rec := doRequest(router, "GET", "/api/v1/graphql?"+query.Encode(), "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"data": {"validator": {"name": "Validator 1"}}}`, rec.Body.String())
+}
+
+func TestGraphQL_RejectsInvalidRequests(t *testing.T) {
+	router := setupGraphQLRouter()
+
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{"invalid JSON", `{"query": `, "Invalid request body: unexpected end of JSON input"},
+		{"no query", `{}`, "Must provide a query"},
+		{"invalid query", `{"query": "{ validator(address: \"val1\") { stake } }"}`,
+			`Field "stake" of type "Stake!" must have a sub selection.`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(router, "POST", "/api/v1/graphql", tt.body, map[string]string{"Content-Type": "application/json"})
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+
+			var resp graphQLResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			require.Len(t, resp.Errors, 1)
+			assert.Equal(t, tt.message, resp.Errors[0].Message)
+			assert.NotContains(t, rec.Body.String(), `"data"`)
+		})
+	}
+}
+
+func TestGraphQL_Schema(t *testing.T) {
+	router := setupGraphQLRouter()
+
+	rec := doRequest(router, "GET", "/api/v1/graphql/schema", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "type Validator {")
+}
@@ -0,0 +1,202 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/routes"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryNotificationStore keeps notification channels in memory
+type memoryNotificationStore struct {
+	store.NotificationChannelStore
+	channels map[int]models.NotificationChannel
+	nextID   int
+}
+
+func newMemoryNotificationStore() *memoryNotificationStore {
+	return &memoryNotificationStore{channels: make(map[int]models.NotificationChannel)}
+}
+
+func (s *memoryNotificationStore) GetAll(ctx context.Context) ([]models.NotificationChannel, error) {
+	channels := []models.NotificationChannel{}
+	for id := 1; id <= s.nextID; id++ {
+		if channel, ok := s.channels[id]; ok {
+			channels = append(channels, channel)
+		}
+	}
+	return channels, nil
+}
+
+func (s *memoryNotificationStore) GetByID(ctx context.Context, id int) (*models.NotificationChannel, error) {
+	channel, ok := s.channels[id]
+	if !ok {
+		return nil, store.ErrNotificationChannelNotFound
+	}
+	return &channel, nil
+}
+
+func (s *memoryNotificationStore) Add(ctx context.Context, channel models.NotificationChannel) (*models.NotificationChannel, error) {
+	s.nextID++
+	channel.ID = s.nextID
+	s.channels[channel.ID] = channel
+	return &channel, nil
+}
+
+func (s *memoryNotificationStore) Update(ctx context.Context, channel models.NotificationChannel) (*models.NotificationChannel, error) {
+	if _, ok := s.channels[channel.ID]; !ok {
+		return nil, store.ErrNotificationChannelNotFound
+	}
+	s.channels[channel.ID] = channel
+	return &channel, nil
+}
+
+func (s *memoryNotificationStore) Delete(ctx context.Context, id int) error {
+	if _, ok := s.channels[id]; !ok {
+		return store.ErrNotificationChannelNotFound
+	}
+	delete(s.channels, id)
+	return nil
+}
+
+// fakeNotificationTester records test notifications and fails with err
+type fakeNotificationTester struct {
+	store  *memoryNotificationStore
+	err    error
+	tested []int
+}
+
+func (t *fakeNotificationTester) Test(ctx context.Context, channelID int) error {
+	if _, err := t.store.GetByID(ctx, channelID); err != nil {
+		return err
+	}
+	t.tested = append(t.tested, channelID)
+	return t.err
+}
+
+func setupNotificationRouter(notificationStore *memoryNotificationStore, tester routes.NotificationTester) http.Handler {
+	return routes.SetupRouter(routes.Dependencies{
+		ValidatorStore:     newMemoryValidatorStore(),
+		NotificationStore:  notificationStore,
+		NotificationTester: tester,
+	})
+}
+
+func TestNotificationChannels(t *testing.T) {
+	notificationStore := newMemoryNotificationStore()
+	router := setupNotificationRouter(notificationStore, nil)
+
+	rec := doRequest(router, "POST", "/api/v1/admin/notification-channels",
+		`{"name": " Ops ", "type": "slack", "webhookUrl": "https://hooks.slack.com/services/T/B/secret",
+		  "eventTypes": ["sync.failed", "sync.failed", "alert.fired"], "syncFailureThreshold": 3,
+		  "templates": {"alert.fired": "{{.Data.rule_name}} fired"}}`, nil)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.NotContains(t, rec.Body.String(), "hooks.slack.com")
+
+	stored := notificationStore.channels[1]
+	assert.Equal(t, "Ops", stored.Name)
+	assert.Equal(t, "https://hooks.slack.com/services/T/B/secret", stored.WebhookURL)
+	assert.Equal(t, []models.EventType{models.EventSyncFailed, models.EventAlertFired}, stored.EventTypes)
+	assert.Equal(t, 3, stored.SyncFailureThreshold)
+	assert.Equal(t, map[models.EventType]string{models.EventAlertFired: "{{.Data.rule_name}} fired"}, stored.Templates)
+	assert.True(t, stored.Enabled)
+
+	// Updates keep the webhook URL unless the type changes
+	rec = doRequest(router, "PUT", "/api/v1/admin/notification-channels/1",
+		`{"name": "Ops", "type": "slack", "eventTypes": ["delegation.changed"], "enabled": false}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	stored = notificationStore.channels[1]
+	assert.Equal(t, "https://hooks.slack.com/services/T/B/secret", stored.WebhookURL)
+	assert.Equal(t, 1, stored.SyncFailureThreshold)
+	assert.Nil(t, stored.Templates)
+	assert.False(t, stored.Enabled)
+
+	rec = doRequest(router, "PUT", "/api/v1/admin/notification-channels/1",
+		`{"name": "Ops", "type": "telegram", "chatId": "-100", "eventTypes": ["delegation.changed"]}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "botToken is required")
+
+	rec = doRequest(router, "GET", "/api/v1/admin/notification-channels", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var list struct {
+		Data struct {
+			Channels []models.NotificationChannel `json:"channels"`
+			Count    int                          `json:"count"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.Data.Count)
+	assert.NotContains(t, rec.Body.String(), "hooks.slack.com")
+
+	// No tester is configured
+	rec = doRequest(router, "POST", "/api/v1/admin/notification-channels/1/test", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+
+	rec = doRequest(router, "DELETE", "/api/v1/admin/notification-channels/1", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotContains(t, notificationStore.channels, 1)
+
+	rec = doRequest(router, "GET", "/api/v1/admin/notification-channels/1", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestNotificationChannels_RejectsInvalidChannels(t *testing.T) {
+	router := setupNotificationRouter(newMemoryNotificationStore(), nil)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"missing name", "POST", "/api/v1/admin/notification-channels", `{"type": "slack", "webhookUrl": "https://example.com", "eventTypes": ["alert.fired"]}`, http.StatusBadRequest},
+		{"unknown type", "POST", "/api/v1/admin/notification-channels", `{"name": "a", "type": "email", "eventTypes": ["alert.fired"]}`, http.StatusBadRequest},
+		{"missing webhook URL", "POST", "/api/v1/admin/notification-channels", `{"name": "a", "type": "discord", "eventTypes": ["alert.fired"]}`, http.StatusBadRequest},
+		{"relative webhook URL", "POST", "/api/v1/admin/notification-channels", `{"name": "a", "type": "slack", "webhookUrl": "/hook", "eventTypes": ["alert.fired"]}`, http.StatusBadRequest},
+		{"missing chat ID", "POST", "/api/v1/admin/notification-channels", `{"name": "a", "type": "telegram", "botToken": "1:abc", "eventTypes": ["alert.fired"]}`, http.StatusBadRequest},
+		{"invalid bot token", "POST", "/api/v1/admin/notification-channels", `{"name": "a", "type": "telegram", "botToken": "1/abc", "chatId": "1", "eventTypes": ["alert.fired"]}`, http.StatusBadRequest},
+		{"no event types", "POST", "/api/v1/admin/notification-channels", `{"name": "a", "type": "slack", "webhookUrl": "https://example.com"}`, http.StatusBadRequest},
+		{"unknown event type", "POST", "/api/v1/admin/notification-channels", `{"name": "a", "type": "slack", "webhookUrl": "https://example.com", "eventTypes": ["validator.created"]}`, http.StatusBadRequest},
+		{"zero threshold", "POST", "/api/v1/admin/notification-channels", `{"name": "a", "type": "slack", "webhookUrl": "https://example.com", "eventTypes": ["sync.failed"], "syncFailureThreshold": 0}`, http.StatusBadRequest},
+		{"invalid template", "POST", "/api/v1/admin/notification-channels", `{"name": "a", "type": "slack", "webhookUrl": "https://example.com", "eventTypes": ["alert.fired"], "templates": {"alert.fired": "{{.Data"}}`, http.StatusBadRequest},
+		{"template of unknown type", "POST", "/api/v1/admin/notification-channels", `{"name": "a", "type": "slack", "webhookUrl": "https://example.com", "eventTypes": ["alert.fired"], "templates": {"other": "x"}}`, http.StatusBadRequest},
+		{"invalid body", "POST", "/api/v1/admin/notification-channels", `{"name":`, http.StatusBadRequest},
+		{"unknown channel", "PUT", "/api/v1/admin/notification-channels/9", `{"name": "a", "type": "slack", "webhookUrl": "https://example.com", "eventTypes": ["alert.fired"]}`, http.StatusNotFound},
+		{"invalid channel ID", "DELETE", "/api/v1/admin/notification-channels/abc", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(router, tt.method, tt.path, tt.body, nil)
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestNotificationChannels_Test(t *testing.T) {
+	notificationStore := newMemoryNotificationStore()
+	notificationStore.Add(context.Background(), models.NotificationChannel{Name: "ops", Type: models.NotificationDiscord,
+		WebhookURL: "https://example.com", EventTypes: []models.EventType{models.EventAlertFired}})
+	tester := &fakeNotificationTester{store: notificationStore}
+	router := setupNotificationRouter(notificationStore, tester)
+
+	rec := doRequest(router, "POST", "/api/v1/admin/notification-channels/1/test", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, []int{1}, tester.tested)
+
+	tester.err = errors.New("unexpected status code 404: unknown webhook")
+	rec = doRequest(router, "POST", "/api/v1/admin/notification-channels/1/test", "", nil)
+	assert.Equal(t, http.StatusBadGateway, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "unknown webhook")
+
+	rec = doRequest(router, "POST", "/api/v1/admin/notification-channels/2/test", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
@@ -0,0 +1,235 @@
+package routes_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/routes"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/novintriantonius/cosmos-validator-service/internal/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storedEventStore returns stored delegation events for resumed streams
+type storedEventStore struct {
+	store.DelegationStore
+	events []models.DelegationEvent
+}
+
+func (s *storedEventStore) GetDelegationEventsAfter(ctx context.Context, validatorAddress string, afterID int64, limit int) ([]models.DelegationEvent, error) {
+	events := []models.DelegationEvent{}
+	for _, event := range s.events {
+		if event.ID > afterID && (validatorAddress == "" || event.ValidatorAddress == validatorAddress) && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func delegationChange(id int64, validatorAddress string) models.DelegationEvent {
+	return models.DelegationEvent{ID: id, ValidatorAddress: validatorAddress, DelegatorAddress: "del1",
+		Type: models.DelegationEventIncrease, PreviousShares: "100", Shares: "150", Delta: "50"}
+}
+
+// sseEvent is an event or comment read from an SSE stream
+type sseEvent struct {
+	ID      string
+	Event   string
+	Data    string
+	Comment string
+}
+
+// sseClient reads the events of a streaming response
+type sseClient struct {
+	t      *testing.T
+	reader *bufio.Reader
+}
+
+// openStream requests a stream and reads its opening retry field
+func openStream(t *testing.T, server *httptest.Server, path string, headers map[string]string) *sseClient {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL+path, nil)
+	require.NoError(t, err)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	client := &sseClient{t: t, reader: bufio.NewReader(resp.Body)}
+	line, err := client.reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "retry: 5000\n", line)
+	client.reader.ReadString('\n')
+	return client
+}
+
+// next reads the next event or comment
+func (c *sseClient) next() sseEvent {
+	var event sseEvent
+	for {
+		line, err := c.reader.ReadString('\n')
+		require.NoError(c.t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return event
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Event = value
+		case "data":
+			event.Data = value
+		case "":
+			event.Comment = value
+		}
+	}
+}
+
+func setupStreamServer(t *testing.T, delegationStore store.DelegationStore, heartbeat time.Duration) (*httptest.Server, *stream.Broker) {
+	broker := stream.NewBroker(10)
+	router := routes.SetupRouter(routes.Dependencies{
+		ValidatorStore:   newMemoryValidatorStore(),
+		DelegationStore:  delegationStore,
+		DelegationStream: broker,
+		StreamHeartbeat:  heartbeat,
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	t.Cleanup(broker.Close)
+	return server, broker
+}
+
+func publishChanges(t *testing.T, broker *stream.Broker, changes ...models.DelegationEvent) {
+	for _, change := range changes {
+		event, err := events.New(models.EventDelegationChanged, change.ValidatorAddress, change)
+		require.NoError(t, err)
+		require.NoError(t, broker.Publish(context.Background(), []models.Event{event}))
+	}
+}
+
+func TestDelegationStream_PushesLiveChanges(t *testing.T) {
+	server, broker := setupStreamServer(t, &storedEventStore{}, time.Minute)
+	client := openStream(t, server, "/api/v1/stream/delegations?validator=val1", nil)
+
+	publishChanges(t, broker, delegationChange(7, "val2"), delegationChange(8, "val1"))
+
+	event := client.next()
+	assert.Equal(t, "8", event.ID)
+	assert.Equal(t, "delegation.changed", event.Event)
+	var change models.DelegationEvent
+	require.NoError(t, json.Unmarshal([]byte(event.Data), &change))
+	assert.Equal(t, delegationChange(8, "val1"), change)
+}
+
+func TestDelegationStream_ResumesFromLastEventID(t *testing.T) {
+	delegationStore := &storedEventStore{events: []models.DelegationEvent{
+		delegationChange(1, "val1"), delegationChange(2, "val2"), delegationChange(3, "val1"), delegationChange(4, "val1"),
+	}}
+	server, broker := setupStreamServer(t, delegationStore, time.Minute)
+	client := openStream(t, server, "/api/v1/stream/delegations?validator=val1", map[string]string{"Last-Event-ID": "1"})
+
+	assert.Equal(t, "3", client.next().ID)
+	assert.Equal(t, "4", client.next().ID)
+
+	// Changes that were already replayed are not sent twice
+	publishChanges(t, broker, delegationChange(4, "val1"), delegationChange(5, "val1"))
+	assert.Equal(t, "5", client.next().ID)
+
+	// Clients restoring a stream on their first connection pass the ID in the query string
+	client = openStream(t, server, "/api/v1/stream/delegations?validator=val1&last_event_id=3", nil)
+	assert.Equal(t, "4", client.next().ID)
+}
+
+func TestDelegationStream_OfEveryValidatorIsNotResumable(t *testing.T) {
+	server, broker := setupStreamServer(t, &storedEventStore{}, time.Minute)
+	client := openStream(t, server, "/api/v1/stream/delegations", nil)
+
+	// The change of val1 can be relayed after the later change of val2, so
+	// the stream carries no IDs to resume from
+	publishChanges(t, broker, delegationChange(12, "val2"), delegationChange(10, "val1"))
+	first, second := client.next(), client.next()
+	assert.Empty(t, first.ID)
+	assert.Contains(t, first.Data, `"id":12`)
+	assert.Empty(t, second.ID)
+	assert.Contains(t, second.Data, `"id":10`)
+
+	router := routes.SetupRouter(routes.Dependencies{
+		ValidatorStore:   newMemoryValidatorStore(),
+		DelegationStore:  &storedEventStore{},
+		DelegationStream: stream.NewBroker(1),
+	})
+	rec := doRequest(router, "GET", "/api/v1/stream/delegations", "", map[string]string{"Last-Event-ID": "12"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "requires a validator address")
+}
+
+func TestDelegationStream_SendsHeartbeats(t *testing.T) {
+	server, _ := setupStreamServer(t, &storedEventStore{}, 20*time.Millisecond)
+	client := openStream(t, server, "/api/v1/stream/delegations", nil)
+
+	assert.Equal(t, sseEvent{Comment: "heartbeat"}, client.next())
+}
+
+func TestDelegationStream_EndsWhenClientLags(t *testing.T) {
+	server, broker := setupStreamServer(t, &storedEventStore{}, time.Minute)
+	client := openStream(t, server, "/api/v1/stream/delegations?validator=val1", nil)
+
+	// Fill the buffer faster than the stream can be written
+	var changes []models.DelegationEvent
+	for id := int64(1); id <= 200; id++ {
+		changes = append(changes, delegationChange(id, "val1"))
+	}
+	event, err := events.New(models.EventDelegationChanged, "val1", changes[0])
+	require.NoError(t, err)
+	batch := make([]models.Event, 0, len(changes))
+	for _, change := range changes {
+		event.Data, _ = json.Marshal(change)
+		batch = append(batch, event)
+	}
+	require.NoError(t, broker.Publish(context.Background(), batch))
+
+	// The stream ends after the buffered changes, and the client resumes
+	// from the last one it received
+	var last int
+	for {
+		line, err := client.reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		if id, ok := strings.CutPrefix(strings.TrimSpace(line), "id: "); ok {
+			last, _ = strconv.Atoi(id)
+		}
+	}
+	assert.GreaterOrEqual(t, last, 10)
+	assert.Less(t, last, 200)
+}
+
+func TestDelegationStream_RejectsInvalidLastEventID(t *testing.T) {
+	router := routes.SetupRouter(routes.Dependencies{
+		ValidatorStore:   newMemoryValidatorStore(),
+		DelegationStore:  &storedEventStore{},
+		DelegationStream: stream.NewBroker(1),
+	})
+
+	rec := doRequest(router, "GET", "/api/v1/stream/delegations", "", map[string]string{"Last-Event-ID": "abc"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "must be a non-negative integer")
+}
@@ -0,0 +1,111 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchResponse is the body of a batch response
+type batchResponse struct {
+	Data struct {
+		Applied bool `json:"applied"`
+		Results []struct {
+			Index   int                `json:"index"`
+			Address string             `json:"address"`
+			Status  store.UpsertStatus `json:"status"`
+			Errors  []string           `json:"errors"`
+		} `json:"results"`
+		Counts map[store.UpsertStatus]int `json:"counts"`
+	} `json:"data"`
+}
+
+func batchStatuses(t *testing.T, body []byte) (bool, []store.UpsertStatus) {
+	var resp batchResponse
+	require.NoError(t, json.Unmarshal(body, &resp))
+	statuses := make([]store.UpsertStatus, len(resp.Data.Results))
+	for i, result := range resp.Data.Results {
+		assert.Equal(t, i, result.Index)
+		statuses[i] = result.Status
+	}
+	return resp.Data.Applied, statuses
+}
+
+func TestBatchValidators(t *testing.T) {
+	archivedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	existing := []models.Validator{
+		{Address: "val1", Name: "Validator 1", EnabledTracking: true},
+		{Address: "val2", Name: "Validator 2", ArchivedAt: &archivedAt},
+	}
+	body := `[
+		{"address": "val1", "name": "Renamed", "enabledTracking": false},
+		{"address": "val3", "name": "Validator 3", "enabledTracking": true},
+		{"address": "val2", "name": "Validator 2"},
+		{"address": "val4", "name": ""},
+		{"address": "val3", "name": "Again"}
+	]`
+
+	t.Run("partial", func(t *testing.T) {
+		router, validatorStore := setupRouter(existing...)
+		rec := doRequest(router, "POST", "/api/v1/validators:batch", body, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		applied, statuses := batchStatuses(t, rec.Body.Bytes())
+		assert.True(t, applied)
+		assert.Equal(t, []store.UpsertStatus{
+			store.UpsertUpdated, store.UpsertCreated, store.UpsertConflict, store.UpsertInvalid, store.UpsertConflict,
+		}, statuses)
+		assert.Equal(t, "Renamed", validatorStore.validators["val1"].Name)
+		assert.Equal(t, "Validator 3", validatorStore.validators["val3"].Name)
+		assert.NotContains(t, validatorStore.validators, "val4")
+	})
+
+	t.Run("atomic", func(t *testing.T) {
+		router, validatorStore := setupRouter(existing...)
+		rec := doRequest(router, "POST", "/api/v1/validators:batch?atomic=true", body, nil)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+		applied, statuses := batchStatuses(t, rec.Body.Bytes())
+		assert.False(t, applied)
+		assert.Equal(t, []store.UpsertStatus{
+			store.UpsertSkipped, store.UpsertSkipped, store.UpsertSkipped, store.UpsertInvalid, store.UpsertConflict,
+		}, statuses)
+		assert.Equal(t, "Validator 1", validatorStore.validators["val1"].Name)
+		assert.NotContains(t, validatorStore.validators, "val3")
+	})
+
+	t.Run("atomic with archived validator", func(t *testing.T) {
+		router, validatorStore := setupRouter(existing...)
+		rec := doRequest(router, "POST", "/api/v1/validators:batch?atomic=true",
+			`[{"address": "val3", "name": "Validator 3"}, {"address": "val2", "name": "Validator 2"}]`, nil)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+		_, statuses := batchStatuses(t, rec.Body.Bytes())
+		assert.Equal(t, []store.UpsertStatus{store.UpsertSkipped, store.UpsertConflict}, statuses)
+		assert.NotContains(t, validatorStore.validators, "val3")
+	})
+
+	t.Run("atomic success", func(t *testing.T) {
+		router, validatorStore := setupRouter(existing...)
+		rec := doRequest(router, "POST", "/api/v1/validators:batch?atomic=true",
+			`[{"address": "val3", "name": "Validator 3"}, {"address": "val1", "name": "Renamed"}]`, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		applied, statuses := batchStatuses(t, rec.Body.Bytes())
+		assert.True(t, applied)
+		assert.Equal(t, []store.UpsertStatus{store.UpsertCreated, store.UpsertUpdated}, statuses)
+		assert.Contains(t, validatorStore.validators, "val3")
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		router, _ := setupRouter(existing...)
+		rec := doRequest(router, "POST", "/api/v1/validators:batch", `[]`, nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,118 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/routes"
+	"github.com/novintriantonius/cosmos-validator-service/internal/services"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newValidatorSetServer serves a bonded validator set of valN with N million tokens, over two pages
+func newValidatorSetServer(t *testing.T, count int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, models.BondStatusBonded, r.URL.Query().Get("status"))
+
+		var page models.ChainValidatorsResponse
+		start, end := 1, count/2
+		if r.URL.Query().Get("pagination.key") == "page2" {
+			start, end = count/2+1, count
+		} else {
+			page.Pagination.NextKey = "page2"
+		}
+		for i := start; i <= end; i++ {
+			v := models.ChainValidator{
+				OperatorAddress: fmt.Sprintf("val%d", i),
+				Status:          models.BondStatusBonded,
+				Tokens:          fmt.Sprintf("%d000000", i),
+			}
+			v.Description.Moniker = fmt.Sprintf("Validator %d", i)
+			page.Validators = append(page.Validators, v)
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+}
+
+func TestImportValidators(t *testing.T) {
+	chain := newValidatorSetServer(t, 6)
+	defer chain.Close()
+
+	archivedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	validatorStore := newMemoryValidatorStore(
+		models.Validator{Address: "val6", Name: "Mine", EnabledTracking: false},
+		models.Validator{Address: "val5", Name: "Archived", EnabledTracking: false, ArchivedAt: &archivedAt},
+	)
+	cosmosService := services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{BaseURL: chain.URL, MaxRetries: 1})
+	router := routes.SetupRouter(routes.Dependencies{
+		ValidatorStore:    validatorStore,
+		ValidatorImporter: tasks.NewValidatorImportTask(validatorStore, cosmosService),
+	})
+
+	rec := doRequest(router, "POST", "/api/v1/admin/validators/import", `{"minVotingPower": 2, "topN": 3}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var body struct {
+		Data tasks.ImportSummary `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 6, body.Data.Listed)
+	assert.Equal(t, 3, body.Data.Tracked)
+	assert.Equal(t, 4, body.Data.Created)
+	assert.Equal(t, 1, body.Data.Updated)
+	assert.Equal(t, 1, body.Data.Archived)
+
+	// The top 3 by voting power are selected; val5 is archived and left alone
+	assert.True(t, validatorStore.validators["val6"].EnabledTracking)
+	assert.Equal(t, "Mine", validatorStore.validators["val6"].Name)
+	assert.False(t, validatorStore.validators["val5"].EnabledTracking)
+	assert.True(t, validatorStore.validators["val4"].EnabledTracking)
+	assert.False(t, validatorStore.validators["val3"].EnabledTracking)
+	assert.False(t, validatorStore.validators["val2"].EnabledTracking)
+	assert.False(t, validatorStore.validators["val1"].EnabledTracking)
+	assert.Equal(t, "Validator 1", validatorStore.validators["val1"].Name)
+	require.NotNil(t, validatorStore.validators["val1"].Metadata)
+	assert.Equal(t, "1000000", validatorStore.validators["val1"].Metadata.Tokens)
+}
+
+func TestImportValidators_RejectsInvalidFilter(t *testing.T) {
+	validatorStore := newMemoryValidatorStore()
+	router := routes.SetupRouter(routes.Dependencies{
+		ValidatorStore:    validatorStore,
+		ValidatorImporter: tasks.NewValidatorImportTask(validatorStore, services.NewCosmosService()),
+	})
+
+	for _, body := range []string{`{"status": "bonded"}`, `{"topN": -1}`, `{"top": 3}`} {
+		rec := doRequest(router, "POST", "/api/v1/admin/validators/import", body, nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+}
+
+func TestImportValidators_EmptyChunkedBody(t *testing.T) {
+	chain := newValidatorSetServer(t, 4)
+	defer chain.Close()
+
+	validatorStore := newMemoryValidatorStore()
+	cosmosService := services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{BaseURL: chain.URL, MaxRetries: 1})
+	router := routes.SetupRouter(routes.Dependencies{
+		ValidatorStore:    validatorStore,
+		ValidatorImporter: tasks.NewValidatorImportTask(validatorStore, cosmosService),
+	})
+
+	// A chunked request has an unknown content length
+	req := httptest.NewRequest("POST", "/api/v1/admin/validators/import", strings.NewReader(""))
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Len(t, validatorStore.validators, 4)
+}
@@ -0,0 +1,95 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatorTags(t *testing.T) {
+	router, validatorStore := setupRouter(
+		models.Validator{Address: "val1", Name: "Validator 1", Tags: []string{"client-a"}},
+		models.Validator{Address: "val2", Name: "Validator 2"},
+	)
+
+	rec := doRequest(router, "PUT", "/api/v1/validators/val2/tags", `{"tags": ["Client-A", "eu", "eu"]}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, []string{"client-a", "eu"}, validatorStore.validators["val2"].Tags)
+	assert.NotEmpty(t, rec.Header().Get("ETag"))
+
+	rec = doRequest(router, "PUT", "/api/v1/validators/val1/tags/US", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, []string{"client-a", "us"}, validatorStore.validators["val1"].Tags)
+
+	rec = doRequest(router, "GET", "/api/v1/validators?tag=client-a", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var list struct {
+		Data struct {
+			Validators []models.Validator `json:"validators"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Len(t, list.Data.Validators, 2)
+
+	rec = doRequest(router, "GET", "/api/v1/tags", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var tags struct {
+		Data struct {
+			Tags []models.TagCount `json:"tags"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tags))
+	assert.Equal(t, []models.TagCount{{Tag: "client-a", Validators: 2}, {Tag: "eu", Validators: 1}, {Tag: "us", Validators: 1}}, tags.Data.Tags)
+
+	rec = doRequest(router, "PUT", "/api/v1/tags/client-a", `{"name": "client-b"}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, []string{"client-b", "us"}, validatorStore.validators["val1"].Tags)
+	assert.Equal(t, []string{"client-b", "eu"}, validatorStore.validators["val2"].Tags)
+
+	rec = doRequest(router, "DELETE", "/api/v1/validators/val1/tags/us", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, []string{"client-b"}, validatorStore.validators["val1"].Tags)
+
+	rec = doRequest(router, "DELETE", "/api/v1/tags/client-b", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Empty(t, validatorStore.validators["val1"].Tags)
+	assert.Equal(t, []string{"eu"}, validatorStore.validators["val2"].Tags)
+}
+
+func TestValidatorTags_Errors(t *testing.T) {
+	archivedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	router, _ := setupRouter(
+		models.Validator{Address: "val1", Name: "Validator 1", Tags: []string{"client-a"}},
+		models.Validator{Address: "val2", Name: "Validator 2", ArchivedAt: &archivedAt},
+	)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"invalid tag", "PUT", "/api/v1/validators/val1/tags", `{"tags": ["client a"]}`, http.StatusBadRequest},
+		{"invalid path tag", "PUT", "/api/v1/validators/val1/tags/-a", "", http.StatusBadRequest},
+		{"unknown validator", "PUT", "/api/v1/validators/missing/tags/eu", "", http.StatusNotFound},
+		{"archived validator", "PUT", "/api/v1/validators/val2/tags/eu", "", http.StatusConflict},
+		{"tag not on validator", "DELETE", "/api/v1/validators/val1/tags/eu", "", http.StatusNotFound},
+		{"unknown tag", "DELETE", "/api/v1/tags/eu", "", http.StatusNotFound},
+		{"invalid new name", "PUT", "/api/v1/tags/client-a", `{"name": ""}`, http.StatusBadRequest},
+		{"invalid filter", "GET", "/api/v1/validators?tag=a%20b", "", http.StatusBadRequest},
+		{"tags are read-only in patches", "PATCH", "/api/v1/validators/val1", `{"tags": ["eu"]}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(router, tt.method, tt.path, tt.body, map[string]string{"Content-Type": "application/json"})
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,616 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/novintriantonius/cosmos-validator-service/internal/address"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/routes"
+	"github.com/novintriantonius/cosmos-validator-service/internal/services"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryValidatorStore is an in-memory ValidatorStore
+type memoryValidatorStore struct {
+	validators map[string]models.Validator
+	clock      time.Time
+}
+
+func newMemoryValidatorStore(validators ...models.Validator) *memoryValidatorStore {
+	s := &memoryValidatorStore{
+		validators: make(map[string]models.Validator),
+		clock:      time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+	for _, v := range validators {
+		v.CreatedAt, v.UpdatedAt = s.clock, s.clock
+		s.validators[v.Address] = v
+	}
+	return s
+}
+
+func (s *memoryValidatorStore) tick() time.Time {
+	s.clock = s.clock.Add(time.Second)
+	return s.clock
+}
+
+func (s *memoryValidatorStore) GetAll(ctx context.Context) ([]models.Validator, error) {
+	var validators []models.Validator
+	for _, v := range s.validators {
+		if v.ArchivedAt == nil {
+			validators = append(validators, v)
+		}
+	}
+	return validators, nil
+}
+
+func (s *memoryValidatorStore) List(ctx context.Context, filter store.ValidatorFilter) ([]models.Validator, int, error) {
+	validators := []models.Validator{}
+	for _, v := range s.validators {
+		if (v.ArchivedAt != nil) != filter.Archived {
+			continue
+		}
+		if filter.Search != "" && !strings.Contains(strings.ToLower(v.Name), strings.ToLower(filter.Search)) {
+			continue
+		}
+		if filter.EnabledTracking != nil && v.EnabledTracking != *filter.EnabledTracking {
+			continue
+		}
+		if filter.Tag != "" && len(removeTag(v.Tags, filter.Tag)) == len(v.Tags) {
+			continue
+		}
+		validators = append(validators, v)
+	}
+
+	less := func(a, b models.Validator) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	if filter.Sort == store.ValidatorSortCreatedAt {
+		less = func(a, b models.Validator) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	}
+	sort.SliceStable(validators, func(i, j int) bool {
+		if filter.Descending {
+			return less(validators[j], validators[i])
+		}
+		return less(validators[i], validators[j])
+	})
+
+	total := len(validators)
+	if filter.Offset >= total {
+		return []models.Validator{}, total, nil
+	}
+	validators = validators[filter.Offset:]
+	if filter.Limit > 0 && len(validators) > filter.Limit {
+		validators = validators[:filter.Limit]
+	}
+	return validators, total, nil
+}
+
+func (s *memoryValidatorStore) GetByAddress(ctx context.Context, address string) (*models.Validator, error) {
+	v, ok := s.validators[address]
+	if !ok {
+		return nil, store.ErrValidatorNotFound
+	}
+	return &v, nil
+}
+
+func (s *memoryValidatorStore) GetByAddresses(ctx context.Context, addresses []string) ([]models.Validator, error) {
+	validators := []models.Validator{}
+	for _, addr := range addresses {
+		if v, ok := s.validators[addr]; ok {
+			validators = append(validators, v)
+		}
+	}
+	sort.Slice(validators, func(i, j int) bool { return validators[i].Address < validators[j].Address })
+	return validators, nil
+}
+
+func (s *memoryValidatorStore) GetEnabledValidators(ctx context.Context) ([]string, error) {
+	var addresses []string
+	for _, v := range s.validators {
+		if v.EnabledTracking && v.ArchivedAt == nil {
+			addresses = append(addresses, v.Address)
+		}
+	}
+	return addresses, nil
+}
+
+func (s *memoryValidatorStore) Add(ctx context.Context, validator models.Validator) error {
+	if _, ok := s.validators[validator.Address]; ok {
+		return store.ErrValidatorAlreadyExists
+	}
+	now := s.tick()
+	validator.CreatedAt, validator.UpdatedAt = now, now
+	if validator.Metadata != nil {
+		validator.Metadata.UpdatedAt = now
+	}
+	s.validators[validator.Address] = validator
+	return nil
+}
+
+func (s *memoryValidatorStore) Update(ctx context.Context, address string, validator models.Validator, expectedUpdatedAt *time.Time) error {
+	current, ok := s.validators[address]
+	if !ok {
+		return store.ErrValidatorNotFound
+	}
+	if current.ArchivedAt != nil {
+		return store.ErrValidatorArchived
+	}
+	if expectedUpdatedAt != nil && !current.UpdatedAt.Equal(*expectedUpdatedAt) {
+		return store.ErrValidatorModified
+	}
+	current.Name = validator.Name
+	current.EnabledTracking = validator.EnabledTracking
+	current.UpdatedAt = s.tick()
+	s.validators[address] = current
+	return nil
+}
+
+func (s *memoryValidatorStore) Archive(ctx context.Context, address string) error {
+	current, ok := s.validators[address]
+	if !ok {
+		return store.ErrValidatorNotFound
+	}
+	if current.ArchivedAt != nil {
+		return store.ErrValidatorArchived
+	}
+	now := s.tick()
+	current.ArchivedAt, current.UpdatedAt = &now, now
+	s.validators[address] = current
+	return nil
+}
+
+func (s *memoryValidatorStore) Restore(ctx context.Context, address string) error {
+	current, ok := s.validators[address]
+	if !ok {
+		return store.ErrValidatorNotFound
+	}
+	if current.ArchivedAt == nil {
+		return store.ErrValidatorNotArchived
+	}
+	current.ArchivedAt, current.UpdatedAt = nil, s.tick()
+	s.validators[address] = current
+	return nil
+}
+
+func (s *memoryValidatorStore) Delete(ctx context.Context, address string) error {
+	if _, ok := s.validators[address]; !ok {
+		return store.ErrValidatorNotFound
+	}
+	delete(s.validators, address)
+	return nil
+}
+
+func (s *memoryValidatorStore) GetSyncStatuses(ctx context.Context) ([]models.ValidatorSyncStatus, error) {
+	return nil, nil
+}
+
+func (s *memoryValidatorStore) UpdateSyncStatus(ctx context.Context, address string, syncErr error) error {
+	return nil
+}
+
+func (s *memoryValidatorStore) UpdateMetadata(ctx context.Context, address string, metadata models.ValidatorMetadata) error {
+	current, ok := s.validators[address]
+	if !ok {
+		return store.ErrValidatorNotFound
+	}
+	metadata.UpdatedAt = s.clock
+	current.Metadata = &metadata
+	s.validators[address] = current
+	return nil
+}
+
+func (s *memoryValidatorStore) Import(ctx context.Context, validators []models.Validator) (store.ImportResult, error) {
+	var result store.ImportResult
+	for _, v := range validators {
+		current, ok := s.validators[v.Address]
+		switch {
+		case !ok:
+			s.Add(ctx, v)
+			result.Created++
+		case current.ArchivedAt != nil:
+			result.Archived++
+		case current.EnabledTracking != v.EnabledTracking:
+			current.EnabledTracking, current.Metadata, current.UpdatedAt = v.EnabledTracking, v.Metadata, s.tick()
+			s.validators[v.Address] = current
+			result.Updated++
+		default:
+			current.Metadata = v.Metadata
+			s.validators[v.Address] = current
+			result.Unchanged++
+		}
+	}
+	return result, nil
+}
+
+func (s *memoryValidatorStore) Upsert(ctx context.Context, validators []models.Validator, atomic bool) ([]store.UpsertStatus, error) {
+	statuses := make([]store.UpsertStatus, len(validators))
+	conflict := false
+	for i, v := range validators {
+		current, ok := s.validators[v.Address]
+		switch {
+		case !ok:
+			statuses[i] = store.UpsertCreated
+		case current.ArchivedAt != nil:
+			statuses[i] = store.UpsertConflict
+			conflict = true
+		default:
+			statuses[i] = store.UpsertUpdated
+		}
+	}
+	if atomic && conflict {
+		return statuses, store.ErrBatchConflict
+	}
+	for i, v := range validators {
+		switch statuses[i] {
+		case store.UpsertCreated:
+			s.Add(ctx, v)
+		case store.UpsertUpdated:
+			s.Update(ctx, v.Address, v, nil)
+		}
+	}
+	return statuses, nil
+}
+
+func (s *memoryValidatorStore) ListTags(ctx context.Context) ([]models.TagCount, error) {
+	counts := make(map[string]int)
+	for _, v := range s.validators {
+		if v.ArchivedAt == nil {
+			for _, tag := range v.Tags {
+				counts[tag]++
+			}
+		}
+	}
+	tags := []models.TagCount{}
+	for tag, count := range counts {
+		tags = append(tags, models.TagCount{Tag: tag, Validators: count})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+	return tags, nil
+}
+
+func (s *memoryValidatorStore) SetTags(ctx context.Context, address string, tags []string) error {
+	current, ok := s.validators[address]
+	if !ok {
+		return store.ErrValidatorNotFound
+	}
+	if current.ArchivedAt != nil {
+		return store.ErrValidatorArchived
+	}
+	current.Tags = uniqueTags(tags)
+	current.UpdatedAt = s.tick()
+	s.validators[address] = current
+	return nil
+}
+
+func (s *memoryValidatorStore) AddTag(ctx context.Context, address, tag string) error {
+	current, ok := s.validators[address]
+	if !ok {
+		return store.ErrValidatorNotFound
+	}
+	return s.SetTags(ctx, address, append(append([]string(nil), current.Tags...), tag))
+}
+
+func (s *memoryValidatorStore) RemoveTag(ctx context.Context, address, tag string) error {
+	current, ok := s.validators[address]
+	if !ok {
+		return store.ErrValidatorNotFound
+	}
+	remaining := removeTag(current.Tags, tag)
+	if len(remaining) == len(current.Tags) {
+		return store.ErrTagNotFound
+	}
+	return s.SetTags(ctx, address, remaining)
+}
+
+func (s *memoryValidatorStore) RenameTag(ctx context.Context, tag, newTag string) (int, error) {
+	return s.retag(tag, func(tags []string) []string { return append(removeTag(tags, tag), newTag) })
+}
+
+func (s *memoryValidatorStore) DeleteTag(ctx context.Context, tag string) (int, error) {
+	return s.retag(tag, func(tags []string) []string { return removeTag(tags, tag) })
+}
+
+func (s *memoryValidatorStore) retag(tag string, change func([]string) []string) (int, error) {
+	changed := 0
+	for address, v := range s.validators {
+		if len(removeTag(v.Tags, tag)) == len(v.Tags) {
+			continue
+		}
+		v.Tags = uniqueTags(change(v.Tags))
+		v.UpdatedAt = s.tick()
+		s.validators[address] = v
+		changed++
+	}
+	if changed == 0 {
+		return 0, store.ErrTagNotFound
+	}
+	return changed, nil
+}
+
+func uniqueTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+func removeTag(tags []string, tag string) []string {
+	var remaining []string
+	for _, t := range tags {
+		if t != tag {
+			remaining = append(remaining, t)
+		}
+	}
+	return remaining
+}
+
+func setupRouter(validators ...models.Validator) (*mux.Router, *memoryValidatorStore) {
+	validatorStore := newMemoryValidatorStore(validators...)
+	return routes.SetupRouter(routes.Dependencies{ValidatorStore: validatorStore}), validatorStore
+}
+
+func doRequest(router http.Handler, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPatchValidator_MergesFields(t *testing.T) {
+	router, validatorStore := setupRouter(models.Validator{Address: "val1", Name: "Validator 1", EnabledTracking: true})
+
+	rec := doRequest(router, "PATCH", "/api/v1/validators/val1", `{"enabledTracking": false}`,
+		map[string]string{"Content-Type": "application/merge-patch+json"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	updated := validatorStore.validators["val1"]
+	assert.Equal(t, "Validator 1", updated.Name)
+	assert.False(t, updated.EnabledTracking)
+	assert.NotEmpty(t, rec.Header().Get("ETag"))
+}
+
+func TestPatchValidator_RejectsInvalidPatches(t *testing.T) {
+	router, _ := setupRouter(models.Validator{Address: "val1", Name: "Validator 1", EnabledTracking: true})
+	headers := map[string]string{"Content-Type": "application/merge-patch+json"}
+
+	tests := []struct {
+		name           string
+		body           string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{"empty name", `{"name": ""}`, headers, http.StatusBadRequest},
+		{"null name", `{"name": null}`, headers, http.StatusBadRequest},
+		{"read-only address", `{"address": "val2"}`, headers, http.StatusBadRequest},
+		{"read-only metadata", `{"metadata": {"moniker": "v"}}`, headers, http.StatusBadRequest},
+		{"unknown field", `{"nickname": "v"}`, headers, http.StatusBadRequest},
+		{"wrong type", `{"enabledTracking": "no"}`, headers, http.StatusBadRequest},
+		{"not an object", `["name"]`, headers, http.StatusBadRequest},
+		{"wrong content type", `{"name": "v"}`, map[string]string{"Content-Type": "text/plain"}, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(router, "PATCH", "/api/v1/validators/val1", tt.body, tt.headers)
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestPutValidator_RequiresName(t *testing.T) {
+	router, validatorStore := setupRouter(models.Validator{Address: "val1", Name: "Validator 1", EnabledTracking: true})
+
+	rec := doRequest(router, "PUT", "/api/v1/validators/val1", `{"enabledTracking": false}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "Validator 1", validatorStore.validators["val1"].Name)
+}
+
+func TestUpdateValidator_IfMatch(t *testing.T) {
+	router, _ := setupRouter(models.Validator{Address: "val1", Name: "Validator 1", EnabledTracking: true})
+
+	rec := doRequest(router, "GET", "/api/v1/validators/val1", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	patchHeaders := map[string]string{"Content-Type": "application/merge-patch+json", "If-Match": etag}
+	rec = doRequest(router, "PATCH", "/api/v1/validators/val1", `{"name": "Renamed"}`, patchHeaders)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	newETag := rec.Header().Get("ETag")
+	assert.NotEqual(t, etag, newETag)
+
+	// The old ETag no longer matches
+	rec = doRequest(router, "PATCH", "/api/v1/validators/val1", `{"name": "Lost update"}`, patchHeaders)
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	rec = doRequest(router, "PUT", "/api/v1/validators/val1", `{"name": "Lost update", "enabledTracking": true}`,
+		map[string]string{"If-Match": etag})
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	rec = doRequest(router, "PUT", "/api/v1/validators/val1", `{"name": "Replaced", "enabledTracking": true}`,
+		map[string]string{"If-Match": newETag})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var body struct {
+		Data models.Validator `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "Replaced", body.Data.Name)
+}
+
+func TestValidatorRoutes_ValidateAddresses(t *testing.T) {
+	const validatorAddress = "cosmosvaloper18ruzecmqj9pv8ac0gvkgryuc7u004te9rh7w5s"
+	router := routes.SetupRouter(routes.Dependencies{
+		ValidatorStore: newMemoryValidatorStore(),
+		Addresses:      address.NewValidator(address.DefaultAccountPrefix, address.DefaultValidatorPrefix),
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"create with valid address", "POST", "/api/v1/validators", `{"address": "` + validatorAddress + `", "name": "Validator"}`, http.StatusCreated},
+		{"create with bad checksum", "POST", "/api/v1/validators", `{"address": "cosmosvaloper18ruzecmqj9pv8ac0gvkgryuc7u004te9rh7w5t", "name": "Validator"}`, http.StatusBadRequest},
+		{"create with account address", "POST", "/api/v1/validators", `{"address": "cosmos18ruzecmqj9pv8ac0gvkgryuc7u004te9rh7w5s", "name": "Validator"}`, http.StatusBadRequest},
+		{"get with invalid path address", "GET", "/api/v1/validators/val1", "", http.StatusBadRequest},
+		{"delegator history with validator as delegator", "GET", "/api/v1/validators/" + validatorAddress + "/delegator/" + validatorAddress + "/history", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(router, tt.method, tt.path, tt.body, nil)
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestCreateValidator_LooksUpChain(t *testing.T) {
+	const validatorAddress = "cosmosvaloper18ruzecmqj9pv8ac0gvkgryuc7u004te9rh7w5s"
+	chain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cosmos/staking/v1beta1/validators/"+validatorAddress {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": 5, "message": "validator not found"}`))
+			return
+		}
+		w.Write([]byte(`{"validator": {
+			"operator_address": "` + validatorAddress + `",
+			"jailed": false,
+			"status": "BOND_STATUS_BONDED",
+			"tokens": "1000000",
+			"description": {"moniker": "Chain Moniker", "website": "https://example.com"},
+			"commission": {"commission_rates": {"rate": "0.050000000000000000"}}
+		}}`))
+	}))
+	defer chain.Close()
+
+	newRouter := func() (*mux.Router, *memoryValidatorStore) {
+		validatorStore := newMemoryValidatorStore()
+		return routes.SetupRouter(routes.Dependencies{
+			ValidatorStore: validatorStore,
+			CosmosService:  services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{BaseURL: chain.URL, MaxRetries: 1}),
+		}), validatorStore
+	}
+
+	t.Run("name defaults to moniker", func(t *testing.T) {
+		router, validatorStore := newRouter()
+		rec := doRequest(router, "POST", "/api/v1/validators", `{"address": "`+validatorAddress+`", "enabledTracking": true}`, nil)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		created := validatorStore.validators[validatorAddress]
+		assert.Equal(t, "Chain Moniker", created.Name)
+		require.NotNil(t, created.Metadata)
+		assert.Equal(t, "https://example.com", created.Metadata.Website)
+		assert.Equal(t, "0.050000000000000000", created.Metadata.CommissionRate)
+		assert.Equal(t, "BOND_STATUS_BONDED", created.Metadata.Status)
+		assert.Equal(t, "1000000", created.Metadata.Tokens)
+	})
+
+	t.Run("lookup keeps the given name", func(t *testing.T) {
+		router, validatorStore := newRouter()
+		rec := doRequest(router, "POST", "/api/v1/validators?lookup=true", `{"address": "`+validatorAddress+`", "name": "Mine"}`, nil)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		created := validatorStore.validators[validatorAddress]
+		assert.Equal(t, "Mine", created.Name)
+		require.NotNil(t, created.Metadata)
+		assert.Equal(t, "Chain Moniker", created.Metadata.Moniker)
+	})
+
+	t.Run("no lookup when a name is given", func(t *testing.T) {
+		router, validatorStore := newRouter()
+		rec := doRequest(router, "POST", "/api/v1/validators", `{"address": "`+validatorAddress+`", "name": "Mine"}`, nil)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		assert.Nil(t, validatorStore.validators[validatorAddress].Metadata)
+	})
+
+	t.Run("unknown validator", func(t *testing.T) {
+		router, validatorStore := newRouter()
+		rec := doRequest(router, "POST", "/api/v1/validators?lookup=true", `{"address": "val2", "name": "Validator"}`, nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+		assert.Empty(t, validatorStore.validators)
+	})
+}
+
+func TestListValidators(t *testing.T) {
+	router, _ := setupRouter(
+		models.Validator{Address: "val1", Name: "Alpha", EnabledTracking: true},
+		models.Validator{Address: "val2", Name: "beta", EnabledTracking: false},
+		models.Validator{Address: "val3", Name: "Gamma Alpha", EnabledTracking: true},
+	)
+
+	list := func(query string) (int, []string, int) {
+		rec := doRequest(router, "GET", "/api/v1/validators"+query, "", nil)
+		var body struct {
+			Data struct {
+				Validators []models.Validator `json:"validators"`
+				Total      int                `json:"total"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		var names []string
+		for _, v := range body.Data.Validators {
+			names = append(names, v.Name)
+		}
+		return rec.Code, names, body.Data.Total
+	}
+
+	code, names, total := list("")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"Alpha", "beta", "Gamma Alpha"}, names)
+	assert.Equal(t, 3, total)
+
+	_, names, total = list("?search=alpha&sort=-name")
+	assert.Equal(t, []string{"Gamma Alpha", "Alpha"}, names)
+	assert.Equal(t, 2, total)
+
+	_, names, total = list("?enabledTracking=false")
+	assert.Equal(t, []string{"beta"}, names)
+	assert.Equal(t, 1, total)
+
+	_, names, total = list("?limit=1&offset=1")
+	assert.Equal(t, []string{"beta"}, names)
+	assert.Equal(t, 3, total)
+
+	for _, query := range []string{"?sort=address", "?limit=0", "?offset=-1", "?enabledTracking=maybe"} {
+		code, _, _ = list(query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+}
+
+func TestListValidators_ListsAllWithoutLimit(t *testing.T) {
+	validators := make([]models.Validator, 150)
+	for i := range validators {
+		validators[i] = models.Validator{Address: fmt.Sprintf("val%d", i), Name: fmt.Sprintf("Validator %03d", i)}
+	}
+	router, _ := setupRouter(validators...)
+
+	rec := doRequest(router, "GET", "/api/v1/validators", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.JSONEq(t, "150", string(body.Data["count"]))
+	assert.NotContains(t, body.Data, "limit")
+}
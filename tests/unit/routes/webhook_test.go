@@ -0,0 +1,259 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/routes"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWebhookStore keeps webhooks in memory and returns fixed deliveries
+type memoryWebhookStore struct {
+	store.WebhookStore
+	webhooks       map[int]models.Webhook
+	nextID         int
+	deliveries     []models.WebhookDelivery
+	deliveryFilter store.WebhookDeliveryFilter
+}
+
+func newMemoryWebhookStore() *memoryWebhookStore {
+	return &memoryWebhookStore{webhooks: make(map[int]models.Webhook)}
+}
+
+func (s *memoryWebhookStore) GetAll(ctx context.Context) ([]models.Webhook, error) {
+	hooks := []models.Webhook{}
+	for id := 1; id <= s.nextID; id++ {
+		if webhook, ok := s.webhooks[id]; ok {
+			hooks = append(hooks, webhook)
+		}
+	}
+	return hooks, nil
+}
+
+func (s *memoryWebhookStore) GetByID(ctx context.Context, id int) (*models.Webhook, error) {
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		return nil, store.ErrWebhookNotFound
+	}
+	return &webhook, nil
+}
+
+func (s *memoryWebhookStore) Add(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	s.nextID++
+	webhook.ID = s.nextID
+	s.webhooks[webhook.ID] = webhook
+	return &webhook, nil
+}
+
+func (s *memoryWebhookStore) Update(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	existing, ok := s.webhooks[webhook.ID]
+	if !ok {
+		return nil, store.ErrWebhookNotFound
+	}
+	webhook.Secret = existing.Secret
+	s.webhooks[webhook.ID] = webhook
+	return &webhook, nil
+}
+
+func (s *memoryWebhookStore) Delete(ctx context.Context, id int) error {
+	if _, ok := s.webhooks[id]; !ok {
+		return store.ErrWebhookNotFound
+	}
+	delete(s.webhooks, id)
+	return nil
+}
+
+func (s *memoryWebhookStore) ListDeliveries(ctx context.Context, filter store.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	s.deliveryFilter = filter
+	return s.deliveries, nil
+}
+
+func (s *memoryWebhookStore) GetDelivery(ctx context.Context, webhookID int, deliveryID int64) (*models.WebhookDelivery, error) {
+	for _, delivery := range s.deliveries {
+		if delivery.WebhookID == webhookID && delivery.ID == deliveryID {
+			return &delivery, nil
+		}
+	}
+	return nil, store.ErrWebhookDeliveryNotFound
+}
+
+// fakeWebhookTester records test deliveries and reports them as delivered
+type fakeWebhookTester struct {
+	store  *memoryWebhookStore
+	tested []int
+}
+
+func (t *fakeWebhookTester) TestFire(ctx context.Context, webhookID int) (*models.WebhookDelivery, error) {
+	if _, err := t.store.GetByID(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	t.tested = append(t.tested, webhookID)
+	return &models.WebhookDelivery{ID: 1, WebhookID: webhookID, EventType: models.EventWebhookTest,
+		Status: models.WebhookDeliveryDelivered, Attempts: 1}, nil
+}
+
+func setupWebhookRouter(webhookStore *memoryWebhookStore, tester routes.WebhookTester) http.Handler {
+	return routes.SetupRouter(routes.Dependencies{
+		ValidatorStore: newMemoryValidatorStore(),
+		WebhookStore:   webhookStore,
+		WebhookTester:  tester,
+	})
+}
+
+func TestWebhooks(t *testing.T) {
+	webhookStore := newMemoryWebhookStore()
+	router := setupWebhookRouter(webhookStore, nil)
+
+	rec := doRequest(router, "POST", "/api/v1/admin/webhooks",
+		`{"url": "https://example.com/hooks", "description": " Alerts ", "eventTypes": ["alert.fired", "alert.fired", "sync.failed"]}`, nil)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created struct {
+		Data struct {
+			Webhook map[string]interface{} `json:"webhook"`
+			Secret  string                 `json:"secret"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Regexp(t, `^whsec_[0-9a-f]{64}$`, created.Data.Secret)
+	assert.NotContains(t, created.Data.Webhook, "secret")
+
+	stored := webhookStore.webhooks[1]
+	assert.Equal(t, created.Data.Secret, stored.Secret)
+	assert.Equal(t, "Alerts", stored.Description)
+	assert.Equal(t, []models.EventType{models.EventAlertFired, models.EventSyncFailed}, stored.EventTypes)
+	assert.True(t, stored.Enabled)
+
+	// Updates keep the secret
+	rec = doRequest(router, "PUT", "/api/v1/admin/webhooks/1", `{"url": "http://example.com/other", "enabled": false}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotContains(t, rec.Body.String(), created.Data.Secret)
+	stored = webhookStore.webhooks[1]
+	assert.Equal(t, "http://example.com/other", stored.URL)
+	assert.Empty(t, stored.EventTypes)
+	assert.False(t, stored.Enabled)
+	assert.Equal(t, created.Data.Secret, stored.Secret)
+
+	rec = doRequest(router, "GET", "/api/v1/admin/webhooks/1", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotContains(t, rec.Body.String(), created.Data.Secret)
+
+	rec = doRequest(router, "GET", "/api/v1/admin/webhooks", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var list struct {
+		Data struct {
+			Webhooks []models.Webhook `json:"webhooks"`
+			Count    int              `json:"count"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.Data.Count)
+	assert.NotContains(t, rec.Body.String(), created.Data.Secret)
+
+	// No tester is configured
+	rec = doRequest(router, "POST", "/api/v1/admin/webhooks/1/test", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+
+	rec = doRequest(router, "DELETE", "/api/v1/admin/webhooks/1", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotContains(t, webhookStore.webhooks, 1)
+
+	rec = doRequest(router, "GET", "/api/v1/admin/webhooks/1", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestWebhooks_RejectsInvalidWebhooks(t *testing.T) {
+	router := setupWebhookRouter(newMemoryWebhookStore(), nil)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"missing URL", "POST", "/api/v1/admin/webhooks", `{}`, http.StatusBadRequest},
+		{"relative URL", "POST", "/api/v1/admin/webhooks", `{"url": "/hooks"}`, http.StatusBadRequest},
+		{"unsupported scheme", "POST", "/api/v1/admin/webhooks", `{"url": "ftp://example.com/hooks"}`, http.StatusBadRequest},
+		{"unknown event type", "POST", "/api/v1/admin/webhooks", `{"url": "https://example.com", "eventTypes": ["validator.created"]}`, http.StatusBadRequest},
+		{"test event type", "POST", "/api/v1/admin/webhooks", `{"url": "https://example.com", "eventTypes": ["webhook.test"]}`, http.StatusBadRequest},
+		{"invalid body", "POST", "/api/v1/admin/webhooks", `{"url":`, http.StatusBadRequest},
+		{"unknown webhook", "PUT", "/api/v1/admin/webhooks/9", `{"url": "https://example.com"}`, http.StatusNotFound},
+		{"invalid webhook ID", "DELETE", "/api/v1/admin/webhooks/abc", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(router, tt.method, tt.path, tt.body, nil)
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestWebhooks_TestFire(t *testing.T) {
+	webhookStore := newMemoryWebhookStore()
+	webhookStore.Add(context.Background(), models.Webhook{URL: "https://example.com", Secret: "whsec_test", Enabled: true})
+	tester := &fakeWebhookTester{store: webhookStore}
+	router := setupWebhookRouter(webhookStore, tester)
+
+	rec := doRequest(router, "POST", "/api/v1/admin/webhooks/1/test", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, []int{1}, tester.tested)
+
+	var response struct {
+		Data models.WebhookDelivery `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, models.WebhookDeliveryDelivered, response.Data.Status)
+
+	rec = doRequest(router, "POST", "/api/v1/admin/webhooks/2/test", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	webhookStore := newMemoryWebhookStore()
+	webhookStore.Add(context.Background(), models.Webhook{URL: "https://example.com", Secret: "whsec_test", Enabled: true})
+	webhookStore.deliveries = []models.WebhookDelivery{{ID: 4, WebhookID: 1, EventID: "abc", EventType: models.EventAlertFired,
+		Status: models.WebhookDeliveryFailed, Attempts: 8, LastStatusCode: 500,
+		AttemptLog: []models.WebhookDeliveryAttempt{{Attempt: 1, StatusCode: 500}}}}
+	router := setupWebhookRouter(webhookStore, nil)
+
+	rec := doRequest(router, "GET", "/api/v1/admin/webhooks/1/deliveries?status=failed&limit=10&offset=5", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, store.WebhookDeliveryFilter{WebhookID: 1, Status: models.WebhookDeliveryFailed, Limit: 10, Offset: 5},
+		webhookStore.deliveryFilter)
+
+	rec = doRequest(router, "GET", "/api/v1/admin/webhooks/1/deliveries/4", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response struct {
+		Data models.WebhookDelivery `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, webhookStore.deliveries[0].AttemptLog, response.Data.AttemptLog)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{"invalid status", "/api/v1/admin/webhooks/1/deliveries?status=sent", http.StatusBadRequest},
+		{"limit too large", "/api/v1/admin/webhooks/1/deliveries?limit=5000", http.StatusBadRequest},
+		{"unknown webhook", "/api/v1/admin/webhooks/2/deliveries", http.StatusNotFound},
+		{"unknown delivery", "/api/v1/admin/webhooks/1/deliveries/5", http.StatusNotFound},
+		{"delivery of another webhook", "/api/v1/admin/webhooks/2/deliveries/4", http.StatusNotFound},
+		{"invalid delivery ID", "/api/v1/admin/webhooks/1/deliveries/abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(router, "GET", tt.path, "", nil)
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wsMessage is a message sent by the WebSocket endpoint
+type wsMessage struct {
+	Type          string                  `json:"type"`
+	Subscription  string                  `json:"subscription"`
+	Subscriptions []string                `json:"subscriptions"`
+	Event         *models.DelegationEvent `json:"event"`
+	Message       string                  `json:"message"`
+	Errors        []string                `json:"errors"`
+}
+
+// dialWebSocket connects to the WebSocket endpoint of a stream server
+func dialWebSocket(t *testing.T, serverURL string) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(serverURL, "http") + "/api/v1/stream/ws"
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+// exchange sends a client message and reads the reply
+func exchange(t *testing.T, conn *websocket.Conn, message string) wsMessage {
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(message)))
+	return readWebSocket(t, conn)
+}
+
+func readWebSocket(t *testing.T, conn *websocket.Conn) wsMessage {
+	var message wsMessage
+	require.NoError(t, conn.ReadJSON(&message))
+	return message
+}
+
+func TestWebSocketStream_FiltersSubscriptions(t *testing.T) {
+	server, broker := setupStreamServer(t, &storedEventStore{}, time.Minute)
+	conn := dialWebSocket(t, server.URL)
+
+	assert.Equal(t, wsMessage{Type: "subscribed", Subscription: "val1"},
+		exchange(t, conn, `{"type":"subscribe","subscription":"val1","validators":["val1"]}`))
+	assert.Equal(t, wsMessage{Type: "subscribed", Subscription: "whales"},
+		exchange(t, conn, `{"type":"subscribe","subscription":"whales","min_delta":"1000"}`))
+
+	small := delegationChange(1, "val2")
+	large := delegationChange(2, "val2")
+	large.Delta = "-5000"
+	both := delegationChange(3, "val1")
+	both.Delta = "1000"
+	publishChanges(t, broker, small, large, both)
+
+	message := readWebSocket(t, conn)
+	assert.Equal(t, "delegation.changed", message.Type)
+	assert.Equal(t, []string{"whales"}, message.Subscriptions)
+	assert.Equal(t, &large, message.Event)
+
+	message = readWebSocket(t, conn)
+	assert.Equal(t, []string{"val1", "whales"}, message.Subscriptions)
+	assert.Equal(t, &both, message.Event)
+}
+
+func TestWebSocketStream_Unsubscribes(t *testing.T) {
+	server, broker := setupStreamServer(t, &storedEventStore{}, time.Minute)
+	conn := dialWebSocket(t, server.URL)
+
+	exchange(t, conn, `{"type":"subscribe","subscription":"a","validators":["val1"]}`)
+	exchange(t, conn, `{"type":"subscribe","subscription":"b","validators":["val2"]}`)
+	assert.Equal(t, wsMessage{Type: "unsubscribed", Subscription: "a"},
+		exchange(t, conn, `{"type":"unsubscribe","subscription":"a"}`))
+
+	publishChanges(t, broker, delegationChange(1, "val1"), delegationChange(2, "val2"))
+	message := readWebSocket(t, conn)
+	assert.Equal(t, []string{"b"}, message.Subscriptions)
+	assert.Equal(t, int64(2), message.Event.ID)
+
+	message = exchange(t, conn, `{"type":"unsubscribe","subscription":"a"}`)
+	assert.Equal(t, "error", message.Type)
+	assert.Equal(t, "Subscription not found", message.Message)
+}
+
+func TestWebSocketStream_RejectsInvalidMessages(t *testing.T) {
+	server, _ := setupStreamServer(t, &storedEventStore{}, time.Minute)
+	conn := dialWebSocket(t, server.URL)
+
+	tests := []struct {
+		name    string
+		message string
+		errors  string
+	}{
+		{"malformed JSON", `{"type":`, "unexpected end of JSON input"},
+		{"unknown type", `{"type":"publish","subscription":"a"}`, `Message type "publish" must be "subscribe" or "unsubscribe"`},
+		{"missing subscription ID", `{"type":"subscribe"}`, "Subscription ID is required"},
+		{"negative minimum delta", `{"type":"subscribe","subscription":"a","min_delta":"-1"}`, `Minimum delta "-1" must be a non-negative number`},
+		{"invalid minimum delta", `{"type":"subscribe","subscription":"a","min_delta":"lots"}`, `Minimum delta "lots" must be a non-negative number`},
+		{"too many validators", `{"type":"subscribe","subscription":"a","validators":[` + strings.Repeat(`"val1",`, 100) + `"val1"]}`, "at most 100 validators"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := exchange(t, conn, tt.message)
+			assert.Equal(t, "error", message.Type)
+			require.Len(t, message.Errors, 1)
+			assert.Contains(t, message.Errors[0], tt.errors)
+		})
+	}
+
+	// The connection stays usable after invalid messages
+	assert.Equal(t, "subscribed", exchange(t, conn, `{"type":"subscribe","subscription":"a"}`).Type)
+}
+
+func TestWebSocketStream_LimitsSubscriptions(t *testing.T) {
+	server, _ := setupStreamServer(t, &storedEventStore{}, time.Minute)
+	conn := dialWebSocket(t, server.URL)
+
+	for i := 0; i < 50; i++ {
+		message, _ := json.Marshal(map[string]string{"type": "subscribe", "subscription": strings.Repeat("s", i+1)})
+		require.Equal(t, "subscribed", exchange(t, conn, string(message)).Type)
+	}
+
+	message := exchange(t, conn, `{"type":"subscribe","subscription":"one-too-many"}`)
+	assert.Equal(t, "error", message.Type)
+	assert.Equal(t, []string{"A connection can have at most 50 subscriptions"}, message.Errors)
+
+	// Existing subscriptions can still be replaced
+	assert.Equal(t, "subscribed", exchange(t, conn, `{"type":"subscribe","subscription":"s","validators":["val1"]}`).Type)
+}
+
+func TestWebSocketStream_ClosesWhenClientLags(t *testing.T) {
+	server, broker := setupStreamServer(t, &storedEventStore{}, time.Minute)
+	conn := dialWebSocket(t, server.URL)
+	exchange(t, conn, `{"type":"subscribe","subscription":"all"}`)
+
+	// Fill the buffer faster than the connection can be written
+	event, err := events.New(models.EventDelegationChanged, "val1", delegationChange(1, "val1"))
+	require.NoError(t, err)
+	batch := make([]models.Event, 0, 200)
+	for id := int64(1); id <= 200; id++ {
+		event.Data, _ = json.Marshal(delegationChange(id, "val1"))
+		batch = append(batch, event)
+	}
+	require.NoError(t, broker.Publish(context.Background(), batch))
+
+	// The buffered changes are delivered before the connection is closed
+	received := 0
+	for {
+		_, _, err := conn.ReadMessage()
+		if err != nil {
+			assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater), err.Error())
+			break
+		}
+		received++
+	}
+	assert.GreaterOrEqual(t, received, 10)
+	assert.Less(t, received, 200)
+}
+
+func TestWebSocketStream_RequiresUpgrade(t *testing.T) {
+	server, _ := setupStreamServer(t, &storedEventStore{}, time.Minute)
+
+	resp, err := http.Get(server.URL + "/api/v1/stream/ws")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
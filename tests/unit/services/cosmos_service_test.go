@@ -2,12 +2,15 @@ package services_test
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/novintriantonius/cosmos-validator-service/internal/metrics"
 	"github.com/novintriantonius/cosmos-validator-service/internal/services"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestNewCosmosService(t *testing.T) {
@@ -77,9 +80,13 @@ func TestRetrieveDelegations_SuccessfulRequest(t *testing.T) {
 			t.Errorf("Expected path %s, got %s", expectedPath, r.URL.Path)
 		}
 		
-		// Send a sample response
+		// Send a sample response, followed by an empty last page
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
+		if r.URL.Query().Get("pagination.key") != "" {
+			w.Write([]byte(`{"delegation_responses": [], "pagination": {"next_key": null}}`))
+			return
+		}
 		w.Write([]byte(`{
 			"delegation_responses": [
 				{
@@ -134,4 +141,251 @@ func TestRetrieveDelegations_SuccessfulRequest(t *testing.T) {
 	if resp.Pagination.Total != "10546" {
 		t.Errorf("Expected pagination total 10546, got %s", resp.Pagination.Total)
 	}
-} 
\ No newline at end of file
+} 
+func TestRetrieveDelegations_RetriesServerErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"delegation_responses": [], "pagination": {"next_key": null, "total": "0"}}`))
+	}))
+	defer server.Close()
+
+	retriesBefore := testutil.ToFloat64(metrics.CosmosRequestRetries.WithLabelValues("delegations"))
+
+	service := services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{
+		BaseURL:    server.URL,
+		MaxRetries: 3,
+		RetryDelay: time.Millisecond,
+		Timeout:    5 * time.Second,
+	})
+
+	_, err := service.RetrieveDelegations(context.Background(), "cosmosvaloper1test")
+	if err != nil {
+		t.Fatalf("Expected no error after retries, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+
+	retries := testutil.ToFloat64(metrics.CosmosRequestRetries.WithLabelValues("delegations")) - retriesBefore
+	if retries != 2 {
+		t.Errorf("Expected 2 retries to be recorded, got %v", retries)
+	}
+}
+
+func TestRetrieveDelegations_DoesNotRetryClientErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	service := services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{
+		BaseURL:    server.URL,
+		MaxRetries: 3,
+		RetryDelay: time.Millisecond,
+	})
+
+	_, err := service.RetrieveDelegations(context.Background(), "cosmosvaloper1test")
+	if err == nil {
+		t.Fatal("Expected error for bad request, got nil")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestGetValidator_SuccessfulRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cosmos/staking/v1beta1/validators/cosmosvaloper1test" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"validator": {
+			"operator_address": "cosmosvaloper1test",
+			"jailed": true,
+			"status": "BOND_STATUS_UNBONDED",
+			"tokens": "42",
+			"description": {"moniker": "Test", "website": "https://example.com"},
+			"commission": {"commission_rates": {"rate": "0.100000000000000000"}}
+		}}`))
+	}))
+	defer server.Close()
+
+	service := services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{BaseURL: server.URL})
+
+	validator, err := service.GetValidator(context.Background(), "cosmosvaloper1test")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	metadata := validator.Metadata()
+	if metadata.Moniker != "Test" || metadata.Website != "https://example.com" {
+		t.Errorf("Unexpected description in metadata: %+v", metadata)
+	}
+	if metadata.CommissionRate != "0.100000000000000000" || metadata.Status != "BOND_STATUS_UNBONDED" {
+		t.Errorf("Unexpected commission or status in metadata: %+v", metadata)
+	}
+	if !metadata.Jailed || metadata.Tokens != "42" {
+		t.Errorf("Unexpected jailed or tokens in metadata: %+v", metadata)
+	}
+}
+
+func TestGetValidator_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code": 5, "message": "validator cosmosvaloper1test not found"}`))
+	}))
+	defer server.Close()
+
+	service := services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{BaseURL: server.URL})
+
+	_, err := service.GetValidator(context.Background(), "cosmosvaloper1test")
+	if !errors.Is(err, services.ErrValidatorNotFoundOnChain) {
+		t.Errorf("Expected ErrValidatorNotFoundOnChain, got %v", err)
+	}
+}
+
+func TestListValidators_FollowsPagination(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if status := r.URL.Query().Get("status"); status != "BOND_STATUS_BONDED" {
+			t.Errorf("Expected status BOND_STATUS_BONDED, got %q", status)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("pagination.key") {
+		case "":
+			w.Write([]byte(`{"validators": [{"operator_address": "val1"}], "pagination": {"next_key": "a2V5+/=="}}`))
+		case "a2V5+/==":
+			w.Write([]byte(`{"validators": [{"operator_address": "val2"}], "pagination": {"next_key": null}}`))
+		default:
+			t.Errorf("Unexpected pagination key: %q", r.URL.Query().Get("pagination.key"))
+		}
+	}))
+	defer server.Close()
+
+	service := services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{BaseURL: server.URL})
+
+	validators, err := service.ListValidators(context.Background(), "BOND_STATUS_BONDED")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(validators) != 2 || validators[0].OperatorAddress != "val1" || validators[1].OperatorAddress != "val2" {
+		t.Errorf("Unexpected validators: %+v", validators)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
+
+func TestRetrieveDelegations_FollowsPagination(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.URL.Query().Get("pagination.key"))
+		if limit := r.URL.Query().Get("pagination.limit"); limit == "" {
+			t.Error("Expected a pagination limit")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("pagination.key") {
+		case "":
+			w.Write([]byte(`{"delegation_responses": [{"delegation": {"delegator_address": "delegator1", "shares": "1.0"}}], "pagination": {"next_key": "a2V5+/==", "total": "2"}}`))
+		case "a2V5+/==":
+			w.Write([]byte(`{"delegation_responses": [{"delegation": {"delegator_address": "delegator2", "shares": "2.0"}}], "pagination": {"next_key": null}}`))
+		default:
+			t.Errorf("Unexpected pagination key: %q", r.URL.Query().Get("pagination.key"))
+		}
+	}))
+	defer server.Close()
+
+	service := services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{BaseURL: server.URL})
+
+	resp, err := service.RetrieveDelegations(context.Background(), "cosmosvaloper1test")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(resp.DelegationResponses) != 2 || resp.DelegationResponses[1].Delegation.DelegatorAddress != "delegator2" {
+		t.Errorf("Unexpected delegations: %+v", resp.DelegationResponses)
+	}
+	if resp.Pagination.NextKey != "" || resp.Pagination.Total != "2" {
+		t.Errorf("Unexpected pagination: %+v", resp.Pagination)
+	}
+	if len(keys) != 2 || keys[1] != "a2V5+/==" {
+		t.Errorf("Unexpected pagination keys: %q", keys)
+	}
+}
+
+func TestRetrieveDelegations_FollowsEmptyPagesWithNextKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("pagination.key") {
+		case "":
+			w.Write([]byte(`{"delegation_responses": [{"delegation": {"delegator_address": "delegator1", "shares": "1.0"}}], "pagination": {"next_key": "a2V5MQ==", "total": "2"}}`))
+		case "a2V5MQ==":
+			w.Write([]byte(`{"delegation_responses": [], "pagination": {"next_key": "a2V5Mg=="}}`))
+		case "a2V5Mg==":
+			w.Write([]byte(`{"delegation_responses": [{"delegation": {"delegator_address": "delegator2", "shares": "2.0"}}], "pagination": {"next_key": null}}`))
+		default:
+			t.Errorf("Unexpected pagination key: %q", r.URL.Query().Get("pagination.key"))
+		}
+	}))
+	defer server.Close()
+
+	service := services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{BaseURL: server.URL})
+
+	resp, err := service.RetrieveDelegations(context.Background(), "cosmosvaloper1test")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(resp.DelegationResponses) != 2 || resp.DelegationResponses[1].Delegation.DelegatorAddress != "delegator2" {
+		t.Errorf("Unexpected delegations: %+v", resp.DelegationResponses)
+	}
+	if resp.Pagination.NextKey != "" {
+		t.Errorf("Expected no next key, got %q", resp.Pagination.NextKey)
+	}
+}
+
+func TestRetrieveDelegations_RepeatedNextKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"delegation_responses": [], "pagination": {"next_key": "a2V5MQ=="}}`))
+	}))
+	defer server.Close()
+
+	service := services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{BaseURL: server.URL})
+
+	if _, err := service.RetrieveDelegations(context.Background(), "cosmosvaloper1test"); err == nil {
+		t.Fatal("Expected an error for a repeated next key")
+	}
+}
+
+func TestListValidators_FollowsEmptyPagesWithNextKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("pagination.key") {
+		case "":
+			w.Write([]byte(`{"validators": [], "pagination": {"next_key": "a2V5MQ=="}}`))
+		case "a2V5MQ==":
+			w.Write([]byte(`{"validators": [{"operator_address": "val1"}], "pagination": {"next_key": null}}`))
+		default:
+			t.Errorf("Unexpected pagination key: %q", r.URL.Query().Get("pagination.key"))
+		}
+	}))
+	defer server.Close()
+
+	service := services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{BaseURL: server.URL})
+
+	validators, err := service.ListValidators(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(validators) != 1 || validators[0].OperatorAddress != "val1" {
+		t.Errorf("Unexpected validators: %+v", validators)
+	}
+}
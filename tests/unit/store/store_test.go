@@ -1,16 +1,36 @@
 package store_test
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/novintriantonius/cosmos-validator-service/internal/audit"
 	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/services"
 	"github.com/novintriantonius/cosmos-validator-service/internal/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// validatorColumns are the columns selected by the validator store
+var validatorColumns = []string{"address", "name", "enabled_tracking", "archived_at", "created_at", "updated_at",
+	"moniker", "website", "commission_rate", "bond_status", "jailed", "tokens", "metadata_updated_at", "tags"}
+
+// validatorRow appends empty metadata and tag columns to the values of a validator row
+func validatorRow(values ...driver.Value) []driver.Value {
+	return append(values, nil, nil, nil, nil, nil, nil, nil, "{}")
+}
+
+// testTime is used for timestamps of mocked rows
+var testTime = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
 func setupMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -26,14 +46,14 @@ func TestValidatorStore_GetAll(t *testing.T) {
 	store := store.NewValidatorStore(db)
 
 	// Mock rows
-	rows := sqlmock.NewRows([]string{"address", "name", "enabled_tracking"}).
-		AddRow("val1", "Validator 1", true).
-		AddRow("val2", "Validator 2", false)
+	rows := sqlmock.NewRows(validatorColumns).
+		AddRow(validatorRow("val1", "Validator 1", true, nil, testTime, testTime)...).
+		AddRow(validatorRow("val2", "Validator 2", false, nil, testTime, testTime)...)
 
-	mock.ExpectQuery("SELECT address, name, enabled_tracking FROM validators").
+	mock.ExpectQuery("SELECT address, name, enabled_tracking, archived_at, created_at, updated_at, moniker, website, commission_rate, bond_status, jailed, tokens, metadata_updated_at, tags FROM validators WHERE archived_at IS NULL").
 		WillReturnRows(rows)
 
-	validators, err := store.GetAll()
+	validators, err := store.GetAll(context.Background())
 	assert.NoError(t, err)
 	assert.Len(t, validators, 2)
 	assert.Equal(t, "val1", validators[0].Address)
@@ -48,25 +68,25 @@ func TestValidatorStore_GetByAddress(t *testing.T) {
 	store := store.NewValidatorStore(db)
 
 	// Test case: Validator found
-	rows := sqlmock.NewRows([]string{"address", "name", "enabled_tracking"}).
-		AddRow("val1", "Validator 1", true)
+	rows := sqlmock.NewRows(validatorColumns).
+		AddRow(validatorRow("val1", "Validator 1", true, nil, testTime, testTime)...)
 
-	mock.ExpectQuery("SELECT address, name, enabled_tracking FROM validators WHERE address = \\$1").
+	mock.ExpectQuery("SELECT address, name, enabled_tracking, archived_at, created_at, updated_at, moniker, website, commission_rate, bond_status, jailed, tokens, metadata_updated_at, tags FROM validators WHERE address = \\$1").
 		WithArgs("val1").
 		WillReturnRows(rows)
 
-	validator, err := store.GetByAddress("val1")
+	validator, err := store.GetByAddress(context.Background(), "val1")
 	assert.NoError(t, err)
 	assert.Equal(t, "val1", validator.Address)
 	assert.Equal(t, "Validator 1", validator.Name)
 	assert.True(t, validator.EnabledTracking)
 
 	// Test case: Validator not found
-	mock.ExpectQuery("SELECT address, name, enabled_tracking FROM validators WHERE address = \\$1").
+	mock.ExpectQuery("SELECT address, name, enabled_tracking, archived_at, created_at, updated_at, moniker, website, commission_rate, bond_status, jailed, tokens, metadata_updated_at, tags FROM validators WHERE address = \\$1").
 		WithArgs("nonexistent").
 		WillReturnError(sql.ErrNoRows)
 
-	_, err = store.GetByAddress("nonexistent")
+	_, err = store.GetByAddress(context.Background(), "nonexistent")
 	assert.Error(t, err)
 	assert.Equal(t, "validator not found", err.Error())
 }
@@ -101,21 +121,202 @@ func TestDelegationStore_SaveDelegations(t *testing.T) {
 	// Mock the database behavior
 	mock.ExpectBegin()
 	
-	// Prepare statement mock
+	// Prepare statement mocks
 	mock.ExpectPrepare("INSERT INTO delegations").WillBeClosed()
+	mock.ExpectPrepare("INSERT INTO delegation_events").WillBeClosed()
 	
 	// Query for existing delegations
 	rows := sqlmock.NewRows([]string{"delegator_address", "delegation_shares"})
-	mock.ExpectQuery("SELECT DISTINCT ON \\(delegator_address\\)").WithArgs("validator1").WillReturnRows(rows)
+	mock.ExpectQuery("SELECT DISTINCT ON \\(delegator_address\\).*ORDER BY delegator_address, created_at DESC, id DESC").WithArgs("validator1").WillReturnRows(rows)
 	
 	// Execute the insert
 	mock.ExpectExec("INSERT INTO delegations").WithArgs("validator1", "delegator1", "100.0").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO delegation_events").
+		WithArgs("validator1", "delegator1", "new", "0.000000000000000000", "100.0", "100.000000000000000000").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, testTime))
+	
+	// Queue the change in the event outbox
+	mock.ExpectPrepare("INSERT INTO event_outbox").WillBeClosed()
+	mock.ExpectExec("INSERT INTO event_outbox").
+		WithArgs("delegation-event-1", "delegation.changed", "validator1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
 	
 	// Commit transaction
 	mock.ExpectCommit()
 
-	err := delegationStore.SaveDelegations("validator1", delegationsResponse)
+	result, err := delegationStore.SaveDelegations(context.Background(), "validator1", delegationsResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Inserted)
+	assert.Equal(t, []models.DelegationEvent{{
+		ID:               1,
+		ValidatorAddress: "validator1",
+		DelegatorAddress: "delegator1",
+		Type:             models.DelegationEventNew,
+		PreviousShares:   "0.000000000000000000",
+		Shares:           "100.0",
+		Delta:            "100.000000000000000000",
+		CreatedAt:        testTime,
+	}}, result.Events)
+}
+
+func TestDelegationStore_SaveDelegationsRecordsEvents(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	delegationStore := store.NewDelegationStore(db)
+
+	delegation := func(delegator, shares string) models.DelegationResponse {
+		return models.DelegationResponse{Delegation: models.DelegationDetails{DelegatorAddress: delegator, ValidatorAddress: "validator1", Shares: shares}}
+	}
+	// A complete response: no next page
+	delegationsResponse := models.DelegationsResponse{
+		DelegationResponses: []models.DelegationResponse{
+			delegation("grower", "150.5"),
+			delegation("shrinker", "20"),
+			delegation("steady", "10"),
+		},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO delegations").WillBeClosed()
+	mock.ExpectPrepare("INSERT INTO delegation_events").WillBeClosed()
+	mock.ExpectQuery("SELECT DISTINCT ON \\(delegator_address\\)").WithArgs("validator1").
+		WillReturnRows(sqlmock.NewRows([]string{"delegator_address", "delegation_shares"}).
+			AddRow("grower", "100").
+			AddRow("shrinker", "50").
+			AddRow("steady", "10").
+			AddRow("leaver", "7.25").
+			AddRow("gone", "0.000000000000000000"))
+	mock.ExpectExec("INSERT INTO delegations").WithArgs("validator1", "grower", "150.5").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO delegation_events").
+		WithArgs("validator1", "grower", "increase", "100", "150.5", "50.500000000000000000").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, testTime))
+	mock.ExpectExec("INSERT INTO delegations").WithArgs("validator1", "shrinker", "20").WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectQuery("INSERT INTO delegation_events").
+		WithArgs("validator1", "shrinker", "decrease", "50", "20", "-30.000000000000000000").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(2, testTime))
+	mock.ExpectExec("INSERT INTO delegations").WithArgs("validator1", "leaver", "0.000000000000000000").WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectQuery("INSERT INTO delegation_events").
+		WithArgs("validator1", "leaver", "exit", "7.25", "0.000000000000000000", "-7.250000000000000000").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, testTime))
+	mock.ExpectPrepare("INSERT INTO event_outbox").WillBeClosed()
+	for _, id := range []string{"delegation-event-1", "delegation-event-2", "delegation-event-3"} {
+		mock.ExpectExec("INSERT INTO event_outbox").
+			WithArgs(id, "delegation.changed", "validator1", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	mock.ExpectCommit()
+
+	result, err := delegationStore.SaveDelegations(context.Background(), "validator1", delegationsResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Processed)
+	assert.Equal(t, 2, result.Inserted)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, 1, result.Exited)
+	if assert.Len(t, result.Events, 3) {
+		assert.Equal(t, models.DelegationEventExit, result.Events[2].Type)
+		assert.Equal(t, int64(3), result.Events[2].ID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelegationStore_SaveDelegationsDetectsExitsAcrossPages(t *testing.T) {
+	// The chain returns the delegations of the validator over two pages
+	lcd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("pagination.key") == "" {
+			w.Write([]byte(`{"delegation_responses": [{"delegation": {"delegator_address": "first", "shares": "10"}}], "pagination": {"next_key": "cGFnZTI="}}`))
+			return
+		}
+		w.Write([]byte(`{"delegation_responses": [{"delegation": {"delegator_address": "second", "shares": "20"}}], "pagination": {"next_key": null}}`))
+	}))
+	defer lcd.Close()
+
+	delegations, err := services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{BaseURL: lcd.URL}).
+		RetrieveDelegations(context.Background(), "validator1")
+	require.NoError(t, err)
+
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	delegationStore := store.NewDelegationStore(db)
+
+	// "second" is only on the second page and did not exit; "leaver" is on neither
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO delegations").WillBeClosed()
+	mock.ExpectPrepare("INSERT INTO delegation_events").WillBeClosed()
+	mock.ExpectQuery("SELECT DISTINCT ON \\(delegator_address\\)").WithArgs("validator1").
+		WillReturnRows(sqlmock.NewRows([]string{"delegator_address", "delegation_shares"}).
+			AddRow("first", "10").
+			AddRow("second", "20").
+			AddRow("leaver", "5"))
+	mock.ExpectExec("INSERT INTO delegations").WithArgs("validator1", "leaver", "0.000000000000000000").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO delegation_events").
+		WithArgs("validator1", "leaver", "exit", "5", "0.000000000000000000", "-5.000000000000000000").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, testTime))
+	mock.ExpectPrepare("INSERT INTO event_outbox").WillBeClosed()
+	mock.ExpectExec("INSERT INTO event_outbox").
+		WithArgs("delegation-event-1", "delegation.changed", "validator1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	result, err := delegationStore.SaveDelegations(context.Background(), "validator1", *delegations)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Processed)
+	assert.Equal(t, 2, result.Skipped)
+	assert.Equal(t, 1, result.Exited)
+	if assert.Len(t, result.Events, 1) {
+		assert.Equal(t, models.DelegationEventExit, result.Events[0].Type)
+		assert.Equal(t, "leaver", result.Events[0].DelegatorAddress)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelegationStore_GetDelegationEvents(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	delegationStore := store.NewDelegationStore(db)
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("FROM delegation_events\\s+WHERE validator_address = \\$1 AND event_type = ANY\\(\\$2\\) AND ABS\\(delta\\) >= CAST\\(\\$3 AS NUMERIC\\) AND created_at >= \\$4\\s+" +
+		"ORDER BY created_at DESC, id DESC\\s+LIMIT \\$5 OFFSET \\$6").
+		WithArgs("validator1", "{\"exit\",\"decrease\"}", "1000", since, store.DefaultDelegationEventLimit, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "validator_address", "delegator_address", "event_type", "previous_shares", "shares", "delta", "created_at"}).
+			AddRow(4, "validator1", "delegator1", "exit", "1500.000000000000000000", "0.000000000000000000", "-1500.000000000000000000", testTime))
+
+	events, err := delegationStore.GetDelegationEvents(context.Background(), store.DelegationEventFilter{
+		ValidatorAddress: "validator1",
+		Types:            []models.DelegationEventType{models.DelegationEventExit, models.DelegationEventDecrease},
+		MinDelta:         "1000",
+		Since:            &since,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, models.DelegationEventExit, events[0].Type)
+		assert.Equal(t, "-1500.000000000000000000", events[0].Delta)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelegationStore_GetDelegationEventsAfter(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	delegationStore := store.NewDelegationStore(db)
+
+	mock.ExpectQuery("FROM delegation_events\\s+WHERE id > \\$1 AND \\(\\$2 = '' OR validator_address = \\$2\\)\\s+ORDER BY id\\s+LIMIT \\$3").
+		WithArgs(int64(41), "validator1", store.MaxDelegationEventLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "validator_address", "delegator_address", "event_type", "previous_shares", "shares", "delta", "created_at"}).
+			AddRow(42, "validator1", "delegator1", "new", "0.000000000000000000", "10.000000000000000000", "10.000000000000000000", testTime).
+			AddRow(45, "validator1", "delegator2", "increase", "5.000000000000000000", "7.000000000000000000", "2.000000000000000000", testTime))
+
+	events, err := delegationStore.GetDelegationEventsAfter(context.Background(), "validator1", 41, 0)
 	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, int64(42), events[0].ID)
+		assert.Equal(t, int64(45), events[1].ID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestDelegationStore_GetDelegations(t *testing.T) {
@@ -136,7 +337,7 @@ func TestDelegationStore_GetDelegations(t *testing.T) {
 		WithArgs("validator1").
 		WillReturnRows(rows)
 
-	delegations, err := delegationStore.GetDelegations("validator1")
+	delegations, err := delegationStore.GetDelegations(context.Background(), "validator1")
 	assert.NoError(t, err)
 	assert.Len(t, delegations, 1)
 	assert.Equal(t, "validator1", delegations[0].ValidatorAddress)
@@ -154,10 +355,10 @@ func TestDelegationStore_GetEnabledValidators(t *testing.T) {
 		AddRow("validator1").
 		AddRow("validator2")
 
-	mock.ExpectQuery("SELECT address FROM validators WHERE enabled_tracking = true").
+	mock.ExpectQuery("SELECT address FROM validators WHERE enabled_tracking = true AND archived_at IS NULL").
 		WillReturnRows(rows)
 
-	validators, err := delegationStore.GetEnabledValidators()
+	validators, err := delegationStore.GetEnabledValidators(context.Background())
 	assert.NoError(t, err)
 	assert.Len(t, validators, 2)
 	assert.Contains(t, validators, "validator1")
@@ -174,7 +375,989 @@ func TestDelegationStore_DelegationExists(t *testing.T) {
 		WithArgs("validator1", "delegator1", "100.0").
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
 
-	exists, err := delegationStore.DelegationExists("validator1", "delegator1", "100.0")
+	exists, err := delegationStore.DelegationExists(context.Background(), "validator1", "delegator1", "100.0")
 	assert.NoError(t, err)
 	assert.True(t, exists)
-} 
\ No newline at end of file
+}
+
+func TestAPIKeyStore_GetByHash(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	keyStore := store.NewAPIKeyStore(db)
+	createdAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "key_prefix", "role", "created_at", "rotated_at", "last_used_at", "revoked_at"}).
+		AddRow(1, "ci", "cvs_12345678", "operator", createdAt, nil, nil, nil)
+	mock.ExpectQuery("SELECT .* FROM api_keys WHERE key_hash = \\$1 AND revoked_at IS NULL").
+		WithArgs("hash1").
+		WillReturnRows(rows)
+
+	key, err := keyStore.GetByHash(context.Background(), "hash1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, key.ID)
+	assert.Equal(t, models.RoleOperator, key.Role)
+	assert.Nil(t, key.RevokedAt)
+
+	mock.ExpectQuery("SELECT .* FROM api_keys WHERE key_hash = \\$1 AND revoked_at IS NULL").
+		WithArgs("unknown").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = keyStore.GetByHash(context.Background(), "unknown")
+	assert.Equal(t, store.ErrAPIKeyNotFound, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyStore_Revoke(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	keyStore := store.NewAPIKeyStore(db)
+
+	mock.ExpectExec("UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = \\$1 AND revoked_at IS NULL").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, keyStore.Revoke(context.Background(), 1))
+
+	mock.ExpectExec("UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = \\$1 AND revoked_at IS NULL").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.Equal(t, store.ErrAPIKeyNotFound, keyStore.Revoke(context.Background(), 2))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_UpdateRecordsAuditEvent(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+	ctx := audit.WithActor(context.Background(), audit.Actor{Name: "ci", KeyID: 3})
+	updatedAt := testTime.Add(time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT address, name, enabled_tracking, archived_at, created_at, updated_at, moniker, website, commission_rate, bond_status, jailed, tokens, metadata_updated_at, tags FROM validators WHERE address = \\$1 FOR UPDATE").
+		WithArgs("val1").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow(validatorRow("val1", "Validator 1", true, nil, testTime, testTime)...))
+	mock.ExpectQuery("UPDATE validators").
+		WithArgs("Validator 1", false, "val1").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow(validatorRow("val1", "Validator 1", false, nil, testTime, updatedAt)...))
+	mock.ExpectExec("INSERT INTO audit_events").
+		WithArgs("validator", "val1", "update", "ci", sqlmock.AnyArg(), sqlmock.AnyArg(),
+			`{"name":"Validator 1","address":"val1","enabledTracking":true,"createdAt":"2024-05-01T10:00:00Z","updatedAt":"2024-05-01T10:00:00Z"}`,
+			`{"name":"Validator 1","address":"val1","enabledTracking":false,"createdAt":"2024-05-01T10:00:00Z","updatedAt":"2024-05-01T10:01:00Z"}`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := validatorStore.Update(ctx, "val1", models.Validator{Name: "Validator 1", EnabledTracking: false}, &testTime)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_UpdateMetadata(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+	metadata := models.ValidatorMetadata{
+		Moniker:        "Moniker",
+		Website:        "https://example.com",
+		CommissionRate: "0.05",
+		Status:         "BOND_STATUS_BONDED",
+		Tokens:         "1000",
+	}
+
+	mock.ExpectExec("UPDATE validators SET moniker = \\$1").
+		WithArgs("Moniker", "https://example.com", "0.05", "BOND_STATUS_BONDED", false, "1000", "val1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE validators SET moniker = \\$1").
+		WithArgs("Moniker", "https://example.com", "0.05", "BOND_STATUS_BONDED", false, "1000", "missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, validatorStore.UpdateMetadata(context.Background(), "val1", metadata))
+	assert.Equal(t, store.ErrValidatorNotFound, validatorStore.UpdateMetadata(context.Background(), "missing", metadata))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_GetByAddressWithMetadata(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+
+	mock.ExpectQuery("SELECT address, name, .* FROM validators WHERE address = \\$1").
+		WithArgs("val1").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow("val1", "Validator 1", true, nil, testTime, testTime,
+			"Moniker", "", "0.05", "BOND_STATUS_UNBONDING", true, "1000", testTime, "{}"))
+
+	validator, err := validatorStore.GetByAddress(context.Background(), "val1")
+	assert.NoError(t, err)
+	if assert.NotNil(t, validator.Metadata) {
+		assert.Equal(t, "Moniker", validator.Metadata.Moniker)
+		assert.True(t, validator.Metadata.Jailed)
+		assert.Equal(t, testTime, validator.Metadata.UpdatedAt)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_Import(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+	metadata := &models.ValidatorMetadata{Moniker: "Moniker", Status: "BOND_STATUS_BONDED", Tokens: "1000"}
+	metadataArgs := []driver.Value{"Moniker", "", "", "BOND_STATUS_BONDED", false, "1000"}
+	archivedAt := testTime.Add(-time.Hour)
+
+	mock.ExpectBegin()
+	// val1 is new
+	mock.ExpectQuery("INSERT INTO validators .* ON CONFLICT \\(address\\) DO NOTHING").
+		WithArgs(append([]driver.Value{"val1", "Validator 1", true}, metadataArgs...)...).
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow(validatorRow("val1", "Validator 1", true, nil, testTime, testTime)...))
+	mock.ExpectExec("INSERT INTO audit_events").WillReturnResult(sqlmock.NewResult(1, 1))
+	// val2 exists and starts being tracked
+	mock.ExpectQuery("INSERT INTO validators .* ON CONFLICT").
+		WillReturnRows(sqlmock.NewRows(validatorColumns))
+	mock.ExpectQuery("SELECT .* FROM validators WHERE address = \\$1 FOR UPDATE").
+		WithArgs("val2").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow(validatorRow("val2", "Mine", false, nil, testTime, testTime)...))
+	mock.ExpectQuery("UPDATE validators SET moniker = \\$1, .* enabled_tracking = \\$8").
+		WithArgs(append(metadataArgs, "val2", true)...).
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow(validatorRow("val2", "Mine", true, nil, testTime, testTime)...))
+	mock.ExpectExec("INSERT INTO audit_events").WillReturnResult(sqlmock.NewResult(2, 1))
+	// val3 is archived
+	mock.ExpectQuery("INSERT INTO validators .* ON CONFLICT").
+		WillReturnRows(sqlmock.NewRows(validatorColumns))
+	mock.ExpectQuery("SELECT .* FROM validators WHERE address = \\$1 FOR UPDATE").
+		WithArgs("val3").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow(validatorRow("val3", "Old", false, archivedAt, testTime, testTime)...))
+	mock.ExpectCommit()
+
+	result, err := validatorStore.Import(context.Background(), []models.Validator{
+		{Address: "val1", Name: "Validator 1", EnabledTracking: true, Metadata: metadata},
+		{Address: "val2", Name: "Validator 2", EnabledTracking: true, Metadata: metadata},
+		{Address: "val3", Name: "Validator 3", EnabledTracking: true, Metadata: metadata},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, store.ImportResult{Created: 1, Updated: 1, Archived: 1}, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_UpsertAtomicRollsBackOnConflict(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+	archivedAt := testTime.Add(-time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO validators .* ON CONFLICT \\(address\\) DO NOTHING").
+		WithArgs("val1", "Validator 1", true).
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow(validatorRow("val1", "Validator 1", true, nil, testTime, testTime)...))
+	mock.ExpectExec("INSERT INTO audit_events").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO validators .* ON CONFLICT").
+		WithArgs("val2", "Validator 2", true).
+		WillReturnRows(sqlmock.NewRows(validatorColumns))
+	mock.ExpectQuery("SELECT .* FROM validators WHERE address = \\$1 FOR UPDATE").
+		WithArgs("val2").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow(validatorRow("val2", "Validator 2", true, archivedAt, testTime, testTime)...))
+	mock.ExpectRollback()
+
+	statuses, err := validatorStore.Upsert(context.Background(), []models.Validator{
+		{Address: "val1", Name: "Validator 1", EnabledTracking: true},
+		{Address: "val2", Name: "Validator 2", EnabledTracking: true},
+	}, true)
+	assert.Equal(t, store.ErrBatchConflict, err)
+	assert.Equal(t, []store.UpsertStatus{store.UpsertCreated, store.UpsertConflict}, statuses)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_AddReturnsAlreadyExists(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO validators .* ON CONFLICT \\(address\\) DO NOTHING").
+		WithArgs("val1", "Validator 1", true).
+		WillReturnRows(sqlmock.NewRows(validatorColumns))
+	mock.ExpectRollback()
+
+	err := validatorStore.Add(context.Background(), models.Validator{Address: "val1", Name: "Validator 1", EnabledTracking: true})
+	assert.Equal(t, store.ErrValidatorAlreadyExists, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_List(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+	enabled := true
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM validators WHERE archived_at IS NULL AND name ILIKE .* AND enabled_tracking = \$2`).
+		WithArgs(`50\%`, true).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT address, name, .* FROM validators WHERE archived_at IS NULL AND name ILIKE .* AND enabled_tracking = \$2 ` +
+		`ORDER BY CAST\(tokens AS NUMERIC\) DESC NULLS LAST, address LIMIT \$3 OFFSET \$4`).
+		WithArgs(`50\%`, true, 2, 1).
+		WillReturnRows(sqlmock.NewRows(validatorColumns).
+			AddRow(validatorRow("val2", "50% Validator", true, nil, testTime, testTime)...))
+
+	validators, total, err := validatorStore.List(context.Background(), store.ValidatorFilter{
+		Search:          "50%",
+		EnabledTracking: &enabled,
+		Sort:            store.ValidatorSortStake,
+		Descending:      true,
+		Limit:           2,
+		Offset:          1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, validators, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_UpdateRejectsStaleVersion(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+	staleUpdatedAt := testTime.Add(-time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT address, name, enabled_tracking, archived_at, created_at, updated_at, moniker, website, commission_rate, bond_status, jailed, tokens, metadata_updated_at, tags FROM validators WHERE address = \\$1 FOR UPDATE").
+		WithArgs("val1").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow(validatorRow("val1", "Validator 1", true, nil, testTime, testTime)...))
+	mock.ExpectRollback()
+
+	err := validatorStore.Update(context.Background(), "val1", models.Validator{Name: "Renamed"}, &staleUpdatedAt)
+	assert.Equal(t, store.ErrValidatorModified, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_DeleteNotFound(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT address, name, enabled_tracking, archived_at, created_at, updated_at, moniker, website, commission_rate, bond_status, jailed, tokens, metadata_updated_at, tags FROM validators WHERE address = \\$1 FOR UPDATE").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	err := validatorStore.Delete(context.Background(), "missing")
+	assert.Equal(t, store.ErrValidatorNotFound, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_ArchiveKeepsHistory(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+	archivedAt := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT address, name, enabled_tracking, archived_at, created_at, updated_at, moniker, website, commission_rate, bond_status, jailed, tokens, metadata_updated_at, tags FROM validators WHERE address = \\$1 FOR UPDATE").
+		WithArgs("val1").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow(validatorRow("val1", "Validator 1", true, nil, testTime, testTime)...))
+	mock.ExpectQuery("UPDATE validators SET archived_at = CURRENT_TIMESTAMP").
+		WithArgs("val1").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow(validatorRow("val1", "Validator 1", true, archivedAt, testTime, testTime)...))
+	mock.ExpectExec("INSERT INTO audit_events").
+		WithArgs("validator", "val1", "archive", "anonymous", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, validatorStore.Archive(context.Background(), "val1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_RestoreRequiresArchived(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT address, name, enabled_tracking, archived_at, created_at, updated_at, moniker, website, commission_rate, bond_status, jailed, tokens, metadata_updated_at, tags FROM validators WHERE address = \\$1 FOR UPDATE").
+		WithArgs("val1").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow(validatorRow("val1", "Validator 1", true, nil, testTime, testTime)...))
+	mock.ExpectRollback()
+
+	assert.Equal(t, store.ErrValidatorNotArchived, validatorStore.Restore(context.Background(), "val1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditStore_ListAppliesFilters(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	auditStore := store.NewAuditStore(db)
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := since.Add(time.Hour)
+
+	rows := sqlmock.NewRows([]string{"id", "entity_type", "entity_id", "action", "actor", "actor_key_id", "request_id", "before_state", "after_state", "created_at"}).
+		AddRow(7, "validator", "val1", "delete", "ci", 3, "req-1", []byte(`{"name":"Validator 1"}`), nil, createdAt)
+	mock.ExpectQuery("FROM audit_events\\s+WHERE entity_id = \\$1 AND action = \\$2 AND created_at >= \\$3\\s+ORDER BY created_at DESC, id DESC\\s+LIMIT \\$4 OFFSET \\$5").
+		WithArgs("val1", "delete", since, 10, 0).
+		WillReturnRows(rows)
+
+	events, err := auditStore.List(context.Background(), store.AuditFilter{
+		EntityID: "val1",
+		Action:   models.AuditActionDelete,
+		Since:    &since,
+		Limit:    10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "ci", events[0].Actor)
+	assert.Equal(t, 3, *events[0].ActorKeyID)
+	assert.JSONEq(t, `{"name":"Validator 1"}`, string(events[0].Before))
+	assert.Nil(t, events[0].After)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_ListByTag(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM validators WHERE archived_at IS NULL AND \$1 = ANY\(tags\)`).
+		WithArgs("client-a").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT address, name, .* FROM validators WHERE archived_at IS NULL AND \$1 = ANY\(tags\) ORDER BY LOWER\(name\) ASC, address OFFSET \$2`).
+		WithArgs("client-a", 0).
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow("val1", "Validator 1", true, nil, testTime, testTime,
+			nil, nil, nil, nil, nil, nil, nil, "{client-a,eu}"))
+
+	validators, total, err := validatorStore.List(context.Background(), store.ValidatorFilter{Tag: "client-a"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	if assert.Len(t, validators, 1) {
+		assert.Equal(t, []string{"client-a", "eu"}, validators[0].Tags)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_AddTag(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+	updatedAt := testTime.Add(time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT address, name, .* FROM validators WHERE address = \\$1 FOR UPDATE").
+		WithArgs("val1").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow("val1", "Validator 1", true, nil, testTime, testTime,
+			nil, nil, nil, nil, nil, nil, nil, "{eu}"))
+	mock.ExpectQuery("UPDATE validators SET tags = \\$1, updated_at = CURRENT_TIMESTAMP WHERE address = \\$2").
+		WithArgs("{\"client-a\",\"eu\"}", "val1").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow("val1", "Validator 1", true, nil, testTime, updatedAt,
+			nil, nil, nil, nil, nil, nil, nil, "{client-a,eu}"))
+	mock.ExpectExec("INSERT INTO audit_events").
+		WithArgs("validator", "val1", "update", "anonymous", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, validatorStore.AddTag(context.Background(), "val1", "client-a"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_AddExistingTagChangesNothing(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT address, name, .* FROM validators WHERE address = \\$1 FOR UPDATE").
+		WithArgs("val1").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).AddRow("val1", "Validator 1", true, nil, testTime, testTime,
+			nil, nil, nil, nil, nil, nil, nil, "{eu}"))
+	mock.ExpectCommit()
+
+	assert.NoError(t, validatorStore.AddTag(context.Background(), "val1", "eu"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_DeleteTagNotFound(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT address, name, .* FROM validators WHERE \\$1 = ANY\\(tags\\) ORDER BY address FOR UPDATE").
+		WithArgs("client-a").
+		WillReturnRows(sqlmock.NewRows(validatorColumns))
+	mock.ExpectRollback()
+
+	count, err := validatorStore.DeleteTag(context.Background(), "client-a")
+	assert.Equal(t, store.ErrTagNotFound, err)
+	assert.Zero(t, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelegationStore_GetStakeSummary(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	delegationStore := store.NewDelegationStore(db)
+
+	mock.ExpectQuery("DISTINCT ON \\(validator_address, delegator_address\\).* WHERE v.archived_at IS NULL AND \\$1 = ANY\\(v.tags\\)").
+		WithArgs("client-a").
+		WillReturnRows(sqlmock.NewRows([]string{"address", "count", "sum"}).
+			AddRow("val1", 2, "1500.000000000000000000").
+			AddRow("val2", 0, "0"))
+
+	stakes, err := delegationStore.GetStakeSummary(context.Background(), "client-a")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ValidatorStake{
+		{ValidatorAddress: "val1", Delegators: 2, TotalShares: "1500.000000000000000000"},
+		{ValidatorAddress: "val2", Delegators: 0, TotalShares: "0"},
+	}, stakes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAlertStore_AddRule(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	alertStore := store.NewAlertStore(db)
+	ruleColumns := []string{"id", "name", "validator_address", "min_delta", "min_stake_percent", "enabled", "created_at", "updated_at"}
+
+	mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM validators WHERE address = \\$1\\)").WithArgs("validator1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("INSERT INTO alert_rules").
+		WithArgs("Whales", "validator1", nil, "2.5", true).
+		WillReturnRows(sqlmock.NewRows(ruleColumns).AddRow(1, "Whales", "validator1", nil, "2.5", true, testTime, testTime))
+
+	rule, err := alertStore.AddRule(context.Background(), models.AlertRule{Name: "Whales", ValidatorAddress: "validator1", MinStakePercent: "2.5", Enabled: true})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.AlertRule{ID: 1, Name: "Whales", ValidatorAddress: "validator1", MinStakePercent: "2.5", Enabled: true,
+		CreatedAt: testTime, UpdatedAt: testTime}, rule)
+
+	mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM validators WHERE address = \\$1\\)").WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	_, err = alertStore.AddRule(context.Background(), models.AlertRule{Name: "Whales", ValidatorAddress: "missing", MinDelta: "10", Enabled: true})
+	assert.Equal(t, store.ErrValidatorNotFound, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAlertStore_RecordAlertsSkipsDuplicates(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	alertStore := store.NewAlertStore(db)
+	ruleID := 1
+	alerts := []models.Alert{
+		{RuleID: &ruleID, RuleName: "Whales", DelegationEventID: 10, ValidatorAddress: "validator1", DelegatorAddress: "delegator1",
+			EventType: models.DelegationEventExit, Delta: "-1500.000000000000000000", StakePercent: "7.5000"},
+		{RuleID: &ruleID, RuleName: "Whales", DelegationEventID: 11, ValidatorAddress: "validator1", DelegatorAddress: "delegator2",
+			EventType: models.DelegationEventNew, Delta: "2500.000000000000000000"},
+	}
+
+	mock.ExpectBegin()
+	// Alerts of deleted rules keep their rule in source_rule_id, which deduplicates them
+	mock.ExpectPrepare("INSERT INTO alerts \\(rule_id, source_rule_id, .*VALUES \\(\\$1, \\$1, .*ON CONFLICT \\(source_rule_id, delegation_event_id\\) DO NOTHING").WillBeClosed()
+	mock.ExpectQuery("INSERT INTO alerts").
+		WithArgs(1, "Whales", 10, "validator1", "delegator1", "exit", "-1500.000000000000000000", "7.5000").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(5, testTime))
+	// Already fired: ON CONFLICT DO NOTHING returns no row
+	mock.ExpectQuery("INSERT INTO alerts").
+		WithArgs(1, "Whales", 11, "validator1", "delegator2", "new", "2500.000000000000000000", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+	// Only the new alert is queued in the event outbox
+	mock.ExpectPrepare("INSERT INTO event_outbox").WillBeClosed()
+	mock.ExpectExec("INSERT INTO event_outbox").
+		WithArgs("alert-5", "alert.fired", "validator1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	recorded, err := alertStore.RecordAlerts(context.Background(), alerts)
+	assert.NoError(t, err)
+	if assert.Len(t, recorded, 1) {
+		assert.Equal(t, int64(5), recorded[0].ID)
+		assert.Equal(t, int64(10), recorded[0].DelegationEventID)
+		assert.Equal(t, testTime, recorded[0].CreatedAt)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookStore_EnqueueCountsDeliveries(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	webhookStore := store.NewWebhookStore(db)
+	events := []models.Event{
+		{ID: "event1", Type: models.EventAlertFired, ValidatorAddress: "validator1", CreatedAt: testTime, Data: []byte(`{}`)},
+		{ID: "event2", Type: models.EventSyncFailed, ValidatorAddress: "validator1", CreatedAt: testTime, Data: []byte(`{}`)},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO webhook_deliveries").WillBeClosed()
+	mock.ExpectExec("INSERT INTO webhook_deliveries").
+		WithArgs("event1", "alert.fired", `{"id":"event1","type":"alert.fired","validator_address":"validator1","created_at":"2024-05-01T10:00:00Z","data":{}}`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	// No webhook subscribes to the second event
+	mock.ExpectExec("INSERT INTO webhook_deliveries").
+		WithArgs("event2", "sync.failed", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	queued, err := webhookStore.Enqueue(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, queued)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookStore_ClaimDueLeasesDeliveries(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	webhookStore := store.NewWebhookStore(db)
+	columns := []string{"id", "webhook_id", "event_id", "event_type", "payload", "attempts", "created_at", "url", "secret"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM webhook_deliveries d JOIN webhooks w (.+) FOR UPDATE OF d SKIP LOCKED").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(7, 1, "event1", "alert.fired", `{"id": "event1"}`, 2, testTime, "https://example.com/hooks", "whsec_test"))
+	mock.ExpectExec("UPDATE webhook_deliveries SET next_attempt_at").WithArgs(float64(90), "{7}").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	jobs, err := webhookStore.ClaimDue(context.Background(), 10, 90*time.Second)
+	assert.NoError(t, err)
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, store.WebhookJob{
+			Delivery: models.WebhookDelivery{ID: 7, WebhookID: 1, EventID: "event1", EventType: models.EventAlertFired,
+				Payload: []byte(`{"id": "event1"}`), Status: models.WebhookDeliveryPending, Attempts: 2, CreatedAt: testTime},
+			URL:    "https://example.com/hooks",
+			Secret: "whsec_test",
+		}, jobs[0])
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationChannelStore_GetEnabledDecodesChannels(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	notificationStore := store.NewNotificationChannelStore(db)
+	columns := []string{"id", "name", "type", "webhook_url", "bot_token", "chat_id", "validator_addresses", "event_types",
+		"sync_failure_threshold", "templates", "enabled", "created_at", "updated_at"}
+
+	mock.ExpectQuery("SELECT (.+) FROM notification_channels WHERE enabled ORDER BY id").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "ops", "telegram", "", "1:abc", "-100", "{}", "{sync.failed,alert.fired}", 3,
+				`{"sync.failed": "{{.ValidatorAddress}} is failing"}`, true, testTime, testTime))
+
+	channels, err := notificationStore.GetEnabled(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []models.NotificationChannel{{
+		ID: 1, Name: "ops", Type: models.NotificationTelegram, BotToken: "1:abc", ChatID: "-100",
+		ValidatorAddresses:   []string{},
+		EventTypes:           []models.EventType{models.EventSyncFailed, models.EventAlertFired},
+		SyncFailureThreshold: 3,
+		Templates:            map[models.EventType]string{models.EventSyncFailed: "{{.ValidatorAddress}} is failing"},
+		Enabled:              true, CreatedAt: testTime, UpdatedAt: testTime,
+	}}, channels)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelegationStore_GetDelegationActivity(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	delegationStore := store.NewDelegationStore(db)
+	since := testTime.Add(-24 * time.Hour)
+
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(delta\\), 0\\)::TEXT.* FROM delegation_events WHERE validator_address = \\$1 AND created_at >= \\$2 AND created_at < \\$3").
+		WithArgs("val1", since, testTime).
+		WillReturnRows(sqlmock.NewRows([]string{"sum", "new", "exit"}).AddRow("750.000000000000000000", 2, 1))
+	mock.ExpectQuery("GROUP BY delegator_address HAVING SUM\\(delta\\) <> 0 ORDER BY ABS\\(SUM\\(delta\\)\\) DESC, delegator_address LIMIT \\$4").
+		WithArgs("val1", since, testTime, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"delegator_address", "sum"}).
+			AddRow("del1", "1000.000000000000000000").
+			AddRow("del2", "-250.000000000000000000"))
+
+	activity, err := delegationStore.GetDelegationActivity(context.Background(), "val1", since, testTime, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, models.DelegationActivity{
+		ValidatorAddress: "val1",
+		StakeChange:      "750.000000000000000000",
+		NewDelegators:    2,
+		LostDelegators:   1,
+		TopMovers: []models.DelegatorMovement{
+			{DelegatorAddress: "del1", Delta: "1000.000000000000000000"},
+			{DelegatorAddress: "del2", Delta: "-250.000000000000000000"},
+		},
+	}, activity)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEmailDigestStore_RecordSend(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	digestStore := store.NewEmailDigestStore(db)
+
+	mock.ExpectExec("UPDATE email_digests SET last_sent_at = \\$1, last_attempt_at = \\$1, last_error = '' WHERE id = \\$2").
+		WithArgs(testTime, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE email_digests SET last_attempt_at = \\$1, last_error = \\$2 WHERE id = \\$3").
+		WithArgs(testTime, "550 mailbox unavailable", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, digestStore.RecordSend(context.Background(), 1, testTime, nil))
+	assert.NoError(t, digestStore.RecordSend(context.Background(), 1, testTime, errors.New("550 mailbox unavailable")))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEmailDigestStore_GetEnabled(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	digestStore := store.NewEmailDigestStore(db)
+	columns := []string{"id", "tag", "frequency", "recipients", "enabled", "last_sent_at", "last_attempt_at", "last_error",
+		"created_at", "updated_at"}
+
+	mock.ExpectQuery("SELECT (.+) FROM email_digests WHERE enabled AND frequency = \\$1 ORDER BY id").
+		WithArgs("weekly").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "acme", "weekly", "{ops@example.com}", true, testTime, testTime, "", testTime, testTime).
+			AddRow(2, "beta", "weekly", "{}", true, nil, nil, "", testTime, testTime))
+
+	digests, err := digestStore.GetEnabled(context.Background(), models.DigestWeekly)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.EmailDigest{
+		{ID: 1, Tag: "acme", Frequency: models.DigestWeekly, Recipients: []string{"ops@example.com"}, Enabled: true,
+			LastSentAt: &testTime, LastAttemptAt: &testTime, CreatedAt: testTime, UpdatedAt: testTime},
+		{ID: 2, Tag: "beta", Frequency: models.DigestWeekly, Recipients: []string{}, Enabled: true,
+			CreatedAt: testTime, UpdatedAt: testTime},
+	}, digests)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBusOutboxStore_EnqueueSkipsQueuedEvents(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	outboxStore := store.NewBusOutboxStore(db)
+	messages := []models.BusMessage{
+		{EventID: "event1", Topic: "changes", Key: "validator1", Payload: []byte(`{"id":"event1"}`)},
+		{EventID: "event2", Topic: "changes", Key: "validator1", Payload: []byte(`{"id":"event2"}`)},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO bus_outbox (.+) ON CONFLICT \\(event_id\\) DO NOTHING").WillBeClosed()
+	mock.ExpectExec("INSERT INTO bus_outbox").WithArgs("event1", "changes", "validator1", `{"id":"event1"}`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO bus_outbox").WithArgs("event2", "changes", "validator1", `{"id":"event2"}`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	queued, err := outboxStore.Enqueue(context.Background(), messages)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, queued)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBusOutboxStore_ClaimNextLocksMessages(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	outboxStore := store.NewBusOutboxStore(db)
+	columns := []string{"id", "event_id", "topic", "message_key", "payload", "attempts", "created_at"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mock.ExpectQuery("SELECT (.+) FROM bus_outbox WHERE published_at IS NULL AND id < (.+) ORDER BY id LIMIT").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(3, "event1", "changes", "validator1", `{"id": "event1"}`, 0, testTime).
+			AddRow(4, "event2", "changes", "validator2", `{"id": "event2"}`, 0, testTime))
+	mock.ExpectExec("UPDATE bus_outbox SET locked_until").WithArgs(float64(20), "{3,4}").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	messages, err := outboxStore.ClaimNext(context.Background(), 10, 20*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BusMessage{
+		{ID: 3, EventID: "event1", Topic: "changes", Key: "validator1", Payload: []byte(`{"id": "event1"}`), CreatedAt: testTime},
+		{ID: 4, EventID: "event2", Topic: "changes", Key: "validator2", Payload: []byte(`{"id": "event2"}`), CreatedAt: testTime},
+	}, messages)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBusOutboxStore_ClaimNextWhileAnotherRelayClaims(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	outboxStore := store.NewBusOutboxStore(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+	mock.ExpectRollback()
+
+	messages, err := outboxStore.ClaimNext(context.Background(), 10, 20*time.Second)
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBusOutboxStore_MarkFailed(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	outboxStore := store.NewBusOutboxStore(db)
+	retryAt := testTime.Add(time.Minute)
+
+	mock.ExpectExec("UPDATE bus_outbox SET attempts = attempts \\+ 1, last_error = \\$1, locked_until = \\$2").
+		WithArgs("connection refused", retryAt, "{3,4}").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := outboxStore.MarkFailed(context.Background(), []int64{3, 4}, "connection refused", retryAt)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEventOutboxStore_EnqueueSkipsQueuedEvents(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	outboxStore := store.NewEventOutboxStore(db)
+	events := []models.Event{
+		{ID: "event1", Type: models.EventSyncFailed, ValidatorAddress: "validator1", CreatedAt: testTime, Data: []byte(`{}`)},
+		{ID: "event2", Type: models.EventSyncFailed, ValidatorAddress: "validator2", CreatedAt: testTime, Data: []byte(`{}`)},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO event_outbox").WillBeClosed()
+	mock.ExpectExec("INSERT INTO event_outbox").WithArgs("event1", "sync.failed", "validator1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// Already queued: ON CONFLICT DO NOTHING affects no row
+	mock.ExpectExec("INSERT INTO event_outbox").WithArgs("event2", "sync.failed", "validator2", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	queued, err := outboxStore.Enqueue(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, queued)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEventOutboxStore_ClaimNextLocksEvents(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	outboxStore := store.NewEventOutboxStore(db)
+	payload := `{"id": "alert-7", "type": "alert.fired", "validator_address": "validator1", "created_at": "2024-05-01T10:00:00Z", "data": {}}`
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mock.ExpectQuery("SELECT (.+) FROM event_outbox o WHERE relayed_at IS NULL AND NOT EXISTS (.+) ORDER BY id LIMIT").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "payload", "attempts", "created_at"}).AddRow(3, payload, 1, testTime))
+	mock.ExpectExec("UPDATE event_outbox SET locked_until").WithArgs(float64(60), "{3}").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	claimed, err := outboxStore.ClaimNext(context.Background(), 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.OutboxEvent{{
+		ID: 3,
+		Event: models.Event{ID: "alert-7", Type: models.EventAlertFired, ValidatorAddress: "validator1",
+			CreatedAt: testTime, Data: []byte(`{}`)},
+		Attempts:  1,
+		CreatedAt: testTime,
+	}}, claimed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEventOutboxStore_MarkRelayed(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	outboxStore := store.NewEventOutboxStore(db)
+
+	mock.ExpectExec("UPDATE event_outbox SET relayed_at = CURRENT_TIMESTAMP").WithArgs("{3,4}").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, outboxStore.MarkRelayed(context.Background(), []int64{3, 4}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatorStore_GetByAddresses(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	validatorStore := store.NewValidatorStore(db)
+
+	mock.ExpectQuery("FROM validators WHERE address = ANY\\(\\$1\\) ORDER BY address").
+		WithArgs("{\"val1\",\"val2\",\"unknown\"}").
+		WillReturnRows(sqlmock.NewRows(validatorColumns).
+			AddRow(validatorRow("val1", "Validator 1", true, nil, testTime, testTime)...).
+			AddRow(validatorRow("val2", "Validator 2", false, testTime, testTime, testTime)...))
+
+	validators, err := validatorStore.GetByAddresses(context.Background(), []string{"val1", "val2", "unknown"})
+	assert.NoError(t, err)
+	if assert.Len(t, validators, 2) {
+		assert.Equal(t, "val1", validators[0].Address)
+		assert.Equal(t, "val2", validators[1].Address)
+		assert.NotNil(t, validators[1].ArchivedAt)
+	}
+
+	mock.ExpectQuery("FROM validators WHERE address = ANY\\(\\$1\\)").
+		WithArgs("{\"unknown\"}").
+		WillReturnRows(sqlmock.NewRows(validatorColumns))
+
+	validators, err = validatorStore.GetByAddresses(context.Background(), []string{"unknown"})
+	assert.NoError(t, err)
+	assert.NotNil(t, validators)
+	assert.Empty(t, validators)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelegationStore_GetCurrentDelegations(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	delegationStore := store.NewDelegationStore(db)
+
+	mock.ExpectQuery("SELECT DISTINCT ON \\(validator_address, delegator_address\\).*" +
+		"WHERE \\(COALESCE\\(cardinality\\(\\$1::TEXT\\[\\]\\), 0\\) = 0 OR validator_address = ANY\\(\\$1\\)\\)\\s+" +
+		"AND \\(COALESCE\\(cardinality\\(\\$2::TEXT\\[\\]\\), 0\\) = 0 OR delegator_address = ANY\\(\\$2\\)\\).*" +
+		"WHERE CAST\\(delegation_shares AS NUMERIC\\) > 0").
+		WithArgs("{\"validator1\",\"validator2\"}", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "validator_address", "delegator_address", "delegation_shares", "created_at", "updated_at"}).
+			AddRow(3, "validator1", "delegator1", "1500.000000000000000000", testTime, testTime).
+			AddRow(7, "validator2", "delegator1", "20.000000000000000000", testTime, testTime))
+
+	delegations, err := delegationStore.GetCurrentDelegations(context.Background(), store.CurrentDelegationFilter{
+		ValidatorAddresses: []string{"validator1", "validator2"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, delegations, 2) {
+		assert.Equal(t, "validator1", delegations[0].ValidatorAddress)
+		assert.Equal(t, "1500.000000000000000000", delegations[0].DelegationShares)
+		assert.Equal(t, "validator2", delegations[1].ValidatorAddress)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelegationStore_GetCurrentDelegations_PagesEachDelegator(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	delegationStore := store.NewDelegationStore(db)
+
+	mock.ExpectQuery("ROW_NUMBER\\(\\) OVER \\(PARTITION BY delegator_address ORDER BY validator_address\\) AS position.*" +
+		"WHERE CAST\\(delegation_shares AS NUMERIC\\) > 0\\s+\\) c\\s+\\) d\\s+" +
+		"WHERE position > \\$3 AND position <= \\$4\\s+ORDER BY delegator_address, position").
+		WithArgs(nil, "{\"delegator1\",\"delegator2\"}", 5, 15).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "validator_address", "delegator_address", "delegation_shares", "created_at", "updated_at"}).
+			AddRow(3, "validator1", "delegator1", "1500.000000000000000000", testTime, testTime).
+			AddRow(7, "validator2", "delegator2", "20.000000000000000000", testTime, testTime))
+
+	delegations, err := delegationStore.GetCurrentDelegations(context.Background(), store.CurrentDelegationFilter{
+		DelegatorAddresses: []string{"delegator1", "delegator2"},
+		Limit:              10,
+		Offset:             5,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, delegations, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelegationStore_GetCurrentDelegationTotals(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	delegationStore := store.NewDelegationStore(db)
+
+	mock.ExpectQuery("SELECT validator_address, COUNT\\(\\*\\), COALESCE\\(SUM\\(CAST\\(delegation_shares AS NUMERIC\\)\\), 0\\)::TEXT.*" +
+		"GROUP BY validator_address\\s+ORDER BY validator_address").
+		WithArgs("{\"validator1\",\"validator2\"}", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"validator_address", "count", "sum"}).
+			AddRow("validator1", 2, "1520.000000000000000000"))
+
+	totals, err := delegationStore.GetCurrentDelegationTotals(context.Background(), store.CurrentDelegationFilter{
+		ValidatorAddresses: []string{"validator1", "validator2"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]models.DelegationTotals{
+		"validator1": {Delegations: 2, TotalShares: "1520.000000000000000000"},
+		"validator2": {TotalShares: "0"},
+	}, totals)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelegationStore_GetDelegationEventsByValidator(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	delegationStore := store.NewDelegationStore(db)
+
+	mock.ExpectQuery("ROW_NUMBER\\(\\) OVER \\(PARTITION BY validator_address ORDER BY created_at DESC, id DESC\\) AS position\\s+" +
+		"FROM delegation_events\\s+WHERE validator_address = ANY\\(\\$1\\) AND event_type = ANY\\(\\$2\\)\\s+\\) e\\s+" +
+		"WHERE position > \\$3 AND position <= \\$4\\s+ORDER BY validator_address, position").
+		WithArgs("{\"validator1\",\"validator2\"}", "{\"new\"}", 10, 15).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "validator_address", "delegator_address", "event_type", "previous_shares", "shares", "delta", "created_at"}).
+			AddRow(9, "validator1", "delegator2", "new", "0.000000000000000000", "5.000000000000000000", "5.000000000000000000", testTime).
+			AddRow(8, "validator1", "delegator1", "new", "0.000000000000000000", "3.000000000000000000", "3.000000000000000000", testTime).
+			AddRow(4, "validator2", "delegator1", "new", "0.000000000000000000", "1.000000000000000000", "1.000000000000000000", testTime))
+
+	events, err := delegationStore.GetDelegationEventsByValidator(context.Background(), []string{"validator1", "validator2"}, store.DelegationEventFilter{
+		Types:  []models.DelegationEventType{models.DelegationEventNew},
+		Limit:  5,
+		Offset: 10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	if assert.Len(t, events["validator1"], 2) {
+		assert.Equal(t, int64(9), events["validator1"][0].ID)
+		assert.Equal(t, int64(8), events["validator1"][1].ID)
+	}
+	assert.Len(t, events["validator2"], 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelegationStore_GetStakeSeries(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	delegationStore := store.NewDelegationStore(db)
+	since := time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("date_trunc\\(\\$2, created_at AT TIME ZONE 'UTC'\\) AS bucket.*" +
+		"WHERE validator_address = ANY\\(\\$1\\) AND created_at < \\$4.*" +
+		"WHERE bucket >= date_trunc\\(\\$2, \\$3 AT TIME ZONE 'UTC'\\)\\s+ORDER BY validator_address, bucket").
+		WithArgs("{\"validator1\"}", "day", since, until).
+		WillReturnRows(sqlmock.NewRows([]string{"validator_address", "bucket", "stake_change", "total_shares", "new_delegators", "lost_delegators", "events"}).
+			AddRow("validator1", time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC), "100.000000000000000000", "1100.000000000000000000", 2, 0, 3).
+			AddRow("validator1", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), "-50.000000000000000000", "1050.000000000000000000", 0, 1, 1))
+
+	points, err := delegationStore.GetStakeSeries(context.Background(), []string{"validator1"}, store.StakeIntervalDay, since, until)
+	assert.NoError(t, err)
+	if assert.Len(t, points, 2) {
+		assert.Equal(t, since, points[0].Timestamp)
+		assert.Equal(t, "1100.000000000000000000", points[0].TotalShares)
+		assert.Equal(t, 2, points[0].NewDelegators)
+		assert.Equal(t, "-50.000000000000000000", points[1].StakeChange)
+		assert.Equal(t, 1, points[1].LostDelegators)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = delegationStore.GetStakeSeries(context.Background(), []string{"validator1"}, store.StakeInterval("week"), since, until)
+	assert.EqualError(t, err, `invalid stake interval "week"`)
+}
@@ -0,0 +1,100 @@
+package stream_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func changeEvent(t *testing.T, id int64, validatorAddress string) models.Event {
+	event, err := events.New(models.EventDelegationChanged, validatorAddress, models.DelegationEvent{
+		ID: id, ValidatorAddress: validatorAddress, DelegatorAddress: "del1", Type: models.DelegationEventIncrease,
+		PreviousShares: "100", Shares: "150", Delta: "50",
+	})
+	require.NoError(t, err)
+	return event
+}
+
+// received drains the buffered delegation changes of a subscription
+func received(subscription *stream.Subscription) []int64 {
+	var ids []int64
+	for {
+		select {
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return ids
+			}
+			ids = append(ids, event.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestBroker_PublishesMatchingChanges(t *testing.T) {
+	broker := stream.NewBroker(10)
+	all := broker.Subscribe(nil)
+	defer all.Close()
+	val1 := broker.Subscribe(func(event models.DelegationEvent) bool { return event.ValidatorAddress == "val1" })
+	defer val1.Close()
+
+	alert, err := events.New(models.EventAlertFired, "val1", map[string]string{"rule_name": "whales"})
+	require.NoError(t, err)
+	require.NoError(t, broker.Publish(context.Background(), []models.Event{
+		changeEvent(t, 1, "val1"), alert, changeEvent(t, 2, "val2"), changeEvent(t, 3, "val1"),
+	}))
+
+	assert.Equal(t, []int64{1, 2, 3}, received(all))
+	assert.Equal(t, []int64{1, 3}, received(val1))
+}
+
+func TestBroker_DropsLaggingSubscribers(t *testing.T) {
+	broker := stream.NewBroker(2)
+	slow := broker.Subscribe(nil)
+	defer slow.Close()
+	other := broker.Subscribe(func(event models.DelegationEvent) bool { return event.ID == 3 })
+	defer other.Close()
+
+	for id := int64(1); id <= 3; id++ {
+		require.NoError(t, broker.Publish(context.Background(), []models.Event{changeEvent(t, id, "val1")}))
+	}
+
+	// The buffered changes are still delivered before the channel closes
+	assert.Equal(t, []int64{1, 2}, received(slow))
+	_, open := <-slow.Events()
+	assert.False(t, open)
+	assert.Equal(t, stream.ErrLagged, slow.Err())
+
+	assert.Equal(t, []int64{3}, received(other))
+	assert.NoError(t, other.Err())
+}
+
+func TestBroker_Close(t *testing.T) {
+	broker := stream.NewBroker(0)
+	subscription := broker.Subscribe(nil)
+
+	broker.Close()
+	_, open := <-subscription.Events()
+	assert.False(t, open)
+	assert.Equal(t, stream.ErrClosed, subscription.Err())
+	subscription.Close()
+
+	late := broker.Subscribe(nil)
+	_, open = <-late.Events()
+	assert.False(t, open)
+	assert.Equal(t, stream.ErrClosed, late.Err())
+}
+
+func TestBroker_RejectsInvalidChanges(t *testing.T) {
+	broker := stream.NewBroker(1)
+	event := models.Event{ID: "abc", Type: models.EventDelegationChanged, Data: json.RawMessage(`"oops"`)}
+
+	err := broker.Publish(context.Background(), []models.Event{event})
+	assert.ErrorContains(t, err, "error decoding delegation change abc")
+}
@@ -0,0 +1,42 @@
+package stream_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter_Matches(t *testing.T) {
+	event := models.DelegationEvent{ValidatorAddress: "val1", DelegatorAddress: "del1", Delta: "-250.5"}
+
+	tests := []struct {
+		name   string
+		filter stream.Filter
+		want   bool
+	}{
+		{"empty filter", stream.NewFilter(nil, nil, nil), true},
+		{"matching validator", stream.NewFilter([]string{"val2", "val1"}, nil, nil), true},
+		{"other validator", stream.NewFilter([]string{"val2"}, nil, nil), false},
+		{"matching delegator", stream.NewFilter(nil, []string{"del1"}, nil), true},
+		{"other delegator", stream.NewFilter([]string{"val1"}, []string{"del2"}, nil), false},
+		{"absolute delta above minimum", stream.NewFilter(nil, nil, big.NewRat(250, 1)), true},
+		{"absolute delta equal to minimum", stream.NewFilter(nil, nil, big.NewRat(501, 2)), true},
+		{"absolute delta below minimum", stream.NewFilter(nil, nil, big.NewRat(251, 1)), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(event))
+		})
+	}
+}
+
+func TestFilter_MatchesUnparsableDelta(t *testing.T) {
+	event := models.DelegationEvent{ValidatorAddress: "val1", Delta: "n/a"}
+
+	assert.True(t, stream.NewFilter(nil, nil, nil).Matches(event))
+	assert.False(t, stream.NewFilter(nil, nil, big.NewRat(1, 1)).Matches(event))
+}
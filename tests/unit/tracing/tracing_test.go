@@ -0,0 +1,83 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/config"
+	"github.com/novintriantonius/cosmos-validator-service/internal/routes"
+	"github.com/novintriantonius/cosmos-validator-service/internal/services"
+	"github.com/novintriantonius/cosmos-validator-service/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func spanNames(recorder *tracetest.SpanRecorder) []string {
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	return names
+}
+
+func TestSetup_Disabled(t *testing.T) {
+	shutdown, err := tracing.Setup(context.Background(), config.TracingConfig{Enabled: false})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestCosmosServiceSpans(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"delegation_responses": [], "pagination": {"total": "0"}}`))
+	}))
+	defer server.Close()
+
+	service := services.NewCosmosServiceWithConfig(services.CosmosServiceConfig{
+		BaseURL:    server.URL,
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+	})
+
+	_, err := service.RetrieveDelegations(context.Background(), "cosmosvaloper1test")
+	require.NoError(t, err)
+
+	names := spanNames(recorder)
+	assert.Contains(t, names, "cosmos.delegations")
+	assert.Contains(t, names, "cosmos.http_request")
+	assert.Contains(t, names, "cosmos.parse_json")
+
+	// The HTTP and parse spans belong to the same trace as the endpoint span
+	spans := recorder.Ended()
+	traceID := spans[0].SpanContext().TraceID()
+	for _, span := range spans {
+		assert.Equal(t, traceID, span.SpanContext().TraceID())
+	}
+}
+
+func TestHTTPMiddlewareSpans(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	router := routes.SetupRouter(routes.Dependencies{})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, spanNames(recorder), "/livez")
+}
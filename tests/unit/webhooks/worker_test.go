@@ -0,0 +1,345 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/novintriantonius/cosmos-validator-service/internal/events"
+	"github.com/novintriantonius/cosmos-validator-service/internal/models"
+	"github.com/novintriantonius/cosmos-validator-service/internal/store"
+	"github.com/novintriantonius/cosmos-validator-service/internal/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWebhookStore keeps webhooks and their delivery queue in memory. Every
+// pending delivery is due, whatever its next attempt time.
+type memoryWebhookStore struct {
+	store.WebhookStore
+	mu         sync.Mutex
+	webhooks   map[int]models.Webhook
+	deliveries []*models.WebhookDelivery
+}
+
+func newMemoryWebhookStore(hooks ...models.Webhook) *memoryWebhookStore {
+	s := &memoryWebhookStore{webhooks: make(map[int]models.Webhook)}
+	for _, webhook := range hooks {
+		s.webhooks[webhook.ID] = webhook
+	}
+	return s
+}
+
+func (s *memoryWebhookStore) GetByID(ctx context.Context, id int) (*models.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		return nil, store.ErrWebhookNotFound
+	}
+	return &webhook, nil
+}
+
+func (s *memoryWebhookStore) Enqueue(ctx context.Context, evts []models.Event) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queued := 0
+	for _, event := range evts {
+		for id := 1; id <= len(s.webhooks); id++ {
+			webhook, ok := s.webhooks[id]
+			if !ok || !webhook.Enabled || !webhook.Subscribes(event.Type) {
+				continue
+			}
+			s.add(id, event)
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+func (s *memoryWebhookStore) EnqueueTo(ctx context.Context, webhookID int, event models.Event, lease time.Duration) (*models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.webhooks[webhookID]; !ok {
+		return nil, store.ErrWebhookNotFound
+	}
+	delivery := s.add(webhookID, event)
+	delivery.NextAttemptAt = time.Now().Add(lease)
+	return delivery, nil
+}
+
+func (s *memoryWebhookStore) add(webhookID int, event models.Event) *models.WebhookDelivery {
+	payload, _ := json.Marshal(event)
+	delivery := &models.WebhookDelivery{
+		ID:        int64(len(s.deliveries) + 1),
+		WebhookID: webhookID,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   payload,
+		Status:    models.WebhookDeliveryPending,
+	}
+	s.deliveries = append(s.deliveries, delivery)
+	return delivery
+}
+
+func (s *memoryWebhookStore) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]store.WebhookJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var jobs []store.WebhookJob
+	for _, delivery := range s.deliveries {
+		webhook := s.webhooks[delivery.WebhookID]
+		if delivery.Status != models.WebhookDeliveryPending || !webhook.Enabled || len(jobs) == limit {
+			continue
+		}
+		jobs = append(jobs, store.WebhookJob{Delivery: *delivery, URL: webhook.URL, Secret: webhook.Secret})
+	}
+	return jobs, nil
+}
+
+func (s *memoryWebhookStore) CompleteAttempt(ctx context.Context, deliveryID int64, attempt models.WebhookDeliveryAttempt,
+	status models.WebhookDeliveryStatus, nextAttemptAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delivery := s.deliveries[deliveryID-1]
+	delivery.Status = status
+	delivery.Attempts = attempt.Attempt
+	delivery.NextAttemptAt = nextAttemptAt
+	delivery.LastStatusCode = attempt.StatusCode
+	delivery.LastError = attempt.Error
+	delivery.AttemptLog = append(delivery.AttemptLog, attempt)
+	return nil
+}
+
+func (s *memoryWebhookStore) GetDelivery(ctx context.Context, webhookID int, deliveryID int64) (*models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if deliveryID < 1 || int(deliveryID) > len(s.deliveries) || s.deliveries[deliveryID-1].WebhookID != webhookID {
+		return nil, store.ErrWebhookDeliveryNotFound
+	}
+	delivery := *s.deliveries[deliveryID-1]
+	return &delivery, nil
+}
+
+// receivedRequest is a request captured by the test receiver
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver starts a webhook endpoint that answers with the given status
+// codes in turn, repeating the last one, and records every request
+func newReceiver(t *testing.T, statusCodes ...int) (*httptest.Server, func() []receivedRequest) {
+	var mu sync.Mutex
+	var received []receivedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, receivedRequest{header: r.Header.Clone(), body: body})
+		status := statusCodes[len(statusCodes)-1]
+		if len(received) <= len(statusCodes) {
+			status = statusCodes[len(received)-1]
+		}
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []receivedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedRequest(nil), received...)
+	}
+}
+
+func newEvent(t *testing.T, eventType models.EventType) models.Event {
+	event, err := events.New(eventType, "val1", map[string]string{"delta": "1000"})
+	require.NoError(t, err)
+	return event
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":"abc"}`)
+	signature := webhooks.Sign("whsec_test", 1700000000, body)
+
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+	assert.True(t, webhooks.Verify("whsec_test", 1700000000, body, signature))
+	assert.False(t, webhooks.Verify("whsec_other", 1700000000, body, signature))
+	assert.False(t, webhooks.Verify("whsec_test", 1700000001, body, signature))
+	assert.False(t, webhooks.Verify("whsec_test", 1700000000, []byte(`{"id":"abd"}`), signature))
+	assert.False(t, webhooks.Verify("whsec_test", 1700000000, body, signature[len("sha256="):]))
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := webhooks.GenerateSecret()
+	require.NoError(t, err)
+	second, err := webhooks.GenerateSecret()
+	require.NoError(t, err)
+
+	assert.Regexp(t, `^whsec_[0-9a-f]{64}$`, first)
+	assert.NotEqual(t, first, second)
+}
+
+func TestWorker_DeliversSignedEvents(t *testing.T) {
+	server, received := newReceiver(t, http.StatusNoContent)
+	webhookStore := newMemoryWebhookStore(
+		models.Webhook{ID: 1, URL: server.URL, Secret: "whsec_one", Enabled: true},
+		models.Webhook{ID: 2, URL: server.URL, Secret: "whsec_two", Enabled: true,
+			EventTypes: []models.EventType{models.EventSyncFailed}},
+		models.Webhook{ID: 3, URL: server.URL, Secret: "whsec_three", Enabled: false},
+	)
+	worker := webhooks.NewWorker(webhookStore, webhooks.WorkerConfig{})
+
+	event := newEvent(t, models.EventDelegationChanged)
+	require.NoError(t, webhooks.NewDispatcher(webhookStore).Publish(context.Background(), []models.Event{event}))
+	require.Len(t, webhookStore.deliveries, 1)
+
+	claimed, err := worker.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+
+	requests := received()
+	require.Len(t, requests, 1)
+	req := requests[0]
+	assert.Equal(t, "application/json", req.header.Get("Content-Type"))
+	assert.Equal(t, string(models.EventDelegationChanged), req.header.Get(webhooks.EventHeader))
+	assert.Equal(t, event.ID, req.header.Get(webhooks.EventIDHeader))
+	assert.Equal(t, "1", req.header.Get(webhooks.DeliveryHeader))
+
+	timestamp, err := strconv.ParseInt(req.header.Get(webhooks.TimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), time.Unix(timestamp, 0), time.Minute)
+	assert.True(t, webhooks.Verify("whsec_one", timestamp, req.body, req.header.Get(webhooks.SignatureHeader)))
+
+	var delivered models.Event
+	require.NoError(t, json.Unmarshal(req.body, &delivered))
+	assert.Equal(t, event.ID, delivered.ID)
+	assert.Equal(t, models.EventDelegationChanged, delivered.Type)
+	assert.Equal(t, "val1", delivered.ValidatorAddress)
+	assert.JSONEq(t, `{"delta": "1000"}`, string(delivered.Data))
+
+	delivery := webhookStore.deliveries[0]
+	assert.Equal(t, models.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.LastStatusCode)
+
+	// Nothing is left to deliver
+	claimed, err = worker.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, claimed)
+}
+
+func TestWorker_RetriesWithBackoffUntilDelivered(t *testing.T) {
+	server, received := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK)
+	webhookStore := newMemoryWebhookStore(models.Webhook{ID: 1, URL: server.URL, Secret: "whsec_one", Enabled: true})
+	worker := webhooks.NewWorker(webhookStore, webhooks.WorkerConfig{
+		InitialBackoff: time.Minute,
+		MaxBackoff:     time.Hour,
+	})
+	require.NoError(t, webhooks.NewDispatcher(webhookStore).Publish(context.Background(),
+		[]models.Event{newEvent(t, models.EventAlertFired)}))
+	delivery := webhookStore.deliveries[0]
+
+	before := time.Now()
+	_, err := worker.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, http.StatusInternalServerError, delivery.LastStatusCode)
+	assert.Equal(t, "unexpected status code 500", delivery.LastError)
+	assert.WithinDuration(t, before.Add(time.Minute), delivery.NextAttemptAt, 5*time.Second)
+
+	before = time.Now()
+	_, err = worker.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.WithinDuration(t, before.Add(2*time.Minute), delivery.NextAttemptAt, 5*time.Second)
+
+	_, err = worker.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	require.Len(t, delivery.AttemptLog, 3)
+	assert.Equal(t, []int{500, 502, 200}, []int{delivery.AttemptLog[0].StatusCode,
+		delivery.AttemptLog[1].StatusCode, delivery.AttemptLog[2].StatusCode})
+
+	// Every attempt carries the same event ID
+	requests := received()
+	require.Len(t, requests, 3)
+	for _, req := range requests {
+		assert.Equal(t, delivery.EventID, req.header.Get(webhooks.EventIDHeader))
+	}
+}
+
+func TestWorker_FailsAfterMaxAttempts(t *testing.T) {
+	server, received := newReceiver(t, http.StatusServiceUnavailable)
+	webhookStore := newMemoryWebhookStore(models.Webhook{ID: 1, URL: server.URL, Secret: "whsec_one", Enabled: true})
+	worker := webhooks.NewWorker(webhookStore, webhooks.WorkerConfig{MaxAttempts: 3})
+	require.NoError(t, webhooks.NewDispatcher(webhookStore).Publish(context.Background(),
+		[]models.Event{newEvent(t, models.EventSyncFailed)}))
+
+	for i := 0; i < 5; i++ {
+		_, err := worker.DeliverDue(context.Background())
+		require.NoError(t, err)
+	}
+
+	delivery := webhookStore.deliveries[0]
+	assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Len(t, received(), 3)
+}
+
+func TestWorker_RecordsConnectionErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	webhookStore := newMemoryWebhookStore(models.Webhook{ID: 1, URL: server.URL, Secret: "whsec_one", Enabled: true})
+	worker := webhooks.NewWorker(webhookStore, webhooks.WorkerConfig{})
+	require.NoError(t, webhooks.NewDispatcher(webhookStore).Publish(context.Background(),
+		[]models.Event{newEvent(t, models.EventSyncFailed)}))
+
+	_, err := worker.DeliverDue(context.Background())
+	require.NoError(t, err)
+
+	delivery := webhookStore.deliveries[0]
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Zero(t, delivery.LastStatusCode)
+	assert.NotEmpty(t, delivery.LastError)
+}
+
+func TestWorker_Backoff(t *testing.T) {
+	worker := webhooks.NewWorker(newMemoryWebhookStore(), webhooks.WorkerConfig{
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     5 * time.Minute,
+	})
+
+	assert.Equal(t, 30*time.Second, worker.Backoff(1))
+	assert.Equal(t, time.Minute, worker.Backoff(2))
+	assert.Equal(t, 4*time.Minute, worker.Backoff(4))
+	assert.Equal(t, 5*time.Minute, worker.Backoff(5))
+	assert.Equal(t, 5*time.Minute, worker.Backoff(50))
+}
+
+func TestWorker_TestFire(t *testing.T) {
+	server, received := newReceiver(t, http.StatusOK)
+	// Test deliveries ignore the event types and enabled flag of the webhook
+	webhookStore := newMemoryWebhookStore(models.Webhook{ID: 1, URL: server.URL, Secret: "whsec_one", Enabled: false,
+		EventTypes: []models.EventType{models.EventSyncFailed}})
+	worker := webhooks.NewWorker(webhookStore, webhooks.WorkerConfig{})
+
+	delivery, err := worker.TestFire(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, models.EventWebhookTest, delivery.EventType)
+	require.Len(t, delivery.AttemptLog, 1)
+	assert.Equal(t, http.StatusOK, delivery.AttemptLog[0].StatusCode)
+
+	requests := received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, string(models.EventWebhookTest), requests[0].header.Get(webhooks.EventHeader))
+
+	_, err = worker.TestFire(context.Background(), 2)
+	assert.ErrorIs(t, err, store.ErrWebhookNotFound)
+}